@@ -31,11 +31,53 @@ func ConnectDatabase() {
 }
 
 func migrate() {
+	// A empresa deixou de ser one-to-one com o utilizador: retirar a restrição única antiga
+	dropCompanyUserUnique()
+
 	// Primeiro, criar tabelas automaticamente baseadas nos models
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Company{},
 		&models.RegistrationRequest{},
+		&models.AssignmentRule{},
+		&models.RequestAssignmentHistory{},
+		&models.EmailTemplate{},
+		&models.OutboxEmail{},
+		&models.Notification{},
+		&models.Document{},
+		&models.ChecklistItem{},
+		&models.TaxObligation{},
+		&models.CalendarToken{},
+		&models.SAFTImport{},
+		&models.SAFTImportError{},
+		&models.SAFTSalesSummary{},
+		&models.EFaturaImport{},
+		&models.PurchaseInvoice{},
+		&models.BankStatementImport{},
+		&models.BankTransaction{},
+		&models.ReconciliationMatch{},
+		&models.SupplierMatchRule{},
+		&models.Account{},
+		&models.Journal{},
+		&models.FiscalYear{},
+		&models.FiscalPeriod{},
+		&models.FiscalPeriodHistory{},
+		&models.JournalEntry{},
+		&models.JournalEntryLine{},
+		&models.Customer{},
+		&models.InvoiceSeries{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
+		&models.AuditLog{},
+		&models.UserPermission{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.AppliedPermissionRollout{},
+		&models.CompanyAccountant{},
+		&models.UserCompany{},
+		&models.ClientImport{},
+		&models.ClientImportRow{},
+		&models.ClientInvitation{},
 	)
 	if err != nil {
 		fmt.Printf("❌ Erro na migração: %v\n", err)
@@ -50,10 +92,148 @@ func migrate() {
 		// Não retornar aqui para permitir que continue
 	}
 	
+	// Criar perfis base (admin, accountant, client) se não existirem
+	createDefaultRoles()
+	rolloutNewPermissions()
+
+	// Ligar cada empresa ao seu titular (dados do antigo one-to-one)
+	backfillUserCompanies()
+
+	// Carteiras iniciais a partir de quem reviu cada solicitação
+	backfillCompanyAccountants()
+
 	// Criar utilizador admin se não existir
 	createDefaultAdmin()
 }
 
+// createDefaultRoles cria os perfis em falta com as permissões por omissão.
+// Perfis já existentes não são alterados, para não desfazer edições feitas pelos admins.
+// O perfil de exemplo "junior" só é criado na primeira vez (tabela vazia).
+func createDefaultRoles() {
+	var existing int64
+	DB.Model(&models.Role{}).Count(&existing)
+
+	for _, def := range models.DefaultRoles {
+		if !def.System && existing > 0 {
+			continue
+		}
+
+		var role models.Role
+		if DB.Where("name = ?", def.Name).First(&role).Error == nil {
+			continue
+		}
+
+		role = models.Role{Name: def.Name, Description: def.Description, System: def.System}
+		for _, permission := range def.Permissions {
+			role.Permissions = append(role.Permissions, models.RolePermission{Permission: permission})
+		}
+		if err := DB.Create(&role).Error; err != nil {
+			fmt.Printf("❌ Erro ao criar perfil %s: %v\n", def.Name, err)
+		} else {
+			fmt.Printf("✅ Perfil %s criado\n", def.Name)
+		}
+	}
+}
+
+// dropCompanyUserUnique retira a restrição única de companies.user_id criada por versões
+// anteriores do GORM (companies_user_id_key); a atual (uni_companies_user_id) é retirada pelo AutoMigrate
+func dropCompanyUserUnique() {
+	if !DB.Migrator().HasTable(&models.Company{}) || !DB.Migrator().HasConstraint(&models.Company{}, "companies_user_id_key") {
+		return
+	}
+	if err := DB.Migrator().DropConstraint(&models.Company{}, "companies_user_id_key"); err != nil {
+		fmt.Printf("❌ Erro ao retirar a restrição única de companies.user_id: %v\n", err)
+	}
+}
+
+// backfillUserCompanies cria a ligação owner entre cada empresa sem utilizadores e o seu titular
+func backfillUserCompanies() {
+	var companies []models.Company
+	DB.Select("id", "user_id").
+		Where("id NOT IN (?)", DB.Model(&models.UserCompany{}).Select("company_id")).
+		Find(&companies)
+
+	created := 0
+	for _, company := range companies {
+		link := models.UserCompany{UserID: company.UserID, CompanyID: company.ID, Role: models.CompanyRoleOwner}
+		if err := DB.Create(&link).Error; err != nil {
+			fmt.Printf("❌ Erro ao ligar a empresa %d ao utilizador %d: %v\n", company.ID, company.UserID, err)
+			continue
+		}
+		created++
+	}
+	if created > 0 {
+		fmt.Printf("✅ Empresas ligadas aos titulares: %d\n", created)
+	}
+}
+
+// rolloutNewPermissions dá aos perfis base as permissões acrescentadas ao catálogo depois
+// de os perfis terem sido criados (ver models.PermissionRollout). Cada permissão é aplicada uma
+// só vez: fica registada em applied_permission_rollouts e, se um admin a retirar, não volta.
+// Quando algum perfil já a tem (instalação nova), só é registada.
+func rolloutNewPermissions() {
+	for permission, roles := range models.PermissionRollout {
+		var applied int64
+		DB.Model(&models.AppliedPermissionRollout{}).Where("permission = ?", permission).Count(&applied)
+		if applied > 0 {
+			continue
+		}
+
+		var count int64
+		DB.Model(&models.RolePermission{}).Where("permission = ?", permission).Count(&count)
+		if count == 0 {
+			for _, name := range roles {
+				var role models.Role
+				if DB.Where("name = ?", name).First(&role).Error != nil {
+					continue
+				}
+				if err := DB.Create(&models.RolePermission{RoleID: role.ID, Permission: permission}).Error; err != nil {
+					fmt.Printf("❌ Erro ao dar %s ao perfil %s: %v\n", permission, name, err)
+				}
+			}
+		}
+
+		if err := DB.Create(&models.AppliedPermissionRollout{Permission: permission}).Error; err != nil {
+			fmt.Printf("❌ Erro ao registar a atribuição de %s: %v\n", permission, err)
+		}
+	}
+}
+
+// backfillCompanyAccountants preenche as carteiras na primeira vez (tabela vazia): cada empresa
+// fica com o contabilista a quem a solicitação foi atribuída ou, na falta dele, com quem a reviu
+func backfillCompanyAccountants() {
+	var existing int64
+	DB.Model(&models.CompanyAccountant{}).Count(&existing)
+	if existing > 0 {
+		return
+	}
+
+	var requests []models.RegistrationRequest
+	DB.Where("company_id IS NOT NULL AND (assigned_to IS NOT NULL OR reviewed_by IS NOT NULL)").
+		Select("id", "company_id", "assigned_to", "reviewed_by").
+		Find(&requests)
+
+	created := 0
+	for _, request := range requests {
+		accountantID := request.AssignedTo
+		if accountantID == nil {
+			accountantID = request.ReviewedBy
+		}
+		var count int64
+		DB.Model(&models.User{}).Where("id = ? AND role <> ?", *accountantID, models.RoleClient).Count(&count)
+		if count == 0 {
+			continue
+		}
+		link := models.CompanyAccountant{CompanyID: *request.CompanyID, AccountantID: *accountantID, AssignedBy: *accountantID}
+		if DB.Where("company_id = ? AND accountant_id = ?", link.CompanyID, link.AccountantID).FirstOrCreate(&link).Error == nil {
+			created++
+		}
+	}
+	if created > 0 {
+		fmt.Printf("✅ Carteiras preenchidas: %d empresas atribuídas\n", created)
+	}
+}
+
 func createDefaultAdmin() {
 	var adminUser models.User
 	if err := DB.Where("role = ? AND username = ?", "admin", "admin").First(&adminUser).Error; err != nil {
@@ -0,0 +1,701 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	accountingService   = services.NewAccountingService()
+	fiscalPeriodService = services.NewFiscalPeriodService()
+)
+
+// GetCompanyAccounts godoc
+// @Summary      Plano de contas
+// @Description  Lista o plano de contas SNC da empresa (criado automaticamente na primeira utilização)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int   true   "ID da empresa"
+// @Param        class     query     int   false  "Classe SNC (1 a 8)"
+// @Param        postable  query     bool  false  "Apenas contas de movimento"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/companies/{id}/accounts [get]
+func GetCompanyAccounts(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	class, _ := strconv.Atoi(c.Query("class"))
+	accounts, err := accountingService.GetAccounts(companyID, class, c.Query("postable") == "true")
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Plano de contas obtido com sucesso",
+		Data:    accounts,
+	})
+}
+
+// CreateCompanyAccount godoc
+// @Summary      Criar subconta
+// @Description  Cria uma subconta no plano de contas da empresa; a conta mãe não pode ter lançamentos
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                      true  "ID da empresa"
+// @Param        request  body      models.CreateAccountDTO  true  "Conta"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/accounts [post]
+func CreateCompanyAccount(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAccountDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	account, err := accountingService.CreateAccount(companyID, req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Conta criada com sucesso",
+		Data:    account,
+	})
+}
+
+// UpdateCompanyAccount godoc
+// @Summary      Atualizar conta
+// @Description  Altera o nome de uma conta ou ativa/desativa-a
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int                      true  "ID da empresa"
+// @Param        accountId  path      int                      true  "ID da conta"
+// @Param        request    body      models.UpdateAccountDTO  true  "Alterações"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/accounts/{accountId} [put]
+func UpdateCompanyAccount(c *gin.Context) {
+	companyID, accountID, ok := parseCompanySubID(c, "accountId", "ID da conta inválido")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAccountDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	account, err := accountingService.UpdateAccount(companyID, accountID, req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Conta atualizada com sucesso",
+		Data:    account,
+	})
+}
+
+// GetCompanyJournals godoc
+// @Summary      Diários
+// @Description  Lista os diários da empresa (vendas, compras, bancos, operações diversas)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da empresa"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/companies/{id}/journals [get]
+func GetCompanyJournals(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	journals, err := accountingService.GetJournals(companyID)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Diários obtidos com sucesso",
+		Data:    journals,
+	})
+}
+
+// CreateCompanyJournal godoc
+// @Summary      Criar diário
+// @Description  Cria um diário adicional para a empresa
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                      true  "ID da empresa"
+// @Param        request  body      models.CreateJournalDTO  true  "Diário"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/journals [post]
+func CreateCompanyJournal(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateJournalDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	journal, err := accountingService.CreateJournal(companyID, req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Diário criado com sucesso",
+		Data:    journal,
+	})
+}
+
+// GetCompanyFiscalYears godoc
+// @Summary      Exercícios
+// @Description  Lista os exercícios da empresa com os períodos mensais e o respetivo estado
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da empresa"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/companies/{id}/fiscal-years [get]
+func GetCompanyFiscalYears(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	years, err := accountingService.GetFiscalYears(companyID)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Exercícios obtidos com sucesso",
+		Data:    years,
+	})
+}
+
+// CreateCompanyFiscalYear godoc
+// @Summary      Abrir exercício
+// @Description  Abre um exercício com 12 períodos mensais
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                         true  "ID da empresa"
+// @Param        request  body      models.CreateFiscalYearDTO  true  "Exercício"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/fiscal-years [post]
+func CreateCompanyFiscalYear(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateFiscalYearDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	year, err := accountingService.CreateFiscalYear(companyID, req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Exercício aberto com sucesso",
+		Data:    year,
+	})
+}
+
+// CloseCompanyFiscalPeriod godoc
+// @Summary      Fechar período
+// @Description  Fecha um período: soft_closed bloqueia alterações dos clientes, hard_closed bloqueia alterações de todos
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int                          true  "ID da empresa"
+// @Param        periodId  path      int                          true  "ID do período"
+// @Param        request   body      models.CloseFiscalPeriodDTO  true  "Estado"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/fiscal-periods/{periodId}/close [post]
+func CloseCompanyFiscalPeriod(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, periodID, ok := parseCompanySubID(c, "periodId", "ID do período inválido")
+	if !ok {
+		return
+	}
+
+	var req models.CloseFiscalPeriodDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	period, err := fiscalPeriodService.ClosePeriod(companyID, periodID, userID.(uint), req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Período fechado com sucesso",
+		Data:    period,
+	})
+}
+
+// CloseCompanyFiscalYear godoc
+// @Summary      Fechar exercício
+// @Description  Fecha todos os períodos do exercício com o estado indicado
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                          true  "ID da empresa"
+// @Param        yearId   path      int                          true  "ID do exercício"
+// @Param        request  body      models.CloseFiscalPeriodDTO  true  "Estado"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/fiscal-years/{yearId}/close [post]
+func CloseCompanyFiscalYear(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, yearID, ok := parseCompanySubID(c, "yearId", "ID do exercício inválido")
+	if !ok {
+		return
+	}
+
+	var req models.CloseFiscalPeriodDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	year, err := fiscalPeriodService.CloseFiscalYear(companyID, yearID, userID.(uint), req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Exercício fechado com sucesso",
+		Data:    year,
+	})
+}
+
+// ReopenCompanyFiscalPeriod godoc
+// @Summary      Reabrir período
+// @Description  Reabre um período fechado (apenas admin); o motivo fica registado no histórico do período
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int                           true  "ID da empresa"
+// @Param        periodId  path      int                           true  "ID do período"
+// @Param        request   body      models.ReopenFiscalPeriodDTO  true  "Motivo e novo estado"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/fiscal-periods/{periodId}/reopen [post]
+func ReopenCompanyFiscalPeriod(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, periodID, ok := parseCompanySubID(c, "periodId", "ID do período inválido")
+	if !ok {
+		return
+	}
+
+	var req models.ReopenFiscalPeriodDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	period, err := fiscalPeriodService.ReopenPeriod(companyID, periodID, userID.(uint), req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Período reaberto com sucesso",
+		Data:    period,
+	})
+}
+
+// GetCompanyFiscalPeriodHistory godoc
+// @Summary      Histórico do período
+// @Description  Lista os fechos e reaberturas do período, com o utilizador e o motivo
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int  true  "ID da empresa"
+// @Param        periodId  path      int  true  "ID do período"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/fiscal-periods/{periodId}/history [get]
+func GetCompanyFiscalPeriodHistory(c *gin.Context) {
+	companyID, periodID, ok := parseCompanySubID(c, "periodId", "ID do período inválido")
+	if !ok {
+		return
+	}
+
+	history, err := fiscalPeriodService.GetPeriodHistory(companyID, periodID)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Histórico obtido com sucesso",
+		Data:    history,
+	})
+}
+
+// GetCompanyJournalEntries godoc
+// @Summary      Lançamentos
+// @Description  Lista os lançamentos da empresa com as linhas
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int     true   "ID da empresa"
+// @Param        journal  query     string  false  "Código do diário"
+// @Param        from     query     string  false  "Data inicial (AAAA-MM-DD)"
+// @Param        to       query     string  false  "Data final (AAAA-MM-DD)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/companies/{id}/journal-entries [get]
+func GetCompanyJournalEntries(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	entries, err := accountingService.GetEntries(companyID, c.Query("journal"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Lançamentos obtidos com sucesso",
+		Data:    entries,
+	})
+}
+
+// CreateCompanyJournalEntry godoc
+// @Summary      Registar lançamento
+// @Description  Regista um lançamento num período aberto; o total a débito tem de igualar o total a crédito
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                           true  "ID da empresa"
+// @Param        request  body      models.CreateJournalEntryDTO  true  "Lançamento"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/journal-entries [post]
+func CreateCompanyJournalEntry(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateJournalEntryDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	entry, err := accountingService.CreateEntry(companyID, userID.(uint), userRole.(string), req)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Lançamento registado com sucesso",
+		Data:    entry,
+	})
+}
+
+// GetCompanyJournalEntry godoc
+// @Summary      Detalhe de lançamento
+// @Description  Devolve um lançamento com as linhas e as contas
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int  true  "ID da empresa"
+// @Param        entryId  path      int  true  "ID do lançamento"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/journal-entries/{entryId} [get]
+func GetCompanyJournalEntry(c *gin.Context) {
+	companyID, entryID, ok := parseCompanySubID(c, "entryId", "ID do lançamento inválido")
+	if !ok {
+		return
+	}
+
+	entry, err := accountingService.GetEntry(companyID, entryID)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Lançamento obtido com sucesso",
+		Data:    entry,
+	})
+}
+
+// DeleteCompanyJournalEntry godoc
+// @Summary      Anular lançamento
+// @Description  Anula o último lançamento de um diário num período que não esteja fechado definitivamente (os restantes corrigem-se com estorno)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int  true  "ID da empresa"
+// @Param        entryId  path      int  true  "ID do lançamento"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/journal-entries/{entryId} [delete]
+func DeleteCompanyJournalEntry(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+
+	companyID, entryID, ok := parseCompanySubID(c, "entryId", "ID do lançamento inválido")
+	if !ok {
+		return
+	}
+
+	if err := accountingService.DeleteEntry(companyID, entryID, userRole.(string)); err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Lançamento anulado com sucesso",
+	})
+}
+
+// GetCompanyTrialBalance godoc
+// @Summary      Balancete
+// @Description  Balancete do exercício entre os períodos indicados, com contas de movimento e contas mãe agregadas
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      int  true   "ID da empresa"
+// @Param        fiscal_year  query     int  false  "Exercício (por omissão o ano corrente)"
+// @Param        period_from  query     int  false  "Período inicial (1 a 12)"
+// @Param        period_to    query     int  false  "Período final (1 a 12)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/trial-balance [get]
+func GetCompanyTrialBalance(c *gin.Context) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return
+	}
+
+	fiscalYear, _ := strconv.Atoi(c.Query("fiscal_year"))
+	periodFrom, _ := strconv.Atoi(c.Query("period_from"))
+	periodTo, _ := strconv.Atoi(c.Query("period_to"))
+
+	balance, err := accountingService.GetTrialBalance(companyID, fiscalYear, periodFrom, periodTo)
+	if err != nil {
+		c.JSON(accountingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Balancete obtido com sucesso",
+		Data:    balance,
+	})
+}
+
+func parseCompanyID(c *gin.Context) (uint, bool) {
+	companyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da empresa inválido",
+		})
+		return 0, false
+	}
+	return uint(companyID), true
+}
+
+func parseCompanySubID(c *gin.Context, param, invalidMsg string) (uint, uint, bool) {
+	companyID, ok := parseCompanyID(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   invalidMsg,
+		})
+		return 0, 0, false
+	}
+	return companyID, uint(id), true
+}
+
+func accountingErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "linha "):
+		return http.StatusBadRequest
+	case strings.HasSuffix(msg, "não encontrada") || strings.HasSuffix(msg, "não encontrado"):
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "já existe") || strings.Contains(msg, "está fechado") ||
+		strings.HasPrefix(msg, "o exercício sobrepõe-se") ||
+		strings.Contains(msg, "não pode ser reaberto") || strings.HasPrefix(msg, "só é possível anular") ||
+		strings.Contains(msg, "já tem lançamentos"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"RVContabilidadeBack/middlewares"
 	"RVContabilidadeBack/models"
 	"RVContabilidadeBack/services"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,15 +17,16 @@ var (
 
 // GetPendingRequests godoc
 // @Summary      Listar solicitações pendentes
-// @Description  Lista todas as solicitações de registo pendentes (apenas contabilistas/admin)
+// @Description  Lista as solicitações de registo pendentes; sem clients.all, só as atribuídas ao contabilista ou de empresas da carteira
 // @Tags         admin
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        verified  query     string  false  "Filtrar por email verificado (true/false)"
 // @Success      200  {object}  models.SuccessResponse
 // @Router       /admin/pending-requests [get]
 func GetPendingRequests(c *gin.Context) {
-	requests, err := adminService.GetPendingRequests()
+	requests, err := adminService.GetPendingRequests(c.Query("verified"), portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -41,7 +44,7 @@ func GetPendingRequests(c *gin.Context) {
 
 // ApproveRequest godoc
 // @Summary      Aprovar/rejeitar solicitação
-// @Description  Aprova ou rejeita uma solicitação de registo (apenas contabilistas/admin)
+// @Description  Aprova ou rejeita uma solicitação de registo (apenas contabilistas/admin). Aprovar ou pedir informação exige o email da solicitação verificado.
 // @Tags         admin
 // @Accept       json
 // @Produce      json
@@ -81,9 +84,13 @@ func ApproveRequest(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "solicitação não encontrada" {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "solicitação já foi processada" {
+		} else if err.Error() == "solicitação já foi processada" ||
+			err.Error() == "indique a informação em falta nas notas" ||
+			err.Error() == "o email da solicitação ainda não foi verificado" {
 			statusCode = http.StatusBadRequest
-		} else if err.Error() == "já existe uma empresa com este NIPC" {
+		} else if err.Error() == "já existe uma empresa com este NIPC" ||
+			err.Error() == "username já está em uso" ||
+			err.Error() == "solicitação atribuída a outro contabilista" {
 			statusCode = http.StatusConflict
 		}
 		
@@ -97,6 +104,8 @@ func ApproveRequest(c *gin.Context) {
 	message := "Solicitação aprovada com sucesso"
 	if req.Status == "rejected" {
 		message = "Solicitação rejeitada"
+	} else if req.Status == "needs_info" {
+		message = "Pedido de informação enviado ao cliente"
 	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
@@ -108,7 +117,7 @@ func ApproveRequest(c *gin.Context) {
 
 // GetAllRequests godoc
 // @Summary      Listar pedidos de registo pendentes
-// @Description  Lista todos os pedidos de registo pendentes para aprovação
+// @Description  Lista os pedidos de registo; sem clients.all, só os atribuídos ao contabilista ou de empresas da carteira
 // @Tags         admin
 // @Accept       json
 // @Produce      json
@@ -116,7 +125,7 @@ func ApproveRequest(c *gin.Context) {
 // @Success      200  {object}  models.SuccessResponse
 // @Router       /admin/requests [get]
 func GetAllRequests(c *gin.Context) {
-	requests, err := adminService.GetAllRequests()
+	requests, err := adminService.GetAllRequests(portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -125,6 +134,12 @@ func GetAllRequests(c *gin.Context) {
 		return
 	}
 
+	if !canRevealCredentials(c) {
+		for i := range requests {
+			services.MaskRequestCredentials(&requests[i])
+		}
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "Lista de pedidos de registo obtida com sucesso",
@@ -134,7 +149,7 @@ func GetAllRequests(c *gin.Context) {
 
 // GetRequestDetails godoc
 // @Summary      Detalhes de um pedido de registo
-// @Description  Obtém detalhes completos de um pedido de registo específico
+// @Description  Obtém detalhes completos de um pedido de registo específico (404 se estiver fora da carteira)
 // @Tags         admin
 // @Accept       json
 // @Produce      json
@@ -152,7 +167,7 @@ func GetRequestDetails(c *gin.Context) {
 		return
 	}
 
-	request, err := adminService.GetRequestDetails(uint(requestID))
+	request, err := adminService.GetRequestDetails(uint(requestID), portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Success: false,
@@ -161,6 +176,10 @@ func GetRequestDetails(c *gin.Context) {
 		return
 	}
 
+	if !canRevealCredentials(c) {
+		services.MaskRequestCredentials(request)
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "Detalhes do pedido de registo obtidos com sucesso",
@@ -170,7 +189,7 @@ func GetRequestDetails(c *gin.Context) {
 
 // UpdateUserStatus godoc
 // @Summary      Alterar status de utilizador
-// @Description  Bloqueia/desbloqueia utilizador (apenas admin)
+// @Description  Bloqueia/desbloqueia utilizador (requer users.manage)
 // @Tags         admin
 // @Accept       json
 // @Produce      json
@@ -180,15 +199,6 @@ func GetRequestDetails(c *gin.Context) {
 // @Success      200     {object}  models.SuccessResponse
 // @Router       /admin/users/{id}/status [put]
 func UpdateUserStatus(c *gin.Context) {
-	userRole, _ := c.Get("user_role")
-	if userRole != "admin" {
-		c.JSON(http.StatusForbidden, models.ErrorResponse{
-			Success: false,
-			Error:   "Apenas admins podem alterar status",
-		})
-		return
-	}
-
 	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -207,7 +217,7 @@ func UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
-	user, err := adminService.UpdateUserStatus(uint(userID), req.Status)
+	user, err := adminService.UpdateUserStatus(uint(userID), req.Status, req.Notes)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "utilizador não encontrado" {
@@ -241,7 +251,7 @@ func GetAllUsers(c *gin.Context) {
 	status := c.Query("status")
 	role := c.Query("role")
 	
-	users, stats, err := adminService.GetAllUsers(status, role)
+	users, stats, err := adminService.GetAllUsers(status, role, portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -250,6 +260,12 @@ func GetAllUsers(c *gin.Context) {
 		return
 	}
 
+	if !canRevealCredentials(c) {
+		for i := range users {
+			services.MaskUserCredentials(&users[i])
+		}
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "Utilizadores obtidos com sucesso",
@@ -261,6 +277,56 @@ func GetAllUsers(c *gin.Context) {
 	})
 }
 
+// CreateUser godoc
+// @Summary      Criar utilizador
+// @Description  Cria diretamente um utilizador já aprovado. Contabilistas só podem criar clientes; admins podem criar clientes, contabilistas e admins. A criação fica no registo de auditoria.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user  body      models.CreateUserDTO  true  "Dados do utilizador"
+// @Success      201   {object}  models.SuccessResponse
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      403   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /admin/users [post]
+func CreateUser(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	var req models.CreateUserDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	user, err := adminService.CreateUser(req, userID.(uint), userRole.(string), c.ClientIP())
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case strings.HasPrefix(err.Error(), "sem permissão"):
+			statusCode = http.StatusForbidden
+		case strings.HasSuffix(err.Error(), "já está em uso"):
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Utilizador criado com sucesso",
+		Data:    user,
+	})
+}
+
 // GetUserDetails godoc
 // @Summary      Detalhes de um utilizador
 // @Description  Obtém detalhes completos de um utilizador
@@ -290,6 +356,10 @@ func GetUserDetails(c *gin.Context) {
 		return
 	}
 
+	if !canRevealCredentials(c) {
+		services.MaskUserCredentials(user)
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "Detalhes do utilizador obtidos com sucesso",
@@ -299,15 +369,16 @@ func GetUserDetails(c *gin.Context) {
 
 // GetApprovedClients godoc
 // @Summary      Listar clientes aprovados
-// @Description  Lista todos os clientes com status aprovado (apenas contabilista/admin)
+// @Description  Lista os clientes com status aprovado. Sem a permissão clients.all, só os da carteira do utilizador
 // @Tags         admin
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        search  query     string  false  "Pesquisa por nome, email, username, NIF, empresa ou NIPC"
 // @Success      200  {object}  models.SuccessResponse
 // @Router       /admin/clients [get]
 func GetApprovedClients(c *gin.Context) {
-	clients, err := adminService.GetApprovedClients()
+	clients, err := adminService.GetApprovedClients(c.Query("search"), portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -316,6 +387,12 @@ func GetApprovedClients(c *gin.Context) {
 		return
 	}
 
+	if !canRevealCredentials(c) {
+		for i := range clients {
+			services.MaskUserCredentials(&clients[i])
+		}
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "Clientes aprovados obtidos com sucesso",
@@ -356,7 +433,9 @@ func UpdateClientData(c *gin.Context) {
 		return
 	}
 
-	err = adminService.UpdateClientData(uint(clientID), req)
+	editorID, _ := c.Get("user_id")
+
+	err = adminService.UpdateClientData(uint(clientID), req, editorID.(uint))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "cliente aprovado não encontrado" {
@@ -379,16 +458,19 @@ func UpdateClientData(c *gin.Context) {
 
 // AdminUpdateClientCompany godoc
 // @Summary      Atualizar empresa do cliente
-// @Description  Atualiza dados da empresa de um cliente (apenas contabilista/admin)
+// @Description  Atualiza dados de uma empresa do cliente; sem company_id, a empresa principal (apenas contabilista/admin)
 // @Tags         admin
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        id       path      int                           true  "ID do cliente"
-// @Param        request  body      models.AdminUpdateCompanyDTO true  "Dados da empresa para atualizar"
+// @Param        id          path      int                           true   "ID do cliente"
+// @Param        company_id  query     int                           false  "ID da empresa (o cliente pode ter várias)"
+// @Param        request     body      models.AdminUpdateCompanyDTO  true   "Dados da empresa para atualizar"
 // @Success      200      {object}  models.SuccessResponse
 // @Router       /admin/clients/{id}/company [put]
 func AdminUpdateClientCompany(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+
 	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -398,6 +480,17 @@ func AdminUpdateClientCompany(c *gin.Context) {
 		return
 	}
 
+	var companyID uint64
+	if value := c.Query("company_id"); value != "" {
+		if companyID, err = strconv.ParseUint(value, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Error:   "ID da empresa inválido",
+			})
+			return
+		}
+	}
+
 	var req models.AdminUpdateCompanyDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -407,12 +500,14 @@ func AdminUpdateClientCompany(c *gin.Context) {
 		return
 	}
 
-	company, err := adminService.UpdateClientCompany(uint(clientID), req)
+	company, err := adminService.UpdateClientCompany(uint(clientID), uint(companyID), req, userRole.(string))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "cliente aprovado não encontrado" ||
 		   err.Error() == "empresa do cliente não encontrada" {
 			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "está fechado") {
+			statusCode = http.StatusConflict
 		}
 		
 		c.JSON(statusCode, models.ErrorResponse{
@@ -431,15 +526,18 @@ func AdminUpdateClientCompany(c *gin.Context) {
 
 // DeleteClient godoc
 // @Summary      Eliminar cliente
-// @Description  Elimina um cliente e a sua empresa (apenas contabilista/admin)
+// @Description  Elimina um cliente. As empresas em que é o único utilizador ligado são eliminadas com todos os dados (documentos, extratos, ...); nas outras só lhe é retirado o acesso. Se alguma dessas empresas tiver faturas emitidas, lançamentos ou períodos fechados, nada é eliminado (409) e o cliente deve ser bloqueado. Fica registado na auditoria.
 // @Tags         admin
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "ID do cliente"
 // @Success      200  {object}  models.SuccessResponse
+// @Failure      409  {object}  models.ErrorResponse
 // @Router       /admin/clients/{id} [delete]
 func DeleteClient(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
 	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -449,11 +547,13 @@ func DeleteClient(c *gin.Context) {
 		return
 	}
 
-	err = adminService.DeleteClient(uint(clientID))
+	err = adminService.DeleteClient(uint(clientID), userID.(uint), c.ClientIP())
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "cliente não encontrado" {
 			statusCode = http.StatusNotFound
+		} else if strings.HasPrefix(err.Error(), "o cliente não pode ser eliminado") {
+			statusCode = http.StatusConflict
 		}
 		
 		c.JSON(statusCode, models.ErrorResponse{
@@ -506,7 +606,7 @@ func GetUsersCount(c *gin.Context) {
 // @Success      200  {object}  models.SuccessResponse
 // @Router       /admin/users/simple [get]
 func GetAllUsersSimple(c *gin.Context) {
-	users, err := adminService.GetAllUsersSimple()
+	users, err := adminService.GetAllUsersSimple(portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -515,6 +615,12 @@ func GetAllUsersSimple(c *gin.Context) {
 		return
 	}
 
+	if !canRevealCredentials(c) {
+		for i := range users {
+			services.MaskUserCredentials(&users[i])
+		}
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "Utilizadores obtidos com sucesso",
@@ -535,7 +641,7 @@ func GetAllUsersSimple(c *gin.Context) {
 // @Success      200  {object}  models.SuccessResponse
 // @Router       /admin/dashboard [get]
 func GetDashboardData(c *gin.Context) {
-	dashboardData, err := adminService.GetDashboardData()
+	dashboardData, err := adminService.GetDashboardData(portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -561,7 +667,7 @@ func GetDashboardData(c *gin.Context) {
 // @Success      200  {object}  models.SuccessResponse
 // @Router       /admin/clients/overview [get]
 func GetAllClientsOverview(c *gin.Context) {
-	overview, err := adminService.GetAllClientsOverview()
+	overview, err := adminService.GetAllClientsOverview(portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -587,7 +693,7 @@ func GetAllClientsOverview(c *gin.Context) {
 // @Success      200  {object}  models.SuccessResponse
 // @Router       /admin/complete-users-overview [get]
 func GetCompleteUsersOverview(c *gin.Context) {
-	overview, err := adminService.GetCompleteUsersOverview()
+	overview, err := adminService.GetCompleteUsersOverview(portfolioScope(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -596,9 +702,27 @@ func GetCompleteUsersOverview(c *gin.Context) {
 		return
 	}
 
+	if !canRevealCredentials(c) {
+		for i := range overview {
+			services.MaskOverviewCredentials(&overview[i])
+		}
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "Visão completa dos usuários obtida com sucesso",
 		Data:    overview,
 	})
 }
+
+// canRevealCredentials indica se o utilizador pode ver IBAN, cartão de cidadão e email oficial sem máscara
+func canRevealCredentials(c *gin.Context) bool {
+	return middlewares.HasPermission(c, models.PermissionCredentialsReveal)
+}
+
+// portfolioScope limita as listagens à carteira do utilizador, exceto se tiver clients.all
+func portfolioScope(c *gin.Context) *services.PortfolioScope {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uint)
+	return portfolioService.ScopeFor(id, middlewares.HasPermission(c, models.PermissionClientsAll))
+}
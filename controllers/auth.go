@@ -9,7 +9,9 @@ import (
 )
 
 var (
-authService = services.NewAuthService()
+authService              = services.NewAuthService()
+emailVerificationService = services.NewEmailVerificationService()
+activationService        = services.NewActivationService()
 )
 
 // RegisterClient godoc
@@ -38,7 +40,11 @@ statusCode := http.StatusInternalServerError
 if err.Error() == "já existe uma solicitação pendente com este NIF" ||
    err.Error() == "já existe uma solicitação pendente com este email" ||
    err.Error() == "já existe uma conta aprovada com este NIF" ||
-   err.Error() == "já existe uma conta aprovada com este email" {
+   err.Error() == "já existe uma conta aprovada com este email" ||
+   err.Error() == "username já está em uso" ||
+   err.Error() == "já existe uma solicitação pendente com este username" ||
+   err.Error() == "já existe uma empresa com este NIPC" ||
+   err.Error() == "já existe uma solicitação pendente com este NIPC" {
 statusCode = http.StatusConflict
 }
 
@@ -56,45 +62,6 @@ Data:    registrationRequest,
 })
 }
 
-// Register godoc (mantido para compatibilidade)
-// @Summary      Criar conta diretamente
-// @Description  Regista novo utilizador diretamente (para uso interno)
-// @Tags         auth
-// @Accept       json
-// @Produce      json
-// @Param        user  body      models.RegisterRequest  true  "Dados de registo"
-// @Success      201   {object}  models.AuthResponse
-// @Router       /auth/register-direct [post]
-func Register(c *gin.Context) {
-var req models.RegisterRequest
-
-if err := c.ShouldBindJSON(&req); err != nil {
-c.JSON(http.StatusBadRequest, models.ErrorResponse{
-Success: false,
-Error:   err.Error(),
-})
-return
-}
-
-response, err := authService.Register(req)
-if err != nil {
-statusCode := http.StatusInternalServerError
-if err.Error() == "username já está em uso" ||
-   err.Error() == "email já está em uso" ||
-   err.Error() == "NIF já está em uso" {
-statusCode = http.StatusConflict
-}
-
-c.JSON(statusCode, models.ErrorResponse{
-Success: false,
-Error:   err.Error(),
-})
-return
-}
-
-c.JSON(http.StatusCreated, response)
-}
-
 // Login godoc
 // @Summary      Entrar
 // @Description  Login com username e password
@@ -147,3 +114,149 @@ c.JSON(http.StatusOK, gin.H{
 "message": "Logout realizado com sucesso",
 })
 }
+
+// VerifyEmail godoc
+// @Summary      Verificar email
+// @Description  Confirma o email de uma solicitação de registo através do link enviado
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token  query     string  true  "Token de verificação"
+// @Success      200    {object}  models.SuccessResponse
+// @Router       /auth/verify-email [get]
+func VerifyEmail(c *gin.Context) {
+	request, err := emailVerificationService.VerifyEmail(c.Query("token"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "token de verificação inválido" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "token de verificação expirado" {
+			statusCode = http.StatusGone
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Email verificado com sucesso",
+		Data: gin.H{
+			"request_id":        request.ID,
+			"email_verified_at": request.EmailVerifiedAt,
+		},
+	})
+}
+
+// ResendVerification godoc
+// @Summary      Reenviar verificação de email
+// @Description  Reenvia o link de verificação para uma solicitação pendente. A resposta é sempre a mesma, exista ou não a solicitação, e o link só é reenviado 5 minutos depois do anterior.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ResendVerificationDTO  true  "Email da solicitação"
+// @Success      200      {object}  models.SuccessResponse
+// @Router       /auth/resend-verification [post]
+func ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	emailVerificationService.ResendVerification(req.Email)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Se existir uma solicitação pendente por verificar com este email, foi enviado um novo link de verificação",
+	})
+}
+
+// CheckActivation godoc
+// @Summary      Validar link de ativação
+// @Description  Confirma que o token de ativação de uma conta importada é válido antes de pedir a password
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token  query     string  true  "Token de ativação"
+// @Success      200    {object}  models.SuccessResponse
+// @Failure      404    {object}  models.ErrorResponse
+// @Failure      410    {object}  models.ErrorResponse
+// @Router       /auth/activate [get]
+func CheckActivation(c *gin.Context) {
+	user, err := activationService.CheckToken(c.Query("token"))
+	if err != nil {
+		c.JSON(activationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Link de ativação válido",
+		Data: gin.H{
+			"username": user.Username,
+			"name":     user.Name,
+		},
+	})
+}
+
+// ActivateAccount godoc
+// @Summary      Ativar conta
+// @Description  Define a password de uma conta criada por importação através do token enviado por email
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ActivateAccountDTO  true  "Token e nova password"
+// @Success      200      {object}  models.SuccessResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      410      {object}  models.ErrorResponse
+// @Router       /auth/activate [post]
+func ActivateAccount(c *gin.Context) {
+	var req models.ActivateAccountDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := activationService.Activate(req.Token, req.Password)
+	if err != nil {
+		c.JSON(activationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Conta ativada com sucesso",
+		Data: gin.H{
+			"username":     user.Username,
+			"activated_at": user.ActivatedAt,
+		},
+	})
+}
+
+func activationErrorStatus(err error) int {
+	switch err.Error() {
+	case "token de ativação inválido", "utilizador não encontrado":
+		return http.StatusNotFound
+	case "token de ativação expirado":
+		return http.StatusGone
+	case "conta já ativada", "o utilizador não tem ativação pendente", "utilizador sem email":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
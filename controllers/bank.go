@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	bankService = services.NewBankService()
+)
+
+// ImportClientBankStatement godoc
+// @Summary      Importar extrato bancário
+// @Description  Importa um extrato (CAMT.053, OFX ou CSV dos bancos portugueses) para o livro bancário da empresa do cliente; movimentos com a mesma referência do banco são ignorados
+// @Tags         admin
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int     true   "ID do cliente"
+// @Param        file    formData  file    true   "Extrato"
+// @Param        format  formData  string  false  "Formato (camt053, ofx, csv, csv_cgd, csv_millennium, csv_novobanco, csv_santander, csv_bpi, csv_credito_agricola); por omissão é detetado"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/bank-statements [post]
+func ImportClientBankStatement(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Ficheiro em falta (campo file)",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	statementImport, err := bankService.ImportStatement(uint(clientID), companyID, userID.(uint), fileHeader, c.PostForm("format"), userRole.(string))
+	if err != nil {
+		c.JSON(bankErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		services.MaskBankStatementCredentials(statementImport)
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Extrato importado com sucesso",
+		Data:    statementImport,
+	})
+}
+
+// GetClientBankStatements godoc
+// @Summary      Extratos importados
+// @Description  Lista os extratos bancários importados para a empresa do cliente
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do cliente"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/bank-statements [get]
+func GetClientBankStatements(c *gin.Context) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	imports, err := bankService.GetImports(uint(clientID), companyID)
+	if err != nil {
+		c.JSON(bankErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		for i := range imports {
+			services.MaskBankStatementCredentials(&imports[i])
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Extratos obtidos com sucesso",
+		Data:    imports,
+	})
+}
+
+// GetClientBankTransactions godoc
+// @Summary      Livro bancário
+// @Description  Lista os movimentos bancários da empresa do cliente
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      int     true   "ID do cliente"
+// @Param        match_status  query     string  false  "Filtrar por estado (unmatched, matched)"
+// @Param        from          query     string  false  "Data inicial (AAAA-MM-DD)"
+// @Param        to            query     string  false  "Data final (AAAA-MM-DD)"
+// @Param        limit         query     int     false  "Máximo de resultados (por omissão 100)"
+// @Param        offset        query     int     false  "Deslocamento"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/bank-transactions [get]
+func GetClientBankTransactions(c *gin.Context) {
+	listBankTransactions(c, c.Query("match_status"))
+}
+
+// GetClientUnmatchedTransactions godoc
+// @Summary      Movimentos por conciliar
+// @Description  Lista os movimentos bancários da empresa do cliente que ainda não foram associados a uma fatura
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int     true   "ID do cliente"
+// @Param        from    query     string  false  "Data inicial (AAAA-MM-DD)"
+// @Param        to      query     string  false  "Data final (AAAA-MM-DD)"
+// @Param        limit   query     int     false  "Máximo de resultados (por omissão 100)"
+// @Param        offset  query     int     false  "Deslocamento"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/bank-transactions/unmatched [get]
+func GetClientUnmatchedTransactions(c *gin.Context) {
+	listBankTransactions(c, models.BankTransactionUnmatched)
+}
+
+func listBankTransactions(c *gin.Context, matchStatus string) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := bankService.GetTransactions(uint(clientID), companyID, matchStatus, c.Query("from"), c.Query("to"), limit, offset)
+	if err != nil {
+		c.JSON(bankErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		for i := range result.Transactions {
+			services.MaskBankTransactionCredentials(&result.Transactions[i])
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Movimentos obtidos com sucesso",
+		Data:    result,
+	})
+}
+
+func bankErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "empresa não encontrada":
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "ficheiro excede"):
+		return http.StatusRequestEntityTooLarge
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	// Restantes erros são de formato ou conteúdo do extrato
+	return http.StatusBadRequest
+}
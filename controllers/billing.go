@@ -0,0 +1,475 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	billingService = services.NewBillingService()
+)
+
+// GetMyBillingCustomers godoc
+// @Summary      Listar clientes de faturação
+// @Description  Lista os clientes a quem a empresa emite faturas, com pesquisa por nome ou NIF
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        search  query     string  false  "Nome ou NIF"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Router       /client/billing/customers [get]
+func GetMyBillingCustomers(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	customers, err := billingService.GetCustomers(userID.(uint), companyID, c.Query("search"))
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Clientes obtidos com sucesso",
+		Data:    customers,
+	})
+}
+
+// CreateMyBillingCustomer godoc
+// @Summary      Criar cliente de faturação
+// @Description  Regista um cliente; os NIF portugueses são validados pelo dígito de controlo
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        customer  body      models.CustomerDTO  true  "Dados do cliente"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /client/billing/customers [post]
+func CreateMyBillingCustomer(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CustomerDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	customer, err := billingService.CreateCustomer(userID.(uint), companyID, req)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Cliente criado com sucesso",
+		Data:    customer,
+	})
+}
+
+// UpdateMyBillingCustomer godoc
+// @Summary      Atualizar cliente de faturação
+// @Description  Atualiza os dados de um cliente; o NIF não pode ser alterado depois de emitidos documentos
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int                 true  "ID do cliente de faturação"
+// @Param        customer  body      models.CustomerDTO  true  "Dados do cliente"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /client/billing/customers/{id} [put]
+func UpdateMyBillingCustomer(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	customerID, ok := parseBillingID(c, "ID do cliente inválido")
+	if !ok {
+		return
+	}
+
+	var req models.CustomerDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	customer, err := billingService.UpdateCustomer(userID.(uint), companyID, customerID, req)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Cliente atualizado com sucesso",
+		Data:    customer,
+	})
+}
+
+// GetMyInvoiceSeries godoc
+// @Summary      Listar séries de faturação
+// @Description  Lista as séries de documentos da empresa, com o último número emitido
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      403  {object}  models.ErrorResponse
+// @Router       /client/billing/series [get]
+func GetMyInvoiceSeries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	series, err := billingService.GetSeries(userID.(uint), companyID)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Séries obtidas com sucesso",
+		Data:    series,
+	})
+}
+
+// CreateMyInvoiceSeries godoc
+// @Summary      Registar série de faturação
+// @Description  Regista uma série já comunicada à AT com o código de validação atribuído, usado no ATCUD
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        series  body      models.CreateInvoiceSeriesDTO  true  "Dados da série"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /client/billing/series [post]
+func CreateMyInvoiceSeries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CreateInvoiceSeriesDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	series, err := billingService.CreateSeries(userID.(uint), companyID, req)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Série registada com sucesso",
+		Data:    series,
+	})
+}
+
+// GetMyInvoices godoc
+// @Summary      Listar documentos emitidos
+// @Description  Lista as faturas e notas de crédito emitidas, filtradas por tipo e datas
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        document_type  query     string  false  "Tipo (FT, FR, FS, NC)"
+// @Param        from           query     string  false  "Data inicial (AAAA-MM-DD)"
+// @Param        to             query     string  false  "Data final (AAAA-MM-DD)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /client/billing/invoices [get]
+func GetMyInvoices(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	invoices, err := billingService.GetInvoices(userID.(uint), companyID, c.Query("document_type"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Documentos obtidos com sucesso",
+		Data:    invoices,
+	})
+}
+
+// IssueMyInvoice godoc
+// @Summary      Emitir fatura
+// @Description  Emite uma fatura com o próximo número da série, ATCUD e assinatura encadeada. Sem cliente, é emitida ao consumidor final.
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        invoice  body      models.CreateInvoiceDTO  true  "Dados da fatura"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /client/billing/invoices [post]
+func IssueMyInvoice(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CreateInvoiceDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := billingService.IssueInvoice(userID.(uint), companyID, req)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Fatura emitida com sucesso",
+		Data:    invoice,
+	})
+}
+
+// GetMyInvoice godoc
+// @Summary      Obter documento emitido
+// @Description  Devolve um documento com as linhas e o cliente
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path      int  true  "ID do documento"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /client/billing/invoices/{id} [get]
+func GetMyInvoice(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	invoiceID, ok := parseBillingID(c, "ID do documento inválido")
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := billingService.GetInvoice(userID.(uint), companyID, invoiceID)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Documento obtido com sucesso",
+		Data:    invoice,
+	})
+}
+
+// DownloadMyInvoicePDF godoc
+// @Summary      Descarregar documento em PDF
+// @Description  Gera o PDF do documento com ATCUD, código QR e excerto da assinatura
+// @Tags         client
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id  path      int  true  "ID do documento"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {file}    file
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /client/billing/invoices/{id}/pdf [get]
+func DownloadMyInvoicePDF(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	invoiceID, ok := parseBillingID(c, "ID do documento inválido")
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	content, filename, err := billingService.GetInvoicePDF(userID.(uint), companyID, invoiceID)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", content)
+}
+
+// IssueMyCreditNote godoc
+// @Summary      Emitir nota de crédito
+// @Description  Emite uma nota de crédito sobre uma fatura, total ou por linhas, limitada ao valor ainda por creditar
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      int                         true  "ID da fatura de origem"
+// @Param        credit_note  body      models.CreateCreditNoteDTO  true  "Dados da nota de crédito"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /client/billing/invoices/{id}/credit-notes [post]
+func IssueMyCreditNote(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	invoiceID, ok := parseBillingID(c, "ID do documento inválido")
+	if !ok {
+		return
+	}
+
+	var req models.CreateCreditNoteDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	creditNote, err := billingService.IssueCreditNote(userID.(uint), companyID, invoiceID, req)
+	if err != nil {
+		c.JSON(billingErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Nota de crédito emitida com sucesso",
+		Data:    creditNote,
+	})
+}
+
+func parseBillingID(c *gin.Context, invalidMsg string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   invalidMsg,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func billingErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "o cliente já usa software de faturação próprio" || msg == "sem permissão para alterar esta empresa":
+		return http.StatusForbidden
+	case strings.HasSuffix(msg, "não encontrada") || strings.HasSuffix(msg, "não encontrado"):
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "já existe") || strings.Contains(msg, "está fechado") ||
+		strings.HasPrefix(msg, "não é possível") || msg == "a fatura já foi totalmente creditada":
+		return http.StatusConflict
+	case strings.Contains(msg, "chave de assinatura"):
+		return http.StatusServiceUnavailable
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	calendarService = services.NewCalendarService()
+)
+
+// GetCalendarFeed godoc
+// @Summary      Endereço do calendário de prazos
+// @Description  Devolve o endereço (com token) do feed ICS de prazos fiscais do utilizador logado, criando-o se necessário
+// @Tags         calendar
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /calendar/feed [get]
+func GetCalendarFeed(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	feed, err := calendarService.GetFeed(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Endereço do calendário obtido com sucesso",
+		Data:    feed,
+	})
+}
+
+// RotateCalendarFeed godoc
+// @Summary      Gerar novo endereço do calendário
+// @Description  Gera um novo token para o feed ICS; o endereço anterior deixa de funcionar
+// @Tags         calendar
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /calendar/feed [post]
+func RotateCalendarFeed(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	feed, err := calendarService.RotateFeed(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Novo endereço do calendário gerado",
+		Data:    feed,
+	})
+}
+
+// RevokeCalendarFeed godoc
+// @Summary      Revogar calendário
+// @Description  Desativa o feed ICS do utilizador logado
+// @Tags         calendar
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /calendar/feed [delete]
+func RevokeCalendarFeed(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := calendarService.RevokeFeed(userID.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Calendário revogado",
+	})
+}
+
+// DownloadCalendarICS godoc
+// @Summary      Feed ICS de prazos fiscais
+// @Description  Calendário iCalendar (RFC 5545) com os prazos do dono do token: a empresa do cliente ou as empresas acompanhadas pelo contabilista
+// @Tags         calendar
+// @Produce      text/calendar
+// @Param        token  path      string  true  "Token do calendário"
+// @Success      200  {string}  string
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /calendar/{token}/deadlines.ics [get]
+func DownloadCalendarICS(c *gin.Context) {
+	ics, err := calendarService.BuildFeed(c.Param("token"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "calendário não encontrado" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	checklistService = services.NewChecklistService()
+)
+
+// GetMyChecklist godoc
+// @Summary      Minha checklist de documentos
+// @Description  Lista os documentos esperados por período para a empresa do cliente logado (use status=missing para ver o que falta)
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period  query     string  false  "Período (AAAA-MM, AAAA-T1 ou AAAA)"
+// @Param        status  query     string  false  "Filtrar por status (missing, submitted, waived)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /client/checklist [get]
+func GetMyChecklist(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	items, err := checklistService.GetClientChecklist(userID.(uint), companyID, c.Query("period"), c.Query("status"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "empresa não encontrada" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Checklist obtida com sucesso",
+		Data:    items,
+	})
+}
+
+// GetChecklistLateMatrix godoc
+// @Summary      Matriz de atrasos
+// @Description  Mostra, por empresa e por mês, se os documentos do período foram entregues, estão pendentes ou atrasados
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  false  "Primeiro mês (AAAA-MM, por omissão mostra os 6 meses até to)"
+// @Param        to    query     string  false  "Último mês (AAAA-MM, por omissão o mês anterior)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/checklists/late-matrix [get]
+func GetChecklistLateMatrix(c *gin.Context) {
+	matrix, err := checklistService.GetLateMatrix(c.Query("from"), c.Query("to"), portfolioScope(c))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "inválido") {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Matriz de atrasos obtida com sucesso",
+		Data:    matrix,
+	})
+}
+
+// GenerateChecklists godoc
+// @Summary      Gerar checklists
+// @Description  Gera os itens da checklist das empresas ativas cujo período termina no mês indicado
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        month  query     string  false  "Mês (AAAA-MM, por omissão o mês anterior)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/checklists/generate [post]
+func GenerateChecklists(c *gin.Context) {
+	month := time.Now().AddDate(0, -1, 0)
+	if value := c.Query("month"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Error:   "mês inválido (use AAAA-MM)",
+			})
+			return
+		}
+		month = parsed
+	}
+
+	created, err := checklistService.GenerateForMonth(month.Year(), month.Month())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Checklists geradas com sucesso",
+		Data:    gin.H{"month": month.Format("2006-01"), "created": created},
+	})
+}
+
+// UpdateChecklistItem godoc
+// @Summary      Atualizar item da checklist
+// @Description  Dispensa, reabre ou dá como entregue um item da checklist
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                            true  "ID do item"
+// @Param        request  body      models.UpdateChecklistItemDTO  true  "Novo status"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/checklists/{id} [put]
+func UpdateChecklistItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do item inválido",
+		})
+		return
+	}
+
+	var req models.UpdateChecklistItemDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	item, err := checklistService.UpdateItem(uint(itemID), req, portfolioScope(c))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "item da checklist não encontrado" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Item da checklist atualizado",
+		Data:    item,
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"RVContabilidadeBack/models"
 	"RVContabilidadeBack/services"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -93,15 +95,52 @@ func UpdateClientProfile(c *gin.Context) {
 	})
 }
 
+// GetClientCompanies godoc
+// @Summary      Empresas do cliente
+// @Description  Lista as empresas a que o cliente logado está ligado, com o papel em cada uma (owner, manager ou viewer)
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse{data=[]models.ClientCompanyDTO}
+// @Router       /client/companies [get]
+func GetClientCompanies(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Error:   "Utilizador não autenticado",
+		})
+		return
+	}
+
+	companies, err := clientCompanyService.GetUserCompanies(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Empresas obtidas com sucesso",
+		Data:    companies,
+	})
+}
+
 // GetClientCompany godoc
-// @Summary      Dados da empresa do cliente
-// @Description  Obtém dados da empresa do cliente logado
+// @Summary      Dados de uma empresa do cliente
+// @Description  Obtém dados de uma empresa a que o cliente logado está ligado
 // @Tags         client
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object}  models.SuccessResponse
-// @Router       /client/company [get]
+// @Param        id   path      int  true  "ID da empresa"
+// @Success      200  {object}  models.SuccessResponse{data=models.ClientCompanyDTO}
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /client/companies/{id} [get]
 func GetClientCompany(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -112,7 +151,16 @@ func GetClientCompany(c *gin.Context) {
 		return
 	}
 
-	company, err := clientCompanyService.GetByUserID(userID.(uint))
+	companyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da empresa inválido",
+		})
+		return
+	}
+
+	company, err := clientCompanyService.GetUserCompany(userID.(uint), uint(companyID))
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Success: false,
@@ -129,15 +177,18 @@ func GetClientCompany(c *gin.Context) {
 }
 
 // UpdateClientCompany godoc
-// @Summary      Atualizar dados da empresa
-// @Description  Atualiza dados da empresa do cliente (campos limitados)
+// @Summary      Atualizar dados de uma empresa
+// @Description  Atualiza dados de uma empresa do cliente (campos limitados). Só o titular (owner) e os gerentes (manager) podem alterar.
 // @Tags         client
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        id       path      int                      true  "ID da empresa"
 // @Param        company  body      models.UpdateCompanyDTO  true  "Dados a atualizar"
 // @Success      200      {object}  models.SuccessResponse
-// @Router       /client/company [put]
+// @Failure      403      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Router       /client/companies/{id} [put]
 func UpdateClientCompany(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -148,6 +199,15 @@ func UpdateClientCompany(c *gin.Context) {
 		return
 	}
 
+	companyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da empresa inválido",
+		})
+		return
+	}
+
 	var req models.UpdateCompanyDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -157,11 +217,13 @@ func UpdateClientCompany(c *gin.Context) {
 		return
 	}
 
-	company, err := clientCompanyService.UpdateCompany(userID.(uint), req)
+	company, err := clientCompanyService.UpdateUserCompany(userID.(uint), uint(companyID), req)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "empresa não encontrada" {
 			statusCode = http.StatusNotFound
+		} else if strings.HasPrefix(err.Error(), "sem permissão") {
+			statusCode = http.StatusForbidden
 		}
 		
 		c.JSON(statusCode, models.ErrorResponse{
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	clientImportService = services.NewClientImportService()
+)
+
+// GetClientImportColumns godoc
+// @Summary      Colunas da importação de clientes
+// @Description  Lista os campos de User/Company aceites no ficheiro de importação, com os nomes de coluna reconhecidos e os obrigatórios
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/client-imports/columns [get]
+func GetClientImportColumns(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Colunas obtidas com sucesso",
+		Data:    clientImportService.GetColumns(),
+	})
+}
+
+// ImportClients godoc
+// @Summary      Importar clientes (CSV/XLSX)
+// @Description  Valida cada linha (campos obrigatórios, NIF/NIPC, duplicados no ficheiro e na base de dados) e devolve o relatório por linha. Com dry_run=true nada é criado; caso contrário cria em lotes os clientes aprovados (mode=approved) ou solicitações existing_client. Os clientes criados recebem por email um link de ativação para definirem a password.
+// @Tags         admin
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file     formData  file    true   "Ficheiro CSV ou XLSX"
+// @Param        mode     formData  string  false  "Modo (approved, existing_client)"
+// @Param        dry_run  formData  bool    false  "Simulação sem criar registos"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/client-imports [post]
+func ImportClients(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Ficheiro em falta (campo file)",
+		})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+
+	result, err := clientImportService.Import(fileHeader, c.PostForm("mode"), dryRun, userID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(clientImportErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusCreated
+	message := "Importação concluída"
+	if dryRun {
+		status = http.StatusOK
+		message = "Simulação concluída"
+	}
+	c.JSON(status, models.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// GetClientImports godoc
+// @Summary      Importações de clientes
+// @Description  Lista as importações e simulações de clientes feitas, das mais recentes para as mais antigas
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/client-imports [get]
+func GetClientImports(c *gin.Context) {
+	imports, err := clientImportService.GetImports()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Importações obtidas com sucesso",
+		Data:    imports,
+	})
+}
+
+// GetClientImport godoc
+// @Summary      Relatório de uma importação de clientes
+// @Description  Devolve a importação com o estado e os erros de cada linha
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path      int  true  "ID da importação"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/client-imports/{id} [get]
+func GetClientImport(c *gin.Context) {
+	importID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da importação inválido",
+		})
+		return
+	}
+
+	result, err := clientImportService.GetImport(uint(importID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Importação obtida com sucesso",
+		Data:    result,
+	})
+}
+
+// ResendUserActivation godoc
+// @Summary      Reenviar link de ativação
+// @Description  Gera um novo link de ativação para um cliente importado que ainda não definiu a password
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path      int  true  "ID do utilizador"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/users/{id}/activation [post]
+func ResendUserActivation(c *gin.Context) {
+	targetID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := activationService.ResendActivation(targetID)
+	if err != nil {
+		c.JSON(activationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Link de ativação reenviado",
+		Data: gin.H{
+			"user_id":            user.ID,
+			"activation_sent_at": user.ActivationSentAt,
+		},
+	})
+}
+
+func clientImportErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "ficheiro excede"):
+		return http.StatusRequestEntityTooLarge
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	companyUserService = services.NewCompanyService()
+)
+
+// AdminGetClientCompanies godoc
+// @Summary      Empresas de um cliente
+// @Description  Lista as empresas a que o cliente está ligado, com o papel em cada uma
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do cliente"
+// @Success      200  {object}  models.SuccessResponse{data=[]models.ClientCompanyDTO}
+// @Router       /admin/clients/{id}/companies [get]
+func AdminGetClientCompanies(c *gin.Context) {
+	clientID, ok := parseIDParam(c, "ID do cliente inválido")
+	if !ok {
+		return
+	}
+
+	companies, err := companyUserService.GetUserCompanies(clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		for i := range companies {
+			services.MaskCompanyCredentials(&companies[i].Company)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Empresas do cliente obtidas com sucesso",
+		Data:    companies,
+	})
+}
+
+// AdminCreateClientCompany godoc
+// @Summary      Acrescentar empresa a um cliente
+// @Description  Cria uma nova empresa para um cliente existente (por exemplo, um ENI que abre uma Lda). Entra nas carteiras dos contabilistas das outras empresas do cliente e fica registada na auditoria.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                            true  "ID do cliente"
+// @Param        request  body      models.CreateClientCompanyDTO  true  "Dados da empresa"
+// @Success      201      {object}  models.SuccessResponse{data=models.ClientCompanyDTO}
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      409      {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/companies [post]
+func AdminCreateClientCompany(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	clientID, ok := parseIDParam(c, "ID do cliente inválido")
+	if !ok {
+		return
+	}
+
+	var req models.CreateClientCompanyDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	company, err := companyUserService.CreateClientCompany(clientID, req, userID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(companyUserErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Empresa criada com sucesso",
+		Data:    company,
+	})
+}
+
+// GetCompanyUsers godoc
+// @Summary      Utilizadores de uma empresa
+// @Description  Lista os clientes com acesso à empresa e o papel de cada um (owner, manager ou viewer)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da empresa"
+// @Success      200  {object}  models.SuccessResponse{data=[]models.UserCompany}
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/users [get]
+func GetCompanyUsers(c *gin.Context) {
+	companyID, ok := parseIDParam(c, "ID da empresa inválido")
+	if !ok {
+		return
+	}
+
+	links, err := companyUserService.GetCompanyUsers(companyID)
+	if err != nil {
+		c.JSON(companyUserErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		for i := range links {
+			if links[i].User != nil {
+				services.MaskUserCredentials(links[i].User)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Utilizadores da empresa obtidos com sucesso",
+		Data:    links,
+	})
+}
+
+// SetCompanyUser godoc
+// @Summary      Dar acesso a uma empresa
+// @Description  Liga um cliente à empresa com um papel (owner, manager ou viewer) ou muda o papel que já tem. A empresa mantém sempre um titular. Fica registado na auditoria.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                    true  "ID da empresa"
+// @Param        request  body      models.CompanyUserDTO  true  "Cliente e papel"
+// @Success      200      {object}  models.SuccessResponse{data=models.UserCompany}
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      409      {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/users [put]
+func SetCompanyUser(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := parseIDParam(c, "ID da empresa inválido")
+	if !ok {
+		return
+	}
+
+	var req models.CompanyUserDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	link, err := companyUserService.SetCompanyUser(companyID, req, userID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(companyUserErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) && link.User != nil {
+		services.MaskUserCredentials(link.User)
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Acesso à empresa atualizado com sucesso",
+		Data:    link,
+	})
+}
+
+// RemoveCompanyUser godoc
+// @Summary      Retirar acesso a uma empresa
+// @Description  Retira o acesso de um cliente à empresa. O último titular não pode ser retirado. Fica registado na auditoria.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int  true  "ID da empresa"
+// @Param        userId  path      int  true  "ID do cliente"
+// @Success      200     {object}  models.SuccessResponse
+// @Failure      404     {object}  models.ErrorResponse
+// @Failure      409     {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/users/{userId} [delete]
+func RemoveCompanyUser(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := parseIDParam(c, "ID da empresa inválido")
+	if !ok {
+		return
+	}
+	clientID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	if err := companyUserService.RemoveCompanyUser(companyID, uint(clientID), userID.(uint), c.ClientIP()); err != nil {
+		c.JSON(companyUserErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Acesso à empresa retirado com sucesso",
+	})
+}
+
+// companyIDQuery lê o parâmetro opcional company_id; 0 (omitido) escolhe a empresa principal do cliente
+func companyIDQuery(c *gin.Context) (uint, bool) {
+	raw := c.Query("company_id")
+	if raw == "" {
+		return 0, true
+	}
+	companyID, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || companyID == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da empresa inválido",
+		})
+		return 0, false
+	}
+	return uint(companyID), true
+}
+
+func companyUserErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "não encontrad"):
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "já existe") || strings.HasPrefix(msg, "a empresa tem de manter"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
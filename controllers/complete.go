@@ -4,6 +4,7 @@ import (
 	"RVContabilidadeBack/models"
 	"RVContabilidadeBack/services"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -47,7 +48,7 @@ func CompleteUserData(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "utilizador não encontrado" {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "conta ainda não aprovada" {
+		} else if err.Error() == "conta ainda não aprovada" || err.Error() == "sem permissão para alterar esta empresa" {
 			statusCode = http.StatusForbidden
 		}
 		
@@ -67,13 +68,15 @@ func CompleteUserData(c *gin.Context) {
 
 // CompleteCompanyData godoc
 // @Summary      Completar dados da empresa
-// @Description  Completa dados da empresa adicionais após aprovação
+// @Description  Completa dados adicionais de uma empresa do cliente após aprovação. Só o titular ou um gerente da empresa o pode fazer.
 // @Tags         client
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        data  body      models.CompleteCompanyDataDTO  true  "Dados da empresa completos"
-// @Success      200   {object}  models.SuccessResponse
+// @Param        company_id  query     int                            false  "ID da empresa (por omissão a principal)"
+// @Param        data        body      models.CompleteCompanyDataDTO  true   "Dados da empresa completos"
+// @Success      200         {object}  models.SuccessResponse
+// @Failure      403         {object}  models.ErrorResponse
 // @Router       /client/complete-company-data [put]
 func CompleteCompanyData(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -85,6 +88,11 @@ func CompleteCompanyData(c *gin.Context) {
 		return
 	}
 
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
 	var dto models.CompleteCompanyDataDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -94,13 +102,15 @@ func CompleteCompanyData(c *gin.Context) {
 		return
 	}
 
-	company, err := completeCompanyService.CompleteCompanyData(userID.(uint), dto)
+	company, err := completeCompanyService.CompleteCompanyData(userID.(uint), companyID, dto)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "utilizador não encontrado" || err.Error() == "empresa não encontrada" {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "conta ainda não aprovada" {
+		} else if err.Error() == "conta ainda não aprovada" || err.Error() == "sem permissão para alterar esta empresa" {
 			statusCode = http.StatusForbidden
+		} else if strings.Contains(err.Error(), "está fechado") {
+			statusCode = http.StatusConflict
 		}
 		
 		c.JSON(statusCode, models.ErrorResponse{
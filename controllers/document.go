@@ -0,0 +1,318 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	documentService = services.NewDocumentService()
+)
+
+// UploadDocument godoc
+// @Summary      Enviar documento
+// @Description  Envia um documento (fatura, recibo, extrato, cartão de cidadão...) para a empresa do cliente logado
+// @Tags         client
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file           formData  file    true   "Ficheiro (PDF, JPEG, PNG, WEBP, CSV/TXT, XML ou ZIP)"
+// @Param        type           formData  string  true   "Tipo (invoice, receipt, bank_statement, citizen_card, other)"
+// @Param        fiscal_period  formData  string  false  "Período fiscal (AAAA-MM ou AAAA)"
+// @Param        notes          formData  string  false  "Notas"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /client/documents [post]
+func UploadDocument(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Ficheiro em falta (campo file)",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	document, err := documentService.UploadDocument(userID.(uint), companyID, fileHeader, c.PostForm("type"), c.PostForm("fiscal_period"), c.PostForm("notes"))
+	if err != nil {
+		c.JSON(documentErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Documento enviado com sucesso",
+		Data:    document,
+	})
+}
+
+// GetMyDocuments godoc
+// @Summary      Meus documentos
+// @Description  Lista os documentos enviados pelo cliente logado
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        type           query     string  false  "Filtrar por tipo"
+// @Param        fiscal_period  query     string  false  "Filtrar por período fiscal"
+// @Param        status         query     string  false  "Filtrar por status (received, accepted, rejected)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /client/documents [get]
+func GetMyDocuments(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	documents, err := documentService.GetClientDocuments(userID.(uint), companyID, c.Query("type"), c.Query("fiscal_period"), c.Query("status"))
+	if err != nil {
+		c.JSON(documentErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Documentos obtidos com sucesso",
+		Data:    documents,
+	})
+}
+
+// DownloadMyDocument godoc
+// @Summary      Descarregar documento
+// @Description  Descarrega um documento enviado pelo cliente logado
+// @Tags         client
+// @Produce      octet-stream
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do documento"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {file}    file
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /client/documents/{id}/download [get]
+func DownloadMyDocument(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	documentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do documento inválido",
+		})
+		return
+	}
+
+	serveDocument(c, userID.(uint), uint(documentID))
+}
+
+// GetClientDocuments godoc
+// @Summary      Documentos de um cliente
+// @Description  Lista os documentos enviados por um cliente (contabilista/admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      int     true   "ID do cliente"
+// @Param        type           query     string  false  "Filtrar por tipo"
+// @Param        fiscal_period  query     string  false  "Filtrar por período fiscal"
+// @Param        status         query     string  false  "Filtrar por status (received, accepted, rejected)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/documents [get]
+func GetClientDocuments(c *gin.Context) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	documents, err := documentService.GetDocumentsByClient(uint(clientID), companyID, c.Query("type"), c.Query("fiscal_period"), c.Query("status"))
+	if err != nil {
+		c.JSON(documentErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Documentos obtidos com sucesso",
+		Data:    documents,
+	})
+}
+
+// DownloadClientDocument godoc
+// @Summary      Descarregar documento de um cliente
+// @Description  Descarrega um documento enviado por um cliente (contabilista/admin)
+// @Tags         admin
+// @Produce      octet-stream
+// @Security     BearerAuth
+// @Param        id      path      int  true  "ID do cliente"
+// @Param        docId   path      int  true  "ID do documento"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {file}    file
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/documents/{docId}/download [get]
+func DownloadClientDocument(c *gin.Context) {
+	clientID, documentID, ok := parseClientDocumentIDs(c)
+	if !ok {
+		return
+	}
+
+	serveDocument(c, clientID, documentID)
+}
+
+// UpdateClientDocumentStatus godoc
+// @Summary      Alterar status de documento
+// @Description  Aceita ou rejeita um documento enviado por um cliente (contabilista/admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                             true  "ID do cliente"
+// @Param        docId    path      int                             true  "ID do documento"
+// @Param        request  body      models.UpdateDocumentStatusDTO  true  "Novo status"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/documents/{docId}/status [put]
+func UpdateClientDocumentStatus(c *gin.Context) {
+	reviewerID, _ := c.Get("user_id")
+	reviewerRole, _ := c.Get("user_role")
+
+	clientID, documentID, ok := parseClientDocumentIDs(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateDocumentStatusDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	document, err := documentService.UpdateDocumentStatus(clientID, companyID, documentID, req, reviewerID.(uint), reviewerRole.(string))
+	if err != nil {
+		c.JSON(documentErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Status do documento atualizado",
+		Data:    document,
+	})
+}
+
+// serveDocument envia o conteúdo de um documento de um cliente em streaming
+func serveDocument(c *gin.Context, clientID, documentID uint) {
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	document, err := documentService.GetClientDocument(clientID, companyID, documentID)
+	if err != nil {
+		c.JSON(documentErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	reader, err := documentService.OpenDocument(document)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, document.Size, document.MimeType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", document.OriginalName),
+	})
+}
+
+func parseClientDocumentIDs(c *gin.Context) (uint, uint, bool) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return 0, 0, false
+	}
+
+	documentID, err := strconv.ParseUint(c.Param("docId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do documento inválido",
+		})
+		return 0, 0, false
+	}
+
+	return uint(clientID), uint(documentID), true
+}
+
+func documentErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "empresa não encontrada" || msg == "documento não encontrado":
+		return http.StatusNotFound
+	case msg == "sem permissão para alterar esta empresa":
+		return http.StatusForbidden
+	case msg == "este documento já foi enviado" || strings.Contains(msg, "está fechado"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "ficheiro excede"):
+		return http.StatusRequestEntityTooLarge
+	case msg == "tipo de documento inválido" || msg == "ficheiro vazio" ||
+		strings.HasPrefix(msg, "período fiscal inválido") || strings.HasPrefix(msg, "tipo de ficheiro não permitido"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
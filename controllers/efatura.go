@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	eFaturaService = services.NewEFaturaService()
+)
+
+// ImportClientPurchaseInvoices godoc
+// @Summary      Importar faturas de compra do e-Fatura
+// @Description  Importa o CSV de faturas de compra exportado do portal e-Fatura para a empresa do cliente, ignorando faturas já importadas (mesmo ATCUD ou mesmo NIF do emitente e número) e associando-as aos documentos enviados pelo cliente
+// @Tags         admin
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int   true  "ID do cliente"
+// @Param        file  formData  file  true  "CSV exportado do e-Fatura"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/purchase-invoices/import [post]
+func ImportClientPurchaseInvoices(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Ficheiro em falta (campo file)",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := eFaturaService.ImportCSV(uint(clientID), companyID, userID.(uint), fileHeader, userRole.(string))
+	if err != nil {
+		c.JSON(eFaturaErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Faturas de compra importadas com sucesso",
+		Data:    result,
+	})
+}
+
+// GetClientPurchaseInvoices godoc
+// @Summary      Faturas de compra de um cliente
+// @Description  Lista as faturas de compra importadas do e-Fatura para a empresa do cliente
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      int     true   "ID do cliente"
+// @Param        fiscal_period  query     string  false  "Filtrar por mês (AAAA-MM)"
+// @Param        match_status   query     string  false  "Filtrar por correspondência (matched, unmatched)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/purchase-invoices [get]
+func GetClientPurchaseInvoices(c *gin.Context) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	invoices, err := eFaturaService.GetPurchaseInvoices(uint(clientID), companyID, c.Query("fiscal_period"), c.Query("match_status"))
+	if err != nil {
+		c.JSON(eFaturaErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Faturas de compra obtidas com sucesso",
+		Data:    invoices,
+	})
+}
+
+// MatchClientPurchaseInvoice godoc
+// @Summary      Associar fatura a documento
+// @Description  Associa manualmente uma fatura do e-Fatura a um documento enviado pelo cliente (document_id null desfaz a associação)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int                             true  "ID do cliente"
+// @Param        invoiceId  path      int                             true  "ID da fatura"
+// @Param        request    body      models.MatchPurchaseInvoiceDTO  true  "Documento"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/purchase-invoices/{invoiceId}/match [put]
+func MatchClientPurchaseInvoice(c *gin.Context) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	invoiceID, err := strconv.ParseUint(c.Param("invoiceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da fatura inválido",
+		})
+		return
+	}
+
+	var req models.MatchPurchaseInvoiceDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := eFaturaService.SetMatch(uint(clientID), companyID, uint(invoiceID), req)
+	if err != nil {
+		c.JSON(eFaturaErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Correspondência atualizada",
+		Data:    invoice,
+	})
+}
+
+// ClassifyClientPurchaseInvoice godoc
+// @Summary      Classificar fatura para o IVA
+// @Description  Define a categoria da fatura (imobilizado, existências ou outros) e a percentagem de IVA dedutível usadas na declaração periódica
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int                                true  "ID do cliente"
+// @Param        invoiceId  path      int                                true  "ID da fatura"
+// @Param        request    body      models.ClassifyPurchaseInvoiceDTO  true  "Classificação"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/purchase-invoices/{invoiceId}/vat [put]
+func ClassifyClientPurchaseInvoice(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	invoiceID, err := strconv.ParseUint(c.Param("invoiceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da fatura inválido",
+		})
+		return
+	}
+
+	var req models.ClassifyPurchaseInvoiceDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := eFaturaService.ClassifyVAT(uint(clientID), companyID, uint(invoiceID), req, userRole.(string))
+	if err != nil {
+		c.JSON(eFaturaErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Classificação de IVA atualizada",
+		Data:    invoice,
+	})
+}
+
+// GetClientPurchaseDiscrepancies godoc
+// @Summary      Faturas em falta
+// @Description  Relatório, por mês, das faturas de compra comunicadas à AT que o cliente não enviou
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int     true   "ID do cliente"
+// @Param        from  query     string  false  "Mês inicial (AAAA-MM)"
+// @Param        to    query     string  false  "Mês final (AAAA-MM)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/purchase-invoices/discrepancies [get]
+func GetClientPurchaseDiscrepancies(c *gin.Context) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	report, err := eFaturaService.GetDiscrepancyReport(uint(clientID), companyID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(eFaturaErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Relatório de faturas em falta obtido com sucesso",
+		Data:    report,
+	})
+}
+
+func eFaturaErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "empresa não encontrada" || msg == "fatura não encontrada" || msg == "documento não encontrado":
+		return http.StatusNotFound
+	case strings.Contains(msg, "está fechado"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "ficheiro excede"):
+		return http.StatusRequestEntityTooLarge
+	case msg == "ficheiro vazio" || msg == "ficheiro sem cabeçalho" || msg == "mês inválido (use AAAA-MM)" ||
+		strings.HasPrefix(msg, "formato não reconhecido"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	exportService     = services.NewExportService()
+	permissionService = services.NewPermissionService()
+	auditService      = services.NewAuditService()
+)
+
+// GetExportDatasets godoc
+// @Summary      Conjuntos de dados exportáveis
+// @Description  Lista as exportações disponíveis com as colunas (indicando as sensíveis) e os filtros aceites
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/exports [get]
+func GetExportDatasets(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Exportações obtidas com sucesso",
+		Data:    exportService.GetDatasets(),
+	})
+}
+
+// ExportDataset godoc
+// @Summary      Exportar dados (CSV/XLSX)
+// @Description  Exporta users-overview, requests ou clients em CSV ou XLSX, escrito em lotes na resposta. As colunas sensíveis (IBAN, cartão de cidadão, credenciais) saem com máscara sem a permissão export_sensitive. Cada exportação fica na auditoria.
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security     BearerAuth
+// @Param        dataset  path      string  true   "Conjunto de dados (users-overview, requests, clients)"
+// @Param        format   query     string  false  "Formato (csv, xlsx)"
+// @Param        columns  query     string  false  "Colunas separadas por vírgulas (por omissão, todas)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/exports/{dataset} [get]
+func ExportDataset(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	query := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			query[key] = values[0]
+		}
+	}
+
+	job, err := exportService.Prepare(userID.(uint), c.Param("dataset"), c.Query("format"), c.Query("columns"), query, c.ClientIP())
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.HasSuffix(err.Error(), "não encontrada") {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", job.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.Filename))
+	c.Status(http.StatusOK)
+	if err := job.Write(c.Writer); err != nil {
+		log.Printf("Erro na exportação %s: %v", job.Filename, err)
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Error:   "Erro ao gerar exportação",
+			})
+		}
+	}
+}
+
+// GetUserPermissions godoc
+// @Summary      Permissões de um utilizador
+// @Description  Lista as permissões concedidas explicitamente a um contabilista ou admin
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path      int  true  "ID do utilizador"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/users/{id}/permissions [get]
+func GetUserPermissions(c *gin.Context) {
+	targetID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	permissions, err := permissionService.GetUserPermissions(targetID)
+	if err != nil {
+		c.JSON(permissionErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Permissões obtidas com sucesso",
+		Data:    permissions,
+	})
+}
+
+// GrantUserPermission godoc
+// @Summary      Conceder permissão
+// @Description  Concede uma permissão (por exemplo, export_sensitive) a um contabilista ou admin; fica registado na auditoria
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      int                        true  "ID do utilizador"
+// @Param        permission  body      models.GrantPermissionDTO  true  "Permissão"
+// @Success      201  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/users/{id}/permissions [post]
+func GrantUserPermission(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	targetID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.GrantPermissionDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	grant, err := permissionService.Grant(targetID, req.Permission, adminID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(permissionErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Permissão concedida com sucesso",
+		Data:    grant,
+	})
+}
+
+// RevokeUserPermission godoc
+// @Summary      Retirar permissão
+// @Description  Retira uma permissão a um utilizador; fica registado na auditoria
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      int     true  "ID do utilizador"
+// @Param        permission  path      string  true  "Permissão"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/users/{id}/permissions/{permission} [delete]
+func RevokeUserPermission(c *gin.Context) {
+	adminID, _ := c.Get("user_id")
+
+	targetID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := permissionService.Revoke(targetID, c.Param("permission"), adminID.(uint), c.ClientIP()); err != nil {
+		c.JSON(permissionErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Permissão retirada com sucesso",
+	})
+}
+
+// GetAuditLogs godoc
+// @Summary      Registo de auditoria
+// @Description  Lista as ações auditadas (exportações, permissões), das mais recentes para as mais antigas
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        action   query     string  false  "Ação (export, permission_grant, permission_revoke)"
+// @Param        user_id  query     int     false  "ID do utilizador que fez a ação"
+// @Param        from     query     string  false  "Data inicial (AAAA-MM-DD)"
+// @Param        to       query     string  false  "Data final (AAAA-MM-DD)"
+// @Param        limit    query     int     false  "Número máximo de entradas (até 500)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/audit-logs [get]
+func GetAuditLogs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	logs, err := auditService.GetLogs(c.Query("action"), c.Query("user_id"), c.Query("from"), c.Query("to"), limit)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "erro ao") {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Registo de auditoria obtido com sucesso",
+		Data:    logs,
+	})
+}
+
+func parseUserIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do utilizador inválido",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func permissionErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "não encontrado") || strings.HasSuffix(msg, "não encontrada"):
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "o utilizador já tem"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	inboxService = services.NewInboxService()
+)
+
+// GetNotifications godoc
+// @Summary      Minhas notificações
+// @Description  Lista as notificações da caixa de entrada do utilizador logado (as mais recentes primeiro)
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        unread  query     bool  false  "Apenas por ler"
+// @Param        limit   query     int   false  "Máximo de resultados (por omissão 50)"
+// @Param        offset  query     int   false  "Deslocamento"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /notifications [get]
+func GetNotifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	result, err := inboxService.GetNotifications(userID.(uint), c.Query("unread") == "true", limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Notificações obtidas com sucesso",
+		Data:    result,
+	})
+}
+
+// MarkNotificationAsRead godoc
+// @Summary      Marcar notificação como lida
+// @Description  Marca uma notificação do utilizador logado como lida
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da notificação"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /notifications/{id}/read [put]
+func MarkNotificationAsRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da notificação inválido",
+		})
+		return
+	}
+
+	notification, err := inboxService.MarkAsRead(userID.(uint), uint(notificationID))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "notificação não encontrada" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Notificação marcada como lida",
+		Data:    notification,
+	})
+}
+
+// MarkNotificationsAsRead godoc
+// @Summary      Marcar notificações como lidas
+// @Description  Marca várias notificações (ou todas, com all=true) do utilizador logado como lidas
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body      models.NotificationBulkDTO  true  "Notificações a marcar"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /notifications/read [put]
+func MarkNotificationsAsRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.NotificationBulkDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	updated, err := inboxService.MarkManyAsRead(userID.(uint), req)
+	if err != nil {
+		c.JSON(inboxErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Notificações marcadas como lidas",
+		Data:    gin.H{"updated": updated},
+	})
+}
+
+// DeleteNotifications godoc
+// @Summary      Eliminar notificações
+// @Description  Elimina várias notificações (ou todas, com all=true) do utilizador logado
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body      models.NotificationBulkDTO  true  "Notificações a eliminar"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /notifications [delete]
+func DeleteNotifications(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.NotificationBulkDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	deleted, err := inboxService.DeleteMany(userID.(uint), req)
+	if err != nil {
+		c.JSON(inboxErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Notificações eliminadas",
+		Data:    gin.H{"deleted": deleted},
+	})
+}
+
+func inboxErrorStatus(err error) int {
+	if err.Error() == "indique as notificações ou all=true" {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	invitationService = services.NewInvitationService()
+)
+
+// CreateInvitation godoc
+// @Summary      Convidar cliente
+// @Description  O contabilista pré-preenche os dados da empresa (e, se souber, os pessoais) e o cliente recebe por email um link para aceitar o convite e escolher a password
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        invitation  body      models.CreateInvitationDTO  true  "Dados do convite"
+// @Success      201         {object}  models.SuccessResponse
+// @Failure      400         {object}  models.ErrorResponse
+// @Failure      409         {object}  models.ErrorResponse
+// @Router       /admin/invitations [post]
+func CreateInvitation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CreateInvitationDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	invitation, err := invitationService.CreateInvitation(req, userID.(uint))
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Convite enviado com sucesso",
+		Data:    invitation,
+	})
+}
+
+// GetInvitations godoc
+// @Summary      Convites de clientes
+// @Description  Lista os convites enviados, dos mais recentes para os mais antigos
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "Estado (pending, accepted, revoked, expired)"
+// @Success      200     {object}  models.SuccessResponse
+// @Router       /admin/invitations [get]
+func GetInvitations(c *gin.Context) {
+	invitations, err := invitationService.GetInvitations(c.Query("status"), portfolioScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Convites obtidos com sucesso",
+		Data:    invitations,
+	})
+}
+
+// GetInvitation godoc
+// @Summary      Detalhes de um convite
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do convite"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/invitations/{id} [get]
+func GetInvitation(c *gin.Context) {
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := invitationService.GetInvitation(invitationID, portfolioScope(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Convite obtido com sucesso",
+		Data:    invitation,
+	})
+}
+
+// ResendInvitation godoc
+// @Summary      Reenviar convite
+// @Description  Gera um novo link (o anterior deixa de funcionar) e renova o prazo de um convite pendente ou expirado
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do convite"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/invitations/{id}/resend [post]
+func ResendInvitation(c *gin.Context) {
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := invitationService.ResendInvitation(invitationID, portfolioScope(c))
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Convite reenviado com sucesso",
+		Data:    invitation,
+	})
+}
+
+// RevokeInvitation godoc
+// @Summary      Revogar convite
+// @Description  Cancela um convite que ainda não foi aceite; o link deixa de funcionar
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do convite"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/invitations/{id}/revoke [post]
+func RevokeInvitation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := invitationService.RevokeInvitation(invitationID, userID.(uint), portfolioScope(c))
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Convite revogado com sucesso",
+		Data:    invitation,
+	})
+}
+
+// CheckInvitation godoc
+// @Summary      Validar convite
+// @Description  Devolve os dados pré-preenchidos de um convite válido, para o cliente confirmar antes de aceitar
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token  query     string  true  "Token do convite"
+// @Success      200    {object}  models.SuccessResponse
+// @Failure      404    {object}  models.ErrorResponse
+// @Failure      410    {object}  models.ErrorResponse
+// @Router       /auth/invitation [get]
+func CheckInvitation(c *gin.Context) {
+	invitation, err := invitationService.CheckToken(c.Query("token"))
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Convite válido",
+		Data: gin.H{
+			"email":        invitation.Email,
+			"name":         invitation.Name,
+			"phone":        invitation.Phone,
+			"nif":          invitation.NIF,
+			"username":     invitation.Username,
+			"company_name": invitation.CompanyName,
+			"nipc":         invitation.NIPC,
+			"legal_form":   invitation.LegalForm,
+			"expires_at":   invitation.ExpiresAt,
+		},
+	})
+}
+
+// AcceptInvitation godoc
+// @Summary      Aceitar convite
+// @Description  Cria a conta e a empresa já aprovadas com os dados do convite e a password escolhida. Devolve a sessão para o cliente completar os dados pessoais (/client/complete-user-data) e da empresa (/client/complete-company-data).
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.AcceptInvitationDTO  true  "Token, password e dados pessoais em falta"
+// @Success      201      {object}  models.SuccessResponse{data=models.AuthResponse}
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Failure      410      {object}  models.ErrorResponse
+// @Router       /auth/invitation/accept [post]
+func AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := invitationService.AcceptInvitation(req)
+	if err != nil {
+		c.JSON(invitationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Convite aceite. Complete os seus dados pessoais e os dados da empresa.",
+		Data:    response,
+	})
+}
+
+func parseInvitationID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do convite inválido",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func invitationErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "convite inválido" || msg == "convite não encontrado":
+		return http.StatusNotFound
+	case msg == "convite expirado" || msg == "convite foi revogado":
+		return http.StatusGone
+	case strings.HasPrefix(msg, "já existe") || strings.HasPrefix(msg, "convite já") ||
+		strings.HasSuffix(msg, "já está em uso"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	notificationService = services.NewNotificationService()
+)
+
+// GetNotificationTemplates godoc
+// @Summary      Templates de email
+// @Description  Lista os templates de email de cada evento e idioma (editados ou por omissão)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/notification-templates [get]
+func GetNotificationTemplates(c *gin.Context) {
+	templates, err := notificationService.GetTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Templates obtidos com sucesso",
+		Data:    templates,
+	})
+}
+
+// UpdateNotificationTemplate godoc
+// @Summary      Editar template de email
+// @Description  Cria ou atualiza o template (text/template) de um evento num idioma
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        event     path      string                   true  "Evento"
+// @Param        language  path      string                   true  "Idioma (ex.: pt, en)"
+// @Param        request   body      models.EmailTemplateDTO  true  "Assunto e corpo"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/notification-templates/{event}/{language} [put]
+func UpdateNotificationTemplate(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.EmailTemplateDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	tmpl, err := notificationService.UpdateTemplate(c.Param("event"), c.Param("language"), req, userID.(uint))
+	if err != nil {
+		status := http.StatusBadRequest
+		if err.Error() == "erro ao guardar template" {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Template atualizado com sucesso",
+		Data:    tmpl,
+	})
+}
+
+// GetNotificationOutbox godoc
+// @Summary      Caixa de saída de emails
+// @Description  Lista os emails enviados, pendentes ou falhados (os mais recentes primeiro)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "Filtrar por status (pending, sending, sent, failed)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/notification-outbox [get]
+func GetNotificationOutbox(c *gin.Context) {
+	emails, err := notificationService.GetOutbox(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Caixa de saída obtida com sucesso",
+		Data:    emails,
+	})
+}
+
+// RetryNotificationEmail godoc
+// @Summary      Reenviar email
+// @Description  Volta a colocar na fila de envio um email pendente ou falhado
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do email"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/notification-outbox/{id}/retry [post]
+func RetryNotificationEmail(c *gin.Context) {
+	emailID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do email inválido",
+		})
+		return
+	}
+
+	email, err := notificationService.RetryOutboxEmail(uint(emailID))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "email não encontrado":
+			status = http.StatusNotFound
+		case "email já foi enviado":
+			status = http.StatusConflict
+		}
+		c.JSON(status, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Email colocado na fila de envio",
+		Data:    email,
+	})
+}
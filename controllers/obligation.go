@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	obligationService = services.NewObligationService()
+)
+
+// GetMyObligations godoc
+// @Summary      Minhas obrigações fiscais
+// @Description  Calendário de obrigações fiscais e contributivas da empresa do cliente logado (IVA, SAF-T, DMR, Segurança Social, Modelo 22, IES)
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from    query     string  false  "Prazo a partir de (AAAA-MM-DD, por omissão há 3 meses)"
+// @Param        to      query     string  false  "Prazo até (AAAA-MM-DD, por omissão daqui a 12 meses)"
+// @Param        status  query     string  false  "Filtrar por status (pending, submitted, waived)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /client/obligations [get]
+func GetMyObligations(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	obligations, err := obligationService.GetClientObligations(userID.(uint), companyID, c.Query("from"), c.Query("to"), c.Query("status"))
+	if err != nil {
+		c.JSON(obligationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Obrigações obtidas com sucesso",
+		Data:    obligations,
+	})
+}
+
+// GetObligations godoc
+// @Summary      Obrigações fiscais dos clientes
+// @Description  Lista as obrigações de todas as empresas ativas com prazo até due_before (inclui as atrasadas)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        due_before  query     string  false  "Prazo até (AAAA-MM-DD, por omissão daqui a 30 dias)"
+// @Param        status      query     string  false  "Filtrar por status (pending, submitted, waived)"
+// @Param        company_id  query     int     false  "Filtrar por empresa"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/obligations [get]
+func GetObligations(c *gin.Context) {
+	var companyID uint64
+	if value := c.Query("company_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Error:   "ID da empresa inválido",
+			})
+			return
+		}
+		companyID = parsed
+	}
+
+	obligations, err := obligationService.GetObligations(c.Query("due_before"), c.Query("status"), uint(companyID), portfolioScope(c))
+	if err != nil {
+		c.JSON(obligationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Obrigações obtidas com sucesso",
+		Data:    obligations,
+	})
+}
+
+// UpdateObligation godoc
+// @Summary      Atualizar obrigação
+// @Description  Regista a submissão, dispensa ou reabertura de uma obrigação
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                         true  "ID da obrigação"
+// @Param        request  body      models.UpdateObligationDTO  true  "Novo status"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/obligations/{id} [put]
+func UpdateObligation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	obligationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da obrigação inválido",
+		})
+		return
+	}
+
+	var req models.UpdateObligationDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	obligation, err := obligationService.UpdateObligation(uint(obligationID), req, userID.(uint), portfolioScope(c))
+	if err != nil {
+		c.JSON(obligationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Obrigação atualizada com sucesso",
+		Data:    obligation,
+	})
+}
+
+func obligationErrorStatus(err error) int {
+	switch err.Error() {
+	case "empresa não encontrada", "obrigação não encontrada":
+		return http.StatusNotFound
+	case "data inválida (use AAAA-MM-DD)":
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	portfolioService = services.NewPortfolioService()
+)
+
+// GetPortfolios godoc
+// @Summary      Carteiras de clientes
+// @Description  Lista os membros da equipa com o número de empresas na carteira de cada um
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse{data=[]models.PortfolioSummaryDTO}
+// @Router       /admin/portfolios [get]
+func GetPortfolios(c *gin.Context) {
+	portfolios, err := portfolioService.GetPortfolios()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Carteiras obtidas com sucesso",
+		Data:    portfolios,
+	})
+}
+
+// GetPortfolio godoc
+// @Summary      Carteira de um contabilista
+// @Description  Lista as empresas atribuídas a um membro da equipa
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do contabilista"
+// @Success      200  {object}  models.SuccessResponse{data=[]models.Company}
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/portfolios/{id} [get]
+func GetPortfolio(c *gin.Context) {
+	accountantID, ok := parseIDParam(c, "ID do contabilista inválido")
+	if !ok {
+		return
+	}
+
+	companies, err := portfolioService.GetPortfolio(accountantID)
+	if err != nil {
+		c.JSON(portfolioErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		for i := range companies {
+			services.MaskCompanyCredentials(&companies[i])
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Carteira obtida com sucesso",
+		Data:    companies,
+	})
+}
+
+// ReassignPortfolio godoc
+// @Summary      Reatribuir carteira
+// @Description  Passa empresas da carteira de um contabilista para outro (por exemplo, quando alguém sai do gabinete). Sem company_ids passa a carteira inteira. Fica registado na auditoria.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.ReassignPortfolioDTO  true  "Origem, destino e empresas"
+// @Success      200      {object}  models.SuccessResponse{data=models.ReassignPortfolioResultDTO}
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Router       /admin/portfolios/reassign [post]
+func ReassignPortfolio(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.ReassignPortfolioDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := portfolioService.Reassign(req, userID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(portfolioErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Carteira reatribuída com sucesso",
+		Data:    result,
+	})
+}
+
+// GetCompanyAccountants godoc
+// @Summary      Contabilistas de uma empresa
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da empresa"
+// @Success      200  {object}  models.SuccessResponse{data=[]models.CompanyAccountant}
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/accountants [get]
+func GetCompanyAccountants(c *gin.Context) {
+	companyID, ok := parseIDParam(c, "ID da empresa inválido")
+	if !ok {
+		return
+	}
+
+	links, err := portfolioService.GetCompanyAccountants(companyID)
+	if err != nil {
+		c.JSON(portfolioErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Contabilistas da empresa obtidos com sucesso",
+		Data:    links,
+	})
+}
+
+// SetCompanyAccountants godoc
+// @Summary      Atribuir contabilistas a uma empresa
+// @Description  Substitui a lista de contabilistas de uma empresa (uma lista vazia retira a empresa de todas as carteiras). Fica registado na auditoria.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                              true  "ID da empresa"
+// @Param        request  body      models.SetCompanyAccountantsDTO  true  "IDs dos contabilistas"
+// @Success      200      {object}  models.SuccessResponse{data=[]models.CompanyAccountant}
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Router       /admin/companies/{id}/accountants [put]
+func SetCompanyAccountants(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	companyID, ok := parseIDParam(c, "ID da empresa inválido")
+	if !ok {
+		return
+	}
+
+	var req models.SetCompanyAccountantsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	links, err := portfolioService.SetCompanyAccountants(companyID, req.AccountantIDs, userID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(portfolioErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Contabilistas da empresa atualizados com sucesso",
+		Data:    links,
+	})
+}
+
+func parseIDParam(c *gin.Context, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func portfolioErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "não encontrad"):
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -46,3 +46,54 @@ func GetProfile(c *gin.Context) {
 		Data:    user,
 	})
 }
+
+// ChangePassword godoc
+// @Summary      Alterar password
+// @Description  Altera a password do utilizador logado depois de confirmar a password atual
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body      models.ChangePasswordDTO  true  "Password atual e nova password"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /profile/password [put]
+func ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Error:   "Utilizador não autenticado",
+		})
+		return
+	}
+
+	var req models.ChangePasswordDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	if err := userService.ChangePassword(userID.(uint), req); err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "utilizador não encontrado":
+			status = http.StatusNotFound
+		case "password atual incorreta":
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Password alterada com sucesso",
+	})
+}
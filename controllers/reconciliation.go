@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	reconciliationService = services.NewReconciliationService()
+)
+
+// RunClientReconciliation godoc
+// @Summary      Propor conciliações
+// @Description  Compara os pagamentos por conciliar com as faturas de compra em aberto (valor, data, NIF/IBAN do fornecedor e referência) e cria propostas com grau de confiança
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do cliente"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/reconciliation/run [post]
+func RunClientReconciliation(c *gin.Context) {
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := reconciliationService.RunMatching(clientID, companyID)
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Conciliação automática concluída",
+		Data:    result,
+	})
+}
+
+// GetClientReconciliationMatches godoc
+// @Summary      Propostas de conciliação
+// @Description  Lista as conciliações da empresa do cliente, com o movimento e a fatura
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int     true   "ID do cliente"
+// @Param        status  query     string  false  "Estado (proposed, accepted, rejected); por omissão proposed"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/reconciliation/matches [get]
+func GetClientReconciliationMatches(c *gin.Context) {
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	matches, err := reconciliationService.GetMatches(clientID, companyID, c.Query("status"))
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		for i := range matches {
+			services.MaskReconciliationMatchCredentials(&matches[i])
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Conciliações obtidas com sucesso",
+		Data:    matches,
+	})
+}
+
+// AcceptReconciliationMatch godoc
+// @Summary      Aceitar conciliação
+// @Description  Aceita uma proposta; o movimento e a fatura ficam conciliados pelo valor em aberto e o fornecedor ganha uma regra para próximas propostas
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int  true  "ID do cliente"
+// @Param        matchId  path      int  true  "ID da proposta"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/reconciliation/matches/{matchId}/accept [post]
+func AcceptReconciliationMatch(c *gin.Context) {
+	decideReconciliationMatch(c, true)
+}
+
+// RejectReconciliationMatch godoc
+// @Summary      Rejeitar conciliação
+// @Description  Rejeita uma proposta; o mesmo par movimento/fatura não volta a ser proposto
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int  true  "ID do cliente"
+// @Param        matchId  path      int  true  "ID da proposta"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/reconciliation/matches/{matchId}/reject [post]
+func RejectReconciliationMatch(c *gin.Context) {
+	decideReconciliationMatch(c, false)
+}
+
+// SplitClientTransaction godoc
+// @Summary      Dividir movimento por faturas
+// @Description  Concilia um movimento com várias faturas, indicando o valor atribuído a cada uma
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                         true  "ID do cliente"
+// @Param        request  body      models.SplitTransactionDTO  true  "Divisão"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/reconciliation/split [post]
+func SplitClientTransaction(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SplitTransactionDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	matches, err := reconciliationService.SplitTransaction(clientID, companyID, req, userID.(uint), userRole.(string))
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Movimento conciliado com as faturas indicadas",
+		Data:    matches,
+	})
+}
+
+// GetClientReconciliationRules godoc
+// @Summary      Regras por fornecedor
+// @Description  Lista as regras (IBAN e palavra-chave) aprendidas com as conciliações aceites de cada fornecedor
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do cliente"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/reconciliation/rules [get]
+func GetClientReconciliationRules(c *gin.Context) {
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	rules, err := reconciliationService.GetRules(clientID, companyID)
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if !canRevealCredentials(c) {
+		for i := range rules {
+			services.MaskSupplierRuleCredentials(&rules[i])
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Regras obtidas com sucesso",
+		Data:    rules,
+	})
+}
+
+func decideReconciliationMatch(c *gin.Context, accept bool) {
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("matchId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da proposta inválido",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	var match *models.ReconciliationMatch
+	message := "Conciliação aceite"
+	if accept {
+		match, err = reconciliationService.AcceptMatch(clientID, companyID, uint(matchID), userID.(uint), userRole.(string))
+	} else {
+		match, err = reconciliationService.RejectMatch(clientID, companyID, uint(matchID), userID.(uint))
+		message = "Proposta rejeitada"
+	}
+	if err != nil {
+		c.JSON(reconciliationErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    match,
+	})
+}
+
+func parseClientID(c *gin.Context) (uint, bool) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return 0, false
+	}
+	return uint(clientID), true
+}
+
+func reconciliationErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "não encontrada") || strings.HasSuffix(msg, "não encontrado"):
+		return http.StatusNotFound
+	case msg == "a proposta já foi decidida" || strings.Contains(msg, "está fechado") || strings.HasPrefix(msg, "o movimento ou a fatura já") ||
+		(strings.HasPrefix(msg, "a fatura ") && strings.HasSuffix(msg, "já está conciliada com este movimento")):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
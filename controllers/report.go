@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"RVContabilidadeBack/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	reportService = services.NewReportService()
+)
+
+// GetClientDossierPDF godoc
+// @Summary      Dossier do cliente (PDF)
+// @Description  Gera o dossier do cliente com os dados pessoais, preferências, empresa e registo. IBAN, cartão de cidadão e email oficial saem com máscara sem credentials.reveal.
+// @Tags         admin
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id  path      int  true  "ID do cliente"
+// @Success      200  {file}    file
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/reports/dossier [get]
+func GetClientDossierPDF(c *gin.Context) {
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	report, filename, err := reportService.ClientDossier(clientID, canRevealCredentials(c))
+	streamPDFReport(c, report, filename, err)
+}
+
+// GetClientCompanySheetPDF godoc
+// @Summary      Ficha de empresa (PDF)
+// @Description  Gera a ficha da empresa do cliente com identificação, sede, enquadramento fiscal e dados bancários. O IBAN sai com máscara sem credentials.reveal.
+// @Tags         admin
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id  path      int  true  "ID do cliente"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {file}    file
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/reports/company [get]
+func GetClientCompanySheetPDF(c *gin.Context) {
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	report, filename, err := reportService.CompanySheet(clientID, companyID, canRevealCredentials(c))
+	streamPDFReport(c, report, filename, err)
+}
+
+// GetClientChecklistPDF godoc
+// @Summary      Checklist mensal (PDF)
+// @Description  Gera a checklist dos períodos que terminam no mês, com o estado de cada entregável
+// @Tags         admin
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id     path      int     true  "ID do cliente"
+// @Param        month  query     string  true  "Mês (AAAA-MM)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/reports/checklist [get]
+func GetClientChecklistPDF(c *gin.Context) {
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	report, filename, err := reportService.MonthlyChecklist(clientID, companyID, c.Query("month"))
+	streamPDFReport(c, report, filename, err)
+}
+
+// GetClientVATSummaryPDF godoc
+// @Summary      Resumo do IVA (PDF)
+// @Description  Gera o resumo da declaração periódica de IVA, com os campos preenchidos e as vendas por taxa
+// @Tags         admin
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        id              path      int     true   "ID do cliente"
+// @Param        period          query     string  true   "Período (AAAA-MM ou AAAA-Tn)"
+// @Param        carried_credit  query     number  false  "Excesso a reportar do período anterior (campo 61)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/reports/vat-summary [get]
+func GetClientVATSummaryPDF(c *gin.Context) {
+	clientID, carriedCredit, ok := parseVATReturnParams(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	report, filename, err := reportService.VATSummary(clientID, companyID, c.Query("period"), carriedCredit)
+	streamPDFReport(c, report, filename, err)
+}
+
+// streamPDFReport escreve o relatório diretamente na resposta, sem o guardar em disco
+func streamPDFReport(c *gin.Context, report *utils.PDFReport, filename string, err error) {
+	if err != nil {
+		c.JSON(reportErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+	if err := utils.RenderPDFReport(c.Writer, *report); err != nil {
+		log.Printf("Erro ao gerar relatório %s: %v", filename, err)
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Error:   "Erro ao gerar relatório",
+			})
+		}
+	}
+}
+
+func reportErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "cliente não encontrado" || msg == "empresa não encontrada":
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "a empresa está isenta"):
+		return http.StatusUnprocessableEntity
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
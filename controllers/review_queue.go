@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	reviewQueueService = services.NewReviewQueueService()
+)
+
+// GetMyQueue godoc
+// @Summary      Minha fila de revisão
+// @Description  Lista as solicitações pendentes atribuídas ao contabilista autenticado e as que estão por atribuir, com o estado do SLA
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/my-queue [get]
+func GetMyQueue(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	queue, err := reviewQueueService.GetMyQueue(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Fila de revisão obtida com sucesso",
+		Data:    queue,
+	})
+}
+
+// ClaimRequest godoc
+// @Summary      Reclamar solicitação
+// @Description  Atribui uma solicitação pendente, livre e com o email verificado ao contabilista autenticado
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da solicitação"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/requests/{id}/claim [post]
+func ClaimRequest(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da solicitação inválido",
+		})
+		return
+	}
+
+	request, err := reviewQueueService.ClaimRequest(uint(requestID), userID.(uint))
+	if err != nil {
+		c.JSON(queueErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Solicitação atribuída com sucesso",
+		Data:    request,
+	})
+}
+
+// AssignRequest godoc
+// @Summary      Atribuir solicitação
+// @Description  Atribui ou reatribui uma solicitação pendente a um contabilista (apenas admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                      true  "ID da solicitação"
+// @Param        request  body      models.AssignRequestDTO  true  "Contabilista"
+// @Success      200      {object}  models.SuccessResponse
+// @Router       /admin/requests/{id}/assign [post]
+func AssignRequest(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da solicitação inválido",
+		})
+		return
+	}
+
+	var req models.AssignRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	request, err := reviewQueueService.AssignRequest(uint(requestID), req, userID.(uint))
+	if err != nil {
+		c.JSON(queueErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Solicitação atribuída com sucesso",
+		Data:    request,
+	})
+}
+
+// ReleaseRequest godoc
+// @Summary      Libertar solicitação
+// @Description  Devolve uma solicitação à fila (responsável atual ou admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                       true   "ID da solicitação"
+// @Param        request  body      models.ReleaseRequestDTO  false  "Notas"
+// @Success      200      {object}  models.SuccessResponse
+// @Router       /admin/requests/{id}/release [post]
+func ReleaseRequest(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da solicitação inválido",
+		})
+		return
+	}
+
+	var req models.ReleaseRequestDTO
+	_ = c.ShouldBindJSON(&req)
+
+	request, err := reviewQueueService.ReleaseRequest(uint(requestID), userID.(uint), req.Notes)
+	if err != nil {
+		c.JSON(queueErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Solicitação devolvida à fila",
+		Data:    request,
+	})
+}
+
+// GetRequestAssignmentHistory godoc
+// @Summary      Histórico de atribuições
+// @Description  Lista as atribuições, reclamações e libertações de uma solicitação
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da solicitação"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/requests/{id}/assignments [get]
+func GetRequestAssignmentHistory(c *gin.Context) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da solicitação inválido",
+		})
+		return
+	}
+
+	history, err := reviewQueueService.GetAssignmentHistory(uint(requestID), portfolioScope(c))
+	if err != nil {
+		c.JSON(queueErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Histórico de atribuições obtido com sucesso",
+		Data:    history,
+	})
+}
+
+// AutoAssignPendingRequests godoc
+// @Summary      Atribuir solicitações pendentes
+// @Description  Aplica as regras de atribuição a todas as solicitações pendentes sem responsável (apenas admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/pending-requests/auto-assign [post]
+func AutoAssignPendingRequests(c *gin.Context) {
+	assigned, err := reviewQueueService.AutoAssignPending()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Regras de atribuição aplicadas",
+		Data:    gin.H{"assigned": assigned},
+	})
+}
+
+// GetAssignmentRules godoc
+// @Summary      Listar regras de atribuição
+// @Description  Lista as regras de distribuição de solicitações pelos contabilistas (apenas admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/assignment-rules [get]
+func GetAssignmentRules(c *gin.Context) {
+	rules, err := reviewQueueService.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Regras de atribuição obtidas com sucesso",
+		Data:    rules,
+	})
+}
+
+// CreateAssignmentRule godoc
+// @Summary      Criar regra de atribuição
+// @Description  Cria uma regra round-robin, por distrito ou por forma jurídica (apenas admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        rule  body      models.AssignmentRuleDTO  true  "Regra"
+// @Success      201   {object}  models.SuccessResponse
+// @Router       /admin/assignment-rules [post]
+func CreateAssignmentRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.AssignmentRuleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rule, err := reviewQueueService.CreateRule(req, userID.(uint))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "valor de correspondência é obrigatório" ||
+			err.Error() == "contabilista é obrigatório" {
+			statusCode = http.StatusBadRequest
+		} else if err.Error() == "contabilista não encontrado" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Regra de atribuição criada com sucesso",
+		Data:    rule,
+	})
+}
+
+// DeleteAssignmentRule godoc
+// @Summary      Eliminar regra de atribuição
+// @Description  Elimina uma regra de atribuição (apenas admin)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da regra"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/assignment-rules/{id} [delete]
+func DeleteAssignmentRule(c *gin.Context) {
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da regra inválido",
+		})
+		return
+	}
+
+	if err := reviewQueueService.DeleteRule(uint(ruleID)); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "regra não encontrada" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Regra de atribuição eliminada com sucesso",
+		Data:    gin.H{"deleted_rule_id": ruleID},
+	})
+}
+
+// queueErrorStatus converte os erros da fila de revisão em códigos HTTP
+func queueErrorStatus(err error) int {
+	switch err.Error() {
+	case "solicitação não encontrada", "contabilista não encontrado":
+		return http.StatusNotFound
+	case "solicitação já foi processada", "solicitação não está atribuída", "o email da solicitação ainda não foi verificado":
+		return http.StatusBadRequest
+	case "solicitação já atribuída a outro contabilista", "solicitação atribuída a outro contabilista":
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
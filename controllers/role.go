@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPermissionCatalog godoc
+// @Summary      Catálogo de permissões
+// @Description  Lista as permissões que podem ser incluídas nos perfis ou concedidas a utilizadores
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse{data=[]models.PermissionDTO}
+// @Router       /admin/permissions [get]
+func GetPermissionCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Permissões obtidas com sucesso",
+		Data:    models.AllPermissions,
+	})
+}
+
+// GetRoles godoc
+// @Summary      Perfis
+// @Description  Lista os perfis com as respetivas permissões e o número de utilizadores de cada um
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SuccessResponse{data=[]models.Role}
+// @Router       /admin/roles [get]
+func GetRoles(c *gin.Context) {
+	roles, err := permissionService.GetRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Perfis obtidos com sucesso",
+		Data:    roles,
+	})
+}
+
+// GetRole godoc
+// @Summary      Detalhes de um perfil
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do perfil"
+// @Success      200  {object}  models.SuccessResponse{data=models.Role}
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/roles/{id} [get]
+func GetRole(c *gin.Context) {
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	role, err := permissionService.GetRole(roleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Perfil obtido com sucesso",
+		Data:    role,
+	})
+}
+
+// CreateRole godoc
+// @Summary      Criar perfil
+// @Description  Cria um perfil com um conjunto de permissões do catálogo; fica registado na auditoria
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role  body      models.RoleDTO  true  "Nome, descrição e permissões"
+// @Success      201   {object}  models.SuccessResponse{data=models.Role}
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /admin/roles [post]
+func CreateRole(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.RoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	role, err := permissionService.CreateRole(req, userID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(roleErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "Perfil criado com sucesso",
+		Data:    role,
+	})
+}
+
+// UpdateRole godoc
+// @Summary      Alterar perfil
+// @Description  Substitui as permissões (e a descrição) de um perfil. Os perfis base não podem ser renomeados e o perfil admin mantém sempre backoffice.access e roles.manage. As alterações aplicam-se de imediato a todos os utilizadores com o perfil.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int             true  "ID do perfil"
+// @Param        role  body      models.RoleDTO  true  "Nome, descrição e permissões"
+// @Success      200   {object}  models.SuccessResponse{data=models.Role}
+// @Failure      400   {object}  models.ErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Router       /admin/roles/{id} [put]
+func UpdateRole(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	role, err := permissionService.UpdateRole(roleID, req, userID.(uint), c.ClientIP())
+	if err != nil {
+		c.JSON(roleErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Perfil atualizado com sucesso",
+		Data:    role,
+	})
+}
+
+// DeleteRole godoc
+// @Summary      Eliminar perfil
+// @Description  Elimina um perfil que não seja base e que não esteja atribuído a nenhum utilizador
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do perfil"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/roles/{id} [delete]
+func DeleteRole(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	roleID, ok := parseRoleID(c)
+	if !ok {
+		return
+	}
+
+	if err := permissionService.DeleteRole(roleID, userID.(uint), c.ClientIP()); err != nil {
+		c.JSON(roleErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Perfil eliminado com sucesso",
+	})
+}
+
+// UpdateUserRole godoc
+// @Summary      Mudar perfil de um utilizador
+// @Description  Atribui outro perfil a um membro da equipa. Só é possível atribuir perfis cujas permissões o próprio tem; fica registado na auditoria.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                       true  "ID do utilizador"
+// @Param        request  body      models.UpdateUserRoleDTO  true  "Novo perfil"
+// @Success      200      {object}  models.SuccessResponse
+// @Failure      400      {object}  models.ErrorResponse
+// @Failure      403      {object}  models.ErrorResponse
+// @Failure      404      {object}  models.ErrorResponse
+// @Router       /admin/users/{id}/role [put]
+func UpdateUserRole(c *gin.Context) {
+	editorID, _ := c.Get("user_id")
+	editorRole, _ := c.Get("user_role")
+
+	targetID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Dados inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := permissionService.SetUserRole(targetID, req.Role, editorID.(uint), editorRole.(string), c.ClientIP())
+	if err != nil {
+		c.JSON(roleErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Perfil do utilizador alterado com sucesso",
+		Data:    gin.H{"user_id": user.ID, "role": user.Role},
+	})
+}
+
+func parseRoleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do perfil inválido",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func roleErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "não encontrado"):
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "sem permissão") || strings.HasPrefix(msg, "não pode alterar"):
+		return http.StatusForbidden
+	case strings.HasPrefix(msg, "já existe") || strings.HasPrefix(msg, "perfil atribuído") ||
+		strings.HasPrefix(msg, "os perfis base"):
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	saftService = services.NewSAFTService()
+)
+
+// UploadMySAFT godoc
+// @Summary      Importar SAF-T
+// @Description  Envia um ficheiro SAF-T (PT) 1.04_01 de faturação da empresa do cliente logado; o ficheiro é validado em segundo plano
+// @Tags         client
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file  formData  file  true  "Ficheiro SAF-T (XML)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      202  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /client/saft-imports [post]
+func UploadMySAFT(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	startSAFTImport(c, userID.(uint), userID.(uint))
+}
+
+// GetMySAFTImports godoc
+// @Summary      Minhas importações SAF-T
+// @Description  Lista as importações SAF-T da empresa do cliente logado
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /client/saft-imports [get]
+func GetMySAFTImports(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	listSAFTImports(c, userID.(uint))
+}
+
+// GetMySAFTImport godoc
+// @Summary      Detalhe de importação SAF-T
+// @Description  Devolve o estado, os totais, os resumos de vendas e os erros por linha de uma importação
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID da importação"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /client/saft-imports/{id} [get]
+func GetMySAFTImport(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	importID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da importação inválido",
+		})
+		return
+	}
+
+	getSAFTImportDetail(c, userID.(uint), uint(importID))
+}
+
+// GetMySAFTSummary godoc
+// @Summary      Resumo de vendas SAF-T
+// @Description  Agrega as vendas de uma importação por período, taxa de IVA ou cliente
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int     true   "ID da importação"
+// @Param        group_by  query     string  false  "Agrupamento (period, tax_rate, customer)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /client/saft-imports/{id}/summary [get]
+func GetMySAFTSummary(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	importID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da importação inválido",
+		})
+		return
+	}
+
+	getSAFTSummary(c, userID.(uint), uint(importID))
+}
+
+// UploadClientSAFT godoc
+// @Summary      Importar SAF-T de um cliente
+// @Description  Envia um ficheiro SAF-T (PT) 1.04_01 em nome de um cliente (contabilista/admin)
+// @Tags         admin
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int   true  "ID do cliente"
+// @Param        file  formData  file  true  "Ficheiro SAF-T (XML)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      202  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/saft-imports [post]
+func UploadClientSAFT(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	startSAFTImport(c, uint(clientID), userID.(uint))
+}
+
+// GetClientSAFTImports godoc
+// @Summary      Importações SAF-T de um cliente
+// @Description  Lista as importações SAF-T da empresa de um cliente
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID do cliente"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Router       /admin/clients/{id}/saft-imports [get]
+func GetClientSAFTImports(c *gin.Context) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return
+	}
+
+	listSAFTImports(c, uint(clientID))
+}
+
+// GetClientSAFTImport godoc
+// @Summary      Detalhe de importação SAF-T de um cliente
+// @Description  Devolve o estado, os totais, os resumos de vendas e os erros por linha de uma importação
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int  true  "ID do cliente"
+// @Param        importId  path      int  true  "ID da importação"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/saft-imports/{importId} [get]
+func GetClientSAFTImport(c *gin.Context) {
+	clientID, importID, ok := parseClientSAFTImportIDs(c)
+	if !ok {
+		return
+	}
+	getSAFTImportDetail(c, clientID, importID)
+}
+
+// GetClientSAFTSummary godoc
+// @Summary      Resumo de vendas SAF-T de um cliente
+// @Description  Agrega as vendas de uma importação por período, taxa de IVA ou cliente
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      int     true   "ID do cliente"
+// @Param        importId  path      int     true   "ID da importação"
+// @Param        group_by  query     string  false  "Agrupamento (period, tax_rate, customer)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/saft-imports/{importId}/summary [get]
+func GetClientSAFTSummary(c *gin.Context) {
+	clientID, importID, ok := parseClientSAFTImportIDs(c)
+	if !ok {
+		return
+	}
+	getSAFTSummary(c, clientID, importID)
+}
+
+func startSAFTImport(c *gin.Context, clientID, uploadedBy uint) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Ficheiro em falta (campo file)",
+		})
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	saftImport, err := saftService.StartImport(clientID, companyID, uploadedBy, fileHeader)
+	if err != nil {
+		c.JSON(saftErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Success: true,
+		Message: "Ficheiro SAF-T recebido; a importação está a ser processada",
+		Data:    saftImport,
+	})
+}
+
+func listSAFTImports(c *gin.Context, clientID uint) {
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	imports, err := saftService.GetImports(clientID, companyID)
+	if err != nil {
+		c.JSON(saftErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Importações obtidas com sucesso",
+		Data:    imports,
+	})
+}
+
+func getSAFTImportDetail(c *gin.Context, clientID, importID uint) {
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	detail, err := saftService.GetImportDetail(clientID, companyID, importID)
+	if err != nil {
+		c.JSON(saftErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Importação obtida com sucesso",
+		Data:    detail,
+	})
+}
+
+func getSAFTSummary(c *gin.Context, clientID, importID uint) {
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	rows, err := saftService.GetSalesSummary(clientID, companyID, importID, c.Query("group_by"))
+	if err != nil {
+		c.JSON(saftErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Resumo de vendas obtido com sucesso",
+		Data:    rows,
+	})
+}
+
+func parseClientSAFTImportIDs(c *gin.Context) (uint, uint, bool) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID do cliente inválido",
+		})
+		return 0, 0, false
+	}
+
+	importID, err := strconv.ParseUint(c.Param("importId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "ID da importação inválido",
+		})
+		return 0, 0, false
+	}
+
+	return uint(clientID), uint(importID), true
+}
+
+func saftErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "empresa não encontrada" || msg == "importação não encontrada":
+		return http.StatusNotFound
+	case msg == "sem permissão para alterar esta empresa":
+		return http.StatusForbidden
+	case msg == "este ficheiro SAF-T já foi importado":
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "ficheiro excede"):
+		return http.StatusRequestEntityTooLarge
+	case strings.HasPrefix(msg, "agrupamento inválido"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
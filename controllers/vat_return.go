@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	vatReturnService = services.NewVATReturnService()
+)
+
+// GetClientVATReturn godoc
+// @Summary      Declaração periódica de IVA
+// @Description  Calcula os campos 1 a 24 da declaração periódica a partir do SAF-T e do e-Fatura importados, com o imposto a entregar ou a recuperar
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id              path      int     true   "ID do cliente"
+// @Param        period          query     string  true   "Período (AAAA-MM no regime mensal, AAAA-Tn no trimestral)"
+// @Param        carried_credit  query     number  false  "Excesso a reportar do período anterior (campo 61)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {object}  models.SuccessResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/vat-return [get]
+func GetClientVATReturn(c *gin.Context) {
+	clientID, carriedCredit, ok := parseVATReturnParams(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := vatReturnService.Compute(clientID, companyID, c.Query("period"), carriedCredit)
+	if err != nil {
+		c.JSON(vatReturnErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "Declaração periódica calculada com sucesso",
+		Data:    result,
+	})
+}
+
+// ExportClientVATReturnXML godoc
+// @Summary      Exportar declaração periódica de IVA (XML)
+// @Description  Exporta a declaração calculada em XML com a estrutura da declaração periódica da AT (rosto, quadros 01, 02, 05 e 06 e campos pelos códigos oficiais), para conferência
+// @Tags         admin
+// @Produce      xml
+// @Security     BearerAuth
+// @Param        id              path      int     true   "ID do cliente"
+// @Param        period          query     string  true   "Período (AAAA-MM ou AAAA-Tn)"
+// @Param        carried_credit  query     number  false  "Excesso a reportar do período anterior (campo 61)"
+// @Param        company_id  query     int     false  "ID da empresa (por omissão a principal)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  models.ErrorResponse
+// @Router       /admin/clients/{id}/vat-return/xml [get]
+func ExportClientVATReturnXML(c *gin.Context) {
+	clientID, carriedCredit, ok := parseVATReturnParams(c)
+	if !ok {
+		return
+	}
+
+	companyID, ok := companyIDQuery(c)
+	if !ok {
+		return
+	}
+
+	content, filename, err := vatReturnService.ExportXML(clientID, companyID, c.Query("period"), carriedCredit)
+	if err != nil {
+		c.JSON(vatReturnErrorStatus(err), models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", content)
+}
+
+func parseVATReturnParams(c *gin.Context) (uint, float64, bool) {
+	clientID, ok := parseClientID(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	carriedCredit := 0.0
+	if value := c.Query("carried_credit"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Error:   "Valor de carried_credit inválido",
+			})
+			return 0, 0, false
+		}
+		carriedCredit = parsed
+	}
+	return clientID, carriedCredit, true
+}
+
+func vatReturnErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "empresa não encontrada":
+		return http.StatusNotFound
+	case strings.HasPrefix(msg, "a empresa está isenta"):
+		return http.StatusUnprocessableEntity
+	case strings.HasPrefix(msg, "erro ao"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
@@ -22,7 +22,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Aprova ou rejeita uma solicitação de registo (apenas contabilistas/admin)",
+                "description": "Aprova ou rejeita uma solicitação de registo (apenas contabilistas/admin). Aprovar ou pedir informação exige o email da solicitação verificado.",
                 "consumes": [
                     "application/json"
                 ],
@@ -54,14 +54,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/clients": {
+        "/admin/assignment-rules": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lista todos os clientes com status aprovado (apenas contabilista/admin)",
+                "description": "Lista as regras de distribuição de solicitações pelos contabilistas (apenas admin)",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,7 +71,7 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Listar clientes aprovados",
+                "summary": "Listar regras de atribuição",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -80,16 +80,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/clients/overview": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lista resumida de todos os clientes (pendentes e aprovados) para visão geral",
+                "description": "Cria uma regra round-robin, por distrito ou por forma jurídica (apenas admin)",
                 "consumes": [
                     "application/json"
                 ],
@@ -99,10 +97,21 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Visão geral de todos os clientes",
+                "summary": "Criar regra de atribuição",
+                "parameters": [
+                    {
+                        "description": "Regra",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AssignmentRuleDTO"
+                        }
+                    }
+                ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
@@ -110,14 +119,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/clients/{id}": {
-            "put": {
+        "/admin/assignment-rules/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Atualiza dados pessoais de um cliente (apenas contabilista/admin)",
+                "description": "Elimina uma regra de atribuição (apenas admin)",
                 "consumes": [
                     "application/json"
                 ],
@@ -127,23 +136,14 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Atualizar dados de cliente",
+                "summary": "Eliminar regra de atribuição",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID do cliente",
+                        "description": "ID da regra",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Dados para atualizar",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AdminUpdateClientDTO"
-                        }
                     }
                 ],
                 "responses": {
@@ -154,14 +154,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/audit-logs": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Elimina um cliente e a sua empresa (apenas contabilista/admin)",
+                "description": "Lista as ações auditadas (exportações, permissões), das mais recentes para as mais antigas",
                 "consumes": [
                     "application/json"
                 ],
@@ -171,14 +173,37 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Eliminar cliente",
+                "summary": "Registo de auditoria",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ação (export, permission_grant, permission_revoke)",
+                        "name": "action",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "ID do cliente",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ID do utilizador que fez a ação",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data inicial (AAAA-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data final (AAAA-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número máximo de entradas (até 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -187,18 +212,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/clients/{id}/company": {
-            "put": {
+        "/admin/checklists/generate": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Atualiza dados da empresa de um cliente (apenas contabilista/admin)",
+                "description": "Gera os itens da checklist das empresas ativas cujo período termina no mês indicado",
                 "consumes": [
                     "application/json"
                 ],
@@ -208,23 +239,13 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Atualizar empresa do cliente",
+                "summary": "Gerar checklists",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do cliente",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Dados da empresa para atualizar",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AdminUpdateCompanyDTO"
-                        }
+                        "type": "string",
+                        "description": "Mês (AAAA-MM, por omissão o mês anterior)",
+                        "name": "month",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -237,14 +258,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/dashboard": {
+        "/admin/checklists/late-matrix": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Obtém dados resumidos para o dashboard da contabilista incluindo estatísticas e clientes pendentes",
+                "description": "Mostra, por empresa e por mês, se os documentos do período foram entregues, estão pendentes ou atrasados",
                 "consumes": [
                     "application/json"
                 ],
@@ -254,7 +275,21 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Dados do dashboard para contabilista",
+                "summary": "Matriz de atrasos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Primeiro mês (AAAA-MM, por omissão mostra os 6 meses até to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último mês (AAAA-MM, por omissão o mês anterior)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -265,14 +300,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/pending-requests": {
-            "get": {
+        "/admin/checklists/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lista todas as solicitações de registo pendentes (apenas contabilistas/admin)",
+                "description": "Dispensa, reabre ou dá como entregue um item da checklist",
                 "consumes": [
                     "application/json"
                 ],
@@ -282,25 +317,49 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Listar solicitações pendentes",
+                "summary": "Atualizar item da checklist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do item",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateChecklistItemDTO"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/requests": {
+        "/admin/client-imports": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lista todos os pedidos de registo pendentes para aprovação",
+                "description": "Lista as importações e simulações de clientes feitas, das mais recentes para as mais antigas",
                 "consumes": [
                     "application/json"
                 ],
@@ -310,7 +369,7 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Listar pedidos de registo pendentes",
+                "summary": "Importações de clientes",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -319,18 +378,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/requests/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Obtém detalhes completos de um pedido de registo específico",
+                "description": "Valida cada linha (campos obrigatórios, NIF/NIPC, duplicados no ficheiro e na base de dados) e devolve o relatório por linha. Com dry_run=true nada é criado; caso contrário cria em lotes os clientes aprovados (mode=approved) ou solicitações existing_client. Os clientes criados recebem por email um link de ativação para definirem a password.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
@@ -338,34 +395,52 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Detalhes de um pedido de registo",
+                "summary": "Importar clientes (CSV/XLSX)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do pedido",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Ficheiro CSV ou XLSX",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Modo (approved, existing_client)",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Simulação sem criar registos",
+                        "name": "dry_run",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/users": {
+        "/admin/client-imports/columns": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lista todos os utilizadores do sistema (apenas admin/contabilista)",
+                "description": "Lista os campos de User/Company aceites no ficheiro de importação, com os nomes de coluna reconhecidos e os obrigatórios",
                 "consumes": [
                     "application/json"
                 ],
@@ -375,7 +450,7 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Listar todos os utilizadores",
+                "summary": "Colunas da importação de clientes",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -386,14 +461,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/count": {
+        "/admin/client-imports/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Conta quantos utilizadores existem por status e role",
+                "description": "Devolve a importação com o estado e os erros de cada linha",
                 "consumes": [
                     "application/json"
                 ],
@@ -403,25 +478,40 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Contar utilizadores",
+                "summary": "Relatório de uma importação de clientes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da importação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/users/simple": {
+        "/admin/clients": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lista utilizadores sem relacionamentos para debug",
+                "description": "Lista os clientes com status aprovado. Sem a permissão clients.all, só os da carteira do utilizador",
                 "consumes": [
                     "application/json"
                 ],
@@ -431,7 +521,15 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Listar utilizadores (simples)",
+                "summary": "Listar clientes aprovados",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pesquisa por nome, email, username, NIF, empresa ou NIPC",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -442,14 +540,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{id}": {
+        "/admin/clients/overview": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Obtém detalhes completos de um utilizador",
+                "description": "Lista resumida de todos os clientes (pendentes e aprovados) para visão geral",
                 "consumes": [
                     "application/json"
                 ],
@@ -459,16 +557,7 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Detalhes de um utilizador",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID do utilizador",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Visão geral de todos os clientes",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -479,14 +568,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{id}/status": {
+        "/admin/clients/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Bloqueia/desbloqueia utilizador (apenas admin)",
+                "description": "Atualiza dados pessoais de um cliente (apenas contabilista/admin)",
                 "consumes": [
                     "application/json"
                 ],
@@ -496,22 +585,22 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Alterar status de utilizador",
+                "summary": "Atualizar dados de cliente",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID do utilizador",
+                        "description": "ID do cliente",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Novo status",
+                        "description": "Dados para atualizar",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateUserStatusDTO"
+                            "$ref": "#/definitions/models.AdminUpdateClientDTO"
                         }
                     }
                 ],
@@ -523,11 +612,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/login": {
-            "post": {
-                "description": "Login com username e password",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina um cliente. As empresas em que é o único utilizador ligado são eliminadas com todos os dados (documentos, extratos, ...); nas outras só lhe é retirado o acesso. Se alguma dessas empresas tiver faturas emitidas, lançamentos ou períodos fechados, nada é eliminado (409) e o cliente deve ser bloqueado. Fica registado na auditoria.",
                 "consumes": [
                     "application/json"
                 ],
@@ -535,38 +627,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Entrar",
+                "summary": "Eliminar cliente",
                 "parameters": [
                     {
-                        "description": "Username e password",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.AuthResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/logout": {
-            "post": {
+        "/admin/clients/{id}/bank-statements": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove token do lado do cliente",
+                "description": "Lista os extratos bancários importados para a empresa do cliente",
                 "consumes": [
                     "application/json"
                 ],
@@ -574,42 +670,76 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Extratos importados",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Logout do utilizador",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     }
                 }
-            }
-        },
-        "/auth/register": {
+            },
             "post": {
-                "description": "Cria uma nova solicitação de registo para aprovação da contabilista",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Importa um extrato (CAMT.053, OFX ou CSV dos bancos portugueses) para o livro bancário da empresa do cliente; movimentos com a mesma referência do banco são ignorados",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Registo de novo cliente",
+                "summary": "Importar extrato bancário",
                 "parameters": [
                     {
-                        "description": "Dados de registo completos",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RegistrationRequestDTO"
-                        }
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Extrato",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Formato (camt053, ofx, csv, csv_cgd, csv_millennium, csv_novobanco, csv_santander, csv_bpi, csv_credito_agricola); por omissão é detetado",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -618,13 +748,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/register-direct": {
-            "post": {
-                "description": "Regista novo utilizador diretamente (para uso interno)",
+        "/admin/clients/{id}/bank-transactions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os movimentos bancários da empresa do cliente",
                 "consumes": [
                     "application/json"
                 ],
@@ -632,38 +773,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Criar conta diretamente",
+                "summary": "Livro bancário",
                 "parameters": [
                     {
-                        "description": "Dados de registo",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RegisterRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por estado (unmatched, matched)",
+                        "name": "match_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data inicial (AAAA-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data final (AAAA-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de resultados (por omissão 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Deslocamento",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.AuthResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     }
                 }
             }
         },
-        "/client/company": {
+        "/admin/clients/{id}/bank-transactions/unmatched": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Obtém dados da empresa do cliente logado",
+                "description": "Lista os movimentos bancários da empresa do cliente que ainda não foram associados a uma fatura",
                 "consumes": [
                     "application/json"
                 ],
@@ -671,9 +846,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "client"
+                    "admin"
+                ],
+                "summary": "Movimentos por conciliar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data inicial (AAAA-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data final (AAAA-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de resultados (por omissão 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Deslocamento",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Dados da empresa do cliente",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -682,14 +896,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/clients/{id}/companies": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Atualiza dados da empresa do cliente (campos limitados)",
+                "description": "Lista as empresas a que o cliente está ligado, com o papel em cada uma",
                 "consumes": [
                     "application/json"
                 ],
@@ -697,38 +913,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "client"
+                    "admin"
                 ],
-                "summary": "Atualizar dados da empresa",
+                "summary": "Empresas de um cliente",
                 "parameters": [
                     {
-                        "description": "Dados a atualizar",
-                        "name": "company",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateCompanyDTO"
-                        }
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ClientCompanyDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            }
-        },
-        "/client/complete-company-data": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Completa dados da empresa adicionais após aprovação",
+                "description": "Cria uma nova empresa para um cliente existente (por exemplo, um ENI que abre uma Lda). Entra nas carteiras dos contabilistas das outras empresas do cliente e fica registada na auditoria.",
                 "consumes": [
                     "application/json"
                 ],
@@ -736,38 +963,75 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "client"
+                    "admin"
                 ],
-                "summary": "Completar dados da empresa",
+                "summary": "Acrescentar empresa a um cliente",
                 "parameters": [
                     {
-                        "description": "Dados da empresa completos",
-                        "name": "data",
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados da empresa",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CompleteCompanyDataDTO"
+                            "$ref": "#/definitions/models.CreateClientCompanyDTO"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ClientCompanyDTO"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/client/complete-user-data": {
+        "/admin/clients/{id}/company": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Completa dados pessoais adicionais após aprovação",
+                "description": "Atualiza dados de uma empresa do cliente; sem company_id, a empresa principal (apenas contabilista/admin)",
                 "consumes": [
                     "application/json"
                 ],
@@ -775,17 +1039,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "client"
+                    "admin"
                 ],
-                "summary": "Completar dados pessoais",
+                "summary": "Atualizar empresa do cliente",
                 "parameters": [
                     {
-                        "description": "Dados pessoais completos",
-                        "name": "data",
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (o cliente pode ter várias)",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Dados da empresa para atualizar",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CompleteUserDataDTO"
+                            "$ref": "#/definitions/models.AdminUpdateCompanyDTO"
                         }
                     }
                 ],
@@ -799,14 +1076,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/client/profile": {
+        "/admin/clients/{id}/documents": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Obtém perfil do cliente logado",
+                "description": "Lista os documentos enviados por um cliente (contabilista/admin)",
                 "consumes": [
                     "application/json"
                 ],
@@ -814,9 +1091,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "client"
+                    "admin"
+                ],
+                "summary": "Documentos de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por tipo",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por período fiscal",
+                        "name": "fiscal_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por status (received, accepted, rejected)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Perfil do cliente",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -825,53 +1135,69 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/clients/{id}/documents/{docId}/download": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Atualiza dados pessoais do cliente",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Descarrega um documento enviado por um cliente (contabilista/admin)",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "client"
+                    "admin"
                 ],
-                "summary": "Atualizar perfil do cliente",
+                "summary": "Descarregar documento de um cliente",
                 "parameters": [
                     {
-                        "description": "Dados a atualizar",
-                        "name": "profile",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateProfileDTO"
-                        }
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do documento",
+                        "name": "docId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/client/requests": {
-            "get": {
+        "/admin/clients/{id}/documents/{docId}/status": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lista todas as solicitações do cliente logado",
+                "description": "Aceita ou rejeita um documento enviado por um cliente (contabilista/admin)",
                 "consumes": [
                     "application/json"
                 ],
@@ -879,22 +1205,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "client"
+                    "admin"
+                ],
+                "summary": "Alterar status de documento",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do documento",
+                        "name": "docId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateDocumentStatusDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Histórico de solicitações do cliente",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
-                    }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "/info": {
+        "/admin/clients/{id}/purchase-invoices": {
             "get": {
-                "description": "Como usar a API e autenticação",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as faturas de compra importadas do e-Fatura para a empresa do cliente",
                 "consumes": [
                     "application/json"
                 ],
@@ -902,497 +1270,7721 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "info"
+                    "admin"
+                ],
+                "summary": "Faturas de compra de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por mês (AAAA-MM)",
+                        "name": "fiscal_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por correspondência (matched, unmatched)",
+                        "name": "match_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Informações da API",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/purchase-invoices/discrepancies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Relatório, por mês, das faturas de compra comunicadas à AT que o cliente não enviou",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Faturas em falta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês inicial (AAAA-MM)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês final (AAAA-MM)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/purchase-invoices/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Importa o CSV de faturas de compra exportado do portal e-Fatura para a empresa do cliente, ignorando faturas já importadas (mesmo ATCUD ou mesmo NIF do emitente e número) e associando-as aos documentos enviados pelo cliente",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Importar faturas de compra do e-Fatura",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV exportado do e-Fatura",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/purchase-invoices/{invoiceId}/match": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Associa manualmente uma fatura do e-Fatura a um documento enviado pelo cliente (document_id null desfaz a associação)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Associar fatura a documento",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da fatura",
+                        "name": "invoiceId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Documento",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchPurchaseInvoiceDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/purchase-invoices/{invoiceId}/vat": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Define a categoria da fatura (imobilizado, existências ou outros) e a percentagem de IVA dedutível usadas na declaração periódica",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Classificar fatura para o IVA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da fatura",
+                        "name": "invoiceId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Classificação",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ClassifyPurchaseInvoiceDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reconciliation/matches": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as conciliações da empresa do cliente, com o movimento e a fatura",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Propostas de conciliação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estado (proposed, accepted, rejected); por omissão proposed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reconciliation/matches/{matchId}/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aceita uma proposta; o movimento e a fatura ficam conciliados pelo valor em aberto e o fornecedor ganha uma regra para próximas propostas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Aceitar conciliação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da proposta",
+                        "name": "matchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reconciliation/matches/{matchId}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rejeita uma proposta; o mesmo par movimento/fatura não volta a ser proposto",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rejeitar conciliação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da proposta",
+                        "name": "matchId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reconciliation/rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as regras (IBAN e palavra-chave) aprendidas com as conciliações aceites de cada fornecedor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Regras por fornecedor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reconciliation/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compara os pagamentos por conciliar com as faturas de compra em aberto (valor, data, NIF/IBAN do fornecedor e referência) e cria propostas com grau de confiança",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Propor conciliações",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reconciliation/split": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Concilia um movimento com várias faturas, indicando o valor atribuído a cada uma",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dividir movimento por faturas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Divisão",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SplitTransactionDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reports/checklist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera a checklist dos períodos que terminam no mês, com o estado de cada entregável",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Checklist mensal (PDF)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mês (AAAA-MM)",
+                        "name": "month",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reports/company": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera a ficha da empresa do cliente com identificação, sede, enquadramento fiscal e dados bancários. O IBAN sai com máscara sem credentials.reveal.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Ficha de empresa (PDF)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reports/dossier": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera o dossier do cliente com os dados pessoais, preferências, empresa e registo. IBAN, cartão de cidadão e email oficial saem com máscara sem credentials.reveal.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dossier do cliente (PDF)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/reports/vat-summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera o resumo da declaração periódica de IVA, com os campos preenchidos e as vendas por taxa",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Resumo do IVA (PDF)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Período (AAAA-MM ou AAAA-Tn)",
+                        "name": "period",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Excesso a reportar do período anterior (campo 61)",
+                        "name": "carried_credit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/saft-imports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as importações SAF-T da empresa de um cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Importações SAF-T de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Envia um ficheiro SAF-T (PT) 1.04_01 em nome de um cliente (contabilista/admin)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Importar SAF-T de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Ficheiro SAF-T (XML)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/saft-imports/{importId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devolve o estado, os totais, os resumos de vendas e os erros por linha de uma importação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Detalhe de importação SAF-T de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da importação",
+                        "name": "importId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/saft-imports/{importId}/summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Agrega as vendas de uma importação por período, taxa de IVA ou cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Resumo de vendas SAF-T de um cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da importação",
+                        "name": "importId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Agrupamento (period, tax_rate, customer)",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/vat-return": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Calcula os campos 1 a 24 da declaração periódica a partir do SAF-T e do e-Fatura importados, com o imposto a entregar ou a recuperar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Declaração periódica de IVA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Período (AAAA-MM no regime mensal, AAAA-Tn no trimestral)",
+                        "name": "period",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Excesso a reportar do período anterior (campo 61)",
+                        "name": "carried_credit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/clients/{id}/vat-return/xml": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exporta a declaração calculada em XML com a estrutura da declaração periódica da AT (rosto, quadros 01, 02, 05 e 06 e campos pelos códigos oficiais), para conferência",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Exportar declaração periódica de IVA (XML)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Período (AAAA-MM ou AAAA-Tn)",
+                        "name": "period",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Excesso a reportar do período anterior (campo 61)",
+                        "name": "carried_credit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/accountants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Contabilistas de uma empresa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.CompanyAccountant"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Substitui a lista de contabilistas de uma empresa (uma lista vazia retira a empresa de todas as carteiras). Fica registado na auditoria.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Atribuir contabilistas a uma empresa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "IDs dos contabilistas",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetCompanyAccountantsDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.CompanyAccountant"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/accounts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista o plano de contas SNC da empresa (criado automaticamente na primeira utilização)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Plano de contas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Classe SNC (1 a 8)",
+                        "name": "class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas contas de movimento",
+                        "name": "postable",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria uma subconta no plano de contas da empresa; a conta mãe não pode ter lançamentos",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Criar subconta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Conta",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAccountDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/accounts/{accountId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Altera o nome de uma conta ou ativa/desativa-a",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Atualizar conta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da conta",
+                        "name": "accountId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Alterações",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateAccountDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/fiscal-periods/{periodId}/close": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fecha um período: soft_closed bloqueia alterações dos clientes, hard_closed bloqueia alterações de todos",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Fechar período",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do período",
+                        "name": "periodId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Estado",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CloseFiscalPeriodDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/fiscal-periods/{periodId}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os fechos e reaberturas do período, com o utilizador e o motivo",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Histórico do período",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do período",
+                        "name": "periodId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/fiscal-periods/{periodId}/reopen": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reabre um período fechado (apenas admin); o motivo fica registado no histórico do período",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reabrir período",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do período",
+                        "name": "periodId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo e novo estado",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReopenFiscalPeriodDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/fiscal-years": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os exercícios da empresa com os períodos mensais e o respetivo estado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Exercícios",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Abre um exercício com 12 períodos mensais",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Abrir exercício",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Exercício",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateFiscalYearDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/fiscal-years/{yearId}/close": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fecha todos os períodos do exercício com o estado indicado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Fechar exercício",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do exercício",
+                        "name": "yearId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Estado",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CloseFiscalPeriodDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/journal-entries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os lançamentos da empresa com as linhas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lançamentos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Código do diário",
+                        "name": "journal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data inicial (AAAA-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data final (AAAA-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Regista um lançamento num período aberto; o total a débito tem de igualar o total a crédito",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Registar lançamento",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lançamento",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateJournalEntryDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/journal-entries/{entryId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devolve um lançamento com as linhas e as contas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Detalhe de lançamento",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do lançamento",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anula o último lançamento de um diário num período que não esteja fechado definitivamente (os restantes corrigem-se com estorno)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Anular lançamento",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do lançamento",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/journals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os diários da empresa (vendas, compras, bancos, operações diversas)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Diários",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria um diário adicional para a empresa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Criar diário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Diário",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateJournalDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/trial-balance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Balancete do exercício entre os períodos indicados, com contas de movimento e contas mãe agregadas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Balancete",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Exercício (por omissão o ano corrente)",
+                        "name": "fiscal_year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Período inicial (1 a 12)",
+                        "name": "period_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Período final (1 a 12)",
+                        "name": "period_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os clientes com acesso à empresa e o papel de cada um (owner, manager ou viewer)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Utilizadores de uma empresa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.UserCompany"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Liga um cliente à empresa com um papel (owner, manager ou viewer) ou muda o papel que já tem. A empresa mantém sempre um titular. Fica registado na auditoria.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dar acesso a uma empresa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cliente e papel",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CompanyUserDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserCompany"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/companies/{id}/users/{userId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retira o acesso de um cliente à empresa. O último titular não pode ser retirado. Fica registado na auditoria.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Retirar acesso a uma empresa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/complete-users-overview": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista todos os usuários com dados combinados de users, registration_requests e companies (apenas contabilistas/admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Listar visão completa de todos os usuários",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Obtém dados resumidos para o dashboard da contabilista incluindo estatísticas e clientes pendentes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dados do dashboard para contabilista",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as exportações disponíveis com as colunas (indicando as sensíveis) e os filtros aceites",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Conjuntos de dados exportáveis",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exports/{dataset}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exporta users-overview, requests ou clients em CSV ou XLSX, escrito em lotes na resposta. As colunas sensíveis (IBAN, cartão de cidadão, credenciais) saem com máscara sem a permissão export_sensitive. Cada exportação fica na auditoria.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Exportar dados (CSV/XLSX)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Conjunto de dados (users-overview, requests, clients)",
+                        "name": "dataset",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Formato (csv, xlsx)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Colunas separadas por vírgulas (por omissão, todas)",
+                        "name": "columns",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os convites enviados, dos mais recentes para os mais antigos",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Convites de clientes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Estado (pending, accepted, revoked, expired)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "O contabilista pré-preenche os dados da empresa (e, se souber, os pessoais) e o cliente recebe por email um link para aceitar o convite e escolher a password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Convidar cliente",
+                "parameters": [
+                    {
+                        "description": "Dados do convite",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateInvitationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/invitations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Detalhes de um convite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do convite",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/invitations/{id}/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera um novo link (o anterior deixa de funcionar) e renova o prazo de um convite pendente ou expirado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reenviar convite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do convite",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/invitations/{id}/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancela um convite que ainda não foi aceite; o link deixa de funcionar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revogar convite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do convite",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/my-queue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as solicitações pendentes atribuídas ao contabilista autenticado e as que estão por atribuir, com o estado do SLA",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Minha fila de revisão",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/notification-outbox": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os emails enviados, pendentes ou falhados (os mais recentes primeiro)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Caixa de saída de emails",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filtrar por status (pending, sending, sent, failed)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/notification-outbox/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Volta a colocar na fila de envio um email pendente ou falhado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reenviar email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do email",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/notification-templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os templates de email de cada evento e idioma (editados ou por omissão)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Templates de email",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/notification-templates/{event}/{language}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria ou atualiza o template (text/template) de um evento num idioma",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Editar template de email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Evento",
+                        "name": "event",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Idioma (ex.: pt, en)",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Assunto e corpo",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailTemplateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/obligations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as obrigações de todas as empresas ativas com prazo até due_before (inclui as atrasadas)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Obrigações fiscais dos clientes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prazo até (AAAA-MM-DD, por omissão daqui a 30 dias)",
+                        "name": "due_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por status (pending, submitted, waived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtrar por empresa",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/obligations/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Regista a submissão, dispensa ou reabertura de uma obrigação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Atualizar obrigação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da obrigação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateObligationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pending-requests": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as solicitações de registo pendentes; sem clients.all, só as atribuídas ao contabilista ou de empresas da carteira",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Listar solicitações pendentes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filtrar por email verificado (true/false)",
+                        "name": "verified",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pending-requests/auto-assign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aplica as regras de atribuição a todas as solicitações pendentes sem responsável (apenas admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Atribuir solicitações pendentes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as permissões que podem ser incluídas nos perfis ou concedidas a utilizadores",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Catálogo de permissões",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PermissionDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/portfolios": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os membros da equipa com o número de empresas na carteira de cada um",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Carteiras de clientes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PortfolioSummaryDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/portfolios/reassign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Passa empresas da carteira de um contabilista para outro (por exemplo, quando alguém sai do gabinete). Sem company_ids passa a carteira inteira. Fica registado na auditoria.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reatribuir carteira",
+                "parameters": [
+                    {
+                        "description": "Origem, destino e empresas",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReassignPortfolioDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ReassignPortfolioResultDTO"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/portfolios/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as empresas atribuídas a um membro da equipa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Carteira de um contabilista",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do contabilista",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Company"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/requests": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os pedidos de registo; sem clients.all, só os atribuídos ao contabilista ou de empresas da carteira",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Listar pedidos de registo pendentes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/requests/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Obtém detalhes completos de um pedido de registo específico (404 se estiver fora da carteira)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Detalhes de um pedido de registo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do pedido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/requests/{id}/assign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Atribui ou reatribui uma solicitação pendente a um contabilista (apenas admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Atribuir solicitação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da solicitação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Contabilista",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AssignRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/requests/{id}/assignments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as atribuições, reclamações e libertações de uma solicitação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Histórico de atribuições",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da solicitação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/requests/{id}/claim": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Atribui uma solicitação pendente, livre e com o email verificado ao contabilista autenticado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reclamar solicitação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da solicitação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/requests/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devolve uma solicitação à fila (responsável atual ou admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Libertar solicitação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da solicitação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notas",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReleaseRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os perfis com as respetivas permissões e o número de utilizadores de cada um",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Perfis",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Role"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria um perfil com um conjunto de permissões do catálogo; fica registado na auditoria",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Criar perfil",
+                "parameters": [
+                    {
+                        "description": "Nome, descrição e permissões",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Role"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/roles/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Detalhes de um perfil",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do perfil",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Role"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Substitui as permissões (e a descrição) de um perfil. Os perfis base não podem ser renomeados e o perfil admin mantém sempre backoffice.access e roles.manage. As alterações aplicam-se de imediato a todos os utilizadores com o perfil.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Alterar perfil",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do perfil",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nome, descrição e permissões",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Role"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina um perfil que não seja base e que não esteja atribuído a nenhum utilizador",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Eliminar perfil",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do perfil",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista todos os utilizadores do sistema (apenas admin/contabilista)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Listar todos os utilizadores",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria diretamente um utilizador já aprovado. Contabilistas só podem criar clientes; admins podem criar clientes, contabilistas e admins. A criação fica no registo de auditoria.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Criar utilizador",
+                "parameters": [
+                    {
+                        "description": "Dados do utilizador",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateUserDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/count": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Conta quantos utilizadores existem por status e role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Contar utilizadores",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/simple": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista utilizadores sem relacionamentos para debug",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Listar utilizadores (simples)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Obtém detalhes completos de um utilizador",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Detalhes de um utilizador",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do utilizador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/activation": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera um novo link de ativação para um cliente importado que ainda não definiu a password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reenviar link de ativação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do utilizador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as permissões concedidas explicitamente a um contabilista ou admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Permissões de um utilizador",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do utilizador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Concede uma permissão (por exemplo, export_sensitive) a um contabilista ou admin; fica registado na auditoria",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Conceder permissão",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do utilizador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permissão",
+                        "name": "permission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GrantPermissionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/permissions/{permission}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retira uma permissão a um utilizador; fica registado na auditoria",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Retirar permissão",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do utilizador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Permissão",
+                        "name": "permission",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Atribui outro perfil a um membro da equipa. Só é possível atribuir perfis cujas permissões o próprio tem; fica registado na auditoria.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Mudar perfil de um utilizador",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do utilizador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo perfil",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRoleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bloqueia/desbloqueia utilizador (requer users.manage)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Alterar status de utilizador",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do utilizador",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserStatusDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/activate": {
+            "get": {
+                "description": "Confirma que o token de ativação de uma conta importada é válido antes de pedir a password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Validar link de ativação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de ativação",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Define a password de uma conta criada por importação através do token enviado por email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Ativar conta",
+                "parameters": [
+                    {
+                        "description": "Token e nova password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ActivateAccountDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/invitation": {
+            "get": {
+                "description": "Devolve os dados pré-preenchidos de um convite válido, para o cliente confirmar antes de aceitar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Validar convite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token do convite",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/invitation/accept": {
+            "post": {
+                "description": "Cria a conta e a empresa já aprovadas com os dados do convite e a password escolhida. Devolve a sessão para o cliente completar os dados pessoais (/client/complete-user-data) e da empresa (/client/complete-company-data).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Aceitar convite",
+                "parameters": [
+                    {
+                        "description": "Token, password e dados pessoais em falta",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AcceptInvitationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login com username e password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Entrar",
+                "parameters": [
+                    {
+                        "description": "Username e password",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove token do lado do cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout do utilizador",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Cria uma nova solicitação de registo para aprovação da contabilista",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Registo de novo cliente",
+                "parameters": [
+                    {
+                        "description": "Dados de registo completos",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegistrationRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/resend-verification": {
+            "post": {
+                "description": "Reenvia o link de verificação para uma solicitação pendente. A resposta é sempre a mesma, exista ou não a solicitação, e o link só é reenviado 5 minutos depois do anterior.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reenviar verificação de email",
+                "parameters": [
+                    {
+                        "description": "Email da solicitação",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResendVerificationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "get": {
+                "description": "Confirma o email de uma solicitação de registo através do link enviado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verificar email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token de verificação",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/calendar/feed": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devolve o endereço (com token) do feed ICS de prazos fiscais do utilizador logado, criando-o se necessário",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Endereço do calendário de prazos",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera um novo token para o feed ICS; o endereço anterior deixa de funcionar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Gerar novo endereço do calendário",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Desativa o feed ICS do utilizador logado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Revogar calendário",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/calendar/{token}/deadlines.ics": {
+            "get": {
+                "description": "Calendário iCalendar (RFC 5545) com os prazos do dono do token: a empresa do cliente ou as empresas acompanhadas pelo contabilista",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Feed ICS de prazos fiscais",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token do calendário",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/billing/customers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os clientes a quem a empresa emite faturas, com pesquisa por nome ou NIF",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Listar clientes de faturação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nome ou NIF",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Regista um cliente; os NIF portugueses são validados pelo dígito de controlo",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Criar cliente de faturação",
+                "parameters": [
+                    {
+                        "description": "Dados do cliente",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/billing/customers/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Atualiza os dados de um cliente; o NIF não pode ser alterado depois de emitidos documentos",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Atualizar cliente de faturação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do cliente de faturação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados do cliente",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/billing/invoices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as faturas e notas de crédito emitidas, filtradas por tipo e datas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Listar documentos emitidos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tipo (FT, FR, FS, NC)",
+                        "name": "document_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data inicial (AAAA-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data final (AAAA-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Emite uma fatura com o próximo número da série, ATCUD e assinatura encadeada. Sem cliente, é emitida ao consumidor final.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Emitir fatura",
+                "parameters": [
+                    {
+                        "description": "Dados da fatura",
+                        "name": "invoice",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateInvoiceDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/billing/invoices/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devolve um documento com as linhas e o cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Obter documento emitido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do documento",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/billing/invoices/{id}/credit-notes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Emite uma nota de crédito sobre uma fatura, total ou por linhas, limitada ao valor ainda por creditar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Emitir nota de crédito",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da fatura de origem",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados da nota de crédito",
+                        "name": "credit_note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateCreditNoteDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/billing/invoices/{id}/pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera o PDF do documento com ATCUD, código QR e excerto da assinatura",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Descarregar documento em PDF",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do documento",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/billing/series": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as séries de documentos da empresa, com o último número emitido",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Listar séries de faturação",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Regista uma série já comunicada à AT com o código de validação atribuído, usado no ATCUD",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Registar série de faturação",
+                "parameters": [
+                    {
+                        "description": "Dados da série",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateInvoiceSeriesDTO"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/checklist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os documentos esperados por período para a empresa do cliente logado (use status=missing para ver o que falta)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Minha checklist de documentos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Período (AAAA-MM, AAAA-T1 ou AAAA)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por status (missing, submitted, waived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/companies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as empresas a que o cliente logado está ligado, com o papel em cada uma (owner, manager ou viewer)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Empresas do cliente",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ClientCompanyDTO"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/client/companies/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Obtém dados de uma empresa a que o cliente logado está ligado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Dados de uma empresa do cliente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ClientCompanyDTO"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Atualiza dados de uma empresa do cliente (campos limitados). Só o titular (owner) e os gerentes (manager) podem alterar.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Atualizar dados de uma empresa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados a atualizar",
+                        "name": "company",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateCompanyDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/complete-company-data": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Completa dados adicionais de uma empresa do cliente após aprovação. Só o titular ou um gerente da empresa o pode fazer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Completar dados da empresa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Dados da empresa completos",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CompleteCompanyDataDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/complete-user-data": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Completa dados pessoais adicionais após aprovação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Completar dados pessoais",
+                "parameters": [
+                    {
+                        "description": "Dados pessoais completos",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CompleteUserDataDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/documents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista os documentos enviados pelo cliente logado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Meus documentos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filtrar por tipo",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por período fiscal",
+                        "name": "fiscal_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por status (received, accepted, rejected)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Envia um documento (fatura, recibo, extrato, cartão de cidadão...) para a empresa do cliente logado",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Enviar documento",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Ficheiro (PDF, JPEG, PNG, WEBP, CSV/TXT, XML ou ZIP)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tipo (invoice, receipt, bank_statement, citizen_card, other)",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Período fiscal (AAAA-MM ou AAAA)",
+                        "name": "fiscal_period",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Notas",
+                        "name": "notes",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/documents/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Descarrega um documento enviado pelo cliente logado",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Descarregar documento",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do documento",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/obligations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Calendário de obrigações fiscais e contributivas da empresa do cliente logado (IVA, SAF-T, DMR, Segurança Social, Modelo 22, IES)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Minhas obrigações fiscais",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prazo a partir de (AAAA-MM-DD, por omissão há 3 meses)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Prazo até (AAAA-MM-DD, por omissão daqui a 12 meses)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtrar por status (pending, submitted, waived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Obtém perfil do cliente logado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Perfil do cliente",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Atualiza dados pessoais do cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Atualizar perfil do cliente",
+                "parameters": [
+                    {
+                        "description": "Dados a atualizar",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProfileDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/requests": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista todas as solicitações do cliente logado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Histórico de solicitações do cliente",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/saft-imports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as importações SAF-T da empresa do cliente logado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Minhas importações SAF-T",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Envia um ficheiro SAF-T (PT) 1.04_01 de faturação da empresa do cliente logado; o ficheiro é validado em segundo plano",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Importar SAF-T",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Ficheiro SAF-T (XML)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/saft-imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devolve o estado, os totais, os resumos de vendas e os erros por linha de uma importação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Detalhe de importação SAF-T",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da importação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/client/saft-imports/{id}/summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Agrega as vendas de uma importação por período, taxa de IVA ou cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client"
+                ],
+                "summary": "Resumo de vendas SAF-T",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da importação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Agrupamento (period, tax_rate, customer)",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID da empresa (por omissão a principal)",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/info": {
+            "get": {
+                "description": "Como usar a API e autenticação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "info"
+                ],
+                "summary": "Informações da API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lista as notificações da caixa de entrada do utilizador logado (as mais recentes primeiro)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Minhas notificações",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Apenas por ler",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de resultados (por omissão 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Deslocamento",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Elimina várias notificações (ou todas, com all=true) do utilizador logado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Eliminar notificações",
+                "parameters": [
+                    {
+                        "description": "Notificações a eliminar",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationBulkDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marca várias notificações (ou todas, com all=true) do utilizador logado como lidas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Marcar notificações como lidas",
+                "parameters": [
+                    {
+                        "description": "Notificações a marcar",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationBulkDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marca uma notificação do utilizador logado como lida",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Marcar notificação como lida",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da notificação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Obtém dados do utilizador logado (requer token no header Authorization)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Meu perfil",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Altera a password do utilizador logado depois de confirmar a password atual",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Alterar password",
+                "parameters": [
+                    {
+                        "description": "Password atual e nova password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangePasswordDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AcceptInvitationDTO": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "João Silva"
+                },
+                "nif": {
+                    "type": "string",
+                    "example": "123456789"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "password123"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "912345678"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "3f2a9c..."
+                },
+                "username": {
+                    "type": "string",
+                    "example": "joao.silva"
+                }
+            }
+        },
+        "models.ActivateAccountDTO": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "password123"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "3f2a9c..."
+                }
+            }
+        },
+        "models.AdminUpdateClientDTO": {
+            "type": "object",
+            "properties": {
+                "billing_software": {
+                    "type": "string"
+                },
+                "citizen_card_expiry": {
+                    "type": "string"
+                },
+                "citizen_card_number": {
+                    "type": "string"
+                },
+                "date_of_birth": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "fiscal_address": {
+                    "description": "Morada fiscal",
+                    "type": "string"
+                },
+                "fiscal_city": {
+                    "type": "string"
+                },
+                "fiscal_county": {
+                    "type": "string"
+                },
+                "fiscal_district": {
+                    "type": "string"
+                },
+                "fiscal_postal_code": {
+                    "type": "string"
+                },
+                "fixed_phone": {
+                    "type": "string"
+                },
+                "marital_status": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Dados pessoais que admin pode editar",
+                    "type": "string"
+                },
+                "nif": {
+                    "type": "string"
+                },
+                "official_email": {
+                    "description": "Configurações",
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "preferred_contact_hours": {
+                    "type": "string"
+                },
+                "preferred_format": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AdminUpdateCompanyDTO": {
+            "type": "object",
+            "properties": {
+                "accounting_regime": {
+                    "description": "Regimes",
+                    "type": "string"
+                },
+                "address": {
+                    "description": "Morada da empresa",
+                    "type": "string"
+                },
+                "annual_revenue": {
+                    "type": "number"
+                },
+                "bank_name": {
+                    "type": "string"
+                },
+                "bic": {
+                    "type": "string"
+                },
+                "business_activity": {
+                    "description": "Operacionais",
+                    "type": "string"
+                },
+                "cae": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "company_name": {
+                    "type": "string"
+                },
+                "corporate_object": {
+                    "type": "string"
+                },
+                "county": {
+                    "type": "string"
+                },
+                "district": {
+                    "type": "string"
+                },
+                "estimated_revenue": {
+                    "type": "number"
+                },
+                "founding_date": {
+                    "type": "string"
+                },
+                "has_stock": {
+                    "type": "boolean"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "legal_form": {
+                    "type": "string"
+                },
+                "main_clients": {
+                    "type": "string"
+                },
+                "main_suppliers": {
+                    "type": "string"
+                },
+                "monthly_invoices": {
+                    "type": "integer"
+                },
+                "nipc": {
+                    "type": "string"
+                },
+                "number_employees": {
+                    "type": "integer"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "report_frequency": {
+                    "type": "string"
+                },
+                "share_capital": {
+                    "description": "Dados financeiros",
+                    "type": "number"
+                },
+                "trade_name": {
+                    "type": "string"
+                },
+                "vat_regime": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApprovalRequestDTO": {
+            "type": "object",
+            "required": [
+                "request_id",
+                "status"
+            ],
+            "properties": {
+                "request_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "review_notes": {
+                    "type": "string",
+                    "example": "Documentação em ordem"
+                },
+                "status": {
+                    "description": "needs_info mantém a solicitação pendente e pede dados ao cliente",
+                    "type": "string",
+                    "enum": [
+                        "approved",
+                        "rejected",
+                        "needs_info"
+                    ],
+                    "example": "approved"
+                }
+            }
+        },
+        "models.AssignRequestDTO": {
+            "type": "object",
+            "required": [
+                "accountant_id"
+            ],
+            "properties": {
+                "accountant_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Cliente da zona norte"
+                }
+            }
+        },
+        "models.AssignmentRuleDTO": {
+            "type": "object",
+            "required": [
+                "strategy"
+            ],
+            "properties": {
+                "accountant_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "match_value": {
+                    "type": "string",
+                    "example": "Lisboa"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "strategy": {
+                    "type": "string",
+                    "enum": [
+                        "round_robin",
+                        "district",
+                        "legal_form"
+                    ],
+                    "example": "district"
+                }
+            }
+        },
+        "models.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "models.ChangePasswordDTO": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "novaPassword456"
+                }
+            }
+        },
+        "models.ClassifyPurchaseInvoiceDTO": {
+            "type": "object",
+            "required": [
+                "expense_category"
+            ],
+            "properties": {
+                "deductible_percent": {
+                    "description": "null repõe a dedutibilidade automática",
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 50
+                },
+                "expense_category": {
+                    "type": "string",
+                    "enum": [
+                        "assets",
+                        "inventory",
+                        "other"
+                    ],
+                    "example": "inventory"
+                }
+            }
+        },
+        "models.ClientCompanyDTO": {
+            "type": "object",
+            "properties": {
+                "accounting_regime": {
+                    "type": "string"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "annual_revenue": {
+                    "description": "Dados operacionais",
+                    "type": "number"
+                },
+                "bank_name": {
+                    "description": "Informação bancária",
+                    "type": "string"
+                },
+                "bic": {
+                    "type": "string"
+                },
+                "business_activity": {
+                    "type": "string"
+                },
+                "cae": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "company_name": {
+                    "description": "Dados copiados da RegistrationRequest após aprovação",
+                    "type": "string"
+                },
+                "company_role": {
+                    "type": "string",
+                    "example": "owner"
+                },
+                "corporate_object": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "county": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "district": {
+                    "type": "string"
+                },
+                "estimated_revenue": {
+                    "type": "number"
+                },
+                "founding_date": {
+                    "type": "string"
+                },
+                "group_start_date": {
+                    "type": "string"
+                },
+                "has_stock": {
+                    "type": "boolean"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "legal_form": {
+                    "type": "string"
+                },
+                "main_clients": {
+                    "type": "string"
+                },
+                "main_suppliers": {
+                    "type": "string"
+                },
+                "monthly_invoices": {
+                    "type": "integer"
+                },
+                "nipc": {
+                    "type": "string"
+                },
+                "number_employees": {
+                    "type": "integer"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "registration_request": {
+                    "$ref": "#/definitions/models.RegistrationRequest"
+                },
+                "share_capital": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trade_name": {
+                    "description": "Dados completos",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "Relacionamentos",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "Titular que registou a empresa; os acessos estão em UserCompany",
+                    "type": "integer"
+                },
+                "vat_regime": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CloseFiscalPeriodDTO": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Declaração periódica de IVA entregue"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "soft_closed",
+                        "hard_closed"
+                    ],
+                    "example": "hard_closed"
+                }
+            }
+        },
+        "models.Company": {
+            "type": "object",
+            "properties": {
+                "accounting_regime": {
+                    "type": "string"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "annual_revenue": {
+                    "description": "Dados operacionais",
+                    "type": "number"
+                },
+                "bank_name": {
+                    "description": "Informação bancária",
+                    "type": "string"
+                },
+                "bic": {
+                    "type": "string"
+                },
+                "business_activity": {
+                    "type": "string"
+                },
+                "cae": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "company_name": {
+                    "description": "Dados copiados da RegistrationRequest após aprovação",
+                    "type": "string"
+                },
+                "corporate_object": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "county": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "district": {
+                    "type": "string"
+                },
+                "estimated_revenue": {
+                    "type": "number"
+                },
+                "founding_date": {
+                    "type": "string"
+                },
+                "group_start_date": {
+                    "type": "string"
+                },
+                "has_stock": {
+                    "type": "boolean"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "legal_form": {
+                    "type": "string"
+                },
+                "main_clients": {
+                    "type": "string"
+                },
+                "main_suppliers": {
+                    "type": "string"
+                },
+                "monthly_invoices": {
+                    "type": "integer"
+                },
+                "nipc": {
+                    "type": "string"
+                },
+                "number_employees": {
+                    "type": "integer"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "registration_request": {
+                    "$ref": "#/definitions/models.RegistrationRequest"
+                },
+                "share_capital": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trade_name": {
+                    "description": "Dados completos",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "Relacionamentos",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "Titular que registou a empresa; os acessos estão em UserCompany",
+                    "type": "integer"
+                },
+                "vat_regime": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CompanyAccountant": {
+            "type": "object",
+            "properties": {
+                "accountant": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "accountant_id": {
+                    "type": "integer"
+                },
+                "assigned_by": {
+                    "type": "integer"
+                },
+                "company": {
+                    "description": "Relacionamentos",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Company"
+                        }
+                    ]
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CompanyUserDTO": {
+            "type": "object",
+            "required": [
+                "role",
+                "user_id"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "manager",
+                        "viewer"
+                    ],
+                    "example": "manager"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "models.CompleteCompanyDataDTO": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "Rua das Flores, 123"
+                },
+                "annual_revenue": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "bank_name": {
+                    "type": "string",
+                    "example": "Banco Comercial Português"
+                },
+                "bic": {
+                    "type": "string",
+                    "example": "BCOMPTPL"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Lisboa"
+                },
+                "corporate_object": {
+                    "type": "string",
+                    "example": "Prestação de serviços de consultoria"
+                },
+                "county": {
+                    "type": "string",
+                    "example": "Lisboa"
+                },
+                "district": {
+                    "type": "string",
+                    "example": "Lisboa"
+                },
+                "group_start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "has_stock": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "iban": {
+                    "type": "string",
+                    "example": "PT50000201231234567890154"
+                },
+                "main_clients": {
+                    "type": "string",
+                    "example": "Cliente A, Cliente B"
+                },
+                "main_suppliers": {
+                    "type": "string",
+                    "example": "Fornecedor X, Fornecedor Y"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "1000-001"
+                },
+                "share_capital": {
+                    "type": "number",
+                    "example": 5000
+                },
+                "trade_name": {
+                    "type": "string",
+                    "example": "Silva Consultoria"
                 }
             }
         },
-        "/profile": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Obtém dados do utilizador logado (requer token no header Authorization)",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "user"
-                ],
-                "summary": "Meu perfil",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
-                        }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "models.AdminUpdateClientDTO": {
+        "models.CompleteUserDataDTO": {
             "type": "object",
             "properties": {
                 "billing_software": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Moloni"
                 },
                 "citizen_card_expiry": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2030-12-31"
                 },
                 "citizen_card_number": {
-                    "type": "string"
-                },
-                "date_of_birth": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "12345678"
                 },
-                "fiscal_address": {
-                    "description": "Morada fiscal",
-                    "type": "string"
+                "e_fatura_password": {
+                    "type": "string",
+                    "example": "password123"
                 },
-                "fiscal_city": {
-                    "type": "string"
+                "e_fatura_user": {
+                    "type": "string",
+                    "example": "123456789"
                 },
                 "fiscal_county": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Lisboa"
                 },
                 "fiscal_district": {
-                    "type": "string"
-                },
-                "fiscal_postal_code": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Lisboa"
                 },
                 "fixed_phone": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "213456789"
                 },
                 "marital_status": {
-                    "type": "string"
-                },
-                "name": {
-                    "description": "Dados pessoais que admin pode editar",
-                    "type": "string"
-                },
-                "nif": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Solteiro"
                 },
                 "official_email": {
-                    "description": "Configurações",
-                    "type": "string"
+                    "type": "string",
+                    "example": "geral@empresa.com"
                 },
-                "phone": {
-                    "type": "string"
+                "portal_financas_password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "portal_financas_user": {
+                    "type": "string",
+                    "example": "123456789"
                 },
                 "preferred_contact_hours": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "9h-17h"
                 },
                 "preferred_format": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "digital"
                 },
-                "status": {
-                    "type": "string"
+                "ss_direct_password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "ss_direct_user": {
+                    "type": "string",
+                    "example": "123456789"
                 }
             }
         },
-        "models.AdminUpdateCompanyDTO": {
+        "models.CreateAccountDTO": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "22101"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Fornecedores nacionais"
+                }
+            }
+        },
+        "models.CreateClientCompanyDTO": {
             "type": "object",
+            "required": [
+                "company_name",
+                "legal_form"
+            ],
             "properties": {
                 "accounting_regime": {
-                    "description": "Regimes",
-                    "type": "string"
+                    "type": "string",
+                    "example": "organizada"
                 },
                 "address": {
-                    "description": "Morada da empresa",
-                    "type": "string"
-                },
-                "annual_revenue": {
-                    "type": "number"
-                },
-                "bank_name": {
-                    "type": "string"
-                },
-                "bic": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Rua das Flores, 123"
                 },
                 "business_activity": {
-                    "description": "Operacionais",
-                    "type": "string"
+                    "type": "string",
+                    "example": "Consultoria em gestão"
                 },
                 "cae": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "69200"
                 },
                 "city": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Lisboa"
                 },
                 "company_name": {
-                    "type": "string"
-                },
-                "corporate_object": {
-                    "type": "string"
-                },
-                "county": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Silva Unipessoal Lda"
                 },
                 "district": {
-                    "type": "string"
-                },
-                "estimated_revenue": {
-                    "type": "number"
-                },
-                "founding_date": {
-                    "type": "string"
-                },
-                "has_stock": {
-                    "type": "boolean"
-                },
-                "iban": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Lisboa"
                 },
                 "legal_form": {
-                    "type": "string"
-                },
-                "main_clients": {
-                    "type": "string"
-                },
-                "main_suppliers": {
-                    "type": "string"
-                },
-                "monthly_invoices": {
-                    "type": "integer"
+                    "type": "string",
+                    "example": "Sociedade Unipessoal por Quotas"
                 },
                 "nipc": {
-                    "type": "string"
-                },
-                "number_employees": {
-                    "type": "integer"
-                },
-                "postal_code": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "509442013"
                 },
-                "report_frequency": {
-                    "type": "string"
+                "postal_code": {
+                    "type": "string",
+                    "example": "1000-001"
                 },
-                "share_capital": {
-                    "description": "Dados financeiros",
-                    "type": "number"
+                "role": {
+                    "description": "Papel do cliente na nova empresa (owner por omissão)",
+                    "type": "string",
+                    "example": "owner"
                 },
                 "trade_name": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Silva Consultoria"
                 },
                 "vat_regime": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "normal"
                 }
             }
         },
-        "models.ApprovalRequestDTO": {
+        "models.CreateCreditNoteDTO": {
             "type": "object",
             "required": [
-                "request_id",
-                "status"
+                "reason",
+                "series_id"
             ],
             "properties": {
-                "request_id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "review_notes": {
+                "issue_date": {
                     "type": "string",
-                    "example": "Documentação em ordem"
+                    "example": "2025-03-20"
                 },
-                "status": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreditNoteLineDTO"
+                    }
+                },
+                "reason": {
                     "type": "string",
-                    "enum": [
-                        "approved",
-                        "rejected"
-                    ],
-                    "example": "approved"
+                    "example": "Devolução de mercadoria"
+                },
+                "series_id": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "models.AuthResponse": {
+        "models.CreateFiscalYearDTO": {
             "type": "object",
+            "required": [
+                "year"
+            ],
             "properties": {
-                "token": {
+                "start_date": {
+                    "description": "Por omissão 1 de janeiro",
                     "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                    "example": "2025-01-01"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.User"
+                "year": {
+                    "type": "integer",
+                    "maximum": 2100,
+                    "minimum": 2000,
+                    "example": 2025
                 }
             }
         },
-        "models.Company": {
+        "models.CreateInvitationDTO": {
             "type": "object",
+            "required": [
+                "company_name",
+                "email",
+                "legal_form"
+            ],
             "properties": {
                 "accounting_regime": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "organizada"
                 },
-                "address": {
-                    "type": "string"
+                "business_activity": {
+                    "type": "string",
+                    "example": "Consultoria em gestão"
                 },
-                "annual_revenue": {
-                    "description": "Dados operacionais",
-                    "type": "number"
+                "cae": {
+                    "type": "string",
+                    "example": "69200"
                 },
-                "bank_name": {
-                    "description": "Informação bancária",
-                    "type": "string"
+                "company_address": {
+                    "type": "string",
+                    "example": "Rua das Flores, 123"
                 },
-                "bic": {
-                    "type": "string"
+                "company_city": {
+                    "type": "string",
+                    "example": "Lisboa"
                 },
-                "business_activity": {
-                    "type": "string"
+                "company_district": {
+                    "type": "string",
+                    "example": "Lisboa"
                 },
-                "cae": {
-                    "type": "string"
+                "company_name": {
+                    "type": "string",
+                    "example": "Silva \u0026 Associados Lda"
                 },
-                "city": {
-                    "type": "string"
+                "company_postal_code": {
+                    "type": "string",
+                    "example": "1000-001"
                 },
-                "company_name": {
-                    "description": "Dados copiados da RegistrationRequest após aprovação",
-                    "type": "string"
+                "email": {
+                    "type": "string",
+                    "example": "joao@exemplo.com"
                 },
-                "corporate_object": {
-                    "type": "string"
+                "language": {
+                    "type": "string",
+                    "example": "pt"
                 },
-                "country": {
-                    "type": "string"
+                "legal_form": {
+                    "type": "string",
+                    "example": "Sociedade por Quotas"
                 },
-                "county": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "example": "João Silva"
                 },
-                "created_at": {
-                    "type": "string"
+                "nif": {
+                    "type": "string",
+                    "example": "123456789"
                 },
-                "district": {
-                    "type": "string"
+                "nipc": {
+                    "type": "string",
+                    "example": "509442013"
                 },
-                "estimated_revenue": {
-                    "type": "number"
+                "notes": {
+                    "type": "string",
+                    "example": "Bem-vindo! Complete os dados da empresa depois de entrar."
                 },
-                "founding_date": {
-                    "type": "string"
+                "phone": {
+                    "type": "string",
+                    "example": "912345678"
                 },
-                "group_start_date": {
-                    "type": "string"
+                "trade_name": {
+                    "type": "string",
+                    "example": "Silva Consultoria"
                 },
-                "has_stock": {
-                    "type": "boolean"
+                "username": {
+                    "type": "string",
+                    "example": "joao.silva"
                 },
-                "iban": {
-                    "type": "string"
+                "vat_regime": {
+                    "type": "string",
+                    "example": "normal"
+                }
+            }
+        },
+        "models.CreateInvoiceDTO": {
+            "type": "object",
+            "required": [
+                "lines",
+                "series_id"
+            ],
+            "properties": {
+                "customer_id": {
+                    "description": "Vazio para consumidor final",
+                    "type": "integer",
+                    "example": 3
                 },
-                "id": {
-                    "type": "integer"
+                "due_date": {
+                    "type": "string",
+                    "example": "2025-04-14"
                 },
-                "legal_form": {
-                    "type": "string"
+                "issue_date": {
+                    "description": "Por omissão hoje",
+                    "type": "string",
+                    "example": "2025-03-15"
                 },
-                "main_clients": {
-                    "type": "string"
+                "lines": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.InvoiceLineDTO"
+                    }
                 },
-                "main_suppliers": {
-                    "type": "string"
+                "series_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.CreateInvoiceSeriesDTO": {
+            "type": "object",
+            "required": [
+                "code",
+                "document_type",
+                "validation_code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "2025A"
                 },
-                "monthly_invoices": {
-                    "type": "integer"
+                "document_type": {
+                    "type": "string",
+                    "enum": [
+                        "FT",
+                        "FR",
+                        "FS",
+                        "NC"
+                    ],
+                    "example": "FT"
                 },
-                "nipc": {
-                    "type": "string"
+                "validation_code": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "AAJFJMVNTN"
+                }
+            }
+        },
+        "models.CreateJournalDTO": {
+            "type": "object",
+            "required": [
+                "code",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "BNC2"
                 },
-                "number_employees": {
-                    "type": "integer"
+                "name": {
+                    "type": "string",
+                    "example": "Banco - conta secundária"
                 },
-                "postal_code": {
-                    "type": "string"
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "sales",
+                        "purchases",
+                        "bank",
+                        "general"
+                    ],
+                    "example": "bank"
+                }
+            }
+        },
+        "models.CreateJournalEntryDTO": {
+            "type": "object",
+            "required": [
+                "date",
+                "journal_code",
+                "lines"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-03-15"
                 },
-                "registration_request": {
-                    "$ref": "#/definitions/models.RegistrationRequest"
+                "description": {
+                    "type": "string",
+                    "example": "Fatura FT A/123 - Fornecedor X"
                 },
-                "share_capital": {
-                    "type": "number"
+                "document_ref": {
+                    "type": "string",
+                    "example": "FT A/123"
                 },
-                "status": {
-                    "type": "string"
+                "journal_code": {
+                    "type": "string",
+                    "example": "CMP"
                 },
-                "trade_name": {
-                    "description": "Dados completos",
-                    "type": "string"
+                "lines": {
+                    "type": "array",
+                    "minItems": 2,
+                    "items": {
+                        "$ref": "#/definitions/models.JournalEntryLineDTO"
+                    }
+                }
+            }
+        },
+        "models.CreateUserDTO": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "nif",
+                "password",
+                "phone",
+                "role",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "joao@exemplo.com"
                 },
-                "updated_at": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "minLength": 2,
+                    "example": "João Silva"
                 },
-                "user": {
-                    "description": "Relacionamentos",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.User"
-                        }
-                    ]
+                "nif": {
+                    "type": "string",
+                    "example": "123456789"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "123456"
                 },
-                "user_id": {
-                    "description": "One-to-One com User",
-                    "type": "integer"
+                "phone": {
+                    "type": "string",
+                    "example": "912345678"
                 },
-                "vat_regime": {
-                    "type": "string"
+                "role": {
+                    "type": "string",
+                    "example": "client"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "joao.silva"
                 }
             }
         },
-        "models.CompleteCompanyDataDTO": {
+        "models.CreditNoteLineDTO": {
             "type": "object",
+            "required": [
+                "invoice_line_id",
+                "quantity"
+            ],
             "properties": {
-                "address": {
-                    "type": "string",
-                    "example": "Rua das Flores, 123"
+                "invoice_line_id": {
+                    "type": "integer",
+                    "example": 10
                 },
-                "annual_revenue": {
+                "quantity": {
                     "type": "number",
-                    "example": 100000
-                },
-                "bank_name": {
-                    "type": "string",
-                    "example": "Banco Comercial Português"
-                },
-                "bic": {
+                    "example": 1
+                }
+            }
+        },
+        "models.CustomerDTO": {
+            "type": "object",
+            "required": [
+                "name",
+                "tax_id"
+            ],
+            "properties": {
+                "address": {
                     "type": "string",
-                    "example": "BCOMPTPL"
+                    "example": "Rua do Comércio, 10"
                 },
                 "city": {
                     "type": "string",
                     "example": "Lisboa"
                 },
-                "corporate_object": {
-                    "type": "string",
-                    "example": "Prestação de serviços de consultoria"
-                },
-                "county": {
+                "country": {
                     "type": "string",
-                    "example": "Lisboa"
+                    "example": "PT"
                 },
-                "district": {
+                "email": {
                     "type": "string",
-                    "example": "Lisboa"
+                    "example": "geral@cliente.pt"
                 },
-                "group_start_date": {
+                "name": {
                     "type": "string",
-                    "example": "2024-01-01"
+                    "example": "Cliente Exemplo, Lda"
                 },
-                "has_stock": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "iban": {
+                "postal_code": {
                     "type": "string",
-                    "example": "PT50000201231234567890154"
+                    "example": "1100-150"
                 },
-                "main_clients": {
+                "tax_id": {
                     "type": "string",
-                    "example": "Cliente A, Cliente B"
+                    "example": "123456789"
+                }
+            }
+        },
+        "models.DuplicateMatchDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 3
                 },
-                "main_suppliers": {
+                "label": {
                     "type": "string",
-                    "example": "Fornecedor X, Fornecedor Y"
+                    "example": "Silva \u0026 Associados Lda"
                 },
-                "postal_code": {
-                    "type": "string",
-                    "example": "1000-001"
+                "matched_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "nipc",
+                        "iban"
+                    ]
                 },
-                "share_capital": {
+                "name_similarity": {
                     "type": "number",
-                    "example": 5000
+                    "example": 0.92
                 },
-                "trade_name": {
+                "score": {
+                    "description": "0 a 100",
+                    "type": "integer",
+                    "example": 80
+                },
+                "source": {
+                    "description": "user, company, registration_request",
                     "type": "string",
-                    "example": "Silva Consultoria"
+                    "example": "company"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "approved"
                 }
             }
         },
-        "models.CompleteUserDataDTO": {
+        "models.DuplicateReportDTO": {
             "type": "object",
             "properties": {
-                "billing_software": {
-                    "type": "string",
-                    "example": "Moloni"
+                "conflicts": {
+                    "description": "Conflitos que impedem a aprovação (campos únicos já usados por contas ou empresas existentes)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "citizen_card_expiry": {
-                    "type": "string",
-                    "example": "2030-12-31"
+                "highest_score": {
+                    "type": "integer",
+                    "example": 80
                 },
-                "citizen_card_number": {
-                    "type": "string",
-                    "example": "12345678"
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DuplicateMatchDTO"
+                    }
                 },
-                "e_fatura_password": {
+                "risk_level": {
+                    "description": "none, low, medium, high",
                     "type": "string",
-                    "example": "password123"
-                },
-                "e_fatura_user": {
+                    "example": "high"
+                }
+            }
+        },
+        "models.EmailTemplateDTO": {
+            "type": "object",
+            "required": [
+                "body",
+                "subject"
+            ],
+            "properties": {
+                "body": {
                     "type": "string",
-                    "example": "123456789"
+                    "example": "Olá {{.Name}}, o seu pedido foi aprovado."
                 },
-                "fiscal_county": {
+                "subject": {
                     "type": "string",
-                    "example": "Lisboa"
-                },
-                "fiscal_district": {
+                    "example": "O seu pedido foi aprovado"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string",
-                    "example": "Lisboa"
+                    "example": "Descrição do erro"
                 },
-                "fixed_phone": {
+                "success": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "models.GrantPermissionDTO": {
+            "type": "object",
+            "required": [
+                "permission"
+            ],
+            "properties": {
+                "permission": {
                     "type": "string",
-                    "example": "213456789"
-                },
-                "marital_status": {
+                    "example": "export_sensitive"
+                }
+            }
+        },
+        "models.InvoiceLineDTO": {
+            "type": "object",
+            "required": [
+                "description",
+                "quantity"
+            ],
+            "properties": {
+                "description": {
                     "type": "string",
-                    "example": "Solteiro"
+                    "example": "Serviços de consultoria"
                 },
-                "official_email": {
-                    "type": "string",
-                    "example": "geral@empresa.com"
+                "quantity": {
+                    "type": "number",
+                    "example": 1
                 },
-                "portal_financas_password": {
+                "tax_exemption_code": {
                     "type": "string",
-                    "example": "password123"
+                    "example": "M07"
                 },
-                "portal_financas_user": {
-                    "type": "string",
-                    "example": "123456789"
+                "tax_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 23
                 },
-                "preferred_contact_hours": {
+                "unit_price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
+                }
+            }
+        },
+        "models.JournalEntryLineDTO": {
+            "type": "object",
+            "required": [
+                "account_code"
+            ],
+            "properties": {
+                "account_code": {
                     "type": "string",
-                    "example": "9h-17h"
+                    "example": "6221"
                 },
-                "preferred_format": {
-                    "type": "string",
-                    "example": "digital"
+                "credit": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0
                 },
-                "ss_direct_password": {
-                    "type": "string",
-                    "example": "password123"
+                "debit": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
                 },
-                "ss_direct_user": {
+                "description": {
                     "type": "string",
-                    "example": "123456789"
+                    "example": "Trabalhos especializados"
                 }
             }
         },
@@ -1414,63 +9006,117 @@ const docTemplate = `{
                 }
             }
         },
-        "models.RegisterRequest": {
+        "models.MatchPurchaseInvoiceDTO": {
             "type": "object",
-            "required": [
-                "email",
-                "name",
-                "nif",
-                "password",
-                "phone",
-                "role",
-                "status",
-                "username"
-            ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "joao@exemplo.com"
+                "document_id": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "models.NotificationBulkDTO": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "description": "Aplica a todas as notificações do utilizador",
+                    "type": "boolean",
+                    "example": false
                 },
-                "name": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "models.PermissionDTO": {
+            "type": "object",
+            "properties": {
+                "description": {
                     "type": "string",
-                    "minLength": 2,
-                    "example": "João Silva"
+                    "example": "Ver clientes e empresas"
                 },
-                "nif": {
+                "name": {
                     "type": "string",
-                    "example": "123456789"
+                    "example": "clients.read"
+                }
+            }
+        },
+        "models.PortfolioSummaryDTO": {
+            "type": "object",
+            "properties": {
+                "accountant_id": {
+                    "type": "integer",
+                    "example": 2
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6,
-                    "example": "123456"
+                "companies": {
+                    "type": "integer",
+                    "example": 12
                 },
-                "phone": {
+                "name": {
                     "type": "string",
-                    "example": "912345678"
+                    "example": "Contabilista"
                 },
                 "role": {
                     "type": "string",
-                    "enum": [
-                        "client",
-                        "accountant",
-                        "admin"
-                    ],
-                    "example": "client"
+                    "example": "accountant"
                 },
                 "status": {
                     "type": "string",
-                    "enum": [
-                        "pending",
-                        "approved",
-                        "rejected",
-                        "blocked"
-                    ],
-                    "example": "pending"
+                    "example": "approved"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "contabilista"
+                }
+            }
+        },
+        "models.ReassignPortfolioDTO": {
+            "type": "object",
+            "required": [
+                "from_accountant_id",
+                "to_accountant_id"
+            ],
+            "properties": {
+                "company_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        10,
+                        11
+                    ]
                 },
-                "username": {
-                    "type": "string",
-                    "example": "joao.silva"
+                "from_accountant_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "to_accountant_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.ReassignPortfolioResultDTO": {
+            "type": "object",
+            "properties": {
+                "already_assigned": {
+                    "description": "Empresas que o novo contabilista já tinha",
+                    "type": "integer",
+                    "example": 1
+                },
+                "moved": {
+                    "description": "Empresas que passaram para o novo contabilista",
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
@@ -1491,6 +9137,16 @@ const docTemplate = `{
                 "approval_token": {
                     "type": "string"
                 },
+                "assigned_at": {
+                    "type": "string"
+                },
+                "assigned_to": {
+                    "description": "Contabilista responsável pela revisão",
+                    "type": "integer"
+                },
+                "assigned_to_user": {
+                    "$ref": "#/definitions/models.User"
+                },
                 "bank_name": {
                     "description": "Informação bancária",
                     "type": "string"
@@ -1559,9 +9215,24 @@ const docTemplate = `{
                     "description": "Dados pessoais opcionais",
                     "type": "string"
                 },
+                "duplicate_report": {
+                    "description": "Relatório de prováveis duplicados (calculado nos detalhes, não é guardado)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DuplicateReportDTO"
+                        }
+                    ]
+                },
                 "email": {
                     "type": "string"
                 },
+                "email_verification_sent_at": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "description": "Verificação do email",
+                    "type": "string"
+                },
                 "estimated_revenue": {
                     "type": "number"
                 },
@@ -1646,7 +9317,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "request_type": {
-                    "description": "new_client, existing_client",
+                    "description": "new_client, existing_client, invitation",
                     "type": "string"
                 },
                 "review_notes": {
@@ -1940,6 +9611,166 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ReleaseRequestDTO": {
+            "type": "object",
+            "properties": {
+                "notes": {
+                    "type": "string",
+                    "example": "Sem disponibilidade esta semana"
+                }
+            }
+        },
+        "models.ReopenFiscalPeriodDTO": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "minLength": 5,
+                    "example": "Correção de fatura lançada no mês errado"
+                },
+                "status": {
+                    "description": "Por omissão open",
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "soft_closed"
+                    ],
+                    "example": "open"
+                }
+            }
+        },
+        "models.ResendVerificationDTO": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "joao@exemplo.com"
+                }
+            }
+        },
+        "models.Role": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Contabilista júnior"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "junior"
+                },
+                "permissions": {
+                    "description": "Calculados na leitura (não guardados)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "system": {
+                    "description": "Perfil base (admin, accountant, client)",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RoleDTO": {
+            "type": "object",
+            "required": [
+                "permissions"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Contabilista júnior"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "junior"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "backoffice.access",
+                        "clients.read"
+                    ]
+                }
+            }
+        },
+        "models.SetCompanyAccountantsDTO": {
+            "type": "object",
+            "required": [
+                "accountant_ids"
+            ],
+            "properties": {
+                "accountant_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "models.SplitAllocationDTO": {
+            "type": "object",
+            "required": [
+                "amount",
+                "purchase_invoice_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 123.45
+                },
+                "purchase_invoice_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.SplitTransactionDTO": {
+            "type": "object",
+            "required": [
+                "allocations",
+                "transaction_id"
+            ],
+            "properties": {
+                "allocations": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.SplitAllocationDTO"
+                    }
+                },
+                "transaction_id": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
         "models.SuccessResponse": {
             "type": "object",
             "properties": {
@@ -1954,6 +9785,40 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateAccountDTO": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Fornecedores gerais"
+                }
+            }
+        },
+        "models.UpdateChecklistItemDTO": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "notes": {
+                    "type": "string",
+                    "example": "Sem movimentos bancários neste mês"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "missing",
+                        "submitted",
+                        "waived"
+                    ],
+                    "example": "waived"
+                }
+            }
+        },
         "models.UpdateCompanyDTO": {
             "type": "object",
             "properties": {
@@ -1975,6 +9840,52 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateDocumentStatusDTO": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "notes": {
+                    "type": "string",
+                    "example": "Fatura ilegível, por favor envie novamente"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "received",
+                        "accepted",
+                        "rejected"
+                    ],
+                    "example": "accepted"
+                }
+            }
+        },
+        "models.UpdateObligationDTO": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "notes": {
+                    "type": "string",
+                    "example": "Submetida com reembolso"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "Comprovativo 2024-0001234"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "submitted",
+                        "waived"
+                    ],
+                    "example": "submitted"
+                }
+            }
+        },
         "models.UpdateProfileDTO": {
             "type": "object",
             "properties": {
@@ -1988,6 +9899,18 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateUserRoleDTO": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "example": "junior"
+                }
+            }
+        },
         "models.UpdateUserStatusDTO": {
             "type": "object",
             "required": [
@@ -2012,6 +9935,12 @@ const docTemplate = `{
         "models.User": {
             "type": "object",
             "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "activation_sent_at": {
+                    "type": "string"
+                },
                 "billing_software": {
                     "type": "string"
                 },
@@ -2021,8 +9950,14 @@ const docTemplate = `{
                 "citizen_card_number": {
                     "type": "string"
                 },
+                "companies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClientCompanyDTO"
+                    }
+                },
                 "company": {
-                    "description": "Relacionamentos",
+                    "description": "Empresas, carregadas pelos serviços através de user_companies: Company é a empresa principal\ne Companies todas as empresas a que o utilizador está ligado, com o papel em cada uma",
                     "allOf": [
                         {
                             "$ref": "#/definitions/models.Company"
@@ -2065,6 +10000,11 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "language": {
+                    "description": "Idioma das notificações",
+                    "type": "string",
+                    "example": "pt"
+                },
                 "marital_status": {
                     "type": "string"
                 },
@@ -2092,7 +10032,12 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "registration_request": {
-                    "$ref": "#/definitions/models.RegistrationRequest"
+                    "description": "Relacionamentos",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RegistrationRequest"
+                        }
+                    ]
                 },
                 "report_frequency": {
                     "type": "string"
@@ -2115,6 +10060,10 @@ const docTemplate = `{
                 "tax_residence_country": {
                     "type": "string"
                 },
+                "unread_notifications": {
+                    "description": "Calculado na leitura do perfil (não guardado)",
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string",
                     "example": "2023-01-01T00:00:00Z"
@@ -2124,6 +10073,39 @@ const docTemplate = `{
                     "example": "joao.silva"
                 }
             }
+        },
+        "models.UserCompany": {
+            "type": "object",
+            "properties": {
+                "company": {
+                    "$ref": "#/definitions/models.Company"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "owner, manager, viewer",
+                    "type": "string",
+                    "example": "owner"
+                },
+                "user": {
+                    "description": "Relacionamentos",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -4,6 +4,8 @@ import (
 	"RVContabilidadeBack/config"
 	_ "RVContabilidadeBack/docs" // Será gerado automaticamente
 	"RVContabilidadeBack/routes"
+	"RVContabilidadeBack/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -25,6 +27,15 @@ func main() {
     // Inicializar BD
     config.ConnectDatabase()
 
+    // Envio em segundo plano dos emails da caixa de saída
+    services.StartNotificationDispatcher(30 * time.Second)
+
+    // Geração diária das checklists de documentos
+    services.StartChecklistGenerator()
+
+    // Retomar importações SAF-T interrompidas
+    services.NewSAFTService().ResumePendingImports()
+
     // Configurar rotas
     router := routes.SetupRoutes()
 
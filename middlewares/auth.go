@@ -3,8 +3,10 @@ package middlewares
 import (
 	"RVContabilidadeBack/config"
 	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/services"
 	"RVContabilidadeBack/utils"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -61,7 +63,7 @@ func AuthMiddleware() gin.HandlerFunc {
         c.Set("user_id", claims.UserID)
         c.Set("user_username", claims.Username)
         c.Set("user_nif", claims.NIF)
-        c.Set("user_role", claims.Role)
+        c.Set("user_role", user.Role) // Perfil atual da BD: mudanças de perfil valem sem novo login
         
         c.Next() // Continuar para o próximo handler
     }
@@ -104,3 +106,90 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+// RequirePermission middleware para verificar se o utilizador tem todas as permissões indicadas,
+// pelo seu perfil ou por concessão explícita. As permissões ficam em cache no contexto do pedido.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, ok := UserPermissions(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Role de utilizador não encontrada"})
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			if !granted[permission] {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Não tem permissões para aceder a este recurso", "permission": permission})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// UserPermissions devolve as permissões efetivas do utilizador autenticado (com cache no contexto)
+func UserPermissions(c *gin.Context) (map[string]bool, bool) {
+	if cached, exists := c.Get("user_permissions"); exists {
+		return cached.(map[string]bool), true
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return nil, false
+	}
+	userRole, _ := c.Get("user_role")
+	role, _ := userRole.(string)
+
+	granted := services.NewPermissionService().EffectivePermissions(userID.(uint), role)
+	c.Set("user_permissions", granted)
+	return granted, true
+}
+
+// HasPermission indica se o utilizador autenticado tem a permissão
+func HasPermission(c *gin.Context, permission string) bool {
+	granted, ok := UserPermissions(c)
+	return ok && granted[permission]
+}
+
+// RequirePortfolioAccess middleware para as rotas /clients/:id, /companies/:id e /users/:id:
+// quem não tem clients.all só acede aos clientes e empresas da sua carteira
+func RequirePortfolioAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if c.Param("id") == "" || HasPermission(c, models.PermissionClientsAll) {
+			c.Next()
+			return
+		}
+
+		var check func(accountantID, id uint) bool
+		portfolioService := services.NewPortfolioService()
+		switch {
+		case strings.HasPrefix(path, "/api/admin/clients/:id"):
+			check = portfolioService.CanAccessClient
+		case strings.HasPrefix(path, "/api/admin/companies/:id"):
+			check = portfolioService.CanAccessCompany
+		case strings.HasPrefix(path, "/api/admin/users/:id"):
+			check = portfolioService.CanAccessUser
+		default:
+			c.Next()
+			return
+		}
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			// O controller responde ao ID inválido
+			c.Next()
+			return
+		}
+		userID, _ := c.Get("user_id")
+		accountantID, _ := userID.(uint)
+		if !check(accountantID, uint(id)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Cliente fora da sua carteira"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
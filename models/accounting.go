@@ -0,0 +1,198 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de diário
+const (
+	JournalTypeSales     = "sales"
+	JournalTypePurchases = "purchases"
+	JournalTypeBank      = "bank"
+	JournalTypeGeneral   = "general"
+)
+
+// Estados de um período contabilístico
+const (
+	PeriodStatusOpen       = "open"
+	PeriodStatusSoftClosed = "soft_closed" // Fechado para clientes; contabilistas ainda podem fazer ajustes
+	PeriodStatusHardClosed = "hard_closed" // Fechado para todos (ex.: após entrega da declaração de IVA)
+)
+
+// Account é uma conta do plano de contas (SNC) de uma empresa
+type Account struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CompanyID  uint      `json:"company_id" gorm:"not null;uniqueIndex:idx_account_company_code"`
+	Code       string    `json:"code" gorm:"not null;size:20;uniqueIndex:idx_account_company_code"`
+	Name       string    `json:"name" gorm:"not null"`
+	Class      int       `json:"class"`       // Classe SNC (1 a 8)
+	ParentCode string    `json:"parent_code"` // Conta de nível superior ("" nas contas de 2 dígitos)
+	Active     bool      `json:"active" gorm:"default:true"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Postable indica se a conta aceita lançamentos (não tem subcontas)
+	Postable bool `json:"postable" gorm:"-"`
+}
+
+// Journal é um diário contabilístico (vendas, compras, bancos, operações diversas)
+type Journal struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CompanyID uint      `json:"company_id" gorm:"not null;uniqueIndex:idx_journal_company_code"`
+	Code      string    `json:"code" gorm:"not null;size:10;uniqueIndex:idx_journal_company_code"`
+	Name      string    `json:"name" gorm:"not null"`
+	Type      string    `json:"type" gorm:"not null"` // sales, purchases, bank, general
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// FiscalYear é um exercício contabilístico de uma empresa
+type FiscalYear struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CompanyID uint      `json:"company_id" gorm:"not null;uniqueIndex:idx_fiscal_year_company_year"`
+	Year      int       `json:"year" gorm:"not null;uniqueIndex:idx_fiscal_year_company_year"`
+	StartDate time.Time `json:"start_date" gorm:"type:date"`
+	EndDate   time.Time `json:"end_date" gorm:"type:date"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	Periods []FiscalPeriod `json:"periods,omitempty" gorm:"foreignKey:FiscalYearID"`
+}
+
+// FiscalPeriod é um mês de um exercício; os períodos fechados não aceitam alterações
+type FiscalPeriod struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	CompanyID    uint       `json:"company_id" gorm:"not null;index"`
+	FiscalYearID uint       `json:"fiscal_year_id" gorm:"not null;uniqueIndex:idx_fiscal_period_number"`
+	Number       int        `json:"number" gorm:"not null;uniqueIndex:idx_fiscal_period_number"` // 1 a 12
+	StartDate    time.Time  `json:"start_date" gorm:"type:date"`
+	EndDate      time.Time  `json:"end_date" gorm:"type:date"`
+	Status       string     `json:"status" gorm:"default:'open'"` // open, soft_closed, hard_closed
+	ClosedBy     *uint      `json:"closed_by"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// FiscalPeriodHistory regista cada fecho ou reabertura de um período
+type FiscalPeriodHistory struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CompanyID      uint      `json:"company_id" gorm:"not null;index"`
+	FiscalPeriodID uint      `json:"fiscal_period_id" gorm:"not null;index"`
+	Action         string    `json:"action" gorm:"not null"` // close, reopen
+	FromStatus     string    `json:"from_status"`
+	ToStatus       string    `json:"to_status"`
+	Reason         string    `json:"reason"`
+	PerformedBy    uint      `json:"performed_by"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	PerformedByUser *User `json:"performed_by_user,omitempty" gorm:"foreignKey:PerformedBy"`
+}
+
+// JournalEntry é um lançamento contabilístico (débitos = créditos)
+type JournalEntry struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CompanyID      uint      `json:"company_id" gorm:"not null;index;uniqueIndex:idx_journal_entry_number"`
+	JournalID      uint      `json:"journal_id" gorm:"not null;uniqueIndex:idx_journal_entry_number"`
+	FiscalYearID   uint      `json:"fiscal_year_id" gorm:"not null;uniqueIndex:idx_journal_entry_number"`
+	FiscalPeriodID uint      `json:"fiscal_period_id" gorm:"not null;index"`
+	Number         int       `json:"number" gorm:"not null;uniqueIndex:idx_journal_entry_number"` // Sequencial por diário e exercício
+	Date           time.Time `json:"date" gorm:"type:date;index"`
+	Description    string    `json:"description"`
+	DocumentRef    string    `json:"document_ref"` // Documento de suporte (ex.: "FT A/123")
+	CreatedBy      uint      `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	Journal *Journal           `json:"journal,omitempty" gorm:"foreignKey:JournalID"`
+	Lines   []JournalEntryLine `json:"lines" gorm:"foreignKey:EntryID;constraint:OnDelete:CASCADE"`
+}
+
+// JournalEntryLine é uma linha a débito ou a crédito de um lançamento
+type JournalEntryLine struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	EntryID     uint    `json:"entry_id" gorm:"not null;index"`
+	AccountID   uint    `json:"account_id" gorm:"not null;index"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+	Description string  `json:"description"`
+
+	// Relacionamentos
+	Account *Account `json:"account,omitempty" gorm:"foreignKey:AccountID"`
+}
+
+// CreateAccountDTO para criar uma subconta no plano de contas da empresa
+type CreateAccountDTO struct {
+	Code string `json:"code" binding:"required,numeric,max=20" example:"22101"`
+	Name string `json:"name" binding:"required" example:"Fornecedores nacionais"`
+}
+
+// UpdateAccountDTO para alterar o nome ou desativar uma conta
+type UpdateAccountDTO struct {
+	Name   *string `json:"name" example:"Fornecedores gerais"`
+	Active *bool   `json:"active" example:"true"`
+}
+
+// CreateJournalDTO para criar um diário
+type CreateJournalDTO struct {
+	Code string `json:"code" binding:"required,max=10" example:"BNC2"`
+	Name string `json:"name" binding:"required" example:"Banco - conta secundária"`
+	Type string `json:"type" binding:"required,oneof=sales purchases bank general" example:"bank"`
+}
+
+// CreateFiscalYearDTO para abrir um exercício (12 períodos mensais a partir de start_date)
+type CreateFiscalYearDTO struct {
+	Year      int    `json:"year" binding:"required,min=2000,max=2100" example:"2025"`
+	StartDate string `json:"start_date" example:"2025-01-01"` // Por omissão 1 de janeiro
+}
+
+// CloseFiscalPeriodDTO para fechar um período ou um exercício
+type CloseFiscalPeriodDTO struct {
+	Status string `json:"status" binding:"required,oneof=soft_closed hard_closed" example:"hard_closed"`
+	Reason string `json:"reason" example:"Declaração periódica de IVA entregue"`
+}
+
+// ReopenFiscalPeriodDTO para reabrir um período (apenas admin)
+type ReopenFiscalPeriodDTO struct {
+	Status string `json:"status" binding:"omitempty,oneof=open soft_closed" example:"open"` // Por omissão open
+	Reason string `json:"reason" binding:"required,min=5" example:"Correção de fatura lançada no mês errado"`
+}
+
+// JournalEntryLineDTO é uma linha de um novo lançamento
+type JournalEntryLineDTO struct {
+	AccountCode string  `json:"account_code" binding:"required" example:"6221"`
+	Debit       float64 `json:"debit" binding:"min=0" example:"100.00"`
+	Credit      float64 `json:"credit" binding:"min=0" example:"0"`
+	Description string  `json:"description" example:"Trabalhos especializados"`
+}
+
+// CreateJournalEntryDTO para registar um lançamento
+type CreateJournalEntryDTO struct {
+	JournalCode string                `json:"journal_code" binding:"required" example:"CMP"`
+	Date        string                `json:"date" binding:"required" example:"2025-03-15"`
+	Description string                `json:"description" example:"Fatura FT A/123 - Fornecedor X"`
+	DocumentRef string                `json:"document_ref" example:"FT A/123"`
+	Lines       []JournalEntryLineDTO `json:"lines" binding:"required,min=2,dive"`
+}
+
+// TrialBalanceRowDTO é uma linha do balancete
+type TrialBalanceRowDTO struct {
+	Code          string  `json:"code"`
+	Name          string  `json:"name"`
+	Postable      bool    `json:"postable"`
+	Debit         float64 `json:"debit"`
+	Credit        float64 `json:"credit"`
+	DebitBalance  float64 `json:"debit_balance"`
+	CreditBalance float64 `json:"credit_balance"`
+}
+
+// TrialBalanceDTO é o balancete de uma empresa num intervalo de períodos
+type TrialBalanceDTO struct {
+	CompanyID   uint                 `json:"company_id"`
+	FiscalYear  int                  `json:"fiscal_year"`
+	PeriodFrom  int                  `json:"period_from"`
+	PeriodTo    int                  `json:"period_to"`
+	Rows        []TrialBalanceRowDTO `json:"rows"`
+	TotalDebit  float64              `json:"total_debit"`
+	TotalCredit float64              `json:"total_credit"`
+	Balanced    bool                 `json:"balanced"`
+}
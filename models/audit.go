@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// Ações registadas no registo de auditoria
+const (
+	AuditActionExport           = "export"
+	AuditActionPermissionGrant  = "permission_grant"
+	AuditActionPermissionRevoke = "permission_revoke"
+	AuditActionClientImport     = "client_import"
+	AuditActionUserCreate       = "user_create"
+	AuditActionRoleCreate       = "role_create"
+	AuditActionRoleUpdate       = "role_update"
+	AuditActionRoleDelete       = "role_delete"
+	AuditActionUserRoleChange   = "user_role_change"
+	AuditActionPortfolioAssign  = "portfolio_assign"
+	AuditActionPortfolioMove    = "portfolio_reassign"
+	AuditActionCompanyCreate    = "company_create"
+	AuditActionCompanyUserLink  = "company_user_link"
+	AuditActionCompanyUnlink    = "company_user_unlink"
+	AuditActionClientDelete     = "client_delete"
+)
+
+// AuditLog regista uma ação sensível feita por um utilizador (exportações, permissões, ...)
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	Action     string    `json:"action" gorm:"not null;index"`
+	EntityType string    `json:"entity_type" gorm:"index"` // Ex.: users-overview, user
+	EntityID   *uint     `json:"entity_id"`
+	Details    string    `json:"details" gorm:"type:text"` // JSON com os parâmetros da ação
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+
+	// Relacionamentos
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// Estado de um movimento bancário face às faturas
+const (
+	BankTransactionUnmatched = "unmatched"
+	BankTransactionMatched   = "matched"
+)
+
+// BankStatementImport regista a importação de um extrato bancário
+type BankStatementImport struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CompanyID      uint      `json:"company_id" gorm:"not null;index"`
+	UploadedBy     uint      `json:"uploaded_by" gorm:"not null"`
+	FileName       string    `json:"file_name"`
+	Format         string    `json:"format"` // camt053, ofx, csv_cgd, csv_millennium, ...
+	IBAN           string    `json:"iban"`
+	PeriodStart    time.Time `json:"period_start" gorm:"type:date"`
+	PeriodEnd      time.Time `json:"period_end" gorm:"type:date"`
+	Transactions   int       `json:"transactions"`
+	Created        int       `json:"created"`
+	Duplicates     int       `json:"duplicates"`
+	ClosedPeriod   int       `json:"closed_period"` // movimentos ignorados por terem data num período fechado
+	ClosingBalance *float64  `json:"closing_balance"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BankTransaction é um movimento do livro bancário de uma empresa
+type BankTransaction struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	CompanyID        uint      `json:"company_id" gorm:"not null;index;uniqueIndex:idx_bank_transaction_reference"`
+	ImportID         uint      `json:"import_id" gorm:"not null;index"`
+	IBAN             string    `json:"iban" gorm:"uniqueIndex:idx_bank_transaction_reference"`
+	Reference        string    `json:"reference" gorm:"not null;uniqueIndex:idx_bank_transaction_reference"` // Referência do banco (ou "auto-..." se o formato não a tiver)
+	BookingDate      time.Time `json:"booking_date" gorm:"type:date;index"`
+	ValueDate        time.Time `json:"value_date" gorm:"type:date"`
+	Amount           float64   `json:"amount"` // Positivo para recebimentos, negativo para pagamentos
+	Currency         string    `json:"currency" gorm:"default:'EUR'"`
+	Description      string    `json:"description"`
+	Counterparty     string    `json:"counterparty"`
+	CounterpartyIBAN string    `json:"counterparty_iban"`
+	Balance          *float64  `json:"balance"`
+	MatchedAmount    float64   `json:"matched_amount"`                                // Parte do valor já conciliada com faturas
+	MatchStatus      string    `json:"match_status" gorm:"default:'unmatched';index"` // unmatched, matched
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BankTransactionListDTO é uma página de movimentos com o total
+type BankTransactionListDTO struct {
+	Transactions []BankTransaction `json:"transactions"`
+	Total        int64             `json:"total"`
+	TotalAmount  float64           `json:"total_amount"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de documento de faturação (SAF-T PT)
+const (
+	InvoiceTypeInvoice         = "FT" // Fatura
+	InvoiceTypeInvoiceReceipt  = "FR" // Fatura-recibo
+	InvoiceTypeSimplified      = "FS" // Fatura simplificada
+	InvoiceTypeCreditNote      = "NC" // Nota de crédito
+	InvoiceStatusNormal        = "N"
+	FinalConsumerTaxID         = "999999990" // NIF genérico do consumidor final
+	FinalConsumerName          = "Consumidor final"
+	SimplifiedInvoiceMaxAmount = 1000.0 // Limite das faturas simplificadas (art. 40.º CIVA)
+)
+
+// Customer é um cliente da empresa a quem são emitidas faturas
+type Customer struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CompanyID  uint      `json:"company_id" gorm:"not null;index"`
+	TaxID      string    `json:"tax_id" gorm:"not null;index"`
+	Name       string    `json:"name" gorm:"not null"`
+	Address    string    `json:"address"`
+	PostalCode string    `json:"postal_code"`
+	City       string    `json:"city"`
+	Country    string    `json:"country" gorm:"default:'PT'"`
+	Email      string    `json:"email"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// InvoiceSeries é uma série de documentos comunicada à AT, com o respetivo código de validação
+type InvoiceSeries struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	CompanyID      uint       `json:"company_id" gorm:"not null;uniqueIndex:idx_invoice_series_code"`
+	DocumentType   string     `json:"document_type" gorm:"not null;size:2;uniqueIndex:idx_invoice_series_code"` // FT, FR, FS, NC
+	Code           string     `json:"code" gorm:"not null;size:20;uniqueIndex:idx_invoice_series_code"`
+	ValidationCode string     `json:"validation_code" gorm:"not null"` // Código de validação atribuído pela AT (prefixo do ATCUD)
+	LastNumber     int        `json:"last_number"`
+	LastHash       string     `json:"-"`
+	LastIssueDate  *time.Time `json:"last_issue_date" gorm:"type:date"`
+	Active         bool       `json:"active" gorm:"default:true"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Invoice é um documento de faturação emitido pela empresa (fatura ou nota de crédito)
+type Invoice struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	CompanyID          uint       `json:"company_id" gorm:"not null;index"`
+	SeriesID           uint       `json:"series_id" gorm:"not null;uniqueIndex:idx_invoice_series_number"`
+	CustomerID         uint       `json:"customer_id" gorm:"not null;index"`
+	DocumentType       string     `json:"document_type" gorm:"not null;size:2"`
+	Number             int        `json:"number" gorm:"not null;uniqueIndex:idx_invoice_series_number"`
+	DocumentNo         string     `json:"document_no" gorm:"not null;index"` // Ex.: "FT 2025A/12"
+	ATCUD              string     `json:"atcud" gorm:"not null"`
+	Status             string     `json:"status" gorm:"default:'N'"`
+	IssueDate          time.Time  `json:"issue_date" gorm:"type:date"`
+	SystemEntryDate    time.Time  `json:"system_entry_date"`
+	DueDate            *time.Time `json:"due_date" gorm:"type:date"`
+	NetTotal           float64    `json:"net_total"`
+	TaxTotal           float64    `json:"tax_total"`
+	GrossTotal         float64    `json:"gross_total"`
+	Hash               string     `json:"hash"`
+	HashControl        string     `json:"hash_control"`         // Versão da chave privada
+	ReferenceInvoiceID *uint      `json:"reference_invoice_id"` // Fatura corrigida (notas de crédito)
+	Reason             string     `json:"reason"`               // Motivo da nota de crédito
+	CreatedBy          uint       `json:"created_by"`
+	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	Customer *Customer     `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	Lines    []InvoiceLine `json:"lines,omitempty" gorm:"foreignKey:InvoiceID"`
+}
+
+// InvoiceLine é uma linha de um documento de faturação
+type InvoiceLine struct {
+	ID               uint    `json:"id" gorm:"primaryKey"`
+	InvoiceID        uint    `json:"invoice_id" gorm:"not null;index"`
+	LineNumber       int     `json:"line_number"`
+	Description      string  `json:"description" gorm:"not null"`
+	Quantity         float64 `json:"quantity"`
+	UnitPrice        float64 `json:"unit_price"`
+	TaxRate          float64 `json:"tax_rate"`
+	TaxExemptionCode string  `json:"tax_exemption_code"` // M01 a M99 quando a taxa é 0
+	NetAmount        float64 `json:"net_amount"`
+	TaxAmount        float64 `json:"tax_amount"`
+	ReferenceLineID  *uint   `json:"reference_line_id"` // Linha da fatura corrigida (notas de crédito)
+}
+
+// CustomerDTO para criar ou atualizar um cliente de faturação
+type CustomerDTO struct {
+	TaxID      string `json:"tax_id" binding:"required" example:"123456789"`
+	Name       string `json:"name" binding:"required" example:"Cliente Exemplo, Lda"`
+	Address    string `json:"address" example:"Rua do Comércio, 10"`
+	PostalCode string `json:"postal_code" example:"1100-150"`
+	City       string `json:"city" example:"Lisboa"`
+	Country    string `json:"country" binding:"omitempty,len=2" example:"PT"`
+	Email      string `json:"email" binding:"omitempty,email" example:"geral@cliente.pt"`
+}
+
+// CreateInvoiceSeriesDTO para registar uma série comunicada à AT
+type CreateInvoiceSeriesDTO struct {
+	DocumentType   string `json:"document_type" binding:"required,oneof=FT FR FS NC" example:"FT"`
+	Code           string `json:"code" binding:"required,alphanum,max=20" example:"2025A"`
+	ValidationCode string `json:"validation_code" binding:"required,alphanum,min=8" example:"AAJFJMVNTN"`
+}
+
+// InvoiceLineDTO é uma linha de uma nova fatura
+type InvoiceLineDTO struct {
+	Description      string  `json:"description" binding:"required" example:"Serviços de consultoria"`
+	Quantity         float64 `json:"quantity" binding:"required,gt=0" example:"1"`
+	UnitPrice        float64 `json:"unit_price" binding:"min=0" example:"100.00"`
+	TaxRate          float64 `json:"tax_rate" binding:"min=0" example:"23"`
+	TaxExemptionCode string  `json:"tax_exemption_code" example:"M07"`
+}
+
+// CreateInvoiceDTO para emitir uma fatura
+type CreateInvoiceDTO struct {
+	SeriesID   uint             `json:"series_id" binding:"required" example:"1"`
+	CustomerID *uint            `json:"customer_id" example:"3"`         // Vazio para consumidor final
+	IssueDate  string           `json:"issue_date" example:"2025-03-15"` // Por omissão hoje
+	DueDate    string           `json:"due_date" example:"2025-04-14"`
+	Lines      []InvoiceLineDTO `json:"lines" binding:"required,min=1,dive"`
+}
+
+// CreditNoteLineDTO indica a quantidade a creditar de uma linha da fatura
+type CreditNoteLineDTO struct {
+	InvoiceLineID uint    `json:"invoice_line_id" binding:"required" example:"10"`
+	Quantity      float64 `json:"quantity" binding:"required,gt=0" example:"1"`
+}
+
+// CreateCreditNoteDTO para emitir uma nota de crédito sobre uma fatura (sem linhas, credita o valor em aberto)
+type CreateCreditNoteDTO struct {
+	SeriesID  uint                `json:"series_id" binding:"required" example:"2"`
+	IssueDate string              `json:"issue_date" example:"2025-03-20"`
+	Reason    string              `json:"reason" binding:"required" example:"Devolução de mercadoria"`
+	Lines     []CreditNoteLineDTO `json:"lines" binding:"omitempty,dive"`
+}
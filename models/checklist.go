@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// Entregáveis esperados em cada período
+const (
+	DeliverablePurchaseInvoices = "purchase_invoices"
+	DeliverableSalesInvoices    = "sales_invoices"
+	DeliverableBankStatements   = "bank_statements"
+	DeliverablePayroll          = "payroll"
+	DeliverableStockReport      = "stock_report"
+)
+
+// Estados de um item da checklist
+const (
+	ChecklistStatusMissing   = "missing"
+	ChecklistStatusSubmitted = "submitted"
+	ChecklistStatusWaived    = "waived"
+)
+
+// ChecklistItem é um documento que a empresa deve entregar num período
+type ChecklistItem struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	CompanyID   uint       `json:"company_id" gorm:"not null;uniqueIndex:idx_checklist_company_period_deliverable"`
+	Period      string     `json:"period" gorm:"not null;index;uniqueIndex:idx_checklist_company_period_deliverable"` // AAAA-MM, AAAA-T1 ou AAAA
+	PeriodStart time.Time  `json:"period_start" gorm:"not null"`
+	PeriodEnd   time.Time  `json:"period_end" gorm:"not null"`
+	Deliverable string     `json:"deliverable" gorm:"not null;uniqueIndex:idx_checklist_company_period_deliverable"`
+	DueDate     time.Time  `json:"due_date" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"default:'missing';index"` // missing, submitted, waived
+	DocumentID  *uint      `json:"document_id"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	Notes       string     `json:"notes"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Calculado (não guardado)
+	Late bool `json:"late" gorm:"-"`
+
+	// Relacionamentos
+	Company *Company `json:"company,omitempty" gorm:"foreignKey:CompanyID"`
+}
+
+// UpdateChecklistItemDTO para a contabilista dispensar ou reabrir um item
+type UpdateChecklistItemDTO struct {
+	Status string `json:"status" binding:"required,oneof=missing submitted waived" example:"waived"`
+	Notes  string `json:"notes" example:"Sem movimentos bancários neste mês"`
+}
+
+// LateMatrixCellDTO é o estado de uma empresa num período
+type LateMatrixCellDTO struct {
+	Period  string   `json:"period"`
+	Status  string   `json:"status"`            // complete, pending, late, none
+	Missing []string `json:"missing,omitempty"` // Entregáveis em falta
+}
+
+// LateMatrixRowDTO é uma linha da matriz de atrasos
+type LateMatrixRowDTO struct {
+	CompanyID   uint                `json:"company_id"`
+	CompanyName string              `json:"company_name"`
+	ClientID    uint                `json:"client_id"`
+	LateCount   int                 `json:"late_count"`
+	Cells       []LateMatrixCellDTO `json:"cells"`
+}
+
+// LateMatrixDTO mostra que clientes estão atrasados em que períodos
+type LateMatrixDTO struct {
+	Months []string           `json:"months"`
+	Rows   []LateMatrixRowDTO `json:"rows"`
+}
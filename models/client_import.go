@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// Modos de importação de clientes
+const (
+	ClientImportModeApproved       = "approved"        // Cria logo utilizador e empresa aprovados
+	ClientImportModeExistingClient = "existing_client" // Cria solicitações existing_client para revisão
+)
+
+// Estados de uma linha importada
+const (
+	ClientImportRowValid   = "valid"   // Passou a validação (simulação)
+	ClientImportRowInvalid = "invalid" // Com erros de validação, não importada
+	ClientImportRowCreated = "created" // Cliente ou solicitação criados
+	ClientImportRowFailed  = "failed"  // Válida mas falhou ao gravar
+)
+
+// ClientImport regista uma importação de clientes a partir de CSV ou XLSX
+type ClientImport struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	FileName    string    `json:"file_name"`
+	Mode        string    `json:"mode" gorm:"not null"` // approved, existing_client
+	DryRun      bool      `json:"dry_run"`
+	TotalRows   int       `json:"total_rows"`
+	ValidRows   int       `json:"valid_rows"`
+	InvalidRows int       `json:"invalid_rows"`
+	Created     int       `json:"created"`
+	Failed      int       `json:"failed"`
+	CreatedBy   uint      `json:"created_by" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	Rows []ClientImportRow `json:"rows,omitempty" gorm:"foreignKey:ImportID"`
+}
+
+// ClientImportRow é o resultado de uma linha do ficheiro importado
+type ClientImportRow struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	ImportID    uint   `json:"import_id" gorm:"not null;index"`
+	Line        int    `json:"line"`
+	Status      string `json:"status"` // valid, invalid, created, failed
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	NIF         string `json:"nif"`
+	CompanyName string `json:"company_name"`
+	NIPC        string `json:"nipc"`
+	Errors      string `json:"errors" gorm:"type:text"` // Mensagens separadas por "; "
+	UserID      *uint  `json:"user_id,omitempty"`
+	RequestID   *uint  `json:"request_id,omitempty"`
+}
+
+// ClientImportColumnDTO descreve uma coluna aceite no ficheiro de importação
+type ClientImportColumnDTO struct {
+	Field    string   `json:"field" example:"nif"`
+	Aliases  []string `json:"aliases"`
+	Required bool     `json:"required"`
+}
@@ -7,7 +7,7 @@ import (
 // Company representa uma empresa aprovada
 type Company struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id" gorm:"not null;unique"` // One-to-One com User
+	UserID    uint      `json:"user_id" gorm:"not null;index"` // Titular que registou a empresa; os acessos estão em UserCompany
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	
@@ -53,6 +53,66 @@ type Company struct {
 	RegistrationRequest *RegistrationRequest `json:"registration_request,omitempty" gorm:"foreignKey:CompanyID"`
 }
 
+// Papéis de um utilizador numa empresa (UserCompany.Role)
+const (
+	CompanyRoleOwner   = "owner"   // Titular: vê e edita a empresa
+	CompanyRoleManager = "manager" // Gerente: vê e edita a empresa
+	CompanyRoleViewer  = "viewer"  // Consulta: só vê a empresa
+)
+
+// IsValidCompanyRole indica se o papel existe
+func IsValidCompanyRole(role string) bool {
+	return role == CompanyRoleOwner || role == CompanyRoleManager || role == CompanyRoleViewer
+}
+
+// CanEditCompany indica se o papel permite alterar os dados da empresa
+func CanEditCompany(role string) bool {
+	return role == CompanyRoleOwner || role == CompanyRoleManager
+}
+
+// UserCompany liga um cliente a uma empresa com um papel (many-to-many).
+// Um cliente pode ter várias empresas (por exemplo, ENI e Lda) e uma empresa vários utilizadores.
+type UserCompany struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_company"`
+	CompanyID uint      `json:"company_id" gorm:"not null;uniqueIndex:idx_user_company;index"`
+	Role      string    `json:"role" gorm:"not null;default:'owner'" example:"owner"` // owner, manager, viewer
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	User    *User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Company *Company `json:"company,omitempty" gorm:"foreignKey:CompanyID"`
+}
+
+// ClientCompanyDTO é uma empresa do cliente com o papel que ele tem nessa empresa
+type ClientCompanyDTO struct {
+	Company
+	CompanyRole string `json:"company_role" example:"owner"`
+}
+
+// CompanyUserDTO para ligar um cliente a uma empresa ou mudar o seu papel
+type CompanyUserDTO struct {
+	UserID uint   `json:"user_id" binding:"required" example:"5"`
+	Role   string `json:"role" binding:"required,oneof=owner manager viewer" example:"manager"`
+}
+
+// CreateClientCompanyDTO para acrescentar uma empresa a um cliente existente
+type CreateClientCompanyDTO struct {
+	CompanyName      string `json:"company_name" binding:"required" example:"Silva Unipessoal Lda"`
+	NIPC             string `json:"nipc" example:"509442013"`
+	LegalForm        string `json:"legal_form" binding:"required" example:"Sociedade Unipessoal por Quotas"`
+	CAE              string `json:"cae" example:"69200"`
+	TradeName        string `json:"trade_name" example:"Silva Consultoria"`
+	AccountingRegime string `json:"accounting_regime" example:"organizada"`
+	VATRegime        string `json:"vat_regime" example:"normal"`
+	BusinessActivity string `json:"business_activity" example:"Consultoria em gestão"`
+	Address          string `json:"address" example:"Rua das Flores, 123"`
+	PostalCode       string `json:"postal_code" example:"1000-001"`
+	City             string `json:"city" example:"Lisboa"`
+	District         string `json:"district" example:"Lisboa"`
+	Role             string `json:"role" example:"owner"` // Papel do cliente na nova empresa (owner por omissão)
+}
+
 // UpdateCompanyDTO para atualização de empresa (campos limitados)
 type UpdateCompanyDTO struct {
 	TradeName  string `json:"trade_name" example:"Silva Consultoria"`
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de documento enviados pelos clientes
+const (
+	DocumentTypeInvoice         = "invoice"
+	DocumentTypePurchaseInvoice = "purchase_invoice"
+	DocumentTypeSalesInvoice    = "sales_invoice"
+	DocumentTypeReceipt         = "receipt"
+	DocumentTypeBankStatement   = "bank_statement"
+	DocumentTypePayroll         = "payroll"
+	DocumentTypeStockReport     = "stock_report"
+	DocumentTypeSAFT            = "saft"
+	DocumentTypeCitizenCard     = "citizen_card"
+	DocumentTypeOther           = "other"
+)
+
+// Estados de um documento
+const (
+	DocumentStatusReceived = "received"
+	DocumentStatusAccepted = "accepted"
+	DocumentStatusRejected = "rejected"
+)
+
+// Document é um ficheiro enviado por um cliente e associado à sua empresa
+type Document struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	CompanyID    uint       `json:"company_id" gorm:"not null;index;uniqueIndex:idx_document_company_hash"`
+	UploadedBy   uint       `json:"uploaded_by" gorm:"not null"`
+	Type         string     `json:"type" gorm:"not null;index"` // Ver constantes DocumentType*
+	FiscalPeriod string     `json:"fiscal_period" gorm:"index"` // AAAA-MM ou AAAA (vazio para documentos sem período)
+	OriginalName string     `json:"original_name" gorm:"not null"`
+	MimeType     string     `json:"mime_type" gorm:"not null"`
+	Size         int64      `json:"size"`
+	SHA256       string     `json:"sha256" gorm:"size:64;not null;uniqueIndex:idx_document_company_hash"`
+	StorageKey   string     `json:"-" gorm:"not null"`
+	Notes        string     `json:"notes"`
+	Status       string     `json:"status" gorm:"default:'received';index"` // received, accepted, rejected
+	ReviewNotes  string     `json:"review_notes"`
+	ReviewedBy   *uint      `json:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relacionamentos
+	Company *Company `json:"company,omitempty" gorm:"foreignKey:CompanyID"`
+}
+
+// UpdateDocumentStatusDTO para a contabilista aceitar ou rejeitar um documento
+type UpdateDocumentStatusDTO struct {
+	Status string `json:"status" binding:"required,oneof=received accepted rejected" example:"accepted"`
+	Notes  string `json:"notes" example:"Fatura ilegível, por favor envie novamente"`
+}
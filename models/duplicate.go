@@ -0,0 +1,29 @@
+package models
+
+// Níveis de risco do relatório de duplicados
+const (
+	DuplicateRiskNone   = "none"
+	DuplicateRiskLow    = "low"
+	DuplicateRiskMedium = "medium"
+	DuplicateRiskHigh   = "high"
+)
+
+// DuplicateMatchDTO representa um registo existente parecido com a solicitação analisada
+type DuplicateMatchDTO struct {
+	Source         string   `json:"source" example:"company"` // user, company, registration_request
+	ID             uint     `json:"id" example:"3"`
+	Label          string   `json:"label" example:"Silva & Associados Lda"`
+	Status         string   `json:"status" example:"approved"`
+	Score          int      `json:"score" example:"80"` // 0 a 100
+	MatchedFields  []string `json:"matched_fields" example:"nipc,iban"`
+	NameSimilarity float64  `json:"name_similarity,omitempty" example:"0.92"`
+}
+
+// DuplicateReportDTO resume os prováveis duplicados de uma solicitação de registo
+type DuplicateReportDTO struct {
+	HighestScore int                 `json:"highest_score" example:"80"`
+	RiskLevel    string              `json:"risk_level" example:"high"` // none, low, medium, high
+	Matches      []DuplicateMatchDTO `json:"matches"`
+	// Conflitos que impedem a aprovação (campos únicos já usados por contas ou empresas existentes)
+	Conflicts []string `json:"conflicts"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// ExportColumnDTO descreve uma coluna disponível numa exportação
+type ExportColumnDTO struct {
+	Key       string `json:"key" example:"iban"`
+	Label     string `json:"label" example:"IBAN"`
+	Sensitive bool   `json:"sensitive"` // Exportada com máscara sem a permissão export_sensitive
+}
+
+// ExportDatasetDTO descreve um conjunto de dados exportável, com as colunas e os filtros aceites
+type ExportDatasetDTO struct {
+	Dataset string            `json:"dataset" example:"clients"`
+	Columns []ExportColumnDTO `json:"columns"`
+	Filters []string          `json:"filters"`
+}
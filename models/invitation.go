@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+)
+
+// Estados de um convite de cliente
+const (
+	InvitationStatusPending  = "pending"  // Enviado, aguarda aceitação
+	InvitationStatusAccepted = "accepted" // Cliente criou a conta
+	InvitationStatusRevoked  = "revoked"  // Cancelado pelo contabilista
+	InvitationStatusExpired  = "expired"  // Prazo ultrapassado sem aceitação (calculado na leitura)
+)
+
+// ClientInvitation é um convite enviado pelo contabilista, com os dados da empresa já preenchidos.
+// Ao aceitar, o cliente escolhe a password e a conta e a empresa são criadas já aprovadas.
+type ClientInvitation struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Token      string     `json:"-" gorm:"index"`
+	Status     string     `json:"status" gorm:"default:'pending';index"` // pending, accepted, revoked (expired é calculado)
+	InvitedBy  uint       `json:"invited_by" gorm:"not null;index"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     time.Time  `json:"sent_at"`
+	SendCount  int        `json:"send_count" gorm:"default:1"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	RevokedBy  *uint      `json:"revoked_by"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Dados pessoais pré-preenchidos (o cliente pode completar ao aceitar)
+	Email    string `json:"email" gorm:"not null;index"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	NIF      string `json:"nif"`
+	Username string `json:"username"`
+	Language string `json:"language" gorm:"default:'pt'"`
+
+	// Dados da empresa pré-preenchidos pelo contabilista
+	CompanyName       string `json:"company_name" gorm:"not null"`
+	NIPC              string `json:"nipc" gorm:"index"`
+	LegalForm         string `json:"legal_form" gorm:"not null"`
+	CAE               string `json:"cae"`
+	TradeName         string `json:"trade_name"`
+	AccountingRegime  string `json:"accounting_regime"`
+	VATRegime         string `json:"vat_regime"`
+	BusinessActivity  string `json:"business_activity"`
+	CompanyAddress    string `json:"company_address"`
+	CompanyPostalCode string `json:"company_postal_code"`
+	CompanyCity       string `json:"company_city"`
+	CompanyDistrict   string `json:"company_district"`
+	Notes             string `json:"notes" gorm:"type:text"` // Mensagem do contabilista incluída no email
+
+	// Preenchidos quando o convite é aceite
+	UserID    *uint `json:"user_id,omitempty" gorm:"index"`
+	CompanyID *uint `json:"company_id,omitempty"`
+	RequestID *uint `json:"request_id,omitempty"`
+
+	// Relacionamentos
+	InvitedByUser *User `json:"invited_by_user,omitempty" gorm:"foreignKey:InvitedBy"`
+}
+
+// CreateInvitationDTO para o contabilista convidar um cliente
+type CreateInvitationDTO struct {
+	Email    string `json:"email" binding:"required,email" example:"joao@exemplo.com"`
+	Name     string `json:"name" example:"João Silva"`
+	Phone    string `json:"phone" example:"912345678"`
+	NIF      string `json:"nif" example:"123456789"`
+	Username string `json:"username" example:"joao.silva"`
+	Language string `json:"language" example:"pt"`
+
+	CompanyName       string `json:"company_name" binding:"required" example:"Silva & Associados Lda"`
+	NIPC              string `json:"nipc" example:"509442013"`
+	LegalForm         string `json:"legal_form" binding:"required" example:"Sociedade por Quotas"`
+	CAE               string `json:"cae" example:"69200"`
+	TradeName         string `json:"trade_name" example:"Silva Consultoria"`
+	AccountingRegime  string `json:"accounting_regime" example:"organizada"`
+	VATRegime         string `json:"vat_regime" example:"normal"`
+	BusinessActivity  string `json:"business_activity" example:"Consultoria em gestão"`
+	CompanyAddress    string `json:"company_address" example:"Rua das Flores, 123"`
+	CompanyPostalCode string `json:"company_postal_code" example:"1000-001"`
+	CompanyCity       string `json:"company_city" example:"Lisboa"`
+	CompanyDistrict   string `json:"company_district" example:"Lisboa"`
+	Notes             string `json:"notes" example:"Bem-vindo! Complete os dados da empresa depois de entrar."`
+}
+
+// AcceptInvitationDTO para o cliente aceitar o convite e escolher a password.
+// Os dados pessoais só são necessários se o contabilista não os tiver preenchido.
+type AcceptInvitationDTO struct {
+	Token    string `json:"token" binding:"required" example:"3f2a9c..."`
+	Username string `json:"username" example:"joao.silva"`
+	Password string `json:"password" binding:"required,min=6" example:"password123"`
+	Name     string `json:"name" example:"João Silva"`
+	Phone    string `json:"phone" example:"912345678"`
+	NIF      string `json:"nif" example:"123456789"`
+}
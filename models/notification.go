@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+)
+
+// Eventos que originam notificações
+const (
+	EventEmailVerification = "email_verification"
+	EventRequestReceived   = "request_received"
+	EventRequestApproved   = "request_approved"
+	EventRequestRejected   = "request_rejected"
+	EventRequestNeedsInfo  = "request_needs_info"
+	EventAccountBlocked    = "account_blocked"
+	EventPasswordChanged   = "password_changed"
+	EventRequestAssigned   = "request_assigned"
+	EventProfileUpdated    = "profile_updated"
+	EventStatusChanged     = "status_changed"
+	EventDocumentRejected  = "document_rejected"
+	EventAccountActivation = "account_activation"
+	EventClientInvitation  = "client_invitation"
+	EventInviteAccepted    = "invitation_accepted"
+)
+
+// Estados de um email na caixa de saída
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+// DefaultLanguage é o idioma usado quando não há template no idioma pedido
+const DefaultLanguage = "pt"
+
+// EmailTemplate guarda o assunto e o corpo (text/template) de um evento num idioma
+type EmailTemplate struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Event     string    `json:"event" gorm:"not null;uniqueIndex:idx_template_event_language"`
+	Language  string    `json:"language" gorm:"not null;uniqueIndex:idx_template_event_language"`
+	Subject   string    `json:"subject" gorm:"not null"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	UpdatedBy *uint     `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// OutboxEmail é um email por enviar (ou já enviado) pelo dispatcher
+type OutboxEmail struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Event         string     `json:"event" gorm:"not null;index"`
+	Language      string     `json:"language"`
+	ToAddress     string     `json:"to_address" gorm:"not null"`
+	Subject       string     `json:"subject" gorm:"not null"`
+	Body          string     `json:"body" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"default:'pending';index"` // pending, sending, sent, failed
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	SentAt        *time.Time `json:"sent_at"`
+	UserID        *uint      `json:"user_id,omitempty" gorm:"index"`
+	RequestID     *uint      `json:"request_id,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// EmailTemplateDTO para editar um template
+type EmailTemplateDTO struct {
+	Subject string `json:"subject" binding:"required" example:"O seu pedido foi aprovado"`
+	Body    string `json:"body" binding:"required" example:"Olá {{.Name}}, o seu pedido foi aprovado."`
+}
+
+// Notification é uma notificação da caixa de entrada da aplicação
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Event     string     `json:"event" gorm:"not null"`
+	Title     string     `json:"title" gorm:"not null"`
+	Message   string     `json:"message" gorm:"type:text"`
+	Link      string     `json:"link,omitempty"`
+	ReadAt    *time.Time `json:"read_at" gorm:"index"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// NotificationListDTO é a resposta da listagem da caixa de entrada
+type NotificationListDTO struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int64          `json:"total"`
+	Unread        int64          `json:"unread"`
+}
+
+// NotificationBulkDTO para marcar como lidas ou eliminar várias notificações
+type NotificationBulkDTO struct {
+	IDs []uint `json:"ids" example:"1,2,3"`
+	All bool   `json:"all" example:"false"` // Aplica a todas as notificações do utilizador
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de obrigação fiscal e contributiva
+const (
+	ObligationVATMonthly     = "iva_mensal"       // Declaração periódica de IVA (regime mensal)
+	ObligationVATQuarterly   = "iva_trimestral"   // Declaração periódica de IVA (regime trimestral)
+	ObligationSAFT           = "saft_mensal"      // Comunicação do SAF-T de faturação
+	ObligationDMR            = "dmr"              // Declaração Mensal de Remunerações
+	ObligationSocialSecurity = "seguranca_social" // Pagamento de contribuições à Segurança Social
+	ObligationModelo22       = "modelo_22"        // Declaração de IRC
+	ObligationIES            = "ies"              // Informação Empresarial Simplificada
+)
+
+// Estados de uma obrigação
+const (
+	ObligationStatusPending   = "pending"
+	ObligationStatusSubmitted = "submitted"
+	ObligationStatusWaived    = "waived"
+)
+
+// TaxObligation é uma obrigação de uma empresa num período, com o respetivo prazo
+type TaxObligation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	CompanyID   uint       `json:"company_id" gorm:"not null;uniqueIndex:idx_obligation_company_type_period"`
+	Type        string     `json:"type" gorm:"not null;uniqueIndex:idx_obligation_company_type_period"`
+	Period      string     `json:"period" gorm:"not null;uniqueIndex:idx_obligation_company_type_period"` // AAAA-MM, AAAA-T1 ou AAAA
+	DueDate     time.Time  `json:"due_date" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"default:'pending';index"` // pending, submitted, waived
+	Reference   string     `json:"reference"`                             // Comprovativo/identificação da submissão
+	Notes       string     `json:"notes"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	SubmittedBy *uint      `json:"submitted_by"`
+	Sequence    int        `json:"sequence" gorm:"default:0"` // Incrementado sempre que o prazo muda (feeds ICS)
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Calculado (não guardado)
+	Overdue bool `json:"overdue" gorm:"-"`
+
+	// Relacionamentos
+	Company *Company `json:"company,omitempty" gorm:"foreignKey:CompanyID"`
+}
+
+// UpdateObligationDTO para registar a submissão de uma obrigação
+type UpdateObligationDTO struct {
+	Status    string `json:"status" binding:"required,oneof=pending submitted waived" example:"submitted"`
+	Reference string `json:"reference" example:"Comprovativo 2024-0001234"`
+	Notes     string `json:"notes" example:"Submetida com reembolso"`
+}
+
+// CalendarToken dá acesso sem login ao feed ICS de prazos de um utilizador
+type CalendarToken struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	Token        string     `json:"-" gorm:"not null;uniqueIndex"`
+	LastAccessAt *time.Time `json:"last_access_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// CalendarFeedDTO devolve o endereço do feed ICS
+type CalendarFeedDTO struct {
+	URL          string     `json:"url" example:"http://localhost:8080/api/calendar/abc123/deadlines.ics"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastAccessAt *time.Time `json:"last_access_at"`
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"time"
+)
+
+// Permissões com nome. Cada perfil (Role) é um conjunto destas permissões e um admin
+// pode ainda conceder permissões avulsas a um utilizador (UserPermission).
+const (
+	PermissionBackofficeAccess  = "backoffice.access"  // Entrar na área de gestão (/api/admin)
+	PermissionPortalAccess      = "portal.access"      // Entrar na área de cliente (/api/client)
+	PermissionClientsRead       = "clients.read"       // Ver clientes, empresas e a visão completa
+	PermissionClientsAll        = "clients.all"        // Ver e gerir todos os clientes, não só os da própria carteira
+	PermissionClientsCreate     = "clients.create"     // Criar clientes diretamente, por convite ou por importação
+	PermissionClientsUpdate     = "clients.update"     // Editar dados de clientes e empresas
+	PermissionClientsDelete     = "clients.delete"     // Eliminar clientes
+	PermissionCredentialsReveal = "credentials.reveal" // Ver IBAN, cartão de cidadão e email oficial sem máscara
+	PermissionRequestsApprove   = "requests.approve"   // Aprovar, rejeitar ou pedir informação em solicitações
+	PermissionRequestsAssign    = "requests.assign"    // Atribuir solicitações e gerir as regras de atribuição
+	PermissionPortfoliosManage  = "portfolios.manage"  // Atribuir empresas às carteiras dos contabilistas
+	PermissionUsersManage       = "users.manage"       // Criar equipa, alterar estado, perfil e permissões de utilizadores
+	PermissionRolesManage       = "roles.manage"       // Gerir perfis e as respetivas permissões
+	PermissionSettingsManage    = "settings.manage"    // Templates de email e caixa de saída
+	PermissionPeriodsReopen     = "periods.reopen"     // Reabrir períodos contabilísticos fechados
+	PermissionAuditRead         = "audit.read"         // Consultar o registo de auditoria
+	PermissionExportSensitive   = "export_sensitive"   // Exportar colunas sensíveis (IBAN, cartão de cidadão, credenciais) sem máscara
+)
+
+// AllPermissions é o catálogo de permissões, pela ordem em que são apresentadas
+var AllPermissions = []PermissionDTO{
+	{Name: PermissionBackofficeAccess, Description: "Entrar na área de gestão"},
+	{Name: PermissionPortalAccess, Description: "Entrar na área de cliente"},
+	{Name: PermissionClientsRead, Description: "Ver clientes e empresas"},
+	{Name: PermissionClientsAll, Description: "Aceder a todos os clientes, não só aos da própria carteira"},
+	{Name: PermissionClientsCreate, Description: "Criar clientes (direto, convite ou importação)"},
+	{Name: PermissionClientsUpdate, Description: "Editar clientes e empresas"},
+	{Name: PermissionClientsDelete, Description: "Eliminar clientes"},
+	{Name: PermissionCredentialsReveal, Description: "Ver IBAN, cartão de cidadão e email oficial sem máscara"},
+	{Name: PermissionRequestsApprove, Description: "Aprovar e rejeitar solicitações"},
+	{Name: PermissionRequestsAssign, Description: "Atribuir solicitações e gerir regras de atribuição"},
+	{Name: PermissionPortfoliosManage, Description: "Gerir as carteiras de clientes dos contabilistas"},
+	{Name: PermissionUsersManage, Description: "Gerir utilizadores da equipa"},
+	{Name: PermissionRolesManage, Description: "Gerir perfis e permissões"},
+	{Name: PermissionSettingsManage, Description: "Gerir templates de email e caixa de saída"},
+	{Name: PermissionPeriodsReopen, Description: "Reabrir períodos contabilísticos"},
+	{Name: PermissionAuditRead, Description: "Consultar o registo de auditoria"},
+	{Name: PermissionExportSensitive, Description: "Exportar colunas sensíveis sem máscara"},
+}
+
+// IsValidPermission indica se a permissão existe no catálogo
+func IsValidPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p.Name == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Perfis base, criados no arranque e que não podem ser eliminados nem renomeados
+const (
+	RoleAdmin      = "admin"
+	RoleAccountant = "accountant"
+	RoleClient     = "client"
+)
+
+// DefaultRoles são os perfis criados no arranque quando ainda não existem.
+// Só os perfis base ficam marcados como System; o "junior" é um exemplo editável.
+var DefaultRoles = []struct {
+	Name        string
+	Description string
+	System      bool
+	Permissions []string
+}{
+	{RoleAdmin, "Administrador", true, allPermissionNames()},
+	{RoleAccountant, "Contabilista", true, []string{
+		PermissionBackofficeAccess, PermissionClientsRead, PermissionClientsCreate, PermissionClientsUpdate,
+		PermissionClientsDelete, PermissionCredentialsReveal, PermissionRequestsApprove,
+	}},
+	{RoleClient, "Cliente", true, []string{PermissionPortalAccess}},
+	{"junior", "Contabilista júnior (consulta de clientes, sem eliminar nem ver IBAN)", false, []string{
+		PermissionBackofficeAccess, PermissionClientsRead,
+	}},
+}
+
+// PermissionRollout indica os perfis base que recebem uma permissão acrescentada ao catálogo
+// depois de os perfis já existirem. Cada entrada é aplicada uma única vez (ver AppliedPermissionRollout).
+var PermissionRollout = map[string][]string{
+	PermissionClientsAll:       {RoleAdmin},
+	PermissionPortfoliosManage: {RoleAdmin},
+}
+
+// allPermissionNames devolve todo o catálogo exceto export_sensitive, que continua a ser
+// concedida explicitamente a cada utilizador
+func allPermissionNames() []string {
+	names := make([]string, 0, len(AllPermissions))
+	for _, p := range AllPermissions {
+		if p.Name != PermissionExportSensitive {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// Role é um perfil: um conjunto de permissões editável guardado na base de dados.
+// O campo User.Role guarda o nome do perfil.
+type Role struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	Name        string           `json:"name" gorm:"uniqueIndex;not null" example:"junior"`
+	Description string           `json:"description" example:"Contabilista júnior"`
+	System      bool             `json:"system"` // Perfil base (admin, accountant, client)
+	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	Permissions []RolePermission `json:"-" gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE"`
+
+	// Calculados na leitura (não guardados)
+	PermissionNames []string `json:"permissions" gorm:"-"`
+	UserCount       int64    `json:"user_count" gorm:"-"`
+}
+
+// RolePermission associa uma permissão a um perfil
+type RolePermission struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	RoleID     uint   `json:"role_id" gorm:"not null;uniqueIndex:idx_role_permission"`
+	Permission string `json:"permission" gorm:"not null;uniqueIndex:idx_role_permission"`
+}
+
+// AppliedPermissionRollout regista que a entrada de PermissionRollout de uma permissão já foi
+// aplicada, para que não volte a ser concedida se um admin a retirar depois dos perfis
+type AppliedPermissionRollout struct {
+	Permission string    `json:"permission" gorm:"primaryKey"`
+	AppliedAt  time.Time `json:"applied_at" gorm:"autoCreateTime"`
+}
+
+// UserPermission é uma permissão concedida a um utilizador por um admin
+type UserPermission struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_permission"`
+	Permission string    `json:"permission" gorm:"not null;uniqueIndex:idx_user_permission"`
+	GrantedBy  uint      `json:"granted_by"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// PermissionDTO descreve uma permissão do catálogo
+type PermissionDTO struct {
+	Name        string `json:"name" example:"clients.read"`
+	Description string `json:"description" example:"Ver clientes e empresas"`
+}
+
+// GrantPermissionDTO para conceder uma permissão a um utilizador
+type GrantPermissionDTO struct {
+	Permission string `json:"permission" binding:"required" example:"export_sensitive"`
+}
+
+// RoleDTO para criar ou alterar um perfil
+type RoleDTO struct {
+	Name        string   `json:"name" example:"junior"`
+	Description string   `json:"description" example:"Contabilista júnior"`
+	Permissions []string `json:"permissions" binding:"required" example:"backoffice.access,clients.read"`
+}
+
+// UpdateUserRoleDTO para mudar o perfil de um utilizador
+type UpdateUserRoleDTO struct {
+	Role string `json:"role" binding:"required" example:"junior"`
+}
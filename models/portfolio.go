@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// CompanyAccountant atribui uma empresa à carteira de um contabilista.
+// Uma empresa pode ter vários contabilistas; quem não tem clients.all só vê os clientes da sua carteira.
+type CompanyAccountant struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CompanyID    uint      `json:"company_id" gorm:"not null;uniqueIndex:idx_company_accountant"`
+	AccountantID uint      `json:"accountant_id" gorm:"not null;uniqueIndex:idx_company_accountant;index"`
+	AssignedBy   uint      `json:"assigned_by"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	Company    *Company `json:"company,omitempty" gorm:"foreignKey:CompanyID"`
+	Accountant *User    `json:"accountant,omitempty" gorm:"foreignKey:AccountantID"`
+}
+
+// PortfolioSummaryDTO resume a carteira de um membro da equipa
+type PortfolioSummaryDTO struct {
+	AccountantID uint   `json:"accountant_id" example:"2"`
+	Username     string `json:"username" example:"contabilista"`
+	Name         string `json:"name" example:"Contabilista"`
+	Role         string `json:"role" example:"accountant"`
+	Status       string `json:"status" example:"approved"`
+	Companies    int64  `json:"companies" example:"12"`
+}
+
+// SetCompanyAccountantsDTO substitui os contabilistas de uma empresa
+type SetCompanyAccountantsDTO struct {
+	AccountantIDs []uint `json:"accountant_ids" binding:"required" example:"2,3"`
+}
+
+// ReassignPortfolioDTO passa empresas da carteira de um contabilista para outro.
+// Sem company_ids passa a carteira inteira (por exemplo, quando alguém sai do gabinete).
+type ReassignPortfolioDTO struct {
+	FromAccountantID uint   `json:"from_accountant_id" binding:"required" example:"2"`
+	ToAccountantID   uint   `json:"to_accountant_id" binding:"required" example:"3"`
+	CompanyIDs       []uint `json:"company_ids" example:"10,11"`
+}
+
+// ReassignPortfolioResultDTO resume uma reatribuição em massa
+type ReassignPortfolioResultDTO struct {
+	Moved           int `json:"moved" example:"12"`           // Empresas que passaram para o novo contabilista
+	AlreadyAssigned int `json:"already_assigned" example:"1"` // Empresas que o novo contabilista já tinha
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+)
+
+// Estado da correspondência entre uma fatura do e-Fatura e os documentos enviados pelo cliente
+const (
+	PurchaseInvoiceMatched   = "matched"
+	PurchaseInvoiceUnmatched = "unmatched"
+)
+
+// Categoria de uma fatura de compra na declaração periódica de IVA
+const (
+	PurchaseExpenseAssets    = "assets"    // Imobilizado (campo 20)
+	PurchaseExpenseInventory = "inventory" // Existências (campos 21 a 23)
+	PurchaseExpenseOther     = "other"     // Outros bens e serviços (campo 24)
+)
+
+// PurchaseInvoice é uma fatura de compra comunicada à AT, importada do CSV do e-Fatura
+type PurchaseInvoice struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	CompanyID         uint       `json:"company_id" gorm:"not null;index;uniqueIndex:idx_purchase_invoice_number"`
+	ImportID          uint       `json:"import_id" gorm:"not null;index"`
+	SupplierNIF       string     `json:"supplier_nif" gorm:"not null;uniqueIndex:idx_purchase_invoice_number"`
+	SupplierName      string     `json:"supplier_name"`
+	DocumentNo        string     `json:"document_no" gorm:"not null;uniqueIndex:idx_purchase_invoice_number"`
+	ATCUD             string     `json:"atcud" gorm:"index"`
+	DocumentType      string     `json:"document_type"`
+	IssueDate         time.Time  `json:"issue_date" gorm:"type:date"`
+	FiscalPeriod      string     `json:"fiscal_period" gorm:"index"` // AAAA-MM
+	NetAmount         float64    `json:"net_amount"`
+	VATAmount         float64    `json:"vat_amount"`
+	TotalAmount       float64    `json:"total_amount"`
+	PaidAmount        float64    `json:"paid_amount"` // Soma dos movimentos bancários conciliados
+	Situation         string     `json:"situation"`   // Situação no e-Fatura (registado, pendente, ...)
+	Sector            string     `json:"sector"`
+	ExpenseCategory   string     `json:"expense_category" gorm:"default:'other'"`       // assets, inventory, other
+	DeductiblePercent *float64   `json:"deductible_percent"`                            // nil: dedutibilidade automática pelo setor
+	MatchStatus       string     `json:"match_status" gorm:"default:'unmatched';index"` // matched, unmatched
+	DocumentID        *uint      `json:"document_id"`
+	MatchedAt         *time.Time `json:"matched_at"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relacionamentos
+	Document *Document `json:"document,omitempty" gorm:"foreignKey:DocumentID"`
+}
+
+// EFaturaImport regista uma importação do CSV de faturas de compra do e-Fatura
+type EFaturaImport struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CompanyID  uint      `json:"company_id" gorm:"not null;index"`
+	UploadedBy uint      `json:"uploaded_by" gorm:"not null"`
+	FileName   string    `json:"file_name"`
+	TotalRows  int       `json:"total_rows"`
+	Created    int       `json:"created"`
+	Duplicates int       `json:"duplicates"`
+	Invalid    int       `json:"invalid"`
+	Matched    int       `json:"matched"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// EFaturaRowErrorDTO é uma linha do CSV que não foi importada
+type EFaturaRowErrorDTO struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// EFaturaImportResultDTO é o resultado de uma importação
+type EFaturaImportResultDTO struct {
+	Import EFaturaImport        `json:"import"`
+	Errors []EFaturaRowErrorDTO `json:"errors"`
+}
+
+// MatchPurchaseInvoiceDTO associa manualmente uma fatura a um documento (null para desassociar)
+type MatchPurchaseInvoiceDTO struct {
+	DocumentID *uint `json:"document_id" example:"12"`
+}
+
+// ClassifyPurchaseInvoiceDTO define a categoria e a percentagem de IVA dedutível de uma fatura
+type ClassifyPurchaseInvoiceDTO struct {
+	ExpenseCategory   string   `json:"expense_category" binding:"required,oneof=assets inventory other" example:"inventory"`
+	DeductiblePercent *float64 `json:"deductible_percent" binding:"omitempty,min=0,max=100" example:"50"` // null repõe a dedutibilidade automática
+}
+
+// DiscrepancyPeriodDTO agrupa as faturas em falta de um período
+type DiscrepancyPeriodDTO struct {
+	FiscalPeriod string            `json:"fiscal_period"`
+	Count        int               `json:"count"`
+	TotalAmount  float64           `json:"total_amount"`
+	VATAmount    float64           `json:"vat_amount"`
+	Invoices     []PurchaseInvoice `json:"invoices"`
+}
+
+// DiscrepancyReportDTO lista as faturas conhecidas da AT que o cliente não enviou
+type DiscrepancyReportDTO struct {
+	CompanyID   uint                   `json:"company_id"`
+	From        string                 `json:"from"`
+	To          string                 `json:"to"`
+	Known       int                    `json:"known"`
+	Matched     int                    `json:"matched"`
+	Missing     int                    `json:"missing"`
+	TotalAmount float64                `json:"total_amount"`
+	VATAmount   float64                `json:"vat_amount"`
+	Periods     []DiscrepancyPeriodDTO `json:"periods"`
+}
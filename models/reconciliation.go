@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// Estados de uma proposta de conciliação
+const (
+	ReconciliationProposed = "proposed"
+	ReconciliationAccepted = "accepted"
+	ReconciliationRejected = "rejected"
+)
+
+// ReconciliationMatch associa (parte de) um movimento bancário a uma fatura de compra
+type ReconciliationMatch struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	CompanyID         uint       `json:"company_id" gorm:"not null;index"`
+	TransactionID     uint       `json:"transaction_id" gorm:"not null;uniqueIndex:idx_reconciliation_pair"`
+	PurchaseInvoiceID uint       `json:"purchase_invoice_id" gorm:"not null;uniqueIndex:idx_reconciliation_pair"`
+	Amount            float64    `json:"amount"`                      // Valor conciliado (positivo)
+	Confidence        float64    `json:"confidence"`                  // 0 a 1
+	Reasons           string     `json:"reasons"`                     // Critérios que levaram à proposta, separados por vírgula
+	Status            string     `json:"status" gorm:"index"`         // proposed, accepted, rejected
+	Manual            bool       `json:"manual" gorm:"default:false"` // Criada por divisão manual
+	DecidedBy         *uint      `json:"decided_by"`
+	DecidedAt         *time.Time `json:"decided_at"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	Transaction     *BankTransaction `json:"transaction,omitempty" gorm:"foreignKey:TransactionID"`
+	PurchaseInvoice *PurchaseInvoice `json:"purchase_invoice,omitempty" gorm:"foreignKey:PurchaseInvoiceID"`
+}
+
+// SupplierMatchRule é uma regra aprendida com as conciliações aceites de um fornecedor
+type SupplierMatchRule struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CompanyID      uint      `json:"company_id" gorm:"not null;uniqueIndex:idx_supplier_rule"`
+	SupplierNIF    string    `json:"supplier_nif" gorm:"not null;uniqueIndex:idx_supplier_rule"`
+	SupplierName   string    `json:"supplier_name"`
+	IBAN           string    `json:"iban"`    // IBAN de onde/para onde o fornecedor costuma ser pago
+	Keyword        string    `json:"keyword"` // Texto que aparece na descrição dos movimentos
+	AcceptedCount  int       `json:"accepted_count"`
+	LastAcceptedAt time.Time `json:"last_accepted_at"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ReconciliationRunDTO é o resultado de uma execução do motor de conciliação
+type ReconciliationRunDTO struct {
+	Transactions int `json:"transactions"` // Movimentos analisados
+	Proposals    int `json:"proposals"`    // Propostas criadas
+}
+
+// SplitAllocationDTO é a parte de um movimento atribuída a uma fatura
+type SplitAllocationDTO struct {
+	PurchaseInvoiceID uint    `json:"purchase_invoice_id" binding:"required" example:"7"`
+	Amount            float64 `json:"amount" binding:"required,gt=0" example:"123.45"`
+}
+
+// SplitTransactionDTO divide um movimento por várias faturas
+type SplitTransactionDTO struct {
+	TransactionID uint                 `json:"transaction_id" binding:"required" example:"42"`
+	Allocations   []SplitAllocationDTO `json:"allocations" binding:"required,min=1,dive"`
+}
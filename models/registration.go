@@ -9,13 +9,20 @@ import (
 // RegistrationRequest representa o histórico de solicitações com dados completos
 type RegistrationRequest struct {
 	ID                uint      `json:"id" gorm:"primaryKey"`
-	RequestType       string    `json:"request_type" gorm:"default:'new_client'"` // new_client, existing_client
+	RequestType       string    `json:"request_type" gorm:"default:'new_client'"` // new_client, existing_client, invitation
 	Status            string    `json:"status" gorm:"default:'pending'"`
 	SubmittedAt       time.Time `json:"submitted_at" gorm:"autoCreateTime"`
 	ReviewedAt        *time.Time `json:"reviewed_at"`
 	ReviewedBy        *uint     `json:"reviewed_by"`
 	ReviewNotes       string    `json:"review_notes"`
 	ApprovalToken     string    `json:"approval_token" gorm:"unique"`
+	AssignedTo        *uint      `json:"assigned_to" gorm:"index"` // Contabilista responsável pela revisão
+	AssignedAt        *time.Time `json:"assigned_at"`
+	
+	// Verificação do email
+	EmailVerifiedAt         *time.Time `json:"email_verified_at"`
+	EmailVerificationToken  string     `json:"-" gorm:"index"`
+	EmailVerificationSentAt *time.Time `json:"email_verification_sent_at"`
 	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	
@@ -103,6 +110,10 @@ type RegistrationRequest struct {
 	User           *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Company        *Company `json:"company,omitempty" gorm:"foreignKey:CompanyID"`
 	ReviewedByUser *User `json:"reviewed_by_user,omitempty" gorm:"foreignKey:ReviewedBy"`
+	AssignedToUser *User `json:"assigned_to_user,omitempty" gorm:"foreignKey:AssignedTo"`
+	
+	// Relatório de prováveis duplicados (calculado nos detalhes, não é guardado)
+	DuplicateReport *DuplicateReportDTO `json:"duplicate_report,omitempty" gorm:"-"`
 }
 
 // RegistrationRequestDTO para registo completo
@@ -181,10 +192,15 @@ type RegistrationRequestDTO struct {
 	RegistrationDate  *string         `json:"registration_date,omitempty" example:"2024-01-01"`
 }
 
+// ResendVerificationDTO para reenviar o email de verificação
+type ResendVerificationDTO struct {
+	Email string `json:"email" binding:"required,email" example:"joao@exemplo.com"`
+}
+
 // ApprovalRequestDTO para aprovação de solicitações
 type ApprovalRequestDTO struct {
 	RequestID    uint   `json:"request_id" binding:"required" example:"1"`
-	Status       string `json:"status" binding:"required,oneof=approved rejected" example:"approved"`
+	Status       string `json:"status" binding:"required,oneof=approved rejected needs_info" example:"approved"` // needs_info mantém a solicitação pendente e pede dados ao cliente
 	ReviewNotes  string `json:"review_notes" example:"Documentação em ordem"`
 }
 
@@ -212,6 +228,15 @@ type PendingRequestResponseDTO struct {
 	FiscalPostalCode string `json:"fiscal_postal_code" example:"1000-001"`
 	FiscalCity       string `json:"fiscal_city" example:"Lisboa"`
 	
+	// Atribuição na fila de revisão
+	AssignedTo     *uint      `json:"assigned_to,omitempty" example:"2"`
+	AssignedToName string     `json:"assigned_to_name,omitempty" example:"Contabilista"`
+	AssignedAt     *time.Time `json:"assigned_at,omitempty"`
+	
+	// Verificação do email
+	EmailVerified   bool       `json:"email_verified" example:"true"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	
 	// Dados completos se necessário (para visualização detalhada)
 	RequestData RegistrationRequestDTO `json:"request_data,omitempty"`
 }
@@ -239,7 +264,7 @@ type ClientsOverviewDTO struct {
 	// Clientes pendentes
 	PendingClients []PendingRequestResponseDTO `json:"pending_clients"`
 	
-	// Clientes aprovados (resumo)
+	// Clientes aprovados (resumo, uma linha por empresa de cada cliente)
 	ApprovedClients []struct {
 		ID          uint   `json:"id" example:"1"`
 		Username    string `json:"username" example:"joao.silva"`
@@ -248,8 +273,10 @@ type ClientsOverviewDTO struct {
 		Phone       string `json:"phone" example:"912345678"`
 		NIF         string `json:"nif" example:"123456789"`
 		Status      string `json:"status" example:"approved"`
+		CompanyID   uint   `json:"company_id,omitempty" example:"1"`
 		CompanyName string `json:"company_name" example:"Silva & Associados Lda"`
 		NIPC        string `json:"nipc" example:"123456789"`
+		CompanyRole string `json:"company_role,omitempty" example:"owner"`
 		CreatedAt   string `json:"created_at" example:"2024-01-01T00:00:00Z"`
 	} `json:"approved_clients"`
 	
@@ -388,6 +415,7 @@ type CompleteUserOverviewDTO struct {
 	
 	// === DADOS DA EMPRESA (prioridade: Company > RegistrationRequest) ===
 	CompanyID       *uint      `json:"company_id,omitempty"`
+	CompanyRole     *string    `json:"company_role,omitempty" example:"owner"` // Papel do utilizador nesta empresa (uma linha por empresa)
 	CompanyName     *string    `json:"company_name" example:"Silva & Associados Lda"`
 	TradeName       *string    `json:"trade_name" example:"Silva Consultoria"`
 	NIPC            *string    `json:"nipc" example:"123456789"`
@@ -439,6 +467,12 @@ type CompleteUserOverviewDTO struct {
 	ApprovalToken   *string    `json:"approval_token,omitempty"`
 	ReviewedByName  *string    `json:"reviewed_by_name,omitempty"`
 	
+	// === CONVITE (se o cliente foi convidado pelo contabilista) ===
+	InvitationID        *uint      `json:"invitation_id,omitempty"`
+	InvitationStatus    *string    `json:"invitation_status,omitempty" example:"pending"` // pending, accepted, revoked, expired
+	InvitationExpiresAt *time.Time `json:"invitation_expires_at,omitempty"`
+	InvitedBy           *uint      `json:"invited_by,omitempty"`
+	
 	// === TIMESTAMPS ===
 	UserCreatedAt    *time.Time `json:"user_created_at,omitempty"`    // De users
 	UserUpdatedAt    *time.Time `json:"user_updated_at,omitempty"`    // De users
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// Estratégias de atribuição automática de solicitações
+const (
+	AssignmentStrategyRoundRobin = "round_robin"
+	AssignmentStrategyDistrict   = "district"
+	AssignmentStrategyLegalForm  = "legal_form"
+)
+
+// Ações registadas no histórico de atribuições
+const (
+	AssignmentActionAuto     = "auto_assign"
+	AssignmentActionClaim    = "claim"
+	AssignmentActionAssign   = "assign"
+	AssignmentActionReassign = "reassign"
+	AssignmentActionRelease  = "release"
+)
+
+// Estados do SLA de revisão
+const (
+	SLAStatusOnTrack  = "on_track"
+	SLAStatusWarning  = "warning"
+	SLAStatusBreached = "breached"
+)
+
+// AssignmentRule define como as novas solicitações são distribuídas pelos contabilistas
+type AssignmentRule struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Strategy     string    `json:"strategy" gorm:"not null"`   // round_robin, district, legal_form
+	MatchValue   string    `json:"match_value"`                // Distrito ou forma jurídica (vazio para round_robin)
+	AccountantID *uint     `json:"accountant_id" gorm:"index"` // NULL para round_robin
+	Priority     int       `json:"priority" gorm:"default:0"`  // Regras com maior prioridade são avaliadas primeiro
+	Active       bool      `json:"active" gorm:"default:true"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relacionamentos
+	Accountant *User `json:"accountant,omitempty" gorm:"foreignKey:AccountantID"`
+}
+
+// RequestAssignmentHistory regista cada atribuição, reclamação ou libertação de uma solicitação
+type RequestAssignmentHistory struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	RequestID   uint      `json:"request_id" gorm:"not null;index"`
+	Action      string    `json:"action" gorm:"not null"` // auto_assign, claim, assign, reassign, release
+	FromUserID  *uint     `json:"from_user_id"`
+	ToUserID    *uint     `json:"to_user_id"`
+	PerformedBy *uint     `json:"performed_by"` // NULL quando a atribuição é automática
+	Strategy    string    `json:"strategy,omitempty"`
+	Notes       string    `json:"notes"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relacionamentos
+	FromUser        *User `json:"from_user,omitempty" gorm:"foreignKey:FromUserID"`
+	ToUser          *User `json:"to_user,omitempty" gorm:"foreignKey:ToUserID"`
+	PerformedByUser *User `json:"performed_by_user,omitempty" gorm:"foreignKey:PerformedBy"`
+}
+
+// AssignmentRuleDTO para criar regras de atribuição
+type AssignmentRuleDTO struct {
+	Strategy     string `json:"strategy" binding:"required,oneof=round_robin district legal_form" example:"district"`
+	MatchValue   string `json:"match_value" example:"Lisboa"`
+	AccountantID *uint  `json:"accountant_id" example:"2"`
+	Priority     int    `json:"priority" example:"10"`
+}
+
+// AssignRequestDTO para atribuir uma solicitação a um contabilista
+type AssignRequestDTO struct {
+	AccountantID uint   `json:"accountant_id" binding:"required" example:"2"`
+	Notes        string `json:"notes" example:"Cliente da zona norte"`
+}
+
+// ReleaseRequestDTO para devolver uma solicitação à fila
+type ReleaseRequestDTO struct {
+	Notes string `json:"notes" example:"Sem disponibilidade esta semana"`
+}
+
+// QueueItemDTO representa uma solicitação na fila de revisão com o estado do SLA
+type QueueItemDTO struct {
+	PendingRequestResponseDTO
+	HoursWaiting float64   `json:"hours_waiting" example:"12.5"`
+	SLAHours     int       `json:"sla_hours" example:"48"`
+	SLADeadline  time.Time `json:"sla_deadline"`
+	SLAStatus    string    `json:"sla_status" example:"on_track"` // on_track, warning, breached
+}
+
+// MyQueueDTO para a resposta de GET /admin/my-queue
+type MyQueueDTO struct {
+	Assigned   []QueueItemDTO `json:"assigned"`
+	Unassigned []QueueItemDTO `json:"unassigned"`
+	Stats      struct {
+		TotalAssigned   int `json:"total_assigned"`
+		TotalUnassigned int `json:"total_unassigned"`
+		Warning         int `json:"warning"`
+		Breached        int `json:"breached"`
+	} `json:"stats"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+)
+
+// Estados de uma importação SAF-T
+const (
+	SAFTImportProcessing = "processing"
+	SAFTImportCompleted  = "completed"
+	SAFTImportWithErrors = "completed_with_errors"
+	SAFTImportFailed     = "failed"
+)
+
+// Gravidade de um erro de importação
+const (
+	SAFTSeverityError   = "error"
+	SAFTSeverityWarning = "warning"
+)
+
+// SAFTImport é a importação de um ficheiro SAF-T (PT) de faturação de uma empresa
+type SAFTImport struct {
+	ID                    uint       `json:"id" gorm:"primaryKey"`
+	CompanyID             uint       `json:"company_id" gorm:"not null;index"`
+	UploadedBy            uint       `json:"uploaded_by" gorm:"not null"`
+	FileName              string     `json:"file_name"`
+	FileSize              int64      `json:"file_size"`
+	SHA256                string     `json:"sha256" gorm:"size:64;index"`
+	StorageKey            string     `json:"-"`
+	Status                string     `json:"status" gorm:"default:'processing';index"` // processing, completed, completed_with_errors, failed
+	AuditFileVersion      string     `json:"audit_file_version"`
+	TaxRegistrationNumber string     `json:"tax_registration_number"`
+	ProductID             string     `json:"product_id"` // Software de faturação que gerou o ficheiro
+	FiscalYear            string     `json:"fiscal_year"`
+	StartDate             string     `json:"start_date"`
+	EndDate               string     `json:"end_date"`
+	DocumentCount         int        `json:"document_count"`
+	CancelledCount        int        `json:"cancelled_count"`
+	CustomerCount         int        `json:"customer_count"`
+	NetTotal              float64    `json:"net_total"`
+	TaxTotal              float64    `json:"tax_total"`
+	GrossTotal            float64    `json:"gross_total"`
+	ErrorCount            int        `json:"error_count"`
+	WarningCount          int        `json:"warning_count"`
+	FailureReason         string     `json:"failure_reason,omitempty"`
+	CreatedAt             time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	CompletedAt           *time.Time `json:"completed_at"`
+}
+
+// SAFTImportError é um problema encontrado numa linha do ficheiro
+type SAFTImportError struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	ImportID   uint   `json:"import_id" gorm:"not null;index"`
+	Line       int    `json:"line"`
+	DocumentNo string `json:"document_no"`
+	Severity   string `json:"severity"` // error, warning
+	Message    string `json:"message"`
+}
+
+// SAFTSalesSummary agrega as vendas de uma importação por período, taxa de IVA e cliente
+type SAFTSalesSummary struct {
+	ID            uint    `json:"id" gorm:"primaryKey"`
+	ImportID      uint    `json:"import_id" gorm:"not null;index"`
+	CompanyID     uint    `json:"company_id" gorm:"not null;index"`
+	Period        string  `json:"period" gorm:"index"` // AAAA-MM
+	TaxRate       float64 `json:"tax_rate"`
+	TaxCode       string  `json:"tax_code"`
+	TaxRegion     string  `json:"tax_region"` // PT, PT-AC, PT-MA
+	CustomerTaxID string  `json:"customer_tax_id"`
+	CustomerName  string  `json:"customer_name"`
+	Documents     int     `json:"documents"`
+	NetAmount     float64 `json:"net_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+// SAFTImportDetailDTO junta a importação com os resumos e os erros
+type SAFTImportDetailDTO struct {
+	Import    SAFTImport         `json:"import"`
+	Summaries []SAFTSalesSummary `json:"summaries"`
+	Errors    []SAFTImportError  `json:"errors"`
+}
+
+// SAFTSummaryRowDTO é uma linha do resumo de vendas agrupado (por período, taxa ou cliente)
+type SAFTSummaryRowDTO struct {
+	Key       string  `json:"key"`
+	Documents int     `json:"documents"`
+	NetAmount float64 `json:"net_amount"`
+	TaxAmount float64 `json:"tax_amount"`
+}
@@ -43,9 +43,22 @@ type User struct {
 	PreferredFormat       string `json:"preferred_format" gorm:"default:'digital'"`
 	ReportFrequency       string `json:"report_frequency" gorm:"default:'mensal'"`
 	PreferredContactHours string `json:"preferred_contact_hours"`
+	Language              string `json:"language" gorm:"default:'pt'" example:"pt"` // Idioma das notificações
+
+	// Ativação de contas criadas por importação (o cliente define a password pelo link enviado)
+	ActivationToken  string     `json:"-" gorm:"index"`
+	ActivationSentAt *time.Time `json:"activation_sent_at"`
+	ActivatedAt      *time.Time `json:"activated_at"`
+
+	// Calculado na leitura do perfil (não guardado)
+	UnreadNotifications int64 `json:"unread_notifications" gorm:"-"`
 	
+	// Empresas, carregadas pelos serviços através de user_companies: Company é a empresa principal
+	// e Companies todas as empresas a que o utilizador está ligado, com o papel em cada uma
+	Company   *Company           `json:"company,omitempty" gorm:"-"`
+	Companies []ClientCompanyDTO `json:"companies,omitempty" gorm:"-"`
+
 	// Relacionamentos
-	Company             *Company              `json:"company,omitempty" gorm:"foreignKey:UserID"`
 	RegistrationRequest *RegistrationRequest  `json:"registration_request,omitempty" gorm:"foreignKey:UserID"`
 	ReviewedRequests    []RegistrationRequest `json:"reviewed_requests,omitempty" gorm:"foreignKey:ReviewedBy"`
 }
@@ -74,16 +87,16 @@ type LoginRequest struct {
     Password string `json:"password" binding:"required,min=6" example:"123456"`
 }
 
-// Dados para registo simples (não usado no novo fluxo)
-type RegisterRequest struct {
+// CreateUserDTO para a equipa criar um utilizador diretamente (já aprovado).
+// Contabilistas só podem criar clientes; admins podem criar qualquer perfil, incluindo perfis personalizados.
+type CreateUserDTO struct {
     Username string `json:"username" binding:"required" example:"joao.silva"`
     Email    string `json:"email" binding:"required,email" example:"joao@exemplo.com"`
     Password string `json:"password" binding:"required,min=6" example:"123456"`
     Name     string `json:"name" binding:"required,min=2" example:"João Silva"`
     Phone    string `json:"phone" binding:"required" example:"912345678"`
     NIF      string `json:"nif" binding:"required" example:"123456789"`
-	Role     string `json:"role" binding:"required,oneof=client accountant admin" example:"client"`
-	Status   string `json:"status" binding:"required,oneof=pending approved rejected blocked" example:"pending"`
+	Role     string `json:"role" binding:"required" example:"client"`
 }
 
 // Resposta com token
@@ -111,6 +124,18 @@ type UpdateProfileDTO struct {
 	Phone string `json:"phone" example:"912345678"`
 }
 
+// ActivateAccountDTO para definir a password de uma conta importada
+type ActivateAccountDTO struct {
+	Token    string `json:"token" binding:"required" example:"3f2a9c..."`
+	Password string `json:"password" binding:"required,min=6" example:"password123"`
+}
+
+// ChangePasswordDTO para alterar a password do utilizador autenticado
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"password123"`
+	NewPassword     string `json:"new_password" binding:"required,min=6" example:"novaPassword456"`
+}
+
 // CompleteUserDataDTO para completar dados pessoais após aprovação
 type CompleteUserDataDTO struct {
 	MaritalStatus           string `json:"marital_status" example:"Solteiro"`
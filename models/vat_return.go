@@ -0,0 +1,37 @@
+package models
+
+// VATReturnBoxDTO é um campo da declaração periódica de IVA
+type VATReturnBoxDTO struct {
+	Number int     `json:"number"`
+	Label  string  `json:"label"`
+	Value  float64 `json:"value"`
+}
+
+// VATRateTotalsDTO agrega as vendas de uma taxa de IVA numa região
+type VATRateTotalsDTO struct {
+	Region    string  `json:"region"` // PT, PT-AC, PT-MA
+	Rate      float64 `json:"rate"`
+	NetAmount float64 `json:"net_amount"`
+	TaxAmount float64 `json:"tax_amount"`
+}
+
+// VATReturnDTO é a declaração periódica de IVA calculada para um período
+type VATReturnDTO struct {
+	CompanyID        uint               `json:"company_id"`
+	NIF              string             `json:"nif"`
+	CompanyName      string             `json:"company_name"`
+	Period           string             `json:"period"`    // AAAA-MM ou AAAA-Tn
+	Frequency        string             `json:"frequency"` // mensal, trimestral
+	StartDate        string             `json:"start_date"`
+	EndDate          string             `json:"end_date"`
+	Boxes            []VATReturnBoxDTO  `json:"boxes"` // Campos 1 a 24
+	SalesByRate      []VATRateTotalsDTO `json:"sales_by_rate"`
+	PurchaseInvoices int                `json:"purchase_invoices"`
+	NonDeductibleVAT float64            `json:"non_deductible_vat"`
+	TotalTaxDue      float64            `json:"total_tax_due"`    // Imposto a favor do Estado
+	TotalDeductible  float64            `json:"total_deductible"` // Imposto a favor do sujeito passivo
+	CarriedCredit    float64            `json:"carried_credit"`   // Excesso a reportar do período anterior (campo 61)
+	TaxPayable       float64            `json:"tax_payable"`      // Imposto a entregar ao Estado
+	TaxRecoverable   float64            `json:"tax_recoverable"`  // Crédito a recuperar/reportar
+	Warnings         []string           `json:"warnings"`
+}
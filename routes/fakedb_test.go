@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeUser é o único utilizador que a base de dados de teste conhece
+type fakeUser struct {
+	ID     uint
+	Role   string
+	Status string
+}
+
+// useFakeDB troca config.DB por uma base de dados em memória que só responde às leituras
+// feitas pela autenticação e pelas permissões: o utilizador e as permissões dos perfis base.
+// As escritas falham, para que um teste que chegue a gravar alguma coisa o denuncie.
+func useFakeDB(t *testing.T, user fakeUser) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fakeConnector{user})}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+}
+
+type fakeConnector struct{ user fakeUser }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ user fakeUser }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, io.ErrUnexpectedEOF }
+
+func (c fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, io.ErrUnexpectedEOF
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, `FROM "users"`):
+		if len(args) == 0 || args[0].Value != int64(c.user.ID) {
+			return &fakeRows{columns: []string{"id"}}, nil
+		}
+		return &fakeRows{
+			columns: []string{"id", "role", "status"},
+			values:  [][]driver.Value{{int64(c.user.ID), c.user.Role, c.user.Status}},
+		}, nil
+	case strings.Contains(query, "JOIN roles ON roles.id = role_permissions.role_id"):
+		rows := &fakeRows{columns: []string{"permission"}}
+		for _, role := range models.DefaultRoles {
+			if len(args) > 0 && args[0].Value == role.Name {
+				for _, permission := range role.Permissions {
+					rows.values = append(rows.values, []driver.Value{permission})
+				}
+			}
+		}
+		return rows, nil
+	}
+	return &fakeRows{columns: []string{"id"}}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
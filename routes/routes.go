@@ -3,6 +3,7 @@ package routes
 import (
 	"RVContabilidadeBack/controllers"
 	"RVContabilidadeBack/middlewares"
+	"RVContabilidadeBack/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,8 +22,13 @@ func SetupRoutes() *gin.Engine {
         auth := api.Group("/auth")
         {
             auth.POST("/register", controllers.RegisterClient)      // Novo endpoint principal
-            auth.POST("/register-direct", controllers.Register)     // Registo direto (interno)
             auth.POST("/login", controllers.Login)
+            auth.GET("/verify-email", controllers.VerifyEmail)
+            auth.POST("/resend-verification", controllers.ResendVerification)
+            auth.GET("/activate", controllers.CheckActivation)
+            auth.POST("/activate", controllers.ActivateAccount)
+            auth.GET("/invitation", controllers.CheckInvitation)
+            auth.POST("/invitation/accept", controllers.AcceptInvitation)
             // Logout (protegida - requer token)
             auth.POST("/logout", middlewares.AuthMiddleware(), controllers.Logout)
         }
@@ -32,57 +38,240 @@ func SetupRoutes() *gin.Engine {
         protected.Use(middlewares.AuthMiddleware())
         {
             protected.GET("/profile", controllers.GetProfile)
+            protected.PUT("/profile/password", controllers.ChangePassword)
+
+            // Caixa de entrada
+            protected.GET("/notifications", controllers.GetNotifications)
+            protected.PUT("/notifications/read", controllers.MarkNotificationsAsRead)
+            protected.PUT("/notifications/:id/read", controllers.MarkNotificationAsRead)
+            protected.DELETE("/notifications", controllers.DeleteNotifications)
+
+            // Calendário de prazos (ICS)
+            protected.GET("/calendar/feed", controllers.GetCalendarFeed)
+            protected.POST("/calendar/feed", controllers.RotateCalendarFeed)
+            protected.DELETE("/calendar/feed", controllers.RevokeCalendarFeed)
         }
 
-        // Rotas para administração (contabilistas e admins)
+        // Permissões usadas por várias rotas de gestão
+        canRead := middlewares.RequirePermission(models.PermissionClientsRead)
+        canCreate := middlewares.RequirePermission(models.PermissionClientsCreate)
+        canUpdate := middlewares.RequirePermission(models.PermissionClientsUpdate)
+        canApprove := middlewares.RequirePermission(models.PermissionRequestsApprove)
+        canAssign := middlewares.RequirePermission(models.PermissionRequestsAssign)
+        canManageUsers := middlewares.RequirePermission(models.PermissionUsersManage)
+        canManageRoles := middlewares.RequirePermission(models.PermissionRolesManage)
+        canManageSettings := middlewares.RequirePermission(models.PermissionSettingsManage)
+        canManagePortfolios := middlewares.RequirePermission(models.PermissionPortfoliosManage)
+
+        // Rotas de gestão (perfis com backoffice.access; cada rota exige a sua permissão)
         admin := api.Group("/admin")
         admin.Use(middlewares.AuthMiddleware())
-        admin.Use(middlewares.RequireRole("accountant", "admin"))
+        admin.Use(middlewares.RequirePermission(models.PermissionBackofficeAccess))
+        admin.Use(middlewares.RequirePortfolioAccess()) // Sem clients.all, só clientes e empresas da carteira
         {
             // Dashboard
             admin.GET("/dashboard", controllers.GetDashboardData)
             
             // Gestão de solicitações
-            admin.GET("/pending-requests", controllers.GetPendingRequests)
-            admin.POST("/approve-request", controllers.ApproveRequest)
-            admin.GET("/requests", controllers.GetAllRequests)
-            admin.GET("/requests/:id", controllers.GetRequestDetails)
+            admin.GET("/pending-requests", canRead, controllers.GetPendingRequests)
+            admin.POST("/approve-request", canApprove, controllers.ApproveRequest)
+            admin.GET("/requests", canRead, controllers.GetAllRequests)
+            admin.GET("/requests/:id", canRead, controllers.GetRequestDetails)
+            
+            // Fila de revisão
+            admin.GET("/my-queue", canApprove, controllers.GetMyQueue)
+            admin.POST("/requests/:id/claim", canApprove, controllers.ClaimRequest)
+            admin.POST("/requests/:id/release", canApprove, controllers.ReleaseRequest)
+            admin.GET("/requests/:id/assignments", canRead, controllers.GetRequestAssignmentHistory)
             
             // Gestão de utilizadores
-            admin.GET("/users", controllers.GetAllUsers)
-            admin.GET("/users/count", controllers.GetUsersCount)
-            admin.GET("/users/simple", controllers.GetAllUsersSimple)
-            admin.GET("/users/:id", controllers.GetUserDetails)
+            admin.GET("/users", canRead, controllers.GetAllUsers)
+            admin.GET("/users/count", canRead, controllers.GetUsersCount)
+            admin.GET("/users/simple", canRead, controllers.GetAllUsersSimple)
+            admin.POST("/users", canCreate, controllers.CreateUser) // Contabilistas criam clientes; admins criam também equipa
+            admin.GET("/users/:id", canRead, controllers.GetUserDetails)
             
             // Gestão de clientes aprovados
-            admin.GET("/clients", controllers.GetApprovedClients)
-            admin.GET("/clients/overview", controllers.GetAllClientsOverview)
-            admin.PUT("/clients/:id", controllers.UpdateClientData)
-            admin.PUT("/clients/:id/company", controllers.AdminUpdateClientCompany) 
-            admin.DELETE("/clients/:id", controllers.DeleteClient)
+            admin.GET("/clients", canRead, controllers.GetApprovedClients)
+            admin.GET("/clients/overview", canRead, controllers.GetAllClientsOverview)
+            admin.PUT("/clients/:id", canUpdate, controllers.UpdateClientData)
+            admin.PUT("/clients/:id/company", canUpdate, controllers.AdminUpdateClientCompany) 
+            admin.DELETE("/clients/:id", middlewares.RequirePermission(models.PermissionClientsDelete), controllers.DeleteClient)
+
+            // Empresas de cada cliente e clientes de cada empresa (papéis owner, manager, viewer)
+            admin.GET("/clients/:id/companies", canRead, controllers.AdminGetClientCompanies)
+            admin.POST("/clients/:id/companies", canCreate, controllers.AdminCreateClientCompany)
+            admin.GET("/companies/:id/users", canRead, controllers.GetCompanyUsers)
+            admin.PUT("/companies/:id/users", canUpdate, controllers.SetCompanyUser)
+            admin.DELETE("/companies/:id/users/:userId", canUpdate, controllers.RemoveCompanyUser)
+
+            // Documentos dos clientes
+            admin.GET("/clients/:id/documents", canRead, controllers.GetClientDocuments)
+            admin.GET("/clients/:id/documents/:docId/download", canRead, controllers.DownloadClientDocument)
+            admin.PUT("/clients/:id/documents/:docId/status", canUpdate, controllers.UpdateClientDocumentStatus)
+
+            // Checklist de documentos por período
+            admin.GET("/checklists/late-matrix", canRead, controllers.GetChecklistLateMatrix)
+            admin.POST("/checklists/generate", canUpdate, controllers.GenerateChecklists)
+            admin.PUT("/checklists/:id", canUpdate, controllers.UpdateChecklistItem)
+
+            // Calendário de obrigações fiscais
+            admin.GET("/obligations", canRead, controllers.GetObligations)
+            admin.PUT("/obligations/:id", canUpdate, controllers.UpdateObligation)
+
+            // Importação de SAF-T (PT)
+            admin.POST("/clients/:id/saft-imports", canUpdate, controllers.UploadClientSAFT)
+            admin.GET("/clients/:id/saft-imports", canRead, controllers.GetClientSAFTImports)
+            admin.GET("/clients/:id/saft-imports/:importId", canRead, controllers.GetClientSAFTImport)
+            admin.GET("/clients/:id/saft-imports/:importId/summary", canRead, controllers.GetClientSAFTSummary)
+
+            // Faturas de compra do e-Fatura
+            admin.POST("/clients/:id/purchase-invoices/import", canUpdate, controllers.ImportClientPurchaseInvoices)
+            admin.GET("/clients/:id/purchase-invoices", canRead, controllers.GetClientPurchaseInvoices)
+            admin.GET("/clients/:id/purchase-invoices/discrepancies", canRead, controllers.GetClientPurchaseDiscrepancies)
+            admin.PUT("/clients/:id/purchase-invoices/:invoiceId/match", canUpdate, controllers.MatchClientPurchaseInvoice)
+            admin.PUT("/clients/:id/purchase-invoices/:invoiceId/vat", canUpdate, controllers.ClassifyClientPurchaseInvoice)
+
+            // Extratos e livro bancário
+            admin.POST("/clients/:id/bank-statements", canUpdate, controllers.ImportClientBankStatement)
+            admin.GET("/clients/:id/bank-statements", canRead, controllers.GetClientBankStatements)
+            admin.GET("/clients/:id/bank-transactions", canRead, controllers.GetClientBankTransactions)
+            admin.GET("/clients/:id/bank-transactions/unmatched", canRead, controllers.GetClientUnmatchedTransactions)
+
+            // Conciliação bancária
+            admin.POST("/clients/:id/reconciliation/run", canUpdate, controllers.RunClientReconciliation)
+            admin.GET("/clients/:id/reconciliation/matches", canRead, controllers.GetClientReconciliationMatches)
+            admin.POST("/clients/:id/reconciliation/matches/:matchId/accept", canUpdate, controllers.AcceptReconciliationMatch)
+            admin.POST("/clients/:id/reconciliation/matches/:matchId/reject", canUpdate, controllers.RejectReconciliationMatch)
+            admin.POST("/clients/:id/reconciliation/split", canUpdate, controllers.SplitClientTransaction)
+            admin.GET("/clients/:id/reconciliation/rules", canRead, controllers.GetClientReconciliationRules)
+
+            // Declaração periódica de IVA
+            admin.GET("/clients/:id/vat-return", canRead, controllers.GetClientVATReturn)
+            admin.GET("/clients/:id/vat-return/xml", canRead, controllers.ExportClientVATReturnXML)
+
+            // Relatórios em PDF
+            admin.GET("/clients/:id/reports/dossier", canRead, controllers.GetClientDossierPDF)
+            admin.GET("/clients/:id/reports/company", canRead, controllers.GetClientCompanySheetPDF)
+            admin.GET("/clients/:id/reports/checklist", canRead, controllers.GetClientChecklistPDF)
+            admin.GET("/clients/:id/reports/vat-summary", canRead, controllers.GetClientVATSummaryPDF)
+
+            // Convites de clientes (dados da empresa pré-preenchidos pelo contabilista)
+            admin.GET("/invitations", canRead, controllers.GetInvitations)
+            admin.POST("/invitations", canCreate, controllers.CreateInvitation)
+            admin.GET("/invitations/:id", canRead, controllers.GetInvitation)
+            admin.POST("/invitations/:id/resend", canCreate, controllers.ResendInvitation)
+            admin.POST("/invitations/:id/revoke", canCreate, controllers.RevokeInvitation)
+
+            // Exportações CSV/XLSX (auditadas)
+            admin.GET("/exports", canRead, controllers.GetExportDatasets)
+            admin.GET("/exports/:dataset", canRead, controllers.ExportDataset)
+
+            // Contabilidade (plano de contas SNC, diários, exercícios e lançamentos)
+            admin.GET("/companies/:id/accounts", canRead, controllers.GetCompanyAccounts)
+            admin.POST("/companies/:id/accounts", canUpdate, controllers.CreateCompanyAccount)
+            admin.PUT("/companies/:id/accounts/:accountId", canUpdate, controllers.UpdateCompanyAccount)
+            admin.GET("/companies/:id/journals", canRead, controllers.GetCompanyJournals)
+            admin.POST("/companies/:id/journals", canUpdate, controllers.CreateCompanyJournal)
+            admin.GET("/companies/:id/fiscal-years", canRead, controllers.GetCompanyFiscalYears)
+            admin.POST("/companies/:id/fiscal-years", canUpdate, controllers.CreateCompanyFiscalYear)
+            admin.POST("/companies/:id/fiscal-years/:yearId/close", canUpdate, controllers.CloseCompanyFiscalYear)
+            admin.POST("/companies/:id/fiscal-periods/:periodId/close", canUpdate, controllers.CloseCompanyFiscalPeriod)
+            admin.GET("/companies/:id/fiscal-periods/:periodId/history", canRead, controllers.GetCompanyFiscalPeriodHistory)
+            admin.GET("/companies/:id/journal-entries", canRead, controllers.GetCompanyJournalEntries)
+            admin.POST("/companies/:id/journal-entries", canUpdate, controllers.CreateCompanyJournalEntry)
+            admin.GET("/companies/:id/journal-entries/:entryId", canRead, controllers.GetCompanyJournalEntry)
+            admin.DELETE("/companies/:id/journal-entries/:entryId", canUpdate, controllers.DeleteCompanyJournalEntry)
+            admin.GET("/companies/:id/trial-balance", canRead, controllers.GetCompanyTrialBalance)
+            
+            // Carteiras de clientes (empresas atribuídas a cada contabilista)
+            admin.GET("/portfolios", canManagePortfolios, controllers.GetPortfolios)
+            admin.POST("/portfolios/reassign", canManagePortfolios, controllers.ReassignPortfolio)
+            admin.GET("/portfolios/:id", canManagePortfolios, controllers.GetPortfolio)
+            admin.GET("/companies/:id/accountants", canRead, controllers.GetCompanyAccountants)
+            admin.PUT("/companies/:id/accountants", canManagePortfolios, controllers.SetCompanyAccountants)
             
             // Visão completa de todos os clientes (combina users, registration_requests e companies)
-            admin.GET("/complete-users-overview", controllers.GetCompleteUsersOverview)
-        }
+            admin.GET("/complete-users-overview", canRead, controllers.GetCompleteUsersOverview)
 
-        // Rotas apenas para admins
-        adminOnly := api.Group("/admin")
-        adminOnly.Use(middlewares.AuthMiddleware())
-        adminOnly.Use(middlewares.RequireRole("admin"))
-        {
-            adminOnly.PUT("/users/:id/status", controllers.UpdateUserStatus)
+            // Estado das contas (bloquear/desbloquear)
+            admin.PUT("/users/:id/status", canManageUsers, controllers.UpdateUserStatus)
+
+            // Atribuição de solicitações
+            admin.POST("/requests/:id/assign", canAssign, controllers.AssignRequest)
+            admin.POST("/pending-requests/auto-assign", canAssign, controllers.AutoAssignPendingRequests)
+            admin.GET("/assignment-rules", canAssign, controllers.GetAssignmentRules)
+            admin.POST("/assignment-rules", canAssign, controllers.CreateAssignmentRule)
+            admin.DELETE("/assignment-rules/:id", canAssign, controllers.DeleteAssignmentRule)
+
+            // Notificações por email
+            admin.GET("/notification-templates", canManageSettings, controllers.GetNotificationTemplates)
+            admin.PUT("/notification-templates/:event/:language", canManageSettings, controllers.UpdateNotificationTemplate)
+            admin.GET("/notification-outbox", canManageSettings, controllers.GetNotificationOutbox)
+            admin.POST("/notification-outbox/:id/retry", canManageSettings, controllers.RetryNotificationEmail)
+
+            // Reabertura de períodos contabilísticos fechados (auditada)
+            admin.POST("/companies/:id/fiscal-periods/:periodId/reopen", middlewares.RequirePermission(models.PermissionPeriodsReopen), controllers.ReopenCompanyFiscalPeriod)
+
+            // Permissões explícitas e auditoria
+            admin.GET("/users/:id/permissions", canManageUsers, controllers.GetUserPermissions)
+            admin.POST("/users/:id/permissions", canManageUsers, controllers.GrantUserPermission)
+            admin.DELETE("/users/:id/permissions/:permission", canManageUsers, controllers.RevokeUserPermission)
+            admin.GET("/audit-logs", middlewares.RequirePermission(models.PermissionAuditRead), controllers.GetAuditLogs)
+
+            // Importação de clientes do sistema antigo (CSV/XLSX) e ativação das contas
+            admin.GET("/client-imports/columns", canManageUsers, controllers.GetClientImportColumns)
+            admin.GET("/client-imports", canManageUsers, controllers.GetClientImports)
+            admin.POST("/client-imports", canManageUsers, controllers.ImportClients)
+            admin.GET("/client-imports/:id", canManageUsers, controllers.GetClientImport)
+            admin.POST("/users/:id/activation", canManageUsers, controllers.ResendUserActivation)
+
+            // Perfis (conjuntos de permissões editáveis)
+            admin.GET("/permissions", canManageRoles, controllers.GetPermissionCatalog)
+            admin.GET("/roles", canManageRoles, controllers.GetRoles)
+            admin.POST("/roles", canManageRoles, controllers.CreateRole)
+            admin.GET("/roles/:id", canManageRoles, controllers.GetRole)
+            admin.PUT("/roles/:id", canManageRoles, controllers.UpdateRole)
+            admin.DELETE("/roles/:id", canManageRoles, controllers.DeleteRole)
+            admin.PUT("/users/:id/role", canManageUsers, controllers.UpdateUserRole)
         }
 
         // Rotas para clientes (apenas clientes aprovados)
         client := api.Group("/client")
         client.Use(middlewares.AuthMiddleware())
-        client.Use(middlewares.RequireRole("client"))
+        client.Use(middlewares.RequirePermission(models.PermissionPortalAccess))
         {
             client.GET("/profile", controllers.GetClientProfile)
             client.PUT("/profile", controllers.UpdateClientProfile)
-            client.GET("/company", controllers.GetClientCompany)
-            client.PUT("/company", controllers.UpdateClientCompany)
+            client.GET("/companies", controllers.GetClientCompanies)
+            client.GET("/companies/:id", controllers.GetClientCompany)
+            client.PUT("/companies/:id", controllers.UpdateClientCompany)
             client.GET("/requests", controllers.GetClientRequests)
+
+            // Documentos
+            client.POST("/documents", controllers.UploadDocument)
+            client.GET("/documents", controllers.GetMyDocuments)
+            client.GET("/documents/:id/download", controllers.DownloadMyDocument)
+            client.GET("/checklist", controllers.GetMyChecklist)
+            client.GET("/obligations", controllers.GetMyObligations)
+
+            // Importação de SAF-T (PT)
+            client.POST("/saft-imports", controllers.UploadMySAFT)
+            client.GET("/saft-imports", controllers.GetMySAFTImports)
+            client.GET("/saft-imports/:id", controllers.GetMySAFTImport)
+            client.GET("/saft-imports/:id/summary", controllers.GetMySAFTSummary)
+
+            // Faturação (clientes sem software de faturação próprio)
+            client.GET("/billing/customers", controllers.GetMyBillingCustomers)
+            client.POST("/billing/customers", controllers.CreateMyBillingCustomer)
+            client.PUT("/billing/customers/:id", controllers.UpdateMyBillingCustomer)
+            client.GET("/billing/series", controllers.GetMyInvoiceSeries)
+            client.POST("/billing/series", controllers.CreateMyInvoiceSeries)
+            client.GET("/billing/invoices", controllers.GetMyInvoices)
+            client.POST("/billing/invoices", controllers.IssueMyInvoice)
+            client.GET("/billing/invoices/:id", controllers.GetMyInvoice)
+            client.GET("/billing/invoices/:id/pdf", controllers.DownloadMyInvoicePDF)
+            client.POST("/billing/invoices/:id/credit-notes", controllers.IssueMyCreditNote)
             
             // Novos endpoints para completar dados após aprovação
             client.POST("/complete-user-data", controllers.CompleteUserData)
@@ -91,6 +280,9 @@ func SetupRoutes() *gin.Engine {
 
         // Rota de informações da API (pública)
         api.GET("/info", controllers.GetAPIInfo)
+
+        // Feed ICS público (autenticado pelo token no endereço)
+        api.GET("/calendar/:token/deadlines.ics", controllers.DownloadCalendarICS)
     }
 
     // Rota de health check
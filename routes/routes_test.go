@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"RVContabilidadeBack/services"
+	"RVContabilidadeBack/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Payload que antes permitia a qualquer pessoa criar uma conta de admin já aprovada
+const escalationPayload = `{"username":"intruso","email":"intruso@exemplo.com","password":"password123",` +
+	`"name":"Intruso","phone":"912345678","nif":"123456789","role":"admin","status":"approved"}`
+
+func TestRegisterDirectIsNotPublic(t *testing.T) {
+	router := testRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register-direct", strings.NewReader(escalationPayload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("POST /api/auth/register-direct: esperado 404, obtido %d", w.Code)
+	}
+}
+
+func TestCreateUserRejectsAnonymous(t *testing.T) {
+	router := testRouter()
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":  1,
+		"username": "admin",
+		"role":     "admin",
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	})
+	forgedToken, err := forged.SignedString([]byte("chave-que-o-servidor-nao-conhece"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"sem token":      "",
+		"token inválido": "Bearer abc.def.ghi",
+		"token forjado":  "Bearer " + forgedToken,
+	}
+	for name, header := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/users", strings.NewReader(escalationPayload))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: esperado 401, obtido %d", name, w.Code)
+		}
+	}
+}
+
+func TestCreateUserForbidsAccountantCreatingAdmin(t *testing.T) {
+	useFakeDB(t, fakeUser{ID: 7, Role: "accountant", Status: "approved"})
+	router := testRouter()
+
+	token, err := utils.GenerateToken(7, "contabilista", "123456789", "accountant")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users", strings.NewReader(escalationPayload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("contabilista a criar admin: esperado 403, obtido %d (%s)", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "sem permissão para criar utilizadores com o perfil admin") {
+		t.Errorf("resposta inesperada: %s", w.Body.String())
+	}
+}
+
+func TestCanCreateRole(t *testing.T) {
+	cases := []struct {
+		creator, role string
+		allowed       bool
+	}{
+		{"", "client", false},
+		{"", "admin", false},
+		{"client", "client", false},
+		{"client", "admin", false},
+		{"accountant", "client", true},
+		{"accountant", "accountant", false},
+		{"accountant", "admin", false},
+		{"admin", "client", true},
+		{"admin", "accountant", true},
+		{"admin", "admin", true},
+		{"admin", "superadmin", false},
+	}
+	for _, tc := range cases {
+		if got := services.CanCreateRole(tc.creator, tc.role); got != tc.allowed {
+			t.Errorf("CanCreateRole(%q, %q) = %v, esperado %v", tc.creator, tc.role, got, tc.allowed)
+		}
+	}
+}
+
+func testRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return SetupRoutes()
+}
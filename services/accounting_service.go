@@ -0,0 +1,551 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountingService struct{}
+
+func NewAccountingService() *AccountingService {
+	return &AccountingService{}
+}
+
+// GetAccounts devolve o plano de contas da empresa, criando o plano SNC base se ainda não existir
+func (s *AccountingService) GetAccounts(companyID uint, class int, postableOnly bool) ([]models.Account, error) {
+	if err := s.ensureSetup(companyID); err != nil {
+		return nil, err
+	}
+
+	var accounts []models.Account
+	if err := config.DB.Where("company_id = ?", companyID).Order("code ASC").Find(&accounts).Error; err != nil {
+		return nil, errors.New("erro ao obter plano de contas")
+	}
+	markPostable(accounts)
+
+	filtered := []models.Account{}
+	for _, account := range accounts {
+		if class > 0 && account.Class != class {
+			continue
+		}
+		if postableOnly && !account.Postable {
+			continue
+		}
+		filtered = append(filtered, account)
+	}
+	return filtered, nil
+}
+
+// CreateAccount cria uma subconta; a conta mãe não pode ter lançamentos
+func (s *AccountingService) CreateAccount(companyID uint, req models.CreateAccountDTO) (*models.Account, error) {
+	if err := s.ensureSetup(companyID); err != nil {
+		return nil, err
+	}
+	if len(req.Code) < 3 {
+		return nil, errors.New("as subcontas têm de ter pelo menos 3 dígitos")
+	}
+
+	var existing int64
+	config.DB.Model(&models.Account{}).Where("company_id = ? AND code = ?", companyID, req.Code).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("já existe uma conta com este código")
+	}
+
+	// A conta mãe é a conta existente com o prefixo mais longo
+	var parent models.Account
+	found := false
+	for length := len(req.Code) - 1; length >= 2 && !found; length-- {
+		found = config.DB.Where("company_id = ? AND code = ?", companyID, req.Code[:length]).First(&parent).Error == nil
+	}
+	if !found {
+		return nil, errors.New("conta mãe não encontrada no plano de contas")
+	}
+
+	var movements int64
+	config.DB.Model(&models.JournalEntryLine{}).Where("account_id = ?", parent.ID).Count(&movements)
+	if movements > 0 {
+		return nil, fmt.Errorf("a conta %s já tem lançamentos e não pode ter subcontas", parent.Code)
+	}
+
+	account := models.Account{
+		CompanyID:  companyID,
+		Code:       req.Code,
+		Name:       strings.TrimSpace(req.Name),
+		Class:      int(req.Code[0] - '0'),
+		ParentCode: parent.Code,
+		Active:     true,
+		Postable:   true,
+	}
+	if err := config.DB.Create(&account).Error; err != nil {
+		return nil, errors.New("erro ao criar conta")
+	}
+	return &account, nil
+}
+
+// UpdateAccount altera o nome de uma conta ou ativa/desativa-a (contas inativas não aceitam lançamentos)
+func (s *AccountingService) UpdateAccount(companyID, accountID uint, req models.UpdateAccountDTO) (*models.Account, error) {
+	var account models.Account
+	if err := config.DB.Where("id = ? AND company_id = ?", accountID, companyID).First(&account).Error; err != nil {
+		return nil, errors.New("conta não encontrada")
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, errors.New("o nome da conta é obrigatório")
+		}
+		account.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Active != nil {
+		account.Active = *req.Active
+	}
+	if err := config.DB.Save(&account).Error; err != nil {
+		return nil, errors.New("erro ao atualizar conta")
+	}
+
+	var children int64
+	config.DB.Model(&models.Account{}).Where("company_id = ? AND parent_code = ?", companyID, account.Code).Count(&children)
+	account.Postable = children == 0
+	return &account, nil
+}
+
+// GetJournals lista os diários da empresa
+func (s *AccountingService) GetJournals(companyID uint) ([]models.Journal, error) {
+	if err := s.ensureSetup(companyID); err != nil {
+		return nil, err
+	}
+
+	journals := []models.Journal{}
+	if err := config.DB.Where("company_id = ?", companyID).Order("code ASC").Find(&journals).Error; err != nil {
+		return nil, errors.New("erro ao obter diários")
+	}
+	return journals, nil
+}
+
+// CreateJournal cria um diário adicional (ex.: um diário por conta bancária)
+func (s *AccountingService) CreateJournal(companyID uint, req models.CreateJournalDTO) (*models.Journal, error) {
+	if err := s.ensureSetup(companyID); err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	var existing int64
+	config.DB.Model(&models.Journal{}).Where("company_id = ? AND code = ?", companyID, code).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("já existe um diário com este código")
+	}
+
+	journal := models.Journal{CompanyID: companyID, Code: code, Name: strings.TrimSpace(req.Name), Type: req.Type}
+	if err := config.DB.Create(&journal).Error; err != nil {
+		return nil, errors.New("erro ao criar diário")
+	}
+	return &journal, nil
+}
+
+// GetFiscalYears lista os exercícios da empresa com os respetivos períodos
+func (s *AccountingService) GetFiscalYears(companyID uint) ([]models.FiscalYear, error) {
+	if _, err := s.getCompany(companyID); err != nil {
+		return nil, err
+	}
+
+	years := []models.FiscalYear{}
+	if err := config.DB.Preload("Periods", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number ASC")
+	}).Where("company_id = ?", companyID).Order("year DESC").Find(&years).Error; err != nil {
+		return nil, errors.New("erro ao obter exercícios")
+	}
+	return years, nil
+}
+
+// CreateFiscalYear abre um exercício com 12 períodos mensais
+func (s *AccountingService) CreateFiscalYear(companyID uint, req models.CreateFiscalYearDTO) (*models.FiscalYear, error) {
+	if _, err := s.getCompany(companyID); err != nil {
+		return nil, err
+	}
+
+	start := time.Date(req.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if req.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil || parsed.Day() != 1 {
+			return nil, errors.New("data de início inválida (use AAAA-MM-01)")
+		}
+		start = parsed
+	}
+	end := start.AddDate(1, 0, -1)
+
+	var overlapping int64
+	config.DB.Model(&models.FiscalYear{}).
+		Where("company_id = ? AND (year = ? OR (start_date <= ? AND end_date >= ?))", companyID, req.Year, end, start).
+		Count(&overlapping)
+	if overlapping > 0 {
+		return nil, errors.New("o exercício sobrepõe-se a outro já existente")
+	}
+
+	year := models.FiscalYear{CompanyID: companyID, Year: req.Year, StartDate: start, EndDate: end}
+	for number := 1; number <= 12; number++ {
+		periodStart := start.AddDate(0, number-1, 0)
+		year.Periods = append(year.Periods, models.FiscalPeriod{
+			CompanyID: companyID,
+			Number:    number,
+			StartDate: periodStart,
+			EndDate:   periodStart.AddDate(0, 1, -1),
+			Status:    models.PeriodStatusOpen,
+		})
+	}
+	if err := config.DB.Create(&year).Error; err != nil {
+		return nil, errors.New("erro ao criar exercício")
+	}
+	return &year, nil
+}
+
+// CreateEntry regista um lançamento equilibrado num período que aceite alterações do utilizador
+func (s *AccountingService) CreateEntry(companyID, userID uint, role string, req models.CreateJournalEntryDTO) (*models.JournalEntry, error) {
+	if err := s.ensureSetup(companyID); err != nil {
+		return nil, err
+	}
+
+	var journal models.Journal
+	if err := config.DB.Where("company_id = ? AND code = ?", companyID, strings.ToUpper(req.JournalCode)).First(&journal).Error; err != nil {
+		return nil, errors.New("diário não encontrado")
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, errors.New("data inválida (use AAAA-MM-DD)")
+	}
+	period := NewFiscalPeriodService().PeriodForDate(companyID, date)
+	if period == nil {
+		return nil, errors.New("não existe exercício para a data do lançamento")
+	}
+
+	entry := models.JournalEntry{
+		CompanyID:      companyID,
+		JournalID:      journal.ID,
+		FiscalYearID:   period.FiscalYearID,
+		FiscalPeriodID: period.ID,
+		Date:           date,
+		Description:    strings.TrimSpace(req.Description),
+		DocumentRef:    strings.TrimSpace(req.DocumentRef),
+		CreatedBy:      userID,
+	}
+
+	// Os totais são comparados em cêntimos para evitar erros de arredondamento
+	var debitCents, creditCents int64
+	for i, line := range req.Lines {
+		debit := math.Round(line.Debit * 100)
+		credit := math.Round(line.Credit * 100)
+		if (debit > 0) == (credit > 0) {
+			return nil, fmt.Errorf("linha %d: indique um valor a débito ou a crédito (não ambos)", i+1)
+		}
+
+		var account models.Account
+		if err := config.DB.Where("company_id = ? AND code = ?", companyID, line.AccountCode).First(&account).Error; err != nil {
+			return nil, fmt.Errorf("linha %d: conta %s não encontrada", i+1, line.AccountCode)
+		}
+		if !account.Active {
+			return nil, fmt.Errorf("linha %d: a conta %s está inativa", i+1, account.Code)
+		}
+		var children int64
+		config.DB.Model(&models.Account{}).Where("company_id = ? AND parent_code = ?", companyID, account.Code).Count(&children)
+		if children > 0 {
+			return nil, fmt.Errorf("linha %d: a conta %s tem subcontas; lance numa conta de movimento", i+1, account.Code)
+		}
+
+		debitCents += int64(debit)
+		creditCents += int64(credit)
+		entry.Lines = append(entry.Lines, models.JournalEntryLine{
+			AccountID:   account.ID,
+			Debit:       debit / 100,
+			Credit:      credit / 100,
+			Description: strings.TrimSpace(line.Description),
+		})
+	}
+	if debitCents != creditCents {
+		return nil, fmt.Errorf("lançamento desequilibrado: débito %.2f, crédito %.2f", float64(debitCents)/100, float64(creditCents)/100)
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockJournalPeriod(tx, journal.ID, period, role); err != nil {
+			return err
+		}
+
+		var last struct{ Number int }
+		if err := tx.Model(&models.JournalEntry{}).Select("COALESCE(MAX(number), 0) AS number").
+			Where("company_id = ? AND journal_id = ? AND fiscal_year_id = ?", companyID, journal.ID, period.FiscalYearID).
+			Scan(&last).Error; err != nil {
+			return errors.New("erro ao numerar lançamento")
+		}
+		entry.Number = last.Number + 1
+		if err := tx.Create(&entry).Error; err != nil {
+			return errors.New("erro ao registar lançamento")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Journal = &journal
+	return &entry, nil
+}
+
+// GetEntries lista os lançamentos da empresa (datas AAAA-MM-DD)
+func (s *AccountingService) GetEntries(companyID uint, journalCode, from, to string) ([]models.JournalEntry, error) {
+	if _, err := s.getCompany(companyID); err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Preload("Journal").Preload("Lines.Account").Where("journal_entries.company_id = ?", companyID)
+	if journalCode != "" {
+		query = query.Joins("JOIN journals ON journals.id = journal_entries.journal_id").
+			Where("journals.code = ?", strings.ToUpper(journalCode))
+	}
+	if from != "" {
+		fromDate, err := parseDateOrDefault(from, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("journal_entries.date >= ?", fromDate)
+	}
+	if to != "" {
+		toDate, err := parseDateOrDefault(to, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("journal_entries.date <= ?", toDate)
+	}
+
+	entries := []models.JournalEntry{}
+	if err := query.Order("journal_entries.date ASC, journal_entries.id ASC").Find(&entries).Error; err != nil {
+		return nil, errors.New("erro ao obter lançamentos")
+	}
+	return entries, nil
+}
+
+// GetEntry devolve um lançamento com as linhas
+func (s *AccountingService) GetEntry(companyID, entryID uint) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	if err := config.DB.Preload("Journal").Preload("Lines.Account").
+		Where("id = ? AND company_id = ?", entryID, companyID).First(&entry).Error; err != nil {
+		return nil, errors.New("lançamento não encontrado")
+	}
+	return &entry, nil
+}
+
+// DeleteEntry anula o último lançamento de um diário num período que aceite alterações do utilizador;
+// os restantes corrigem-se com um lançamento de estorno para manter a numeração sem falhas
+func (s *AccountingService) DeleteEntry(companyID, entryID uint, role string) error {
+	entry, err := s.GetEntry(companyID, entryID)
+	if err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockJournalPeriod(tx, entry.JournalID, &models.FiscalPeriod{ID: entry.FiscalPeriodID}, role); err != nil {
+			return err
+		}
+
+		var later int64
+		if err := tx.Model(&models.JournalEntry{}).
+			Where("company_id = ? AND journal_id = ? AND fiscal_year_id = ? AND number > ?", companyID, entry.JournalID, entry.FiscalYearID, entry.Number).
+			Count(&later).Error; err != nil {
+			return errors.New("erro ao anular lançamento")
+		}
+		if later > 0 {
+			return errors.New("só é possível anular o último lançamento do diário; registe um estorno")
+		}
+
+		if err := tx.Where("entry_id = ?", entry.ID).Delete(&models.JournalEntryLine{}).Error; err != nil {
+			return errors.New("erro ao anular lançamento")
+		}
+		if err := tx.Delete(&models.JournalEntry{}, entry.ID).Error; err != nil {
+			return errors.New("erro ao anular lançamento")
+		}
+		return nil
+	})
+}
+
+// lockJournalPeriod bloqueia o diário (serializando a numeração dos seus lançamentos) e o período,
+// que é relido dentro da transação para que um fecho em curso não deixe passar o lançamento
+func lockJournalPeriod(tx *gorm.DB, journalID uint, period *models.FiscalPeriod, role string) error {
+	var journal models.Journal
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&journal, journalID).Error; err != nil {
+		return errors.New("diário não encontrado")
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(period, period.ID).Error; err != nil {
+		return errors.New("período não encontrado")
+	}
+	return checkPeriodWritable(period, role)
+}
+
+// GetTrialBalance calcula o balancete do exercício entre os períodos indicados,
+// com as contas de movimento e as contas mãe agregadas
+func (s *AccountingService) GetTrialBalance(companyID uint, fiscalYear, periodFrom, periodTo int) (*models.TrialBalanceDTO, error) {
+	if err := s.ensureSetup(companyID); err != nil {
+		return nil, err
+	}
+
+	if fiscalYear == 0 {
+		fiscalYear = time.Now().Year()
+	}
+	if periodFrom == 0 {
+		periodFrom = 1
+	}
+	if periodTo == 0 {
+		periodTo = 12
+	}
+	if periodFrom < 1 || periodTo > 12 || periodFrom > periodTo {
+		return nil, errors.New("intervalo de períodos inválido (1 a 12)")
+	}
+
+	var year models.FiscalYear
+	if err := config.DB.Where("company_id = ? AND year = ?", companyID, fiscalYear).First(&year).Error; err != nil {
+		return nil, errors.New("exercício não encontrado")
+	}
+
+	var totals []struct {
+		AccountID uint
+		Debit     float64
+		Credit    float64
+	}
+	if err := config.DB.Table("journal_entry_lines").
+		Select("journal_entry_lines.account_id, SUM(journal_entry_lines.debit) AS debit, SUM(journal_entry_lines.credit) AS credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_entry_lines.entry_id").
+		Joins("JOIN fiscal_periods ON fiscal_periods.id = journal_entries.fiscal_period_id").
+		Where("journal_entries.company_id = ? AND journal_entries.fiscal_year_id = ? AND fiscal_periods.number BETWEEN ? AND ?",
+			companyID, year.ID, periodFrom, periodTo).
+		Group("journal_entry_lines.account_id").
+		Scan(&totals).Error; err != nil {
+		return nil, errors.New("erro ao calcular balancete")
+	}
+
+	var accounts []models.Account
+	config.DB.Where("company_id = ?", companyID).Find(&accounts)
+	byID := make(map[uint]models.Account, len(accounts))
+	byCode := make(map[string]models.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.ID] = account
+		byCode[account.Code] = account
+	}
+
+	balance := models.TrialBalanceDTO{CompanyID: companyID, FiscalYear: fiscalYear, PeriodFrom: periodFrom, PeriodTo: periodTo}
+	rows := make(map[string]*models.TrialBalanceRowDTO)
+	for _, total := range totals {
+		account, ok := byID[total.AccountID]
+		if !ok {
+			continue
+		}
+		balance.TotalDebit += total.Debit
+		balance.TotalCredit += total.Credit
+
+		// Somar na conta e em todas as contas mãe
+		for code := account.Code; code != ""; code = byCode[code].ParentCode {
+			row, ok := rows[code]
+			if !ok {
+				row = &models.TrialBalanceRowDTO{Code: code, Name: byCode[code].Name, Postable: code == account.Code}
+				rows[code] = row
+			}
+			row.Debit += total.Debit
+			row.Credit += total.Credit
+		}
+	}
+
+	balance.Rows = make([]models.TrialBalanceRowDTO, 0, len(rows))
+	for _, row := range rows {
+		row.Debit = roundAmount(row.Debit)
+		row.Credit = roundAmount(row.Credit)
+		if row.Debit >= row.Credit {
+			row.DebitBalance = roundAmount(row.Debit - row.Credit)
+		} else {
+			row.CreditBalance = roundAmount(row.Credit - row.Debit)
+		}
+		balance.Rows = append(balance.Rows, *row)
+	}
+	sort.Slice(balance.Rows, func(i, j int) bool {
+		return balance.Rows[i].Code < balance.Rows[j].Code
+	})
+
+	balance.TotalDebit = roundAmount(balance.TotalDebit)
+	balance.TotalCredit = roundAmount(balance.TotalCredit)
+	balance.Balanced = math.Abs(balance.TotalDebit-balance.TotalCredit) < amountTolerance
+	return &balance, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func (s *AccountingService) getCompany(companyID uint) (*models.Company, error) {
+	var company models.Company
+	if err := config.DB.First(&company, companyID).Error; err != nil {
+		return nil, errors.New("empresa não encontrada")
+	}
+	return &company, nil
+}
+
+// ensureSetup cria o plano de contas SNC e os diários base na primeira utilização
+func (s *AccountingService) ensureSetup(companyID uint) error {
+	if _, err := s.getCompany(companyID); err != nil {
+		return err
+	}
+
+	var count int64
+	config.DB.Model(&models.Account{}).Where("company_id = ?", companyID).Count(&count)
+	if count == 0 {
+		accounts := make([]models.Account, 0, len(sncChartOfAccounts))
+		codes := make(map[string]bool, len(sncChartOfAccounts))
+		for _, item := range sncChartOfAccounts {
+			parent := ""
+			for length := len(item.code) - 1; length >= 2; length-- {
+				if codes[item.code[:length]] {
+					parent = item.code[:length]
+					break
+				}
+			}
+			codes[item.code] = true
+			accounts = append(accounts, models.Account{
+				CompanyID:  companyID,
+				Code:       item.code,
+				Name:       item.name,
+				Class:      int(item.code[0] - '0'),
+				ParentCode: parent,
+				Active:     true,
+			})
+		}
+		if err := config.DB.CreateInBatches(accounts, 100).Error; err != nil {
+			return errors.New("erro ao criar plano de contas")
+		}
+	}
+
+	config.DB.Model(&models.Journal{}).Where("company_id = ?", companyID).Count(&count)
+	if count == 0 {
+		journals := make([]models.Journal, len(defaultJournals))
+		copy(journals, defaultJournals)
+		for i := range journals {
+			journals[i].CompanyID = companyID
+		}
+		if err := config.DB.Create(&journals).Error; err != nil {
+			return errors.New("erro ao criar diários")
+		}
+	}
+	return nil
+}
+
+// markPostable marca as contas sem subcontas como contas de movimento
+func markPostable(accounts []models.Account) {
+	parents := make(map[string]bool)
+	for _, account := range accounts {
+		if account.ParentCode != "" {
+			parents[account.ParentCode] = true
+		}
+	}
+	for i := range accounts {
+		accounts[i].Postable = !parents[accounts[i].Code]
+	}
+}
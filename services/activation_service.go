@@ -0,0 +1,132 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// activationTTL é a validade do link de ativação de uma conta importada
+const activationTTL = 14 * 24 * time.Hour
+
+type ActivationService struct{}
+
+func NewActivationService() *ActivationService {
+	return &ActivationService{}
+}
+
+// SendActivation gera um novo token e envia ao utilizador o link para definir a password
+func (s *ActivationService) SendActivation(user *models.User) error {
+	if user.ActivatedAt != nil {
+		return errors.New("conta já ativada")
+	}
+	if user.Email == "" {
+		return errors.New("utilizador sem email")
+	}
+
+	now := time.Now()
+	user.ActivationToken = utils.GenerateRandomToken()
+	user.ActivationSentAt = &now
+	if err := config.DB.Model(user).Updates(map[string]interface{}{
+		"activation_token":   user.ActivationToken,
+		"activation_sent_at": now,
+	}).Error; err != nil {
+		return errors.New("erro ao gerar token de ativação")
+	}
+
+	link := fmt.Sprintf("%s/api/auth/activate?token=%s", appBaseURL(), url.QueryEscape(user.ActivationToken))
+	if err := NewNotificationService().NotifyUser(models.EventAccountActivation, user, map[string]interface{}{
+		"Link": link,
+		"Days": int(activationTTL.Hours() / 24),
+	}); err != nil {
+		return errors.New("erro ao enviar email de ativação")
+	}
+
+	return nil
+}
+
+// ResendActivation reenvia o link de ativação de um cliente que ainda não definiu a password
+func (s *ActivationService) ResendActivation(userID uint) (*models.User, error) {
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("utilizador não encontrado")
+	}
+	if user.ActivationToken == "" && user.ActivatedAt == nil {
+		return nil, errors.New("o utilizador não tem ativação pendente")
+	}
+
+	if err := s.SendActivation(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CheckToken devolve o utilizador associado a um token de ativação válido
+func (s *ActivationService) CheckToken(token string) (*models.User, error) {
+	if token == "" {
+		return nil, errors.New("token de ativação inválido")
+	}
+
+	var user models.User
+	if err := config.DB.Where("activation_token = ?", token).First(&user).Error; err != nil {
+		return nil, errors.New("token de ativação inválido")
+	}
+	if user.ActivationSentAt == nil || time.Since(*user.ActivationSentAt) > activationTTL {
+		return nil, errors.New("token de ativação expirado")
+	}
+	return &user, nil
+}
+
+// Activate define a password da conta e invalida o token
+func (s *ActivationService) Activate(token, password string) (*models.User, error) {
+	user, err := s.CheckToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("erro ao processar password")
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(user).Updates(map[string]interface{}{
+		"password":         string(hashedPassword),
+		"activation_token": "",
+		"activated_at":     now,
+	}).Error; err != nil {
+		return nil, errors.New("erro ao ativar conta")
+	}
+	user.ActivatedAt = &now
+
+	if err := NewNotificationService().NotifyUser(models.EventPasswordChanged, user, nil); err != nil {
+		log.Printf("⚠️  Erro ao notificar ativação da conta %d: %v", user.ID, err)
+	}
+
+	return user, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// unusablePasswordHash gera um hash de uma password aleatória que ninguém conhece,
+// para contas que só ficam acessíveis depois da ativação
+func unusablePasswordHash() (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(utils.GenerateRandomToken()), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
+// markPendingActivation marca uma conta recém-criada como aguardando ativação
+func markPendingActivation(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).Update("activation_token", utils.GenerateRandomToken()).Error
+}
@@ -3,8 +3,16 @@ package services
 import (
 	"RVContabilidadeBack/config"
 	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
 	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AdminService struct{}
@@ -13,77 +21,54 @@ func NewAdminService() *AdminService {
 	return &AdminService{}
 }
 
-// GetPendingRequests obtém todas as solicitações pendentes
-func (s *AdminService) GetPendingRequests() ([]models.PendingRequestResponseDTO, error) {
+// GetPendingRequests obtém as solicitações pendentes (com scope, só as atribuídas ao contabilista ou de empresas da carteira).
+// verified filtra pelo estado da verificação do email ("true", "false" ou vazio para todas).
+func (s *AdminService) GetPendingRequests(verified string, scope *PortfolioScope) ([]models.PendingRequestResponseDTO, error) {
 	var requests []models.RegistrationRequest
-	if err := config.DB.Where("status = ?", "pending").Find(&requests).Error; err != nil {
+	query := scope.Requests(config.DB.Preload("AssignedToUser")).Where("status = ?", "pending")
+	switch verified {
+	case "true":
+		query = query.Where("email_verified_at IS NOT NULL")
+	case "false":
+		query = query.Where("email_verified_at IS NULL")
+	}
+	if err := query.Find(&requests).Error; err != nil {
 		return nil, errors.New("erro ao obter solicitações pendentes")
 	}
 
 	// Converter para DTO
 	var response []models.PendingRequestResponseDTO
 	for _, req := range requests {
-		dto := models.PendingRequestResponseDTO{
-			ID:           req.ID,
-			RequestType:  req.RequestType,
-			Status:       req.Status,
-			SubmittedAt:  req.SubmittedAt,
-			Username:     req.Username,
-			NIPC:         req.NIPC,
-			LegalForm:    req.LegalForm,
-		}
-		
-		// Campos opcionais do usuário
-		if req.Name != nil {
-			dto.Name = *req.Name
-		}
-		if req.Email != nil {
-			dto.Email = *req.Email
-		}
-		if req.Phone != nil {
-			dto.Phone = *req.Phone
-		}
-		if req.NIF != nil {
-			dto.NIF = *req.NIF
-		}
-		
-		// Campos opcionais da empresa
-		if req.CompanyName != nil {
-			dto.CompanyName = *req.CompanyName
-		}
-		
-		// Campos opcionais da morada fiscal
-		if req.FiscalAddress != nil {
-			dto.FiscalAddress = *req.FiscalAddress
-		}
-		if req.FiscalPostalCode != nil {
-			dto.FiscalPostalCode = *req.FiscalPostalCode
-		}
-		if req.FiscalCity != nil {
-			dto.FiscalCity = *req.FiscalCity
-		}
-		
-		response = append(response, dto)
+		response = append(response, buildPendingRequestDTO(req))
 	}
 
 	return response, nil
 }
 
-// GetAllRequests obtém todos os pedidos de registo
-func (s *AdminService) GetAllRequests() ([]models.RegistrationRequest, error) {
+// GetAllRequests obtém os pedidos de registo (da carteira, com scope)
+func (s *AdminService) GetAllRequests(scope *PortfolioScope) ([]models.RegistrationRequest, error) {
 	var requests []models.RegistrationRequest
-	if err := config.DB.Find(&requests).Error; err != nil {
+	if err := scope.Requests(config.DB).Find(&requests).Error; err != nil {
 		return nil, errors.New("erro ao obter pedidos de registo")
 	}
 	return requests, nil
 }
 
-// GetRequestDetails obtém detalhes completos de um pedido específico
-func (s *AdminService) GetRequestDetails(requestID uint) (*models.RegistrationRequest, error) {
+// GetRequestDetails obtém detalhes completos de um pedido específico. Com scope, os pedidos fora
+// da carteira respondem como inexistentes.
+func (s *AdminService) GetRequestDetails(requestID uint, scope *PortfolioScope) (*models.RegistrationRequest, error) {
 	var request models.RegistrationRequest
-	if err := config.DB.Preload("ReviewedByUser").First(&request, requestID).Error; err != nil {
+	if err := scope.Requests(config.DB.Preload("ReviewedByUser").Preload("AssignedToUser")).First(&request, requestID).Error; err != nil {
 		return nil, errors.New("pedido não encontrado")
 	}
+
+	// Prováveis duplicados para o revisor ver antes de aprovar
+	if request.UserID == nil {
+		if report, err := NewDuplicateService().AnalyzeRequest(&request); err == nil {
+			request.DuplicateReport = report
+		}
+	}
+
 	return &request, nil
 }
 
@@ -100,6 +85,51 @@ func (s *AdminService) ApproveRequest(req models.ApprovalRequestDTO, reviewerID
 		return nil, errors.New("solicitação já foi processada")
 	}
 
+	// Só é possível aprovar ou pedir informação depois de o cliente verificar o email; rejeitar é sempre possível
+	if req.Status != "rejected" && !emailVerifiedOrAbsent(&request) {
+		return nil, errEmailNotVerified
+	}
+
+	// Verificar se a solicitação não está atribuída a outro contabilista
+	if err := NewReviewQueueService().EnsureCanReview(&request, reviewerID); err != nil {
+		return nil, err
+	}
+	if request.AssignedTo == nil {
+		// Reclamar com atualização condicional, como em ClaimRequest, para que dois revisores não decidam a mesma solicitação
+		now := time.Now()
+		result := config.DB.Model(&models.RegistrationRequest{}).
+			Where("id = ? AND status = ? AND (assigned_to IS NULL OR assigned_to = ?)", request.ID, "pending", reviewerID).
+			Updates(map[string]interface{}{"assigned_to": reviewerID, "assigned_at": now})
+		if result.Error != nil {
+			return nil, errors.New("erro ao reclamar solicitação")
+		}
+		if result.RowsAffected == 0 {
+			var current models.RegistrationRequest
+			if err := config.DB.Select("id", "status").First(&current, request.ID).Error; err == nil && current.Status != "pending" {
+				return nil, errors.New("solicitação já foi processada")
+			}
+			return nil, errors.New("solicitação atribuída a outro contabilista")
+		}
+		request.AssignedTo = &reviewerID
+		request.AssignedAt = &now
+		NewReviewQueueService().recordHistory(request.ID, models.AssignmentActionClaim, nil, &reviewerID, &reviewerID, "", "")
+	}
+
+	// Pedido de mais informação: a solicitação continua pendente
+	if req.Status == "needs_info" {
+		if req.ReviewNotes == "" {
+			return nil, errors.New("indique a informação em falta nas notas")
+		}
+		request.ReviewNotes = req.ReviewNotes
+		if err := config.DB.Save(&request).Error; err != nil {
+			return nil, errors.New("erro ao salvar alterações na solicitação")
+		}
+		if err := NewNotificationService().NotifyRequest(models.EventRequestNeedsInfo, &request, nil); err != nil {
+			log.Printf("⚠️  Erro ao notificar pedido de informação da solicitação %d: %v", request.ID, err)
+		}
+		return &request, nil
+	}
+
 	// Atualizar dados de review
 	now := time.Now()
 	request.Status = req.Status
@@ -109,7 +139,7 @@ func (s *AdminService) ApproveRequest(req models.ApprovalRequestDTO, reviewerID
 
 	// Se aprovado, criar User e Company
 	if req.Status == "approved" {
-		userID, companyID, err := s.createUserAndCompany(request)
+		userID, companyID, err := s.createUserAndCompany(config.DB, request)
 		if err != nil {
 			return nil, err
 		}
@@ -124,14 +154,34 @@ func (s *AdminService) ApproveRequest(req models.ApprovalRequestDTO, reviewerID
 		return nil, errors.New("erro ao salvar alterações na solicitação")
 	}
 
+	event := models.EventRequestRejected
+	if request.Status == "approved" {
+		event = models.EventRequestApproved
+	}
+	if request.Status == "approved" && request.RequestType == "existing_client" {
+		// Clientes migrados não escolheram password: recebem o link de ativação em vez da aprovação
+		var user models.User
+		if err := config.DB.First(&user, *request.UserID).Error; err == nil {
+			if err := NewActivationService().SendActivation(&user); err != nil {
+				log.Printf("⚠️  Erro ao enviar ativação da solicitação %d: %v", request.ID, err)
+			}
+		}
+	} else if err := NewNotificationService().NotifyRequest(event, &request, nil); err != nil {
+		log.Printf("⚠️  Erro ao notificar decisão da solicitação %d: %v", request.ID, err)
+	}
+	if request.UserID != nil {
+		NewInboxService().Notify(*request.UserID, models.EventRequestApproved, "Registo aprovado",
+			"O seu pedido de registo foi aprovado. Bem-vindo à RV Contabilidade!", "/api/client/profile")
+	}
+
 	return &request, nil
 }
 
-// GetAllUsers obtém todos os utilizadores com filtros
-func (s *AdminService) GetAllUsers(status, role string) ([]models.User, map[string]interface{}, error) {
+// GetAllUsers obtém todos os utilizadores com filtros. Com scope, os clientes são só os da carteira.
+func (s *AdminService) GetAllUsers(status, role string, scope *PortfolioScope) ([]models.User, map[string]interface{}, error) {
 	var users []models.User
 	
-	query := config.DB
+	query := scope.Users(config.DB.Model(&models.User{}), "users")
 	
 	// Aplicar filtros
 	if status != "" {
@@ -146,14 +196,9 @@ func (s *AdminService) GetAllUsers(status, role string) ([]models.User, map[stri
 		return nil, nil, errors.New("erro ao obter utilizadores")
 	}
 
-	// Para cada usuário, buscar a empresa separadamente
-	for i := range users {
-		if users[i].Role == "client" {
-			var company models.Company
-			if config.DB.Where("user_id = ?", users[i].ID).First(&company).Error == nil {
-				users[i].Company = &company
-			}
-		}
+	// Empresas dos clientes, através de user_companies
+	if err := attachUserCompanies(users, scope); err != nil {
+		return nil, nil, err
 	}
 
 	// Calcular estatísticas
@@ -169,31 +214,37 @@ func (s *AdminService) GetUserDetails(userID uint) (*models.User, error) {
 		return nil, errors.New("utilizador não encontrado")
 	}
 
-	// Buscar empresa se for cliente
-	if user.Role == "client" {
-		var company models.Company
-		if config.DB.Where("user_id = ?", userID).First(&company).Error == nil {
-			user.Company = &company
+	// Empresas se for cliente
+	if user.Role == models.RoleClient {
+		if err := attachUserCompany(&user); err != nil {
+			return nil, err
 		}
 	}
 
 	return &user, nil
 }
 
-// GetApprovedClients obtém todos os clientes aprovados
-func (s *AdminService) GetApprovedClients() ([]models.User, error) {
+// GetApprovedClients obtém os clientes aprovados (da carteira, com scope). search procura no nome,
+// email, username e NIF do cliente e no nome e NIPC da empresa.
+func (s *AdminService) GetApprovedClients(search string, scope *PortfolioScope) ([]models.User, error) {
 	var users []models.User
 	
-	if err := config.DB.Where("role = ? AND status = ?", "client", "approved").Find(&users).Error; err != nil {
+	query := scope.Clients(config.DB.Where("role = ? AND status = ?", models.RoleClient, "approved"), "users.id")
+	if search = strings.TrimSpace(search); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("(users.name ILIKE ? OR users.email ILIKE ? OR users.username ILIKE ? OR users.nif LIKE ? OR users.id IN (?))",
+			like, like, like, like,
+			config.DB.Model(&models.UserCompany{}).Select("user_companies.user_id").
+				Joins("JOIN companies ON companies.id = user_companies.company_id").
+				Where("companies.company_name ILIKE ? OR companies.nipc LIKE ?", like, like))
+	}
+	if err := query.Order("users.name ASC").Find(&users).Error; err != nil {
 		return nil, errors.New("erro ao obter clientes aprovados")
 	}
 
-	// Para cada cliente, buscar a empresa
-	for i := range users {
-		var company models.Company
-		if config.DB.Where("user_id = ?", users[i].ID).First(&company).Error == nil {
-			users[i].Company = &company
-		}
+	// Empresas de cada cliente (com scope, só as da carteira)
+	if err := attachUserCompanies(users, scope); err != nil {
+		return nil, err
 	}
 
 	return users, nil
@@ -217,10 +268,10 @@ func (s *AdminService) GetUsersCount() (map[string]int64, error) {
 	counts["pending"] = pending
 	
 	// Contar por role
-	config.DB.Model(&models.User{}).Where("role = ?", "client").Count(&clients)
+	config.DB.Model(&models.User{}).Where("role = ?", models.RoleClient).Count(&clients)
 	counts["clients"] = clients
 	
-	config.DB.Model(&models.User{}).Where("role IN ?", []string{"admin", "accountant"}).Count(&admins)
+	config.DB.Model(&models.User{}).Where("role <> ?", models.RoleClient).Count(&admins) // Equipa, incluindo perfis personalizados
 	counts["admins"] = admins
 
 	return counts, nil
@@ -228,12 +279,24 @@ func (s *AdminService) GetUsersCount() (map[string]int64, error) {
 
 // ===== MÉTODOS PRIVADOS =====
 
-func (s *AdminService) createUserAndCompany(request models.RegistrationRequest) (uint, uint, error) {
+func (s *AdminService) createUserAndCompany(db *gorm.DB, request models.RegistrationRequest) (uint, uint, error) {
+	// Verificar campos únicos antes de criar qualquer registo
+	var existingUser models.User
+	if db.Where("username = ?", request.Username).First(&existingUser).Error == nil {
+		return 0, 0, errors.New("username já está em uso")
+	}
+	if request.NIPC != "" {
+		var existingCompany models.Company
+		if db.Where("nipc = ?", request.NIPC).First(&existingCompany).Error == nil {
+			return 0, 0, errors.New("já existe uma empresa com este NIPC")
+		}
+	}
+
 	// Criar User
 	user := models.User{
 		Username:            request.Username,
 		Password:            request.PasswordHash,
-		Role:                "client",
+		Role:                models.RoleClient,
 		Status:              "approved",
 		TaxResidenceCountry: "Portugal",
 	}
@@ -307,18 +370,10 @@ func (s *AdminService) createUserAndCompany(request models.RegistrationRequest)
 	}
 
 	// Salvar User
-	if err := config.DB.Create(&user).Error; err != nil {
+	if err := db.Create(&user).Error; err != nil {
 		return 0, 0, errors.New("erro ao criar utilizador: " + err.Error())
 	}
 
-	// Verificar se já existe empresa com este NIPC
-	if request.NIPC != "" {
-		var existingCompany models.Company
-		if config.DB.Where("nipc = ?", request.NIPC).First(&existingCompany).Error == nil {
-			return 0, 0, errors.New("já existe uma empresa com este NIPC")
-		}
-	}
-
 	// Criar Company
 	company := models.Company{
 		UserID:    user.ID,
@@ -411,12 +466,22 @@ func (s *AdminService) createUserAndCompany(request models.RegistrationRequest)
 	}
 
 	// Salvar Company
-	if err := config.DB.Create(&company).Error; err != nil {
+	if err := db.Create(&company).Error; err != nil {
 		// Se falhar, eliminar o utilizador criado
-		config.DB.Delete(&user)
+		db.Delete(&user)
 		return 0, 0, errors.New("erro ao criar empresa: " + err.Error())
 	}
 
+	// O cliente fica como titular da empresa
+	if err := db.Create(&models.UserCompany{UserID: user.ID, CompanyID: company.ID, Role: models.CompanyRoleOwner}).Error; err != nil {
+		return 0, 0, errors.New("erro ao ligar o utilizador à empresa")
+	}
+
+	// A empresa entra na carteira do contabilista responsável pela solicitação
+	if err := NewPortfolioService().assignFromRequest(db, company.ID, request); err != nil {
+		return 0, 0, errors.New("erro ao atribuir empresa à carteira")
+	}
+
 	return user.ID, company.ID, nil
 }
 
@@ -426,16 +491,16 @@ func (s *AdminService) calculateUserStats() map[string]interface{} {
 	var totalClients, approvedClients, pendingClients, rejectedClients, blockedClients, clientsWithCompany int64
 
 	// Contar clientes por status
-	config.DB.Model(&models.User{}).Where("role = ?", "client").Count(&totalClients)
-	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", "client", "approved").Count(&approvedClients)
-	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", "client", "pending").Count(&pendingClients)
-	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", "client", "rejected").Count(&rejectedClients)
-	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", "client", "blocked").Count(&blockedClients)
+	config.DB.Model(&models.User{}).Where("role = ?", models.RoleClient).Count(&totalClients)
+	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", models.RoleClient, "approved").Count(&approvedClients)
+	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", models.RoleClient, "pending").Count(&pendingClients)
+	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", models.RoleClient, "rejected").Count(&rejectedClients)
+	config.DB.Model(&models.User{}).Where("role = ? AND status = ?", models.RoleClient, "blocked").Count(&blockedClients)
 	
 	// Contar clientes com empresa
 	config.DB.Table("users").
 		Joins("JOIN companies ON companies.user_id = users.id").
-		Where("users.role = ?", "client").
+		Where("users.role = ?", models.RoleClient).
 		Count(&clientsWithCompany)
 
 	stats["total_clients"] = totalClients
@@ -448,29 +513,85 @@ func (s *AdminService) calculateUserStats() map[string]interface{} {
 	return stats
 }
 
+// CreateUser cria diretamente um utilizador já aprovado. Contabilistas só podem criar clientes;
+// só admins criam contabilistas e outros admins. Perfis personalizados exigem users.manage e
+// todas as permissões do perfil (ver CanAssignRole). Cada criação fica no registo de auditoria.
+func (s *AdminService) CreateUser(req models.CreateUserDTO, creatorID uint, creatorRole, ip string) (*models.User, error) {
+	if !NewPermissionService().CanAssignRole(creatorID, creatorRole, req.Role) {
+		return nil, errors.New("sem permissão para criar utilizadores com o perfil " + req.Role)
+	}
+	if err := NewAuthService().checkUserDuplicates(req.Username, req.Email, req.NIF); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("erro ao processar password")
+	}
+
+	user := models.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: string(hashedPassword),
+		Name:     req.Name,
+		Phone:    req.Phone,
+		NIF:      req.NIF,
+		Role:     req.Role,
+		Status:   string(models.StatusApproved),
+	}
+	if err := config.DB.Create(&user).Error; err != nil {
+		return nil, errors.New("erro ao criar utilizador")
+	}
+
+	NewAuditService().Record(creatorID, models.AuditActionUserCreate, "user", &user.ID, map[string]interface{}{
+		"username": user.Username,
+		"role":     user.Role,
+	}, ip)
+
+	return &user, nil
+}
+
+// CanCreateRole indica se um utilizador com o perfil creatorRole pode criar contas com o perfil role
+func CanCreateRole(creatorRole, role string) bool {
+	switch creatorRole {
+	case models.RoleAdmin:
+		return role == models.RoleClient || role == models.RoleAccountant || role == models.RoleAdmin
+	case models.RoleAccountant:
+		return role == models.RoleClient
+	}
+	return false
+}
+
 // UpdateUserStatus atualiza o status de um utilizador
-func (s *AdminService) UpdateUserStatus(userID uint, newStatus string) (*models.User, error) {
+func (s *AdminService) UpdateUserStatus(userID uint, newStatus, notes string) (*models.User, error) {
 	var user models.User
 	if err := config.DB.First(&user, userID).Error; err != nil {
 		return nil, errors.New("utilizador não encontrado")
 	}
 
+	previousStatus := user.Status
 	user.Status = newStatus
 	if err := config.DB.Save(&user).Error; err != nil {
 		return nil, errors.New("erro ao atualizar utilizador")
 	}
 
+	if newStatus != previousStatus {
+		s.notifyStatusChanged(&user, notes)
+	}
+
 	return &user, nil
 }
 
 // UpdateClientData atualiza dados pessoais de um cliente
-func (s *AdminService) UpdateClientData(clientID uint, req models.AdminUpdateClientDTO) error {
+func (s *AdminService) UpdateClientData(clientID uint, req models.AdminUpdateClientDTO, editorID uint) error {
 	// Verificar se o cliente existe e é cliente aprovado
 	var client models.User
-	if err := config.DB.Where("id = ? AND role = ? AND status = ?", clientID, "client", "approved").First(&client).Error; err != nil {
+	if err := config.DB.Where("id = ? AND role = ? AND status = ?", clientID, models.RoleClient, "approved").First(&client).Error; err != nil {
 		return errors.New("cliente aprovado não encontrado")
 	}
 
+	previousStatus := client.Status
+
 	// Atualizar apenas os campos fornecidos
 	updateData := make(map[string]interface{})
 	
@@ -540,21 +661,72 @@ func (s *AdminService) UpdateClientData(clientID uint, req models.AdminUpdateCli
 		return errors.New("erro ao atualizar dados do cliente")
 	}
 
+	// Avisar o cliente das alterações feitas pela contabilista
+	if req.Status != nil {
+		delete(updateData, "status")
+		if *req.Status != previousStatus {
+			client.Status = *req.Status
+			s.notifyStatusChanged(&client, "")
+		}
+	}
+	if len(updateData) > 0 && editorID != clientID {
+		fields := make([]string, 0, len(updateData))
+		for field := range updateData {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		NewInboxService().Notify(client.ID, models.EventProfileUpdated, "Dados pessoais atualizados",
+			"A contabilista atualizou os seguintes dados: "+strings.Join(fields, ", "), "/api/client/profile")
+	}
+
 	return nil
 }
 
-// UpdateClientCompany atualiza dados da empresa de um cliente
-func (s *AdminService) UpdateClientCompany(clientID uint, req models.AdminUpdateCompanyDTO) (*models.Company, error) {
+// notifyStatusChanged avisa o utilizador de uma alteração de status (caixa de entrada e, se bloqueado, email)
+func (s *AdminService) notifyStatusChanged(user *models.User, notes string) {
+	message := "O status da sua conta foi alterado para " + user.Status + "."
+	if notes != "" {
+		message += " Motivo: " + notes
+	}
+	NewInboxService().Notify(user.ID, models.EventStatusChanged, "Status da conta alterado", message, "/api/profile")
+
+	if user.Status != "blocked" || user.Email == "" {
+		return
+	}
+	if err := NewNotificationService().NotifyUser(models.EventAccountBlocked, user, map[string]interface{}{"Notes": notes}); err != nil {
+		log.Printf("⚠️  Erro ao notificar bloqueio do utilizador %d: %v", user.ID, err)
+	}
+}
+
+// UpdateClientCompany atualiza dados de uma empresa do cliente (companyID 0 = empresa principal)
+func (s *AdminService) UpdateClientCompany(clientID, companyID uint, req models.AdminUpdateCompanyDTO, role string) (*models.Company, error) {
 	// Verificar se o cliente existe e é cliente aprovado
 	var client models.User
-	if err := config.DB.Where("id = ? AND role = ? AND status = ?", clientID, "client", "approved").First(&client).Error; err != nil {
+	if err := config.DB.Where("id = ? AND role = ? AND status = ?", clientID, models.RoleClient, "approved").First(&client).Error; err != nil {
 		return nil, errors.New("cliente aprovado não encontrado")
 	}
 
 	// Encontrar a empresa do cliente
 	var company models.Company
-	if err := config.DB.Where("user_id = ?", clientID).First(&company).Error; err != nil {
-		return nil, errors.New("empresa do cliente não encontrada")
+	if companyID == 0 {
+		primary, err := NewCompanyService().GetCompanyByUserID(clientID)
+		if err != nil {
+			return nil, errors.New("empresa do cliente não encontrada")
+		}
+		company = *primary
+	} else {
+		link, err := NewCompanyService().GetUserCompany(clientID, companyID)
+		if err != nil {
+			return nil, errors.New("empresa do cliente não encontrada")
+		}
+		company = link.Company
+	}
+
+	// Os dados financeiros não podem mudar enquanto o período corrente estiver fechado
+	if companyFinancialFieldsChanged(&company, req) {
+		if err := NewFiscalPeriodService().EnsureWritable(company.ID, time.Now(), role); err != nil {
+			return nil, err
+		}
 	}
 
 	// Atualizar apenas os campos fornecidos
@@ -639,115 +811,221 @@ func (s *AdminService) UpdateClientCompany(clientID uint, req models.AdminUpdate
 	return &company, nil
 }
 
-// DeleteClient elimina um cliente e a sua empresa
-func (s *AdminService) DeleteClient(clientID uint) error {
+// DeleteClient elimina um cliente. As empresas em que é o único utilizador ligado são eliminadas com
+// todos os dados; nas que têm outros titulares ou gerentes, só lhe é retirado o acesso.
+// Empresas com faturas emitidas, lançamentos ou períodos fechados não podem ser eliminadas: nesse
+// caso nada é apagado e o cliente deve ser bloqueado. A eliminação fica no registo de auditoria.
+func (s *AdminService) DeleteClient(clientID, deletedBy uint, ip string) error {
 	// Verificar se o cliente existe e é cliente
 	var client models.User
-	if err := config.DB.Where("id = ? AND role = ?", clientID, "client").First(&client).Error; err != nil {
+	if err := config.DB.Where("id = ? AND role = ?", clientID, models.RoleClient).First(&client).Error; err != nil {
 		return errors.New("cliente não encontrado")
 	}
 
-	// Iniciar transação para eliminar cliente e empresa
-	tx := config.DB.Begin()
+	// Empresas em que o cliente é a última ligação
+	var removedIDs []uint
+	if err := config.DB.Model(&models.UserCompany{}).
+		Where("company_id IN (?)", userCompanyIDs(clientID)).
+		Group("company_id").Having("COUNT(*) = 1").
+		Pluck("company_id", &removedIDs).Error; err != nil {
+		return errors.New("erro ao obter empresas do cliente")
+	}
+	if err := ensureCompaniesDeletable(removedIDs); err != nil {
+		return err
+	}
+	var unlinkedIDs []uint
+	config.DB.Model(&models.UserCompany{}).
+		Where("user_id = ? AND company_id NOT IN ?", clientID, append([]uint{0}, removedIDs...)).
+		Pluck("company_id", &unlinkedIDs)
+
+	// Ficheiros guardados das empresas eliminadas, apagados depois da transação
+	var storageKeys []string
+	if len(removedIDs) > 0 {
+		config.DB.Model(&models.Document{}).Where("company_id IN ?", removedIDs).Pluck("storage_key", &storageKeys)
+		var saftKeys []string
+		config.DB.Model(&models.SAFTImport{}).Where("company_id IN ? AND storage_key <> ''", removedIDs).Pluck("storage_key", &saftKeys)
+		storageKeys = append(storageKeys, saftKeys...)
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Empresas partilhadas: retirar o acesso do cliente e manter um titular
+		var links []models.UserCompany
+		if err := tx.Where("user_id = ?", clientID).Find(&links).Error; err != nil {
+			return errors.New("erro ao obter empresas do cliente")
+		}
+		if err := tx.Where("user_id = ?", clientID).Delete(&models.UserCompany{}).Error; err != nil {
+			return errors.New("erro ao retirar acessos do cliente")
+		}
+		removed := make(map[uint]bool, len(removedIDs))
+		for _, id := range removedIDs {
+			removed[id] = true
+		}
+		for _, link := range links {
+			if removed[link.CompanyID] {
+				continue
+			}
+			if err := keepCompanyOwner(tx, link.CompanyID, clientID); err != nil {
+				return err
+			}
+		}
 
-	// Eliminar empresa(s) do cliente
-	if err := tx.Where("user_id = ?", clientID).Delete(&models.Company{}).Error; err != nil {
-		tx.Rollback()
-		return errors.New("erro ao eliminar empresa do cliente")
+		if len(removedIDs) > 0 {
+			if err := deleteCompaniesData(tx, removedIDs); err != nil {
+				return err
+			}
+		}
+
+		// Eliminar cliente
+		if err := tx.Delete(&client).Error; err != nil {
+			return errors.New("erro ao eliminar cliente")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	storage := utils.GetStorage()
+	for _, key := range storageKeys {
+		storage.Delete(key)
+	}
+
+	NewAuditService().Record(deletedBy, models.AuditActionClientDelete, "user", &clientID, map[string]interface{}{
+		"name":                 client.Name,
+		"email":                client.Email,
+		"deleted_company_ids":  removedIDs,
+		"unlinked_company_ids": unlinkedIDs,
+	}, ip)
+	return nil
+}
+
+// ensureCompaniesDeletable recusa eliminar empresas com faturas certificadas emitidas (séries e cadeia de
+// hash), lançamentos contabilísticos ou períodos fechados, que têm de ser conservados
+func ensureCompaniesDeletable(companyIDs []uint) error {
+	for _, companyID := range companyIDs {
+		var invoices, entries, closed int64
+		config.DB.Model(&models.Invoice{}).Where("company_id = ?", companyID).Count(&invoices)
+		config.DB.Model(&models.JournalEntry{}).Where("company_id = ?", companyID).Count(&entries)
+		config.DB.Model(&models.FiscalPeriod{}).Where("company_id = ? AND status <> ?", companyID, models.PeriodStatusOpen).Count(&closed)
+		if invoices == 0 && entries == 0 && closed == 0 {
+			continue
+		}
+
+		var company models.Company
+		config.DB.Select("id", "company_name").First(&company, companyID)
+		return fmt.Errorf("o cliente não pode ser eliminado: a empresa %s tem faturas emitidas, lançamentos ou períodos fechados; bloqueie o cliente em vez de o eliminar", company.CompanyName)
 	}
+	return nil
+}
 
-	// Eliminar cliente
-	if err := tx.Delete(&client).Error; err != nil {
-		tx.Rollback()
-		return errors.New("erro ao eliminar cliente")
+// keepCompanyOwner garante que uma empresa de que o cliente eliminado era titular continua com um owner
+// (promovendo o acesso mais antigo) e passa o registo da empresa para esse titular
+func keepCompanyOwner(tx *gorm.DB, companyID, removedUserID uint) error {
+	var owner models.UserCompany
+	err := tx.Where("company_id = ? AND role = ?", companyID, models.CompanyRoleOwner).Order("id ASC").First(&owner).Error
+	if err == gorm.ErrRecordNotFound {
+		if err := tx.Where("company_id = ?", companyID).Order("id ASC").First(&owner).Error; err != nil {
+			return errors.New("erro ao obter acessos da empresa")
+		}
+		if err := tx.Model(&owner).Update("role", models.CompanyRoleOwner).Error; err != nil {
+			return errors.New("erro ao atualizar acessos da empresa")
+		}
+	} else if err != nil {
+		return errors.New("erro ao obter acessos da empresa")
 	}
 
-	tx.Commit()
+	if err := tx.Model(&models.Company{}).Where("id = ? AND user_id = ?", companyID, removedUserID).
+		Update("user_id", owner.UserID).Error; err != nil {
+		return errors.New("erro ao atualizar empresa")
+	}
+	return nil
+}
+
+// deleteCompaniesData elimina as empresas indicadas e todos os registos que dependem delas
+func deleteCompaniesData(tx *gorm.DB, companyIDs []uint) error {
+	companyRows := func(model interface{}) *gorm.DB {
+		return tx.Model(model).Select("id").Where("company_id IN ?", companyIDs)
+	}
+
+	// Linhas que dependem de outros registos da empresa
+	if err := tx.Where("import_id IN (?)", companyRows(&models.SAFTImport{})).Delete(&models.SAFTImportError{}).Error; err != nil {
+		return errors.New("erro ao eliminar dados da empresa do cliente")
+	}
+	if err := tx.Where("entry_id IN (?)", companyRows(&models.JournalEntry{})).Delete(&models.JournalEntryLine{}).Error; err != nil {
+		return errors.New("erro ao eliminar dados da empresa do cliente")
+	}
+	if err := tx.Where("invoice_id IN (?)", companyRows(&models.Invoice{})).Delete(&models.InvoiceLine{}).Error; err != nil {
+		return errors.New("erro ao eliminar dados da empresa do cliente")
+	}
+
+	// Registos da empresa, primeiro os que referem outros
+	for _, model := range []interface{}{
+		&models.SAFTSalesSummary{}, &models.SAFTImport{},
+		&models.ReconciliationMatch{}, &models.SupplierMatchRule{},
+		&models.BankTransaction{}, &models.BankStatementImport{},
+		&models.PurchaseInvoice{}, &models.EFaturaImport{},
+		&models.JournalEntry{}, &models.FiscalPeriodHistory{}, &models.FiscalPeriod{}, &models.FiscalYear{},
+		&models.Journal{}, &models.Account{},
+		&models.Invoice{}, &models.InvoiceSeries{}, &models.Customer{},
+		&models.Document{}, &models.ChecklistItem{}, &models.TaxObligation{},
+		&models.CompanyAccountant{}, &models.UserCompany{},
+	} {
+		if err := tx.Where("company_id IN ?", companyIDs).Delete(model).Error; err != nil {
+			return errors.New("erro ao eliminar dados da empresa do cliente")
+		}
+	}
+	if err := tx.Where("id IN ?", companyIDs).Delete(&models.Company{}).Error; err != nil {
+		return errors.New("erro ao eliminar empresa do cliente")
+	}
 	return nil
 }
 
-// GetAllUsersSimple obtém dados básicos dos utilizadores
-func (s *AdminService) GetAllUsersSimple() ([]models.User, error) {
+// GetAllUsersSimple obtém dados básicos dos utilizadores (equipa e clientes da carteira, com scope)
+func (s *AdminService) GetAllUsersSimple(scope *PortfolioScope) ([]models.User, error) {
 	var users []models.User
 	
 	// Buscar apenas os dados básicos dos usuários
-	if err := config.DB.Select("id, username, name, email, role, status, created_at").Find(&users).Error; err != nil {
+	if err := scope.Users(config.DB.Select("id, username, name, email, role, status, created_at"), "users").Find(&users).Error; err != nil {
 		return nil, errors.New("erro ao obter utilizadores: " + err.Error())
 	}
 
 	return users, nil
 }
 
-// GetDashboardData obtém dados resumidos para o dashboard
-func (s *AdminService) GetDashboardData() (*models.DashboardDataDTO, error) {
+// GetDashboardData obtém dados resumidos para o dashboard (da carteira, com scope)
+func (s *AdminService) GetDashboardData(scope *PortfolioScope) (*models.DashboardDataDTO, error) {
 	var dashboardData models.DashboardDataDTO
 	
 	// Contar solicitações pendentes
 	var pendingCount int64
-	if err := config.DB.Model(&models.RegistrationRequest{}).Where("status = ?", "pending").Count(&pendingCount).Error; err != nil {
+	if err := scope.Requests(config.DB.Model(&models.RegistrationRequest{})).Where("status = ?", "pending").Count(&pendingCount).Error; err != nil {
 		return nil, errors.New("erro ao contar solicitações pendentes")
 	}
 	dashboardData.TotalPendingRequests = int(pendingCount)
 	
 	// Contar clientes aprovados
 	var approvedCount int64
-	if err := config.DB.Model(&models.User{}).Where("role = ? AND status = ?", "client", "approved").Count(&approvedCount).Error; err != nil {
+	if err := scope.Users(config.DB.Model(&models.User{}), "users").Where("role = ? AND status = ?", models.RoleClient, "approved").Count(&approvedCount).Error; err != nil {
 		return nil, errors.New("erro ao contar clientes aprovados")
 	}
 	dashboardData.TotalApprovedClients = int(approvedCount)
 	
 	// Contar solicitações rejeitadas
 	var rejectedCount int64
-	if err := config.DB.Model(&models.RegistrationRequest{}).Where("status = ?", "rejected").Count(&rejectedCount).Error; err != nil {
+	if err := scope.Requests(config.DB.Model(&models.RegistrationRequest{})).Where("status = ?", "rejected").Count(&rejectedCount).Error; err != nil {
 		return nil, errors.New("erro ao contar solicitações rejeitadas")
 	}
 	dashboardData.TotalRejectedRequests = int(rejectedCount)
 	
 	// Obter solicitações pendentes recentes (últimas 10)
 	var recentRequests []models.RegistrationRequest
-	if err := config.DB.Where("status = ?", "pending").Order("submitted_at DESC").Limit(10).Find(&recentRequests).Error; err != nil {
+	if err := scope.Requests(config.DB.Preload("AssignedToUser")).Where("status = ?", "pending").Order("submitted_at DESC").Limit(10).Find(&recentRequests).Error; err != nil {
 		return nil, errors.New("erro ao obter solicitações recentes")
 	}
 	
 	// Converter para DTO
 	for _, req := range recentRequests {
-		dto := models.PendingRequestResponseDTO{
-			ID:           req.ID,
-			RequestType:  req.RequestType,
-			Status:       req.Status,
-			SubmittedAt:  req.SubmittedAt,
-			Username:     req.Username,
-			NIPC:         req.NIPC,
-			LegalForm:    req.LegalForm,
-		}
-		
-		// Campos opcionais
-		if req.Name != nil {
-			dto.Name = *req.Name
-		}
-		if req.Email != nil {
-			dto.Email = *req.Email
-		}
-		if req.Phone != nil {
-			dto.Phone = *req.Phone
-		}
-		if req.NIF != nil {
-			dto.NIF = *req.NIF
-		}
-		if req.CompanyName != nil {
-			dto.CompanyName = *req.CompanyName
-		}
-		if req.FiscalAddress != nil {
-			dto.FiscalAddress = *req.FiscalAddress
-		}
-		if req.FiscalPostalCode != nil {
-			dto.FiscalPostalCode = *req.FiscalPostalCode
-		}
-		if req.FiscalCity != nil {
-			dto.FiscalCity = *req.FiscalCity
-		}
-		
-		dashboardData.RecentPendingRequests = append(dashboardData.RecentPendingRequests, dto)
+		dashboardData.RecentPendingRequests = append(dashboardData.RecentPendingRequests, buildPendingRequestDTO(req))
 	}
 	
 	// Estatísticas do mês atual
@@ -756,21 +1034,21 @@ func (s *AdminService) GetDashboardData() (*models.DashboardDataDTO, error) {
 	
 	// Novas solicitações neste mês
 	var monthlyNew int64
-	if err := config.DB.Model(&models.RegistrationRequest{}).Where("submitted_at >= ?", startOfMonth).Count(&monthlyNew).Error; err != nil {
+	if err := scope.Requests(config.DB.Model(&models.RegistrationRequest{})).Where("submitted_at >= ?", startOfMonth).Count(&monthlyNew).Error; err != nil {
 		return nil, errors.New("erro ao contar solicitações mensais")
 	}
 	dashboardData.MonthlyStats.NewRequests = int(monthlyNew)
 	
 	// Aprovações neste mês
 	var monthlyApproved int64
-	if err := config.DB.Model(&models.RegistrationRequest{}).Where("status = ? AND reviewed_at >= ?", "approved", startOfMonth).Count(&monthlyApproved).Error; err != nil {
+	if err := scope.Requests(config.DB.Model(&models.RegistrationRequest{})).Where("status = ? AND reviewed_at >= ?", "approved", startOfMonth).Count(&monthlyApproved).Error; err != nil {
 		return nil, errors.New("erro ao contar aprovações mensais")
 	}
 	dashboardData.MonthlyStats.ApprovedClients = int(monthlyApproved)
 	
 	// Rejeições neste mês
 	var monthlyRejected int64
-	if err := config.DB.Model(&models.RegistrationRequest{}).Where("status = ? AND reviewed_at >= ?", "rejected", startOfMonth).Count(&monthlyRejected).Error; err != nil {
+	if err := scope.Requests(config.DB.Model(&models.RegistrationRequest{})).Where("status = ? AND reviewed_at >= ?", "rejected", startOfMonth).Count(&monthlyRejected).Error; err != nil {
 		return nil, errors.New("erro ao contar rejeições mensais")
 	}
 	dashboardData.MonthlyStats.RejectedRequests = int(monthlyRejected)
@@ -778,12 +1056,12 @@ func (s *AdminService) GetDashboardData() (*models.DashboardDataDTO, error) {
 	return &dashboardData, nil
 }
 
-// GetAllClientsOverview obtém visão geral de todos os clientes
-func (s *AdminService) GetAllClientsOverview() (*models.ClientsOverviewDTO, error) {
+// GetAllClientsOverview obtém visão geral dos clientes (da carteira, com scope)
+func (s *AdminService) GetAllClientsOverview(scope *PortfolioScope) (*models.ClientsOverviewDTO, error) {
 	var overview models.ClientsOverviewDTO
 	
 	// Obter clientes pendentes
-	pendingRequests, err := s.GetPendingRequests()
+	pendingRequests, err := s.GetPendingRequests("", scope)
 	if err != nil {
 		return nil, err
 	}
@@ -791,55 +1069,61 @@ func (s *AdminService) GetAllClientsOverview() (*models.ClientsOverviewDTO, erro
 	
 	// Obter clientes aprovados
 	var approvedUsers []models.User
-	if err := config.DB.Where("role = ? AND status = ?", "client", "approved").Find(&approvedUsers).Error; err != nil {
+	if err := scope.Clients(config.DB.Where("role = ? AND status = ?", models.RoleClient, "approved"), "users.id").Find(&approvedUsers).Error; err != nil {
 		return nil, errors.New("erro ao obter clientes aprovados")
 	}
 	
-	// Para cada cliente aprovado, buscar a empresa e converter para resumo
+	if err := attachUserCompanies(approvedUsers, scope); err != nil {
+		return nil, err
+	}
+	
+	// Uma linha por empresa de cada cliente aprovado (ou uma só, sem empresa)
 	for _, user := range approvedUsers {
-		var company models.Company
-		companyName := ""
-		nipc := ""
-		
-		if config.DB.Where("user_id = ?", user.ID).First(&company).Error == nil {
-			companyName = company.CompanyName
-			nipc = company.NIPC
+		companies := user.Companies
+		if len(companies) == 0 {
+			companies = []models.ClientCompanyDTO{{}}
 		}
 		
-		approvedClient := struct {
-			ID          uint   `json:"id" example:"1"`
-			Username    string `json:"username" example:"joao.silva"`
-			Name        string `json:"name" example:"João Silva"`
-			Email       string `json:"email" example:"joao@exemplo.com"`
-			Phone       string `json:"phone" example:"912345678"`
-			NIF         string `json:"nif" example:"123456789"`
-			Status      string `json:"status" example:"approved"`
-			CompanyName string `json:"company_name" example:"Silva & Associados Lda"`
-			NIPC        string `json:"nipc" example:"123456789"`
-			CreatedAt   string `json:"created_at" example:"2024-01-01T00:00:00Z"`
-		}{
-			ID:          user.ID,
-			Username:    user.Username,
-			Name:        user.Name,
-			Email:       user.Email,
-			Phone:       user.Phone,
-			NIF:         user.NIF,
-			Status:      user.Status,
-			CompanyName: companyName,
-			NIPC:        nipc,
-			CreatedAt:   user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		for _, company := range companies {
+			approvedClient := struct {
+				ID          uint   `json:"id" example:"1"`
+				Username    string `json:"username" example:"joao.silva"`
+				Name        string `json:"name" example:"João Silva"`
+				Email       string `json:"email" example:"joao@exemplo.com"`
+				Phone       string `json:"phone" example:"912345678"`
+				NIF         string `json:"nif" example:"123456789"`
+				Status      string `json:"status" example:"approved"`
+				CompanyID   uint   `json:"company_id,omitempty" example:"1"`
+				CompanyName string `json:"company_name" example:"Silva & Associados Lda"`
+				NIPC        string `json:"nipc" example:"123456789"`
+				CompanyRole string `json:"company_role,omitempty" example:"owner"`
+				CreatedAt   string `json:"created_at" example:"2024-01-01T00:00:00Z"`
+			}{
+				ID:          user.ID,
+				Username:    user.Username,
+				Name:        user.Name,
+				Email:       user.Email,
+				Phone:       user.Phone,
+				NIF:         user.NIF,
+				Status:      user.Status,
+				CompanyID:   company.ID,
+				CompanyName: company.CompanyName,
+				NIPC:        company.NIPC,
+				CompanyRole: company.CompanyRole,
+				CreatedAt:   user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			}
+			
+			overview.ApprovedClients = append(overview.ApprovedClients, approvedClient)
 		}
-		
-		overview.ApprovedClients = append(overview.ApprovedClients, approvedClient)
 	}
 	
 	// Calcular estatísticas
 	overview.Stats.TotalPending = len(overview.PendingClients)
-	overview.Stats.TotalApproved = len(overview.ApprovedClients)
+	overview.Stats.TotalApproved = len(approvedUsers)
 	
 	// Contar rejeitados
 	var rejectedCount int64
-	if err := config.DB.Model(&models.RegistrationRequest{}).Where("status = ?", "rejected").Count(&rejectedCount).Error; err != nil {
+	if err := scope.Requests(config.DB.Model(&models.RegistrationRequest{})).Where("status = ?", "rejected").Count(&rejectedCount).Error; err != nil {
 		return nil, errors.New("erro ao contar solicitações rejeitadas")
 	}
 	overview.Stats.TotalRejected = int(rejectedCount)
@@ -847,258 +1131,467 @@ func (s *AdminService) GetAllClientsOverview() (*models.ClientsOverviewDTO, erro
 	return &overview, nil
 }
 
-// GetCompleteUsersOverview obtém todos os dados de todos os utilizadores das 3 tabelas
-func (s *AdminService) GetCompleteUsersOverview() ([]models.CompleteUserOverviewDTO, error) {
+// GetCompleteUsersOverview obtém todos os dados de todos os utilizadores das 3 tabelas.
+// Com scope, só entram os clientes, as solicitações (PortfolioScope.Requests) e os convites (PortfolioScope.Invitations) da carteira.
+func (s *AdminService) GetCompleteUsersOverview(scope *PortfolioScope) ([]models.CompleteUserOverviewDTO, error) {
 	var result []models.CompleteUserOverviewDTO
-	
-	// 1. Buscar todos os users aprovados com companies
+	err := s.EachCompleteUserOverview(scope, 500, func(dto models.CompleteUserOverviewDTO) error {
+		result = append(result, dto)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EachCompleteUserOverview percorre a visão completa por lotes de batchSize registos, sem a carregar toda em memória.
+// Entram primeiro os utilizadores aprovados, depois as solicitações pendentes/rejeitadas sem conta e por fim os convites sem conta.
+func (s *AdminService) EachCompleteUserOverview(scope *PortfolioScope, batchSize int, fn func(models.CompleteUserOverviewDTO) error) error {
+	var fnErr error
+
+	// 1. Users aprovados, com a solicitação e o convite de cada lote
 	var users []models.User
-	if err := config.DB.Preload("Company").Where("status = ?", "approved").Find(&users).Error; err != nil {
-		return nil, errors.New("erro ao obter utilizadores aprovados")
+	result := scope.Users(config.DB.Where("status = ?", "approved"), "users").
+		FindInBatches(&users, batchSize, func(tx *gorm.DB, _ int) error {
+			if err := attachUserCompanies(users, scope); err != nil {
+				return err
+			}
+			userIDs := make([]uint, len(users))
+			for i, user := range users {
+				userIDs[i] = user.ID
+			}
+
+			var requests []models.RegistrationRequest
+			if err := config.DB.Preload("ReviewedByUser").Where("user_id IN ?", userIDs).Order("id ASC").Find(&requests).Error; err != nil {
+				return err
+			}
+			requestsByUserID := make(map[uint]*models.RegistrationRequest)
+			for i := range requests {
+				requestsByUserID[*requests[i].UserID] = &requests[i]
+			}
+
+			var invitations []models.ClientInvitation
+			if err := config.DB.Where("user_id IN ?", userIDs).Order("id DESC").Find(&invitations).Error; err != nil {
+				return err
+			}
+			invitationsByUserID := make(map[uint]*models.ClientInvitation)
+			for i := range invitations {
+				refreshInvitationStatus(&invitations[i])
+				invitationsByUserID[*invitations[i].UserID] = &invitations[i]
+			}
+
+			for _, user := range users {
+				for _, dto := range userOverviewRows(user, requestsByUserID[user.ID]) {
+					if invitation := invitationsByUserID[user.ID]; invitation != nil {
+						applyInvitationOverview(&dto, invitation)
+					}
+					if fnErr = fn(dto); fnErr != nil {
+						return fnErr
+					}
+				}
+			}
+			return nil
+		})
+	if fnErr != nil {
+		return fnErr
 	}
-	
-	// 2. Buscar todas as registration_requests
+	if result.Error != nil {
+		return errors.New("erro ao obter utilizadores aprovados")
+	}
+
+	// 2. Requests pendentes/rejeitadas que não têm user associado
 	var requests []models.RegistrationRequest
-	if err := config.DB.Preload("ReviewedByUser").Find(&requests).Error; err != nil {
-		return nil, errors.New("erro ao obter solicitações de registo")
+	requestQuery := scope.Requests(config.DB.Preload("ReviewedByUser")).Where("user_id IS NULL AND status IN ?", []string{"pending", "rejected"})
+	result = requestQuery.FindInBatches(&requests, batchSize, func(tx *gorm.DB, _ int) error {
+		for i := range requests {
+			if fnErr = fn(buildRequestOverviewDTO(requests[i])); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if result.Error != nil {
+		return errors.New("erro ao obter solicitações de registo")
+	}
+
+	// 3. Convites ainda sem conta (pendentes, expirados ou revogados)
+	var invitations []models.ClientInvitation
+	invitationQuery := scope.Invitations(config.DB).Where("user_id IS NULL")
+	result = invitationQuery.FindInBatches(&invitations, batchSize, func(tx *gorm.DB, _ int) error {
+		for i := range invitations {
+			refreshInvitationStatus(&invitations[i])
+			if fnErr = fn(buildInvitationOverviewDTO(invitations[i])); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if result.Error != nil {
+		return errors.New("erro ao obter convites")
+	}
+	return nil
+}
+
+// buildRequestOverviewDTO monta a visão completa de uma solicitação que ainda não tem utilizador
+func buildRequestOverviewDTO(req models.RegistrationRequest) models.CompleteUserOverviewDTO {
+	dto := models.CompleteUserOverviewDTO{
+		// Identificação
+		Username: req.Username,
+		Status:   req.Status,
+		Role:     models.RoleClient,
+		Source:   "registration_request",
+		
+		// Dados da request
+		RequestID:     &req.ID,
+		RequestType:   &req.RequestType,
+		RequestStatus: &req.Status,
+		SubmittedAt:   &req.SubmittedAt,
+		ReviewedAt:    req.ReviewedAt,
+		ReviewedBy:    req.ReviewedBy,
+		ReviewNotes:   stringPtr(req.ReviewNotes),
+		
+		// Dados pessoais da request
+		Name:                req.Name,
+		Email:               req.Email,
+		Phone:               req.Phone,
+		NIF:                 req.NIF,
+		DateOfBirth:         req.DateOfBirth,
+		MaritalStatus:       req.MaritalStatus,
+		CitizenCardNumber:   req.CitizenCardNumber,
+		CitizenCardExpiry:   req.CitizenCardExpiry,
+		TaxResidenceCountry: req.TaxResidenceCountry,
+		FixedPhone:          req.FixedPhone,
+		
+		// Morada fiscal
+		FiscalAddress:    req.FiscalAddress,
+		FiscalPostalCode: req.FiscalPostalCode,
+		FiscalCity:       req.FiscalCity,
+		FiscalCounty:     req.FiscalCounty,
+		FiscalDistrict:   req.FiscalDistrict,
+		
+		// Morada pessoal/empresa
+		Address:    req.Address,
+		PostalCode: req.PostalCode,
+		City:       req.City,
+		Country:    req.Country,
+		
+		// Preferências
+		OfficialEmail:         req.OfficialEmail,
+		BillingSoftware:       req.BillingSoftware,
+		PreferredFormat:       req.PreferredFormat,
+		ReportFrequency:       req.ReportFrequency,
+		PreferredContactHours: req.PreferredContactHours,
+		
+		// Dados da empresa
+		CompanyName:      req.CompanyName,
+		TradeName:        req.TradeName,
+		NIPC:             stringPtr(req.NIPC),
+		LegalForm:        stringPtr(req.LegalForm),
+		CAE:              req.CAE,
+		FoundingDate:     req.FoundingDate,
+		ShareCapital:     req.ShareCapital,
+		AccountingRegime: req.AccountingRegime,
+		VATRegime:        req.VATRegime,
+		BusinessActivity: req.BusinessActivity,
+		EstimatedRevenue: req.EstimatedRevenue,
+		MonthlyInvoices:  req.MonthlyInvoices,
+		NumberEmployees:  req.NumberEmployees,
+		CorporateObject:  req.CorporateObject,
+		CompanyAddress:   req.CompanyAddress,
+		CompanyPostalCode: req.CompanyPostalCode,
+		CompanyCity:      req.CompanyCity,
+		CompanyCounty:    req.CompanyCounty,
+		CompanyDistrict:  req.CompanyDistrict,
+		CompanyCountry:   req.CompanyCountry,
+		GroupStartDate:   req.GroupStartDate,
+		BankName:         req.BankName,
+		IBAN:             req.IBAN,
+		BIC:              req.BIC,
+		AnnualRevenue:    req.AnnualRevenue,
+		HasStock:         req.HasStock,
+		MainClients:      req.MainClients,
+		MainSuppliers:    req.MainSuppliers,
+		
+		// Timestamps da request
+		RequestCreatedAt: &req.CreatedAt,
+		RequestUpdatedAt: &req.UpdatedAt,
 	}
 	
-	// 3. Criar mapa de requests por user_id para fácil acesso
-	requestsByUserID := make(map[uint]*models.RegistrationRequest)
-	requestsByUsername := make(map[string]*models.RegistrationRequest)
-	
-	for i := range requests {
-		req := &requests[i]
-		if req.UserID != nil {
-			requestsByUserID[*req.UserID] = req
+	if req.ReviewedByUser != nil {
+		dto.ReviewedByName = stringPtr(req.ReviewedByUser.Name)
+	}
+	return dto
+}
+
+// buildInvitationOverviewDTO monta a visão completa de um convite que ainda não tem conta
+func buildInvitationOverviewDTO(invitation models.ClientInvitation) models.CompleteUserOverviewDTO {
+	dto := models.CompleteUserOverviewDTO{
+		Username:          firstNonEmpty(invitation.Username, invitation.Email),
+		Status:            invitation.Status,
+		Role:              models.RoleClient,
+		Source:            "invitation",
+		Name:              stringPtr(invitation.Name),
+		Email:             stringPtr(invitation.Email),
+		Phone:             stringPtr(invitation.Phone),
+		NIF:               stringPtr(invitation.NIF),
+		CompanyName:       stringPtr(invitation.CompanyName),
+		TradeName:         stringPtr(invitation.TradeName),
+		NIPC:              stringPtr(invitation.NIPC),
+		LegalForm:         stringPtr(invitation.LegalForm),
+		CAE:               stringPtr(invitation.CAE),
+		AccountingRegime:  stringPtr(invitation.AccountingRegime),
+		VATRegime:         stringPtr(invitation.VATRegime),
+		BusinessActivity:  stringPtr(invitation.BusinessActivity),
+		CompanyAddress:    stringPtr(invitation.CompanyAddress),
+		CompanyPostalCode: stringPtr(invitation.CompanyPostalCode),
+		CompanyCity:       stringPtr(invitation.CompanyCity),
+		CompanyDistrict:   stringPtr(invitation.CompanyDistrict),
+	}
+	applyInvitationOverview(&dto, &invitation)
+	return dto
+}
+
+// applyInvitationOverview acrescenta à visão completa o estado do convite do cliente
+func applyInvitationOverview(dto *models.CompleteUserOverviewDTO, invitation *models.ClientInvitation) {
+	dto.InvitationID = &invitation.ID
+	dto.InvitationStatus = &invitation.Status
+	dto.InvitationExpiresAt = &invitation.ExpiresAt
+	dto.InvitedBy = &invitation.InvitedBy
+}
+
+// GetCompleteUserOverview devolve a visão completa (User, Company e RegistrationRequest) de um cliente aprovado
+func (s *AdminService) GetCompleteUserOverview(userID uint) (*models.CompleteUserOverviewDTO, error) {
+	var user models.User
+	if err := config.DB.Where("id = ? AND status = ?", userID, "approved").First(&user).Error; err != nil {
+		return nil, errors.New("cliente não encontrado")
+	}
+	if err := attachUserCompany(&user); err != nil {
+		return nil, err
+	}
+
+	var request *models.RegistrationRequest
+	var found models.RegistrationRequest
+	if err := config.DB.Preload("ReviewedByUser").Where("user_id = ?", userID).Order("id DESC").First(&found).Error; err == nil {
+		request = &found
+	}
+
+	dto := buildUserOverviewDTO(user, request)
+	for _, link := range user.Companies {
+		if user.Company != nil && link.ID == user.Company.ID {
+			dto.CompanyRole = stringPtr(link.CompanyRole)
 		}
-		// Também indexar por username para requests sem user_id
-		requestsByUsername[req.Username] = req
+	}
+	var invitation models.ClientInvitation
+	if err := config.DB.Where("user_id = ?", userID).First(&invitation).Error; err == nil {
+		applyInvitationOverview(&dto, &invitation)
+	}
+	return &dto, nil
+}
+
+// userOverviewRows devolve uma linha da visão completa por cada empresa a que o utilizador está ligado,
+// com o papel que tem nela, ou uma só linha sem empresa
+func userOverviewRows(user models.User, req *models.RegistrationRequest) []models.CompleteUserOverviewDTO {
+	if len(user.Companies) == 0 {
+		return []models.CompleteUserOverviewDTO{buildUserOverviewDTO(user, req)}
+	}
+	rows := make([]models.CompleteUserOverviewDTO, 0, len(user.Companies))
+	for i := range user.Companies {
+		link := user.Companies[i]
+		user.Company = &link.Company
+		dto := buildUserOverviewDTO(user, req)
+		dto.CompanyRole = stringPtr(link.CompanyRole)
+		rows = append(rows, dto)
+	}
+	return rows
+}
+
+// buildUserOverviewDTO combina os dados de um utilizador aprovado, da empresa e da solicitação de registo
+func buildUserOverviewDTO(user models.User, req *models.RegistrationRequest) models.CompleteUserOverviewDTO {
+	dto := models.CompleteUserOverviewDTO{
+		// Identificação
+		ID:       user.ID,
+		Username: user.Username,
+		Status:   user.Status,
+		Role:     user.Role,
+		
+		// Dados pessoais (prioridade: User)
+		Name:                stringPtr(user.Name),
+		Email:               stringPtr(user.Email),
+		Phone:               stringPtr(user.Phone),
+		NIF:                 stringPtr(user.NIF),
+		DateOfBirth:         user.DateOfBirth,
+		MaritalStatus:       stringPtr(user.MaritalStatus),
+		CitizenCardNumber:   stringPtr(user.CitizenCardNumber),
+		CitizenCardExpiry:   user.CitizenCardExpiry,
+		TaxResidenceCountry: stringPtr(user.TaxResidenceCountry),
+		FixedPhone:          stringPtr(user.FixedPhone),
+		
+		// Morada fiscal
+		FiscalAddress:    stringPtr(user.FiscalAddress),
+		FiscalPostalCode: stringPtr(user.FiscalPostalCode),
+		FiscalCity:       stringPtr(user.FiscalCity),
+		FiscalCounty:     stringPtr(user.FiscalCounty),
+		FiscalDistrict:   stringPtr(user.FiscalDistrict),
+		
+		// Preferências
+		OfficialEmail:         stringPtr(user.OfficialEmail),
+		BillingSoftware:       stringPtr(user.BillingSoftware),
+		PreferredFormat:       stringPtr(user.PreferredFormat),
+		ReportFrequency:       stringPtr(user.ReportFrequency),
+		PreferredContactHours: stringPtr(user.PreferredContactHours),
+		
+		// Timestamps do user
+		UserCreatedAt: &user.CreatedAt,
+		UserUpdatedAt: &user.UpdatedAt,
 	}
 	
-	// 4. Processar users aprovados
-	for _, user := range users {
-		dto := models.CompleteUserOverviewDTO{
-			// Identificação
-			ID:       user.ID,
-			Username: user.Username,
-			Status:   user.Status,
-			Role:     user.Role,
-			
-			// Dados pessoais (prioridade: User)
-			Name:                stringPtr(user.Name),
-			Email:               stringPtr(user.Email),
-			Phone:               stringPtr(user.Phone),
-			NIF:                 stringPtr(user.NIF),
-			DateOfBirth:         user.DateOfBirth,
-			MaritalStatus:       stringPtr(user.MaritalStatus),
-			CitizenCardNumber:   stringPtr(user.CitizenCardNumber),
-			CitizenCardExpiry:   user.CitizenCardExpiry,
-			TaxResidenceCountry: stringPtr(user.TaxResidenceCountry),
-			FixedPhone:          stringPtr(user.FixedPhone),
-			
-			// Morada fiscal
-			FiscalAddress:    stringPtr(user.FiscalAddress),
-			FiscalPostalCode: stringPtr(user.FiscalPostalCode),
-			FiscalCity:       stringPtr(user.FiscalCity),
-			FiscalCounty:     stringPtr(user.FiscalCounty),
-			FiscalDistrict:   stringPtr(user.FiscalDistrict),
-			
-			// Preferências
-			OfficialEmail:         stringPtr(user.OfficialEmail),
-			BillingSoftware:       stringPtr(user.BillingSoftware),
-			PreferredFormat:       stringPtr(user.PreferredFormat),
-			ReportFrequency:       stringPtr(user.ReportFrequency),
-			PreferredContactHours: stringPtr(user.PreferredContactHours),
-			
-			// Timestamps do user
-			UserCreatedAt: &user.CreatedAt,
-			UserUpdatedAt: &user.UpdatedAt,
-		}
+	// Dados da empresa (se existir)
+	if user.Company != nil {
+		company := user.Company
+		dto.CompanyID = &company.ID
+		dto.CompanyName = stringPtr(company.CompanyName)
+		dto.TradeName = stringPtr(company.TradeName)
+		dto.NIPC = stringPtr(company.NIPC)
+		dto.LegalForm = stringPtr(company.LegalForm)
+		dto.CAE = stringPtr(company.CAE)
+		dto.FoundingDate = company.FoundingDate
+		dto.ShareCapital = float64Ptr(company.ShareCapital)
+		dto.CompanyStatus = stringPtr(company.Status)
 		
-		// Dados da empresa (se existir)
-		if user.Company != nil {
-			company := user.Company
-			dto.CompanyID = &company.ID
-			dto.CompanyName = stringPtr(company.CompanyName)
-			dto.TradeName = stringPtr(company.TradeName)
-			dto.NIPC = stringPtr(company.NIPC)
-			dto.LegalForm = stringPtr(company.LegalForm)
-			dto.CAE = stringPtr(company.CAE)
-			dto.FoundingDate = company.FoundingDate
-			dto.ShareCapital = float64Ptr(company.ShareCapital)
-			dto.CompanyStatus = stringPtr(company.Status)
-			
-			// Configurações contabilísticas
-			dto.AccountingRegime = stringPtr(company.AccountingRegime)
-			dto.VATRegime = stringPtr(company.VATRegime)
-			dto.BusinessActivity = stringPtr(company.BusinessActivity)
-			dto.EstimatedRevenue = float64Ptr(company.EstimatedRevenue)
-			dto.MonthlyInvoices = intPtr(company.MonthlyInvoices)
-			dto.NumberEmployees = intPtr(company.NumberEmployees)
-			
-			// Detalhes da empresa
-			dto.CorporateObject = stringPtr(company.CorporateObject)
-			
-			// Morada da empresa
-			dto.CompanyAddress = stringPtr(company.Address)
-			dto.CompanyPostalCode = stringPtr(company.PostalCode)
-			dto.CompanyCity = stringPtr(company.City)
-			dto.CompanyCounty = stringPtr(company.County)
-			dto.CompanyDistrict = stringPtr(company.District)
-			dto.CompanyCountry = stringPtr(company.Country)
-			dto.GroupStartDate = company.GroupStartDate
-			
-			// Informação bancária
-			dto.BankName = stringPtr(company.BankName)
-			dto.IBAN = stringPtr(company.IBAN)
-			dto.BIC = stringPtr(company.BIC)
-			
-			// Dados operacionais
-			dto.AnnualRevenue = float64Ptr(company.AnnualRevenue)
-			dto.HasStock = boolPtr(company.HasStock)
-			dto.MainClients = stringPtr(company.MainClients)
-			dto.MainSuppliers = stringPtr(company.MainSuppliers)
-			
-			// Timestamps da empresa
-			dto.CompanyCreatedAt = &company.CreatedAt
-			dto.CompanyUpdatedAt = &company.UpdatedAt
-		}
+		// Configurações contabilísticas
+		dto.AccountingRegime = stringPtr(company.AccountingRegime)
+		dto.VATRegime = stringPtr(company.VATRegime)
+		dto.BusinessActivity = stringPtr(company.BusinessActivity)
+		dto.EstimatedRevenue = float64Ptr(company.EstimatedRevenue)
+		dto.MonthlyInvoices = intPtr(company.MonthlyInvoices)
+		dto.NumberEmployees = intPtr(company.NumberEmployees)
 		
-		// Dados da registration_request (se existir)
-		if req, exists := requestsByUserID[user.ID]; exists {
-			dto.Source = "both"
-			dto.RequestID = &req.ID
-			dto.RequestType = &req.RequestType
-			dto.RequestStatus = &req.Status
-			dto.SubmittedAt = &req.SubmittedAt
-			dto.ReviewedAt = req.ReviewedAt
-			dto.ReviewedBy = req.ReviewedBy
-			dto.ReviewNotes = stringPtr(req.ReviewNotes)
-			
-			if req.ReviewedByUser != nil {
-				dto.ReviewedByName = stringPtr(req.ReviewedByUser.Name)
-			}
-			
-			// Dados adicionais da request que podem não estar em User/Company
-			if dto.Address == nil && req.Address != nil {
-				dto.Address = req.Address
-			}
-			if dto.PostalCode == nil && req.PostalCode != nil {
-				dto.PostalCode = req.PostalCode
-			}
-			if dto.City == nil && req.City != nil {
-				dto.City = req.City
-			}
-			if dto.Country == nil && req.Country != nil {
-				dto.Country = req.Country
-			}
-			
-			// Timestamps da request
-			dto.RequestCreatedAt = &req.CreatedAt
-			dto.RequestUpdatedAt = &req.UpdatedAt
-		} else {
-			dto.Source = "user_only"
-		}
+		// Detalhes da empresa
+		dto.CorporateObject = stringPtr(company.CorporateObject)
 		
-		result = append(result, dto)
+		// Morada da empresa
+		dto.CompanyAddress = stringPtr(company.Address)
+		dto.CompanyPostalCode = stringPtr(company.PostalCode)
+		dto.CompanyCity = stringPtr(company.City)
+		dto.CompanyCounty = stringPtr(company.County)
+		dto.CompanyDistrict = stringPtr(company.District)
+		dto.CompanyCountry = stringPtr(company.Country)
+		dto.GroupStartDate = company.GroupStartDate
+		
+		// Informação bancária
+		dto.BankName = stringPtr(company.BankName)
+		dto.IBAN = stringPtr(company.IBAN)
+		dto.BIC = stringPtr(company.BIC)
+		
+		// Dados operacionais
+		dto.AnnualRevenue = float64Ptr(company.AnnualRevenue)
+		dto.HasStock = boolPtr(company.HasStock)
+		dto.MainClients = stringPtr(company.MainClients)
+		dto.MainSuppliers = stringPtr(company.MainSuppliers)
+		
+		// Timestamps da empresa
+		dto.CompanyCreatedAt = &company.CreatedAt
+		dto.CompanyUpdatedAt = &company.UpdatedAt
 	}
 	
-	// 5. Processar requests pendentes/rejeitadas que não têm user associado
-	for _, req := range requests {
-		if req.UserID == nil && (req.Status == "pending" || req.Status == "rejected") {
-			dto := models.CompleteUserOverviewDTO{
-				// Identificação
-				Username: req.Username,
-				Status:   req.Status,
-				Role:     "client",
-				Source:   "registration_request",
-				
-				// Dados da request
-				RequestID:     &req.ID,
-				RequestType:   &req.RequestType,
-				RequestStatus: &req.Status,
-				SubmittedAt:   &req.SubmittedAt,
-				ReviewedAt:    req.ReviewedAt,
-				ReviewedBy:    req.ReviewedBy,
-				ReviewNotes:   stringPtr(req.ReviewNotes),
-				
-				// Dados pessoais da request
-				Name:                req.Name,
-				Email:               req.Email,
-				Phone:               req.Phone,
-				NIF:                 req.NIF,
-				DateOfBirth:         req.DateOfBirth,
-				MaritalStatus:       req.MaritalStatus,
-				CitizenCardNumber:   req.CitizenCardNumber,
-				CitizenCardExpiry:   req.CitizenCardExpiry,
-				TaxResidenceCountry: req.TaxResidenceCountry,
-				FixedPhone:          req.FixedPhone,
-				
-				// Morada fiscal
-				FiscalAddress:    req.FiscalAddress,
-				FiscalPostalCode: req.FiscalPostalCode,
-				FiscalCity:       req.FiscalCity,
-				FiscalCounty:     req.FiscalCounty,
-				FiscalDistrict:   req.FiscalDistrict,
-				
-				// Morada pessoal/empresa
-				Address:    req.Address,
-				PostalCode: req.PostalCode,
-				City:       req.City,
-				Country:    req.Country,
-				
-				// Preferências
-				OfficialEmail:         req.OfficialEmail,
-				BillingSoftware:       req.BillingSoftware,
-				PreferredFormat:       req.PreferredFormat,
-				ReportFrequency:       req.ReportFrequency,
-				PreferredContactHours: req.PreferredContactHours,
-				
-				// Dados da empresa
-				CompanyName:      req.CompanyName,
-				TradeName:        req.TradeName,
-				NIPC:             stringPtr(req.NIPC),
-				LegalForm:        stringPtr(req.LegalForm),
-				CAE:              req.CAE,
-				FoundingDate:     req.FoundingDate,
-				ShareCapital:     req.ShareCapital,
-				AccountingRegime: req.AccountingRegime,
-				VATRegime:        req.VATRegime,
-				BusinessActivity: req.BusinessActivity,
-				EstimatedRevenue: req.EstimatedRevenue,
-				MonthlyInvoices:  req.MonthlyInvoices,
-				NumberEmployees:  req.NumberEmployees,
-				CorporateObject:  req.CorporateObject,
-				CompanyAddress:   req.CompanyAddress,
-				CompanyPostalCode: req.CompanyPostalCode,
-				CompanyCity:      req.CompanyCity,
-				CompanyCounty:    req.CompanyCounty,
-				CompanyDistrict:  req.CompanyDistrict,
-				CompanyCountry:   req.CompanyCountry,
-				GroupStartDate:   req.GroupStartDate,
-				BankName:         req.BankName,
-				IBAN:             req.IBAN,
-				BIC:              req.BIC,
-				AnnualRevenue:    req.AnnualRevenue,
-				HasStock:         req.HasStock,
-				MainClients:      req.MainClients,
-				MainSuppliers:    req.MainSuppliers,
-				
-				// Timestamps da request
-				RequestCreatedAt: &req.CreatedAt,
-				RequestUpdatedAt: &req.UpdatedAt,
-			}
-			
-			if req.ReviewedByUser != nil {
-				dto.ReviewedByName = stringPtr(req.ReviewedByUser.Name)
-			}
-			
-			result = append(result, dto)
+	// Dados da registration_request (se existir)
+	if req != nil {
+		dto.Source = "both"
+		dto.RequestID = &req.ID
+		dto.RequestType = &req.RequestType
+		dto.RequestStatus = &req.Status
+		dto.SubmittedAt = &req.SubmittedAt
+		dto.ReviewedAt = req.ReviewedAt
+		dto.ReviewedBy = req.ReviewedBy
+		dto.ReviewNotes = stringPtr(req.ReviewNotes)
+		
+		if req.ReviewedByUser != nil {
+			dto.ReviewedByName = stringPtr(req.ReviewedByUser.Name)
 		}
+		
+		// Dados adicionais da request que podem não estar em User/Company
+		if dto.Address == nil && req.Address != nil {
+			dto.Address = req.Address
+		}
+		if dto.PostalCode == nil && req.PostalCode != nil {
+			dto.PostalCode = req.PostalCode
+		}
+		if dto.City == nil && req.City != nil {
+			dto.City = req.City
+		}
+		if dto.Country == nil && req.Country != nil {
+			dto.Country = req.Country
+		}
+		
+		// Timestamps da request
+		dto.RequestCreatedAt = &req.CreatedAt
+		dto.RequestUpdatedAt = &req.UpdatedAt
+	} else {
+		dto.Source = "user_only"
 	}
 	
-	return result, nil
+	return dto
+}
+
+// buildPendingRequestDTO converte uma solicitação no resumo usado nas listagens
+func buildPendingRequestDTO(req models.RegistrationRequest) models.PendingRequestResponseDTO {
+	dto := models.PendingRequestResponseDTO{
+		ID:          req.ID,
+		RequestType: req.RequestType,
+		Status:      req.Status,
+		SubmittedAt: req.SubmittedAt,
+		Username:    req.Username,
+		NIPC:        req.NIPC,
+		LegalForm:   req.LegalForm,
+		AssignedTo:  req.AssignedTo,
+		AssignedAt:  req.AssignedAt,
+
+		EmailVerified:   req.EmailVerifiedAt != nil,
+		EmailVerifiedAt: req.EmailVerifiedAt,
+	}
+
+	// Campos opcionais do usuário
+	if req.Name != nil {
+		dto.Name = *req.Name
+	}
+	if req.Email != nil {
+		dto.Email = *req.Email
+	}
+	if req.Phone != nil {
+		dto.Phone = *req.Phone
+	}
+	if req.NIF != nil {
+		dto.NIF = *req.NIF
+	}
+
+	// Campos opcionais da empresa
+	if req.CompanyName != nil {
+		dto.CompanyName = *req.CompanyName
+	}
+
+	// Campos opcionais da morada fiscal
+	if req.FiscalAddress != nil {
+		dto.FiscalAddress = *req.FiscalAddress
+	}
+	if req.FiscalPostalCode != nil {
+		dto.FiscalPostalCode = *req.FiscalPostalCode
+	}
+	if req.FiscalCity != nil {
+		dto.FiscalCity = *req.FiscalCity
+	}
+
+	if req.AssignedToUser != nil {
+		dto.AssignedToName = req.AssignedToUser.Name
+	}
+
+	return dto
 }
 
 // Funções auxiliares para criar ponteiros
@@ -1126,3 +1619,15 @@ func intPtr(i int) *int {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+// companyFinancialFieldsChanged indica se o pedido altera dados financeiros da empresa
+func companyFinancialFieldsChanged(company *models.Company, req models.AdminUpdateCompanyDTO) bool {
+	return (req.ShareCapital != nil && *req.ShareCapital != company.ShareCapital) ||
+		(req.BankName != nil && *req.BankName != company.BankName) ||
+		(req.IBAN != nil && *req.IBAN != company.IBAN) ||
+		(req.BIC != nil && *req.BIC != company.BIC) ||
+		(req.AccountingRegime != nil && *req.AccountingRegime != company.AccountingRegime) ||
+		(req.VATRegime != nil && *req.VATRegime != company.VATRegime) ||
+		(req.EstimatedRevenue != nil && *req.EstimatedRevenue != company.EstimatedRevenue) ||
+		(req.AnnualRevenue != nil && *req.AnnualRevenue != company.AnnualRevenue)
+}
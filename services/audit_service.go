@@ -0,0 +1,76 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+)
+
+type AuditService struct{}
+
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// Record guarda uma entrada no registo de auditoria. Uma falha ao gravar é registada no log
+// mas não anula a ação já feita.
+func (s *AuditService) Record(userID uint, action, entityType string, entityID *uint, details map[string]interface{}, ip string) {
+	entry := models.AuditLog{
+		UserID:     userID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IPAddress:  ip,
+	}
+	if len(details) > 0 {
+		if data, err := json.Marshal(details); err == nil {
+			entry.Details = string(data)
+		}
+	}
+
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Erro ao registar auditoria (%s %s): %v", action, entityType, err)
+	}
+}
+
+// GetLogs lista as entradas de auditoria mais recentes, filtradas por ação, utilizador e datas (AAAA-MM-DD)
+func (s *AuditService) GetLogs(action, userID, from, to string, limit int) ([]models.AuditLog, error) {
+	query := config.DB.Preload("User")
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			return nil, errors.New("user_id inválido")
+		}
+		query = query.Where("user_id = ?", id)
+	}
+	if from != "" {
+		date, err := parseDateOrDefault(from, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_at >= ?", date)
+	}
+	if to != "" {
+		date, err := parseDateOrDefault(to, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_at < ?", date.AddDate(0, 0, 1))
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("created_at DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, errors.New("erro ao obter registo de auditoria")
+	}
+	return logs, nil
+}
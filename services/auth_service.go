@@ -5,6 +5,7 @@ import (
 	"RVContabilidadeBack/models"
 	"RVContabilidadeBack/utils"
 	"errors"
+	"log"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -28,6 +29,11 @@ func (s *AuthService) RegisterClient(req models.RegistrationRequestDTO) (*models
 		return nil, err
 	}
 
+	// Verificar username e NIPC, que só falhariam mais tarde na aprovação
+	if err := s.checkUniqueIdentifiers(req.Username, req.NIPC); err != nil {
+		return nil, err
+	}
+
 	// Hash da password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -42,6 +48,18 @@ func (s *AuthService) RegisterClient(req models.RegistrationRequestDTO) (*models
 		return nil, err
 	}
 
+	// Enviar link de verificação; sem email a solicitação entra logo na distribuição
+	if registrationRequest.Email != nil {
+		if err := NewNotificationService().NotifyRequest(models.EventRequestReceived, &registrationRequest, nil); err != nil {
+			log.Printf("⚠️  Erro ao notificar receção da solicitação %d: %v", registrationRequest.ID, err)
+		}
+		if err := NewEmailVerificationService().SendVerification(&registrationRequest); err != nil {
+			log.Printf("⚠️  Erro ao enviar verificação de email da solicitação %d: %v", registrationRequest.ID, err)
+		}
+	} else {
+		_ = NewReviewQueueService().AutoAssign(&registrationRequest)
+	}
+
 	return &registrationRequest, nil
 }
 
@@ -76,57 +94,11 @@ func (s *AuthService) Login(username, password string) (*models.AuthResponse, er
 	}, nil
 }
 
-// Register é um alias para CreateUserDirect (para compatibilidade)
-func (s *AuthService) Register(req models.RegisterRequest) (*models.AuthResponse, error) {
-	return s.CreateUserDirect(req)
-}
-
 // LoginWithCredentials com LoginRequest DTO
 func (s *AuthService) LoginWithCredentials(req models.LoginRequest) (*models.AuthResponse, error) {
 	return s.Login(req.Username, req.Password)
 }
 
-// CreateUserDirect cria utilizador diretamente (para uso interno)
-func (s *AuthService) CreateUserDirect(req models.RegisterRequest) (*models.AuthResponse, error) {
-	// Verificar duplicações
-	if err := s.checkUserDuplicates(req.Username, req.Email, req.NIF); err != nil {
-		return nil, err
-	}
-
-	// Hash da password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, errors.New("erro ao processar password")
-	}
-
-	// Criar utilizador
-	user := models.User{
-		Username: req.Username,
-		Email:    req.Email,
-		Password: string(hashedPassword),
-		Name:     req.Name,
-		Phone:    req.Phone,
-		NIF:      req.NIF,
-		Role:     req.Role,
-		Status:   req.Status,
-	}
-
-	if err := config.DB.Create(&user).Error; err != nil {
-		return nil, errors.New("erro ao criar utilizador")
-	}
-
-	// Gerar token
-	token, err := utils.GenerateToken(user.ID, user.Username, user.NIF, user.Role)
-	if err != nil {
-		return nil, errors.New("erro ao gerar token")
-	}
-
-	return &models.AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
-}
-
 // ===== MÉTODOS PRIVADOS =====
 
 func (s *AuthService) checkExistingRequest(nif, email string) error {
@@ -171,6 +143,32 @@ func (s *AuthService) checkExistingUser(nif, email string) error {
 	return nil
 }
 
+func (s *AuthService) checkUniqueIdentifiers(username, nipc string) error {
+	var existingUser models.User
+	if config.DB.Where("username = ?", username).First(&existingUser).Error == nil {
+		return errors.New("username já está em uso")
+	}
+
+	var existingRequest models.RegistrationRequest
+	if config.DB.Where("username = ? AND status = ?", username, "pending").First(&existingRequest).Error == nil {
+		return errors.New("já existe uma solicitação pendente com este username")
+	}
+
+	if nipc == "" {
+		return nil
+	}
+
+	var existingCompany models.Company
+	if config.DB.Where("nipc = ?", nipc).First(&existingCompany).Error == nil {
+		return errors.New("já existe uma empresa com este NIPC")
+	}
+	if config.DB.Where("nipc = ? AND status = ?", nipc, "pending").First(&existingRequest).Error == nil {
+		return errors.New("já existe uma solicitação pendente com este NIPC")
+	}
+
+	return nil
+}
+
 func (s *AuthService) checkUserDuplicates(username, email, nif string) error {
 	var existingUser models.User
 	
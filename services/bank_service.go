@@ -0,0 +1,203 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BankService struct{}
+
+func NewBankService() *BankService {
+	return &BankService{}
+}
+
+// ImportStatement importa um extrato bancário (CAMT.053, OFX ou CSV de um banco português)
+// para o livro bancário da empresa, ignorando movimentos já importados com a mesma referência
+// e movimentos com data num período fechado (contados em closed_period)
+func (s *BankService) ImportStatement(clientID, companyID, uploadedBy uint, fileHeader *multipart.FileHeader, format, role string) (*models.BankStatementImport, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := maxUploadSize()
+	if fileHeader.Size > maxSize {
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, errors.New("erro ao ler ficheiro")
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, errors.New("erro ao ler ficheiro")
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+
+	statement, detectedFormat, err := utils.ParseBankStatement(data, format, company.BankName)
+	if err != nil {
+		return nil, err
+	}
+
+	// O extrato tem de ser da conta da empresa; os CSV sem IBAN ficam na conta registada
+	companyIBAN := utils.NormalizeIBAN(company.IBAN)
+	iban := statement.IBAN
+	if iban == "" {
+		iban = companyIBAN
+	} else if companyIBAN != "" && iban != companyIBAN {
+		return nil, fmt.Errorf("o IBAN do extrato (%s) não corresponde ao IBAN da empresa (%s)", iban, companyIBAN)
+	}
+
+	statementImport := models.BankStatementImport{
+		CompanyID:      company.ID,
+		UploadedBy:     uploadedBy,
+		FileName:       filepath.Base(fileHeader.Filename),
+		Format:         detectedFormat,
+		IBAN:           iban,
+		Transactions:   len(statement.Lines),
+		ClosingBalance: statement.ClosingBalance,
+	}
+	guard := newPeriodGuard(company.ID, role)
+	closed := make([]bool, len(statement.Lines))
+	for i, line := range statement.Lines {
+		if guard.check(line.BookingDate) != nil {
+			closed[i] = true
+			statementImport.ClosedPeriod++
+		}
+		if statementImport.PeriodStart.IsZero() || line.BookingDate.Before(statementImport.PeriodStart) {
+			statementImport.PeriodStart = line.BookingDate
+		}
+		if line.BookingDate.After(statementImport.PeriodEnd) {
+			statementImport.PeriodEnd = line.BookingDate
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&statementImport).Error; err != nil {
+			return err
+		}
+
+		for i, line := range statement.Lines {
+			if closed[i] {
+				continue
+			}
+			currency := line.Currency
+			if currency == "" {
+				currency = "EUR"
+			}
+			transaction := models.BankTransaction{
+				CompanyID:        company.ID,
+				ImportID:         statementImport.ID,
+				IBAN:             iban,
+				Reference:        line.Reference,
+				BookingDate:      line.BookingDate,
+				ValueDate:        line.ValueDate,
+				Amount:           line.Amount,
+				Currency:         currency,
+				Description:      line.Description,
+				Counterparty:     line.Counterparty,
+				CounterpartyIBAN: line.CounterpartyIBAN,
+				Balance:          line.Balance,
+				MatchStatus:      models.BankTransactionUnmatched,
+			}
+
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "company_id"}, {Name: "iban"}, {Name: "reference"}},
+				DoNothing: true,
+			}).Create(&transaction)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				statementImport.Duplicates++
+			} else {
+				statementImport.Created++
+			}
+		}
+
+		return tx.Model(&statementImport).Updates(map[string]interface{}{
+			"created":    statementImport.Created,
+			"duplicates": statementImport.Duplicates,
+		}).Error
+	})
+	if err != nil {
+		return nil, errors.New("erro ao guardar movimentos bancários")
+	}
+
+	return &statementImport, nil
+}
+
+// GetImports lista os extratos importados da empresa do cliente
+func (s *BankService) GetImports(clientID, companyID uint) ([]models.BankStatementImport, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := []models.BankStatementImport{}
+	if err := config.DB.Where("company_id = ?", company.ID).Order("created_at DESC").Find(&imports).Error; err != nil {
+		return nil, errors.New("erro ao obter extratos")
+	}
+	return imports, nil
+}
+
+// GetTransactions lista o livro bancário da empresa do cliente (datas AAAA-MM-DD)
+func (s *BankService) GetTransactions(clientID, companyID uint, matchStatus, from, to string, limit, offset int) (*models.BankTransactionListDTO, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := config.DB.Model(&models.BankTransaction{}).Where("company_id = ?", company.ID)
+	if matchStatus != "" {
+		query = query.Where("match_status = ?", matchStatus)
+	}
+	if from != "" {
+		fromDate, err := parseDateOrDefault(from, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("booking_date >= ?", fromDate)
+	}
+	if to != "" {
+		toDate, err := parseDateOrDefault(to, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("booking_date <= ?", toDate)
+	}
+
+	result := models.BankTransactionListDTO{Transactions: []models.BankTransaction{}}
+	if err := query.Session(&gorm.Session{}).Count(&result.Total).Error; err != nil {
+		return nil, errors.New("erro ao obter movimentos")
+	}
+	query.Session(&gorm.Session{}).Select("COALESCE(SUM(amount), 0)").Scan(&result.TotalAmount)
+	result.TotalAmount = roundAmount(result.TotalAmount)
+
+	if err := query.Order("booking_date DESC, id DESC").Limit(limit).Offset(offset).Find(&result.Transactions).Error; err != nil {
+		return nil, errors.New("erro ao obter movimentos")
+	}
+	return &result, nil
+}
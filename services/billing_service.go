@@ -0,0 +1,763 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var taxExemptionCodePattern = regexp.MustCompile(`^M\d{2}$`)
+
+// taxExemptionReasons são os motivos de isenção mais comuns (tabela de códigos da AT)
+var taxExemptionReasons = map[string]string{
+	"M01": "Artigo 16.º, n.º 6 do CIVA",
+	"M02": "Artigo 6.º do Decreto-Lei n.º 198/90",
+	"M04": "Isento artigo 13.º do CIVA",
+	"M05": "Isento artigo 14.º do CIVA",
+	"M06": "Isento artigo 15.º do CIVA",
+	"M07": "Isento artigo 9.º do CIVA",
+	"M10": "IVA - regime de isenção (artigo 53.º do CIVA)",
+	"M16": "Isento artigo 14.º do RITI",
+	"M40": "IVA - autoliquidação (artigo 6.º, n.º 6, alínea a) do CIVA)",
+	"M99": "Não sujeito ou não tributado",
+}
+
+// Ilhas cujos distritos pertencem às regiões autónomas (taxas de IVA próprias)
+var (
+	azoresDistricts  = []string{"acores", "ponta delgada", "angra do heroismo", "horta"}
+	madeiraDistricts = []string{"madeira", "funchal"}
+)
+
+var invoiceDocumentTitles = map[string]string{
+	models.InvoiceTypeInvoice:        "Fatura",
+	models.InvoiceTypeInvoiceReceipt: "Fatura-recibo",
+	models.InvoiceTypeSimplified:     "Fatura simplificada",
+	models.InvoiceTypeCreditNote:     "Nota de crédito",
+}
+
+type BillingService struct{}
+
+func NewBillingService() *BillingService {
+	return &BillingService{}
+}
+
+// GetCustomers lista os clientes de faturação da empresa, opcionalmente filtrados por nome ou NIF
+func (s *BillingService) GetCustomers(clientID, companyID uint, search string) ([]models.Customer, error) {
+	company, err := s.getBillingCompany(clientID, companyID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("company_id = ?", company.ID)
+	if search = strings.TrimSpace(search); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR tax_id LIKE ?", like, like)
+	}
+
+	var customers []models.Customer
+	if err := query.Order("name ASC").Find(&customers).Error; err != nil {
+		return nil, errors.New("erro ao buscar clientes")
+	}
+	return customers, nil
+}
+
+// CreateCustomer regista um cliente de faturação
+func (s *BillingService) CreateCustomer(clientID, companyID uint, req models.CustomerDTO) (*models.Customer, error) {
+	company, err := s.getBillingCompany(clientID, companyID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := models.Customer{CompanyID: company.ID}
+	if err := applyCustomerData(&customer, req); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	config.DB.Model(&models.Customer{}).Where("company_id = ? AND tax_id = ?", company.ID, customer.TaxID).Count(&count)
+	if count > 0 && customer.TaxID != models.FinalConsumerTaxID {
+		return nil, errors.New("já existe um cliente com este NIF")
+	}
+
+	if err := config.DB.Create(&customer).Error; err != nil {
+		return nil, errors.New("erro ao criar cliente")
+	}
+	return &customer, nil
+}
+
+// UpdateCustomer atualiza um cliente de faturação. O NIF não pode mudar depois de emitidos documentos.
+func (s *BillingService) UpdateCustomer(clientID, companyID, customerID uint, req models.CustomerDTO) (*models.Customer, error) {
+	company, err := s.getBillingCompany(clientID, companyID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer models.Customer
+	if err := config.DB.Where("id = ? AND company_id = ?", customerID, company.ID).First(&customer).Error; err != nil {
+		return nil, errors.New("cliente não encontrado")
+	}
+
+	previousTaxID := customer.TaxID
+	if err := applyCustomerData(&customer, req); err != nil {
+		return nil, err
+	}
+	if customer.TaxID != previousTaxID {
+		var issued int64
+		config.DB.Model(&models.Invoice{}).Where("customer_id = ?", customer.ID).Count(&issued)
+		if issued > 0 {
+			return nil, errors.New("não é possível alterar o NIF de um cliente com documentos emitidos")
+		}
+	}
+
+	if err := config.DB.Save(&customer).Error; err != nil {
+		return nil, errors.New("erro ao atualizar cliente")
+	}
+	return &customer, nil
+}
+
+// GetSeries lista as séries de documentos da empresa
+func (s *BillingService) GetSeries(clientID, companyID uint) ([]models.InvoiceSeries, error) {
+	company, err := s.getBillingCompany(clientID, companyID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var series []models.InvoiceSeries
+	if err := config.DB.Where("company_id = ?", company.ID).Order("document_type ASC, code ASC").Find(&series).Error; err != nil {
+		return nil, errors.New("erro ao buscar séries")
+	}
+	return series, nil
+}
+
+// CreateSeries regista uma série já comunicada à AT, com o código de validação recebido
+func (s *BillingService) CreateSeries(clientID, companyID uint, req models.CreateInvoiceSeriesDTO) (*models.InvoiceSeries, error) {
+	company, err := s.getBillingCompany(clientID, companyID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	series := models.InvoiceSeries{
+		CompanyID:      company.ID,
+		DocumentType:   req.DocumentType,
+		Code:           strings.ToUpper(req.Code),
+		ValidationCode: strings.ToUpper(req.ValidationCode),
+		Active:         true,
+	}
+
+	var count int64
+	config.DB.Model(&models.InvoiceSeries{}).
+		Where("company_id = ? AND document_type = ? AND code = ?", company.ID, series.DocumentType, series.Code).
+		Count(&count)
+	if count > 0 {
+		return nil, errors.New("já existe uma série com este código para o tipo de documento")
+	}
+
+	if err := config.DB.Create(&series).Error; err != nil {
+		return nil, errors.New("erro ao criar série")
+	}
+	return &series, nil
+}
+
+// GetInvoices lista os documentos emitidos, filtrados por tipo e intervalo de datas
+func (s *BillingService) GetInvoices(clientID, companyID uint, documentType, from, to string) ([]models.Invoice, error) {
+	company, err := s.getBillingCompany(clientID, companyID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("company_id = ?", company.ID)
+	if documentType != "" {
+		query = query.Where("document_type = ?", documentType)
+	}
+	if from != "" {
+		date, err := parseDateOrDefault(from, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("issue_date >= ?", date)
+	}
+	if to != "" {
+		date, err := parseDateOrDefault(to, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("issue_date <= ?", date)
+	}
+
+	var invoices []models.Invoice
+	if err := query.Preload("Customer").Order("issue_date DESC, id DESC").Find(&invoices).Error; err != nil {
+		return nil, errors.New("erro ao buscar documentos")
+	}
+	return invoices, nil
+}
+
+// GetInvoice devolve um documento com as linhas e o cliente
+func (s *BillingService) GetInvoice(clientID, companyID, invoiceID uint) (*models.Invoice, error) {
+	company, err := s.getBillingCompany(clientID, companyID, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadInvoice(company.ID, invoiceID)
+}
+
+// IssueInvoice emite uma fatura (FT, FR ou FS) com o próximo número da série, o ATCUD e a assinatura
+// encadeada com o documento anterior. Os documentos emitidos não podem ser alterados nem apagados.
+func (s *BillingService) IssueInvoice(clientID, companyID uint, req models.CreateInvoiceDTO) (*models.Invoice, error) {
+	company, err := s.getBillingCompany(clientID, companyID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	issueDate, err := parseDateOrDefault(req.IssueDate, today())
+	if err != nil {
+		return nil, err
+	}
+	var dueDate *time.Time
+	if req.DueDate != "" {
+		date, err := parseDateOrDefault(req.DueDate, issueDate)
+		if err != nil {
+			return nil, err
+		}
+		if date.Before(issueDate) {
+			return nil, errors.New("a data de vencimento não pode ser anterior à data de emissão")
+		}
+		dueDate = &date
+	}
+
+	customer, err := s.resolveCustomer(company.ID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	region := companyTaxRegion(company)
+	invoice := &models.Invoice{
+		CompanyID:  company.ID,
+		CustomerID: customer.ID,
+		DueDate:    dueDate,
+		CreatedBy:  clientID,
+	}
+	for i, line := range req.Lines {
+		if err := validateLineTax(region, line.TaxRate, line.TaxExemptionCode); err != nil {
+			return nil, fmt.Errorf("linha %d: %s", i+1, err.Error())
+		}
+		invoice.Lines = append(invoice.Lines, models.InvoiceLine{
+			LineNumber:       i + 1,
+			Description:      strings.TrimSpace(line.Description),
+			Quantity:         line.Quantity,
+			UnitPrice:        line.UnitPrice,
+			TaxRate:          line.TaxRate,
+			TaxExemptionCode: exemptionCodeForRate(line.TaxRate, line.TaxExemptionCode),
+		})
+	}
+	computeInvoiceTotals(invoice)
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		series, err := lockSeries(tx, company.ID, req.SeriesID)
+		if err != nil {
+			return err
+		}
+		if series.DocumentType == models.InvoiceTypeCreditNote {
+			return errors.New("as notas de crédito são emitidas a partir da fatura de origem")
+		}
+		if series.DocumentType == models.InvoiceTypeSimplified && invoice.GrossTotal > models.SimplifiedInvoiceMaxAmount {
+			return fmt.Errorf("as faturas simplificadas estão limitadas a %.2f EUR", models.SimplifiedInvoiceMaxAmount)
+		}
+		return issueDocument(tx, series, invoice, issueDate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadInvoice(company.ID, invoice.ID)
+}
+
+// IssueCreditNote emite uma nota de crédito sobre uma fatura. Sem linhas, credita tudo o que ainda está
+// por creditar; com linhas, cada quantidade não pode exceder o que falta creditar na linha de origem.
+func (s *BillingService) IssueCreditNote(clientID, companyID, invoiceID uint, req models.CreateCreditNoteDTO) (*models.Invoice, error) {
+	company, err := s.getBillingCompany(clientID, companyID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := s.loadInvoice(company.ID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if original.DocumentType == models.InvoiceTypeCreditNote {
+		return nil, errors.New("não é possível emitir uma nota de crédito sobre outra nota de crédito")
+	}
+
+	issueDate, err := parseDateOrDefault(req.IssueDate, today())
+	if err != nil {
+		return nil, err
+	}
+	if issueDate.Before(original.IssueDate) {
+		return nil, errors.New("a nota de crédito não pode ser anterior à fatura de origem")
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("indique o motivo da nota de crédito")
+	}
+
+	creditNote := &models.Invoice{
+		CompanyID:          company.ID,
+		CustomerID:         original.CustomerID,
+		ReferenceInvoiceID: &original.ID,
+		Reason:             reason,
+		CreatedBy:          clientID,
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		series, err := lockSeries(tx, company.ID, req.SeriesID)
+		if err != nil {
+			return err
+		}
+		if series.DocumentType != models.InvoiceTypeCreditNote {
+			return errors.New("a série indicada não é de notas de crédito")
+		}
+
+		// A série bloqueada serializa as notas de crédito, pelo que o saldo por creditar é calculado dentro da transação
+		remaining, err := remainingCreditQuantities(tx, original)
+		if err != nil {
+			return err
+		}
+
+		requested := make(map[uint]float64)
+		if len(req.Lines) == 0 {
+			for _, line := range original.Lines {
+				if remaining[line.ID] > 0 {
+					requested[line.ID] = remaining[line.ID]
+				}
+			}
+		}
+		for i, line := range req.Lines {
+			left, ok := remaining[line.InvoiceLineID]
+			if !ok {
+				return fmt.Errorf("linha %d: a linha não pertence à fatura de origem", i+1)
+			}
+			requested[line.InvoiceLineID] += line.Quantity
+			if requested[line.InvoiceLineID] > left+1e-9 {
+				return fmt.Errorf("linha %d: só é possível creditar %s unidades", i+1, utils.FormatPTNumber(left))
+			}
+		}
+		if len(requested) == 0 {
+			return errors.New("a fatura já foi totalmente creditada")
+		}
+
+		for _, line := range original.Lines {
+			quantity, ok := requested[line.ID]
+			if !ok {
+				continue
+			}
+			lineID := line.ID
+			creditNote.Lines = append(creditNote.Lines, models.InvoiceLine{
+				LineNumber:       len(creditNote.Lines) + 1,
+				Description:      line.Description,
+				Quantity:         quantity,
+				UnitPrice:        line.UnitPrice,
+				TaxRate:          line.TaxRate,
+				TaxExemptionCode: line.TaxExemptionCode,
+				ReferenceLineID:  &lineID,
+			})
+		}
+		computeInvoiceTotals(creditNote)
+
+		return issueDocument(tx, series, creditNote, issueDate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadInvoice(company.ID, creditNote.ID)
+}
+
+// GetInvoicePDF gera o PDF do documento com o ATCUD, o código QR e o excerto da assinatura
+func (s *BillingService) GetInvoicePDF(clientID, companyID, invoiceID uint) ([]byte, string, error) {
+	company, err := s.getBillingCompany(clientID, companyID, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	invoice, err := s.loadInvoice(company.ID, invoiceID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	doc := utils.InvoicePDF{
+		Title:      invoiceDocumentTitles[invoice.DocumentType],
+		DocumentNo: invoice.DocumentNo,
+		ATCUD:      invoice.ATCUD,
+		IssueDate:  invoice.IssueDate.Format("2006-01-02"),
+		Issuer: utils.InvoicePDFParty{
+			Name:       company.CompanyName,
+			TaxID:      company.NIPC,
+			Address:    company.Address,
+			PostalCode: company.PostalCode,
+			City:       company.City,
+		},
+		NetTotal:   invoice.NetTotal,
+		TaxTotal:   invoice.TaxTotal,
+		GrossTotal: invoice.GrossTotal,
+		QRCode:     invoiceQRCode(company, invoice),
+		Footer:     fmt.Sprintf("%s-Processado por programa certificado n.º %s/AT", utils.InvoiceHashExcerpt(invoice.Hash), utils.BillingCertificateNumber()),
+	}
+	if invoice.DueDate != nil {
+		doc.DueDate = invoice.DueDate.Format("2006-01-02")
+	}
+	if invoice.Customer != nil {
+		doc.Customer = utils.InvoicePDFParty{
+			Name:       invoice.Customer.Name,
+			TaxID:      invoice.Customer.TaxID,
+			Address:    invoice.Customer.Address,
+			PostalCode: invoice.Customer.PostalCode,
+			City:       invoice.Customer.City,
+		}
+	}
+	if invoice.ReferenceInvoiceID != nil {
+		var original models.Invoice
+		if err := config.DB.Select("document_no", "issue_date").First(&original, *invoice.ReferenceInvoiceID).Error; err == nil {
+			doc.Reference = fmt.Sprintf("Referente a %s de %s. Motivo: %s", original.DocumentNo, original.IssueDate.Format("2006-01-02"), invoice.Reason)
+		}
+	}
+
+	for _, line := range invoice.Lines {
+		doc.Lines = append(doc.Lines, utils.InvoicePDFLine{
+			Description:     line.Description,
+			Quantity:        line.Quantity,
+			UnitPrice:       line.UnitPrice,
+			TaxRate:         line.TaxRate,
+			NetAmount:       line.NetAmount,
+			ExemptionReason: exemptionReason(line.TaxExemptionCode),
+		})
+	}
+	for _, total := range taxTotalsByRate(invoice.Lines) {
+		doc.Taxes = append(doc.Taxes, utils.InvoicePDFTax{Rate: total.Rate, Base: total.NetAmount, Tax: total.TaxAmount})
+	}
+
+	content, err := utils.RenderInvoicePDF(doc)
+	if err != nil {
+		return nil, "", errors.New("erro ao gerar PDF")
+	}
+
+	filename := strings.NewReplacer(" ", "_", "/", "-").Replace(invoice.DocumentNo) + ".pdf"
+	return content, filename, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// getBillingCompany devolve a empresa do cliente (companyID 0 = principal), se este não usar software de
+// faturação próprio. Para emitir documentos ou alterar dados, o cliente tem de ser owner ou manager da empresa.
+func (s *BillingService) getBillingCompany(clientID, companyID uint, write bool) (*models.Company, error) {
+	var user models.User
+	if err := config.DB.First(&user, clientID).Error; err != nil {
+		return nil, errors.New("utilizador não encontrado")
+	}
+	if strings.TrimSpace(user.BillingSoftware) != "" {
+		return nil, errors.New("o cliente já usa software de faturação próprio")
+	}
+
+	var company *models.Company
+	var err error
+	if write {
+		company, err = writableClientCompany(clientID, companyID, clientID)
+	} else {
+		company, err = clientCompany(clientID, companyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !utils.ValidNIF(company.NIPC) {
+		return nil, errors.New("a empresa não tem um NIPC válido registado")
+	}
+	return company, nil
+}
+
+func (s *BillingService) loadInvoice(companyID, invoiceID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := config.DB.
+		Preload("Customer").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("line_number ASC") }).
+		Where("id = ? AND company_id = ?", invoiceID, companyID).
+		First(&invoice).Error
+	if err != nil {
+		return nil, errors.New("documento não encontrado")
+	}
+	return &invoice, nil
+}
+
+// resolveCustomer devolve o cliente indicado ou, sem cliente, o consumidor final da empresa
+func (s *BillingService) resolveCustomer(companyID uint, customerID *uint) (*models.Customer, error) {
+	var customer models.Customer
+	if customerID != nil {
+		if err := config.DB.Where("id = ? AND company_id = ?", *customerID, companyID).First(&customer).Error; err != nil {
+			return nil, errors.New("cliente não encontrado")
+		}
+		return &customer, nil
+	}
+
+	err := config.DB.
+		Where(models.Customer{CompanyID: companyID, TaxID: models.FinalConsumerTaxID}).
+		Attrs(models.Customer{Name: models.FinalConsumerName, Country: "PT"}).
+		FirstOrCreate(&customer).Error
+	if err != nil {
+		return nil, errors.New("erro ao obter consumidor final")
+	}
+	return &customer, nil
+}
+
+func applyCustomerData(customer *models.Customer, req models.CustomerDTO) error {
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+	if country == "" {
+		country = "PT"
+	}
+	taxID := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(req.TaxID), " ", ""))
+	if country == "PT" {
+		taxID = strings.TrimPrefix(taxID, "PT")
+		if !utils.ValidNIF(taxID) {
+			return errors.New("NIF inválido")
+		}
+	}
+
+	customer.TaxID = taxID
+	customer.Name = strings.TrimSpace(req.Name)
+	customer.Address = strings.TrimSpace(req.Address)
+	customer.PostalCode = strings.TrimSpace(req.PostalCode)
+	customer.City = strings.TrimSpace(req.City)
+	customer.Country = country
+	customer.Email = strings.TrimSpace(req.Email)
+	return nil
+}
+
+// lockSeries bloqueia a série até ao fim da transação para garantir a numeração sem falhas
+func lockSeries(tx *gorm.DB, companyID, seriesID uint) (*models.InvoiceSeries, error) {
+	var series models.InvoiceSeries
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND company_id = ?", seriesID, companyID).
+		First(&series).Error
+	if err != nil {
+		return nil, errors.New("série não encontrada")
+	}
+	if !series.Active {
+		return nil, errors.New("a série está inativa")
+	}
+	return &series, nil
+}
+
+// issueDocument numera, assina e grava o documento, atualizando o último número e hash da série
+func issueDocument(tx *gorm.DB, series *models.InvoiceSeries, invoice *models.Invoice, issueDate time.Time) error {
+	if issueDate.After(today()) {
+		return errors.New("a data de emissão não pode ser futura")
+	}
+	if series.LastIssueDate != nil && issueDate.Before(*series.LastIssueDate) {
+		return errors.New("a data de emissão não pode ser anterior à do último documento da série")
+	}
+	if err := NewFiscalPeriodService().EnsureWritable(series.CompanyID, issueDate, models.RoleClient); err != nil {
+		return err
+	}
+
+	invoice.SeriesID = series.ID
+	invoice.DocumentType = series.DocumentType
+	invoice.Number = series.LastNumber + 1
+	invoice.DocumentNo = fmt.Sprintf("%s %s/%d", series.DocumentType, series.Code, invoice.Number)
+	invoice.ATCUD = utils.InvoiceATCUD(series.ValidationCode, invoice.Number)
+	invoice.Status = models.InvoiceStatusNormal
+	invoice.IssueDate = issueDate
+	invoice.SystemEntryDate = time.Now().Truncate(time.Second)
+
+	hash, err := utils.SignInvoice(utils.InvoiceHashMessage(invoice.IssueDate, invoice.SystemEntryDate, invoice.DocumentNo, invoice.GrossTotal, series.LastHash))
+	if err != nil {
+		return err
+	}
+	invoice.Hash = hash
+	invoice.HashControl = utils.BillingKeyVersion()
+
+	if err := tx.Create(invoice).Error; err != nil {
+		return errors.New("erro ao emitir documento")
+	}
+
+	series.LastNumber = invoice.Number
+	series.LastHash = hash
+	series.LastIssueDate = &issueDate
+	if err := tx.Save(series).Error; err != nil {
+		return errors.New("erro ao atualizar série")
+	}
+	return nil
+}
+
+// remainingCreditQuantities devolve, por linha da fatura, a quantidade ainda não creditada
+func remainingCreditQuantities(tx *gorm.DB, invoice *models.Invoice) (map[uint]float64, error) {
+	var credited []struct {
+		ReferenceLineID uint
+		Quantity        float64
+	}
+	err := tx.Model(&models.InvoiceLine{}).
+		Select("invoice_lines.reference_line_id, SUM(invoice_lines.quantity) AS quantity").
+		Joins("JOIN invoices ON invoices.id = invoice_lines.invoice_id").
+		Where("invoices.reference_invoice_id = ? AND invoice_lines.reference_line_id IS NOT NULL", invoice.ID).
+		Group("invoice_lines.reference_line_id").
+		Scan(&credited).Error
+	if err != nil {
+		return nil, errors.New("erro ao calcular valores creditados")
+	}
+
+	remaining := make(map[uint]float64, len(invoice.Lines))
+	for _, line := range invoice.Lines {
+		remaining[line.ID] = line.Quantity
+	}
+	for _, row := range credited {
+		remaining[row.ReferenceLineID] -= row.Quantity
+	}
+	return remaining, nil
+}
+
+func computeInvoiceTotals(invoice *models.Invoice) {
+	invoice.NetTotal, invoice.TaxTotal = 0, 0
+	for i := range invoice.Lines {
+		line := &invoice.Lines[i]
+		line.NetAmount = roundAmount(line.Quantity * line.UnitPrice)
+		line.TaxAmount = roundAmount(line.NetAmount * line.TaxRate / 100)
+		invoice.NetTotal += line.NetAmount
+		invoice.TaxTotal += line.TaxAmount
+	}
+	invoice.NetTotal = roundAmount(invoice.NetTotal)
+	invoice.TaxTotal = roundAmount(invoice.TaxTotal)
+	invoice.GrossTotal = roundAmount(invoice.NetTotal + invoice.TaxTotal)
+}
+
+// validateLineTax aceita as taxas em vigor na região da empresa, ou taxa 0 com motivo de isenção
+func validateLineTax(region string, rate float64, exemptionCode string) error {
+	if rate == 0 {
+		if !taxExemptionCodePattern.MatchString(strings.ToUpper(exemptionCode)) {
+			return errors.New("indique o motivo de isenção (M01 a M99) para a taxa 0")
+		}
+		return nil
+	}
+	if _, _, ok := classifyVATRate(region, rate); !ok {
+		return fmt.Errorf("taxa de IVA %g%% não existe na região %s", rate, region)
+	}
+	return nil
+}
+
+func exemptionCodeForRate(rate float64, code string) string {
+	if rate != 0 {
+		return ""
+	}
+	return strings.ToUpper(code)
+}
+
+func exemptionReason(code string) string {
+	if code == "" {
+		return ""
+	}
+	if reason, ok := taxExemptionReasons[code]; ok {
+		return code + " - " + reason
+	}
+	return code
+}
+
+// companyTaxRegion deduz a região fiscal (PT, PT-AC, PT-MA) a partir do distrito da sede
+func companyTaxRegion(company *models.Company) string {
+	district := strings.ToLower(utils.RemoveAccents(strings.TrimSpace(company.District)))
+	for _, name := range azoresDistricts {
+		if strings.Contains(district, name) {
+			return "PT-AC"
+		}
+	}
+	for _, name := range madeiraDistricts {
+		if strings.Contains(district, name) {
+			return "PT-MA"
+		}
+	}
+	return "PT"
+}
+
+func taxTotalsByRate(lines []models.InvoiceLine) []models.VATRateTotalsDTO {
+	byRate := make(map[float64]*models.VATRateTotalsDTO)
+	for _, line := range lines {
+		total, ok := byRate[line.TaxRate]
+		if !ok {
+			total = &models.VATRateTotalsDTO{Rate: line.TaxRate}
+			byRate[line.TaxRate] = total
+		}
+		total.NetAmount = roundAmount(total.NetAmount + line.NetAmount)
+		total.TaxAmount = roundAmount(total.TaxAmount + line.TaxAmount)
+	}
+
+	totals := make([]models.VATRateTotalsDTO, 0, len(byRate))
+	for _, total := range byRate {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Rate < totals[j].Rate })
+	return totals
+}
+
+// invoiceQRCode monta o conteúdo do código QR (Portaria n.º 195/2020). Os valores da região da
+// empresa vão nos campos I (Continente), J (Açores) ou K (Madeira).
+func invoiceQRCode(company *models.Company, invoice *models.Invoice) string {
+	customerTaxID, customerCountry := models.FinalConsumerTaxID, "PT"
+	if invoice.Customer != nil {
+		customerTaxID = invoice.Customer.TaxID
+		if invoice.Customer.Country != "" {
+			customerCountry = invoice.Customer.Country
+		}
+	}
+
+	fields := []string{
+		"A:" + company.NIPC,
+		"B:" + customerTaxID,
+		"C:" + customerCountry,
+		"D:" + invoice.DocumentType,
+		"E:" + invoice.Status,
+		"F:" + invoice.IssueDate.Format("20060102"),
+		"G:" + invoice.DocumentNo,
+		"H:" + invoice.ATCUD,
+	}
+
+	region := companyTaxRegion(company)
+	prefix := map[string]string{"PT": "I", "PT-AC": "J", "PT-MA": "K"}[region]
+	if prefix != "I" {
+		fields = append(fields, "I1:0")
+	}
+	fields = append(fields, prefix+"1:"+region)
+
+	// Posições 2 (base isenta), 3/4 (reduzida), 5/6 (intermédia) e 7/8 (normal)
+	var amounts [9]float64
+	for _, total := range taxTotalsByRate(invoice.Lines) {
+		if total.Rate == 0 {
+			amounts[2] += total.NetAmount
+			continue
+		}
+		if _, class, ok := classifyVATRate(region, total.Rate); ok {
+			amounts[3+2*class] += total.NetAmount
+			amounts[4+2*class] += total.TaxAmount
+		}
+	}
+	for position := 2; position <= 8; position++ {
+		if amounts[position] != 0 {
+			fields = append(fields, fmt.Sprintf("%s%d:%.2f", prefix, position, amounts[position]))
+		}
+	}
+
+	fields = append(fields,
+		fmt.Sprintf("N:%.2f", invoice.TaxTotal),
+		fmt.Sprintf("O:%.2f", invoice.GrossTotal),
+		"Q:"+utils.InvoiceHashExcerpt(invoice.Hash),
+		"R:"+utils.BillingCertificateNumber(),
+	)
+	return strings.Join(fields, "*")
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+}
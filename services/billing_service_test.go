@@ -0,0 +1,82 @@
+package services
+
+import (
+	"RVContabilidadeBack/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Exemplo das especificações técnicas do código QR publicadas pela AT (Portaria n.º 195/2020)
+func TestInvoiceQRCodeATExample(t *testing.T) {
+	t.Setenv("BILLING_CERTIFICATE_NUMBER", "9999")
+
+	company := &models.Company{NIPC: "123456789", District: "Lisboa"}
+	invoice := &models.Invoice{
+		DocumentType: "FT",
+		Status:       models.InvoiceStatusNormal,
+		IssueDate:    time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC),
+		DocumentNo:   "FT AB2019/0035",
+		ATCUD:        "CSDF7T5H-0035",
+		TaxTotal:     0.15,
+		GrossTotal:   0.80,
+		Hash:         "b123456789h123456789G123456789U",
+		Lines:        []models.InvoiceLine{{TaxRate: 23, NetAmount: 0.65, TaxAmount: 0.15}},
+	}
+
+	want := "A:123456789*B:999999990*C:PT*D:FT*E:N*F:20191231*G:FT AB2019/0035*H:CSDF7T5H-0035*" +
+		"I1:PT*I7:0.65*I8:0.15*N:0.15*O:0.80*Q:bhGU*R:9999"
+	if got := invoiceQRCode(company, invoice); got != want {
+		t.Fatalf("código QR:\nobtido   %s\nesperado %s", got, want)
+	}
+}
+
+func TestInvoiceQRCodeRegionsAndExemptions(t *testing.T) {
+	t.Setenv("BILLING_CERTIFICATE_NUMBER", "9999")
+
+	customer := &models.Customer{TaxID: "509442013", Country: "PT"}
+	cases := []struct {
+		name     string
+		district string
+		lines    []models.InvoiceLine
+		want     string
+	}{
+		{
+			name:     "continente com base isenta e taxa reduzida",
+			district: "Porto",
+			lines: []models.InvoiceLine{
+				{TaxRate: 0, NetAmount: 10, TaxExemptionCode: "M07"},
+				{TaxRate: 6, NetAmount: 100, TaxAmount: 6},
+			},
+			want: "I1:PT*I2:10.00*I3:100.00*I4:6.00*",
+		},
+		{
+			name:     "Açores leva I1:0 e os valores no campo J",
+			district: "Ponta Delgada (Açores)",
+			lines:    []models.InvoiceLine{{TaxRate: 16, NetAmount: 50, TaxAmount: 8}},
+			want:     "I1:0*J1:PT-AC*J7:50.00*J8:8.00*",
+		},
+		{
+			name:     "Madeira leva I1:0 e os valores no campo K",
+			district: "Funchal (Madeira)",
+			lines:    []models.InvoiceLine{{TaxRate: 12, NetAmount: 20, TaxAmount: 2.4}},
+			want:     "I1:0*K1:PT-MA*K5:20.00*K6:2.40*",
+		},
+	}
+	for _, tc := range cases {
+		invoice := &models.Invoice{
+			DocumentType: "FT",
+			Status:       models.InvoiceStatusNormal,
+			IssueDate:    time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			DocumentNo:   "FT 2025A/1",
+			ATCUD:        "AAJFJMVNTN-1",
+			Customer:     customer,
+			Lines:        tc.lines,
+		}
+		got := invoiceQRCode(&models.Company{NIPC: "123456789", District: tc.district}, invoice)
+		prefix := "A:123456789*B:509442013*C:PT*D:FT*E:N*F:20250301*G:FT 2025A/1*H:AAJFJMVNTN-1*"
+		if !strings.HasPrefix(got, prefix+tc.want) {
+			t.Errorf("%s: obtido %q, esperado a começar por %q", tc.name, got, prefix+tc.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// calendarReminderDays são os alarmes (dias antes do prazo) incluídos em cada evento
+var calendarReminderDays = []int{7, 1}
+
+type CalendarService struct{}
+
+func NewCalendarService() *CalendarService {
+	return &CalendarService{}
+}
+
+// GetFeed devolve o endereço do feed do utilizador, criando o token se ainda não existir
+func (s *CalendarService) GetFeed(userID uint) (*models.CalendarFeedDTO, error) {
+	var token models.CalendarToken
+	if err := config.DB.Where("user_id = ?", userID).First(&token).Error; err != nil {
+		return s.RotateFeed(userID)
+	}
+	return buildCalendarFeedDTO(&token), nil
+}
+
+// RotateFeed gera um novo token, invalidando o endereço anterior
+func (s *CalendarService) RotateFeed(userID uint) (*models.CalendarFeedDTO, error) {
+	var token models.CalendarToken
+	config.DB.Where("user_id = ?", userID).First(&token)
+
+	token.UserID = userID
+	token.Token = utils.GenerateRandomToken() + utils.GenerateRandomToken()
+	token.LastAccessAt = nil
+	token.CreatedAt = time.Now()
+	if err := config.DB.Save(&token).Error; err != nil {
+		return nil, errors.New("erro ao gerar endereço do calendário")
+	}
+	return buildCalendarFeedDTO(&token), nil
+}
+
+// RevokeFeed desativa o feed do utilizador
+func (s *CalendarService) RevokeFeed(userID uint) error {
+	if err := config.DB.Where("user_id = ?", userID).Delete(&models.CalendarToken{}).Error; err != nil {
+		return errors.New("erro ao revogar calendário")
+	}
+	return nil
+}
+
+// BuildFeed gera o calendário ICS do dono do token: prazos da sua empresa (cliente)
+// ou das empresas que acompanha (contabilista)
+func (s *CalendarService) BuildFeed(tokenValue string) ([]byte, error) {
+	var token models.CalendarToken
+	if tokenValue == "" || config.DB.Where("token = ?", tokenValue).First(&token).Error != nil {
+		return nil, errors.New("calendário não encontrado")
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, token.UserID).Error; err != nil || user.Status != "approved" {
+		return nil, errors.New("calendário não encontrado")
+	}
+
+	companies, err := s.feedCompanies(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	obligations, err := NewObligationService().GetCalendarObligations(companies, now.AddDate(0, -3, 0), now.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]utils.ICSEvent, 0, len(obligations))
+	for _, obligation := range obligations {
+		summary := ObligationLabel(obligation.Type)
+		if user.Role != models.RoleClient && obligation.Company != nil {
+			summary = obligation.Company.CompanyName + ": " + summary
+		}
+		if obligation.Status == models.ObligationStatusSubmitted {
+			summary += " (submetida)"
+		}
+
+		event := utils.ICSEvent{
+			UID:          fmt.Sprintf("obligation-%d@rvcontabilidade", obligation.ID),
+			Summary:      summary,
+			Description:  fmt.Sprintf("Período: %s", obligation.Period),
+			Date:         obligation.DueDate,
+			Sequence:     obligation.Sequence,
+			LastModified: obligation.UpdatedAt,
+			Cancelled:    obligation.Status == models.ObligationStatusWaived,
+		}
+		if obligation.Company != nil {
+			event.Description += fmt.Sprintf("\nEmpresa: %s (NIPC %s)", obligation.Company.CompanyName, obligation.Company.NIPC)
+		}
+		if obligation.Status == models.ObligationStatusPending {
+			event.Reminders = calendarReminderDays
+		}
+		events = append(events, event)
+	}
+
+	config.DB.Model(&token).Update("last_access_at", now)
+
+	return utils.BuildICS("Prazos fiscais - RV Contabilidade", events), nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// feedCompanies devolve as empresas cujos prazos aparecem no feed do utilizador:
+// as empresas do cliente ou, para a equipa, as da carteira (todas com clients.all)
+func (s *CalendarService) feedCompanies(user *models.User) ([]models.Company, error) {
+	var companies []models.Company
+	query := config.DB.Where("companies.status = ?", "active")
+
+	if user.Role == models.RoleClient {
+		query = query.Where("companies.id IN (?)", userCompanyIDs(user.ID))
+	} else {
+		global := NewPermissionService().EffectivePermissions(user.ID, user.Role)[models.PermissionClientsAll]
+		query = NewPortfolioService().ScopeFor(user.ID, global).Companies(query, "companies.id")
+	}
+
+	if err := query.Find(&companies).Error; err != nil {
+		return nil, errors.New("erro ao obter empresas")
+	}
+	return companies, nil
+}
+
+func buildCalendarFeedDTO(token *models.CalendarToken) *models.CalendarFeedDTO {
+	return &models.CalendarFeedDTO{
+		URL:          fmt.Sprintf("%s/api/calendar/%s/deadlines.ics", appBaseURL(), token.Token),
+		CreatedAt:    token.CreatedAt,
+		LastAccessAt: token.LastAccessAt,
+	}
+}
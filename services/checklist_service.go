@@ -0,0 +1,383 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// checklistDueDay é o dia do mês seguinte ao fim do período até ao qual os documentos devem ser entregues
+const checklistDueDay = 10
+
+// deliverableDocumentTypes indica que tipos de documento cumprem cada entregável
+var deliverableDocumentTypes = map[string][]string{
+	models.DeliverablePurchaseInvoices: {models.DocumentTypePurchaseInvoice, models.DocumentTypeInvoice, models.DocumentTypeReceipt},
+	models.DeliverableSalesInvoices:    {models.DocumentTypeSalesInvoice, models.DocumentTypeSAFT},
+	models.DeliverableBankStatements:   {models.DocumentTypeBankStatement},
+	models.DeliverablePayroll:          {models.DocumentTypePayroll},
+	models.DeliverableStockReport:      {models.DocumentTypeStockReport},
+}
+
+// checklistPeriod é um período de reporte (mês, trimestre ou ano)
+type checklistPeriod struct {
+	label string
+	start time.Time
+	end   time.Time // Último dia do período
+}
+
+type ChecklistService struct{}
+
+func NewChecklistService() *ChecklistService {
+	return &ChecklistService{}
+}
+
+// GenerateForMonth cria os itens das empresas ativas cujo período de reporte termina no mês indicado
+func (s *ChecklistService) GenerateForMonth(year int, month time.Month) (int, error) {
+	var companies []models.Company
+	if err := config.DB.Preload("User").
+		Joins("JOIN users ON users.id = companies.user_id").
+		Where("companies.status = ? AND users.status = ?", "active", "approved").
+		Find(&companies).Error; err != nil {
+		return 0, errors.New("erro ao obter empresas")
+	}
+
+	created := 0
+	for i := range companies {
+		company := &companies[i]
+		frequency := "mensal"
+		if company.User != nil {
+			frequency = company.User.ReportFrequency
+		}
+
+		period, ok := periodEndingIn(frequency, year, month)
+		if !ok {
+			continue
+		}
+
+		for _, deliverable := range expectedDeliverables(company) {
+			item := models.ChecklistItem{
+				CompanyID:   company.ID,
+				Period:      period.label,
+				PeriodStart: period.start,
+				PeriodEnd:   period.end,
+				Deliverable: deliverable,
+				DueDate:     time.Date(period.end.Year(), period.end.Month()+1, checklistDueDay, 23, 59, 59, 0, time.Local),
+				Status:      models.ChecklistStatusMissing,
+			}
+
+			// A geração pode correr várias vezes para o mesmo mês
+			var count int64
+			config.DB.Model(&models.ChecklistItem{}).
+				Where("company_id = ? AND period = ? AND deliverable = ?", company.ID, period.label, deliverable).
+				Count(&count)
+			if count > 0 {
+				continue
+			}
+
+			if err := config.DB.Create(&item).Error; err != nil {
+				return created, errors.New("erro ao gerar checklist")
+			}
+			created++
+			s.matchExistingDocuments(&item)
+		}
+	}
+
+	return created, nil
+}
+
+// GetClientChecklist lista os itens da checklist da empresa do cliente
+func (s *ChecklistService) GetClientChecklist(userID, companyID uint, period, status string) ([]models.ChecklistItem, error) {
+	company, err := clientCompany(userID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("company_id = ?", company.ID)
+	if period != "" {
+		query = query.Where("period = ?", period)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	items := []models.ChecklistItem{}
+	if err := query.Order("period_start DESC, deliverable ASC").Find(&items).Error; err != nil {
+		return nil, errors.New("erro ao obter checklist")
+	}
+
+	now := time.Now()
+	for i := range items {
+		items[i].Late = isChecklistItemLate(&items[i], now)
+	}
+	return items, nil
+}
+
+// UpdateItem dispensa, reabre ou dá como entregue um item da checklist de uma empresa da carteira
+func (s *ChecklistService) UpdateItem(itemID uint, req models.UpdateChecklistItemDTO, scope *PortfolioScope) (*models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	if err := scope.Companies(config.DB, "company_id").First(&item, itemID).Error; err != nil {
+		return nil, errors.New("item da checklist não encontrado")
+	}
+
+	item.Status = req.Status
+	item.Notes = req.Notes
+	if req.Status == models.ChecklistStatusSubmitted && item.SubmittedAt == nil {
+		now := time.Now()
+		item.SubmittedAt = &now
+	}
+	if req.Status == models.ChecklistStatusMissing {
+		item.SubmittedAt = nil
+		item.DocumentID = nil
+	}
+
+	if err := config.DB.Save(&item).Error; err != nil {
+		return nil, errors.New("erro ao atualizar item da checklist")
+	}
+
+	item.Late = isChecklistItemLate(&item, time.Now())
+	return &item, nil
+}
+
+// MarkDocumentSubmitted dá como entregues os itens em falta cumpridos por um documento
+func (s *ChecklistService) MarkDocumentSubmitted(document *models.Document) {
+	if document.FiscalPeriod == "" {
+		return
+	}
+
+	var deliverables []string
+	for deliverable, types := range deliverableDocumentTypes {
+		for _, docType := range types {
+			if docType == document.Type {
+				deliverables = append(deliverables, deliverable)
+			}
+		}
+	}
+	if len(deliverables) == 0 {
+		return
+	}
+
+	start, end, err := fiscalPeriodRange(document.FiscalPeriod)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&models.ChecklistItem{}).
+		Where("company_id = ? AND status = ? AND deliverable IN ?", document.CompanyID, models.ChecklistStatusMissing, deliverables).
+		Where("period_start <= ? AND period_end >= ?", start, end).
+		Updates(map[string]interface{}{
+			"status":       models.ChecklistStatusSubmitted,
+			"document_id":  document.ID,
+			"submitted_at": now,
+		}).Error; err != nil {
+		log.Printf("⚠️  Erro ao atualizar checklist com o documento %d: %v", document.ID, err)
+	}
+}
+
+// GetLateMatrix devolve, para cada empresa da carteira, o estado da checklist nos meses indicados (AAAA-MM)
+func (s *ChecklistService) GetLateMatrix(from, to string, scope *PortfolioScope) (*models.LateMatrixDTO, error) {
+	now := time.Now()
+	if to == "" {
+		to = now.AddDate(0, -1, 0).Format("2006-01")
+	}
+	if from == "" {
+		toMonth, err := time.ParseInLocation("2006-01", to, time.Local)
+		if err != nil {
+			return nil, errors.New("mês inválido (use AAAA-MM)")
+		}
+		from = toMonth.AddDate(0, -5, 0).Format("2006-01")
+	}
+
+	fromMonth, err := time.ParseInLocation("2006-01", from, time.Local)
+	if err != nil {
+		return nil, errors.New("mês inválido (use AAAA-MM)")
+	}
+	toMonth, err := time.ParseInLocation("2006-01", to, time.Local)
+	if err != nil {
+		return nil, errors.New("mês inválido (use AAAA-MM)")
+	}
+	if toMonth.Before(fromMonth) || toMonth.Sub(fromMonth) > 366*24*time.Hour {
+		return nil, errors.New("intervalo de meses inválido (máximo 12 meses)")
+	}
+
+	matrix := models.LateMatrixDTO{Months: []string{}, Rows: []models.LateMatrixRowDTO{}}
+	for month := fromMonth; !month.After(toMonth); month = month.AddDate(0, 1, 0) {
+		matrix.Months = append(matrix.Months, month.Format("2006-01"))
+	}
+
+	// Itens cujo período termina num dos meses da matriz
+	var items []models.ChecklistItem
+	if err := scope.Companies(config.DB.Preload("Company"), "company_id").
+		Where("period_end >= ? AND period_end < ?", fromMonth, toMonth.AddDate(0, 1, 0)).
+		Order("company_id ASC").
+		Find(&items).Error; err != nil {
+		return nil, errors.New("erro ao obter checklists")
+	}
+
+	rowIndex := make(map[uint]int)
+	cells := make(map[uint]map[string]*models.LateMatrixCellDTO)
+	for i := range items {
+		item := &items[i]
+		if _, ok := rowIndex[item.CompanyID]; !ok {
+			row := models.LateMatrixRowDTO{CompanyID: item.CompanyID}
+			if item.Company != nil {
+				row.CompanyName = item.Company.CompanyName
+				row.ClientID = item.Company.UserID
+			}
+			rowIndex[item.CompanyID] = len(matrix.Rows)
+			matrix.Rows = append(matrix.Rows, row)
+			cells[item.CompanyID] = make(map[string]*models.LateMatrixCellDTO)
+		}
+
+		month := item.PeriodEnd.Format("2006-01")
+		cell, ok := cells[item.CompanyID][month]
+		if !ok {
+			cell = &models.LateMatrixCellDTO{Period: item.Period, Status: "complete"}
+			cells[item.CompanyID][month] = cell
+		}
+		if item.Status != models.ChecklistStatusMissing {
+			continue
+		}
+		cell.Missing = append(cell.Missing, item.Deliverable)
+		if isChecklistItemLate(item, now) {
+			cell.Status = "late"
+		} else if cell.Status != "late" {
+			cell.Status = "pending"
+		}
+	}
+
+	for i := range matrix.Rows {
+		row := &matrix.Rows[i]
+		for _, month := range matrix.Months {
+			cell, ok := cells[row.CompanyID][month]
+			if !ok {
+				row.Cells = append(row.Cells, models.LateMatrixCellDTO{Period: month, Status: "none"})
+				continue
+			}
+			if cell.Status == "late" {
+				row.LateCount++
+			}
+			row.Cells = append(row.Cells, *cell)
+		}
+	}
+
+	return &matrix, nil
+}
+
+// StartChecklistGenerator gera as checklists do mês anterior no arranque e depois uma vez por dia
+func StartChecklistGenerator() {
+	service := NewChecklistService()
+	generate := func() {
+		previous := time.Now().AddDate(0, -1, 0)
+		if created, err := service.GenerateForMonth(previous.Year(), previous.Month()); err != nil {
+			log.Printf("❌ Erro ao gerar checklists: %v", err)
+		} else if created > 0 {
+			log.Printf("✅ %d itens de checklist gerados para %s", created, previous.Format("2006-01"))
+		}
+	}
+
+	go func() {
+		generate()
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			generate()
+		}
+	}()
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// matchExistingDocuments marca como entregue um item novo se já houver documento para o período
+func (s *ChecklistService) matchExistingDocuments(item *models.ChecklistItem) {
+	var periods []string
+	for month := item.PeriodStart; !month.After(item.PeriodEnd); month = month.AddDate(0, 1, 0) {
+		periods = append(periods, month.Format("2006-01"))
+	}
+	if item.PeriodStart.Month() == time.January && item.PeriodEnd.Month() == time.December {
+		periods = append(periods, item.PeriodStart.Format("2006"))
+	}
+
+	var document models.Document
+	if err := config.DB.Where("company_id = ? AND type IN ? AND fiscal_period IN ? AND status <> ?",
+		item.CompanyID, deliverableDocumentTypes[item.Deliverable], periods, models.DocumentStatusRejected).
+		Order("created_at ASC").
+		First(&document).Error; err != nil {
+		return
+	}
+
+	item.Status = models.ChecklistStatusSubmitted
+	item.DocumentID = &document.ID
+	item.SubmittedAt = &document.CreatedAt
+	config.DB.Save(item)
+}
+
+// expectedDeliverables define os documentos a entregar consoante IVA, stock e funcionários
+func expectedDeliverables(company *models.Company) []string {
+	deliverables := []string{models.DeliverableSalesInvoices, models.DeliverableBankStatements}
+
+	// No regime de isenção (art. 53.º) o IVA das compras não é dedutível e as faturas de compra
+	// só são necessárias no fecho do ano
+	if !strings.Contains(strings.ToLower(company.VATRegime), "isen") {
+		deliverables = append(deliverables, models.DeliverablePurchaseInvoices)
+	}
+	if company.NumberEmployees > 0 {
+		deliverables = append(deliverables, models.DeliverablePayroll)
+	}
+	if company.HasStock {
+		deliverables = append(deliverables, models.DeliverableStockReport)
+	}
+
+	return deliverables
+}
+
+// periodEndingIn devolve o período de reporte que termina no mês indicado, se existir
+func periodEndingIn(frequency string, year int, month time.Month) (checklistPeriod, bool) {
+	end := time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local)
+
+	switch strings.ToLower(frequency) {
+	case "trimestral":
+		if month%3 != 0 {
+			return checklistPeriod{}, false
+		}
+		return checklistPeriod{
+			label: fmt.Sprintf("%d-T%d", year, int(month)/3),
+			start: time.Date(year, month-2, 1, 0, 0, 0, 0, time.Local),
+			end:   end,
+		}, true
+	case "anual":
+		if month != time.December {
+			return checklistPeriod{}, false
+		}
+		return checklistPeriod{
+			label: fmt.Sprintf("%d", year),
+			start: time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local),
+			end:   end,
+		}, true
+	default:
+		return checklistPeriod{
+			label: fmt.Sprintf("%d-%02d", year, int(month)),
+			start: time.Date(year, month, 1, 0, 0, 0, 0, time.Local),
+			end:   end,
+		}, true
+	}
+}
+
+// fiscalPeriodRange converte um período fiscal de documento (AAAA-MM ou AAAA) em datas
+func fiscalPeriodRange(period string) (time.Time, time.Time, error) {
+	if month, err := time.ParseInLocation("2006-01", period, time.Local); err == nil {
+		return month, month.AddDate(0, 1, -1), nil
+	}
+	if year, err := time.ParseInLocation("2006", period, time.Local); err == nil {
+		return year, year.AddDate(1, 0, -1), nil
+	}
+	return time.Time{}, time.Time{}, errors.New("período fiscal inválido")
+}
+
+func isChecklistItemLate(item *models.ChecklistItem, now time.Time) bool {
+	return item.Status == models.ChecklistStatusMissing && now.After(item.DueDate)
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// clientImportBatchSize é o número de clientes criados em cada transação
+const clientImportBatchSize = 50
+
+type ClientImportService struct{}
+
+func NewClientImportService() *ClientImportService {
+	return &ClientImportService{}
+}
+
+// clientImportItem junta a linha do relatório à solicitação construída a partir dela
+type clientImportItem struct {
+	row     *models.ClientImportRow
+	request models.RegistrationRequest
+}
+
+// GetColumns lista as colunas aceites no ficheiro de importação
+func (s *ClientImportService) GetColumns() []models.ClientImportColumnDTO {
+	columns := make([]models.ClientImportColumnDTO, 0, len(utils.ClientImportColumns))
+	for _, column := range utils.ClientImportColumns {
+		columns = append(columns, models.ClientImportColumnDTO{
+			Field:    column.Field,
+			Aliases:  column.Aliases,
+			Required: column.Required,
+		})
+	}
+	return columns
+}
+
+// Import valida um ficheiro CSV/XLSX de clientes e, fora do modo de simulação, cria em lotes
+// os utilizadores e empresas aprovados (mode approved) ou as solicitações existing_client.
+// O relatório por linha fica guardado com a importação.
+func (s *ClientImportService) Import(fileHeader *multipart.FileHeader, mode string, dryRun bool, createdBy uint, ip string) (*models.ClientImport, error) {
+	if mode == "" {
+		mode = models.ClientImportModeApproved
+	}
+	if mode != models.ClientImportModeApproved && mode != models.ClientImportModeExistingClient {
+		return nil, errors.New("modo inválido (use approved ou existing_client)")
+	}
+
+	maxSize := maxUploadSize()
+	if fileHeader.Size > maxSize {
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, errors.New("erro ao ler ficheiro")
+	}
+	defer src.Close()
+
+	records, err := utils.ReadClientImportFile(src, fileHeader.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	job := models.ClientImport{
+		FileName:  filepath.Base(fileHeader.Filename),
+		Mode:      mode,
+		DryRun:    dryRun,
+		TotalRows: len(records),
+		CreatedBy: createdBy,
+		Rows:      make([]models.ClientImportRow, len(records)),
+	}
+
+	items := s.validate(records, job.Rows)
+	job.ValidRows = len(items)
+	job.InvalidRows = job.TotalRows - job.ValidRows
+
+	if !dryRun && len(items) > 0 {
+		passwordHash, err := unusablePasswordHash()
+		if err != nil {
+			return nil, errors.New("erro ao preparar contas")
+		}
+		for i := range items {
+			items[i].request.PasswordHash = passwordHash
+		}
+
+		for start := 0; start < len(items); start += clientImportBatchSize {
+			end := start + clientImportBatchSize
+			if end > len(items) {
+				end = len(items)
+			}
+			s.createBatch(items[start:end], mode)
+		}
+
+		for _, item := range items {
+			if item.row.Status == models.ClientImportRowCreated {
+				job.Created++
+			} else {
+				job.Failed++
+			}
+		}
+	}
+
+	if err := config.DB.Create(&job).Error; err != nil {
+		return nil, errors.New("erro ao registar importação")
+	}
+
+	if !dryRun {
+		s.afterCreate(job.Rows, mode)
+	}
+
+	NewAuditService().Record(createdBy, models.AuditActionClientImport, "client_import", &job.ID, map[string]interface{}{
+		"file_name": job.FileName,
+		"mode":      mode,
+		"dry_run":   dryRun,
+		"total":     job.TotalRows,
+		"created":   job.Created,
+	}, ip)
+
+	return &job, nil
+}
+
+// GetImports lista as importações de clientes, das mais recentes para as mais antigas
+func (s *ClientImportService) GetImports() ([]models.ClientImport, error) {
+	var imports []models.ClientImport
+	if err := config.DB.Order("created_at DESC").Find(&imports).Error; err != nil {
+		return nil, errors.New("erro ao obter importações")
+	}
+	return imports, nil
+}
+
+// GetImport devolve uma importação com o relatório por linha
+func (s *ClientImportService) GetImport(importID uint) (*models.ClientImport, error) {
+	var job models.ClientImport
+	if err := config.DB.Preload("Rows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line ASC")
+	}).First(&job, importID).Error; err != nil {
+		return nil, errors.New("importação não encontrada")
+	}
+	return &job, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// validate preenche o relatório de cada linha e devolve as linhas válidas, prontas a criar.
+// Os duplicados são procurados no próprio ficheiro, nos utilizadores e empresas existentes
+// e nas solicitações pendentes.
+func (s *ClientImportService) validate(records []utils.ClientImportRecord, rows []models.ClientImportRow) []clientImportItem {
+	for i := range records {
+		fields := records[i].Fields
+		fields["email"] = strings.ToLower(fields["email"])
+		fields["nif"] = strings.ReplaceAll(fields["nif"], " ", "")
+		fields["nipc"] = strings.ReplaceAll(fields["nipc"], " ", "")
+		if fields["username"] == "" {
+			fields["username"] = fields["email"]
+		}
+	}
+
+	existing := s.existingIdentifiers(records)
+	seen := map[string]int{}
+	items := []clientImportItem{}
+
+	for i, record := range records {
+		fields := record.Fields
+		row := &rows[i]
+		*row = models.ClientImportRow{
+			Line:        record.Line,
+			Name:        fields["name"],
+			Email:       fields["email"],
+			NIF:         fields["nif"],
+			CompanyName: fields["company_name"],
+			NIPC:        fields["nipc"],
+		}
+
+		problems := []string{}
+		for _, column := range utils.ClientImportColumns {
+			if column.Required && fields[column.Field] == "" {
+				problems = append(problems, column.Field+" em falta")
+			}
+		}
+		if fields["email"] != "" {
+			if _, err := mail.ParseAddress(fields["email"]); err != nil {
+				problems = append(problems, "email inválido")
+			}
+		}
+		if fields["nif"] != "" && !utils.ValidNIF(fields["nif"]) {
+			problems = append(problems, "NIF inválido")
+		}
+		if fields["nipc"] != "" && !utils.ValidNIF(fields["nipc"]) {
+			problems = append(problems, "NIPC inválido")
+		}
+
+		for _, key := range []string{"username", "email", "nif", "nipc"} {
+			value := fields[key]
+			if value == "" {
+				continue
+			}
+			if line, found := seen[key+":"+value]; found {
+				problems = append(problems, fmt.Sprintf("%s repetido (linha %d)", key, line))
+				continue
+			}
+			seen[key+":"+value] = record.Line
+			if reason, found := existing[key+":"+value]; found {
+				problems = append(problems, reason)
+			}
+		}
+
+		if len(problems) > 0 {
+			row.Status = models.ClientImportRowInvalid
+			row.Errors = strings.Join(problems, "; ")
+			continue
+		}
+
+		row.Status = models.ClientImportRowValid
+		items = append(items, clientImportItem{row: row, request: buildImportRequest(fields)})
+	}
+
+	return items
+}
+
+// existingIdentifiers procura de uma só vez os identificadores do ficheiro que já existem na base de dados
+func (s *ClientImportService) existingIdentifiers(records []utils.ClientImportRecord) map[string]string {
+	values := map[string][]string{}
+	for _, record := range records {
+		for _, key := range []string{"username", "email", "nif", "nipc"} {
+			if value := record.Fields[key]; value != "" {
+				values[key] = append(values[key], value)
+			}
+		}
+	}
+
+	existing := map[string]string{}
+	mark := func(key, reason string, found []string) {
+		for _, value := range found {
+			if _, done := existing[key+":"+value]; !done {
+				existing[key+":"+value] = reason
+			}
+		}
+	}
+
+	var found []string
+	if len(values["username"]) > 0 {
+		config.DB.Model(&models.User{}).Where("username IN ?", values["username"]).Pluck("username", &found)
+		mark("username", "username já está em uso", found)
+		found = nil
+		config.DB.Model(&models.RegistrationRequest{}).Where("username IN ? AND status = ?", values["username"], "pending").Pluck("username", &found)
+		mark("username", "já existe uma solicitação pendente com este username", found)
+	}
+	if len(values["email"]) > 0 {
+		found = nil
+		config.DB.Model(&models.User{}).Where("LOWER(email) IN ?", values["email"]).Pluck("LOWER(email)", &found)
+		mark("email", "já existe uma conta com este email", found)
+		found = nil
+		config.DB.Model(&models.RegistrationRequest{}).Where("LOWER(email) IN ? AND status = ?", values["email"], "pending").Pluck("LOWER(email)", &found)
+		mark("email", "já existe uma solicitação pendente com este email", found)
+	}
+	if len(values["nif"]) > 0 {
+		found = nil
+		config.DB.Model(&models.User{}).Where("nif IN ?", values["nif"]).Pluck("nif", &found)
+		mark("nif", "já existe uma conta com este NIF", found)
+		found = nil
+		config.DB.Model(&models.RegistrationRequest{}).Where("nif IN ? AND status = ?", values["nif"], "pending").Pluck("nif", &found)
+		mark("nif", "já existe uma solicitação pendente com este NIF", found)
+	}
+	if len(values["nipc"]) > 0 {
+		found = nil
+		config.DB.Model(&models.Company{}).Where("nipc IN ?", values["nipc"]).Pluck("nipc", &found)
+		mark("nipc", "já existe uma empresa com este NIPC", found)
+		found = nil
+		config.DB.Model(&models.RegistrationRequest{}).Where("nipc IN ? AND status = ?", values["nipc"], "pending").Pluck("nipc", &found)
+		mark("nipc", "já existe uma solicitação pendente com este NIPC", found)
+	}
+
+	return existing
+}
+
+// createBatch cria um lote numa só transação. Se o lote falhar, as linhas são repetidas
+// uma a uma para que só as que têm problemas fiquem por criar.
+func (s *ClientImportService) createBatch(items []clientImportItem, mode string) {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			if err := s.createItem(tx, &items[i], mode); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for i := range items {
+			items[i].row.Status = models.ClientImportRowCreated
+		}
+		return
+	}
+
+	for i := range items {
+		items[i].row.UserID = nil
+		items[i].row.RequestID = nil
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			return s.createItem(tx, &items[i], mode)
+		})
+		if err != nil {
+			items[i].row.UserID = nil
+			items[i].row.RequestID = nil
+			items[i].row.Status = models.ClientImportRowFailed
+			items[i].row.Errors = err.Error()
+			continue
+		}
+		items[i].row.Status = models.ClientImportRowCreated
+	}
+}
+
+// createItem cria o utilizador e a empresa aprovados ou a solicitação existing_client de uma linha
+func (s *ClientImportService) createItem(tx *gorm.DB, item *clientImportItem, mode string) error {
+	if mode == models.ClientImportModeExistingClient {
+		request := item.request
+		if err := tx.Create(&request).Error; err != nil {
+			return errors.New("erro ao criar solicitação")
+		}
+		item.row.RequestID = &request.ID
+		return nil
+	}
+
+	userID, _, err := NewAdminService().createUserAndCompany(tx, item.request)
+	if err != nil {
+		return err
+	}
+	if err := markPendingActivation(tx, userID); err != nil {
+		return errors.New("erro ao preparar ativação")
+	}
+	item.row.UserID = &userID
+	return nil
+}
+
+// afterCreate envia os links de ativação dos clientes criados ou distribui as solicitações pela equipa
+func (s *ClientImportService) afterCreate(rows []models.ClientImportRow, mode string) {
+	for _, row := range rows {
+		if row.Status != models.ClientImportRowCreated {
+			continue
+		}
+
+		if row.RequestID != nil {
+			var request models.RegistrationRequest
+			if err := config.DB.First(&request, *row.RequestID).Error; err == nil {
+				_ = NewReviewQueueService().AutoAssign(&request)
+			}
+			continue
+		}
+
+		if row.UserID != nil {
+			var user models.User
+			if err := config.DB.First(&user, *row.UserID).Error; err != nil {
+				continue
+			}
+			if err := NewActivationService().SendActivation(&user); err != nil {
+				log.Printf("⚠️  Erro ao enviar ativação do cliente importado %d: %v", user.ID, err)
+			}
+		}
+	}
+}
+
+// buildImportRequest constrói a solicitação existing_client com os dados de uma linha.
+// No modo approved serve apenas de base para criar o utilizador e a empresa.
+func buildImportRequest(fields map[string]string) models.RegistrationRequest {
+	optional := func(key string) *string {
+		if value := fields[key]; value != "" {
+			return &value
+		}
+		return nil
+	}
+
+	now := time.Now()
+	companyName := fields["company_name"]
+	return models.RegistrationRequest{
+		RequestType:       models.ClientImportModeExistingClient,
+		Status:            "pending",
+		ApprovalToken:     utils.GenerateRandomToken(),
+		EmailVerifiedAt:   &now, // Dados vindos do sistema antigo do gabinete
+		Username:          fields["username"],
+		Name:              optional("name"),
+		Email:             optional("email"),
+		Phone:             optional("phone"),
+		NIF:               optional("nif"),
+		FiscalAddress:     optional("fiscal_address"),
+		FiscalPostalCode:  optional("fiscal_postal_code"),
+		FiscalCity:        optional("fiscal_city"),
+		BillingSoftware:   optional("billing_software"),
+		CompanyName:       &companyName,
+		NIPC:              fields["nipc"],
+		LegalForm:         fields["legal_form"],
+		CAE:               optional("cae"),
+		VATRegime:         optional("vat_regime"),
+		AccountingRegime:  optional("accounting_regime"),
+		TradeName:         optional("trade_name"),
+		CompanyAddress:    optional("company_address"),
+		CompanyPostalCode: optional("company_postal_code"),
+		CompanyCity:       optional("company_city"),
+		BankName:          optional("bank_name"),
+		IBAN:              optional("iban"),
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"RVContabilidadeBack/config"
 	"RVContabilidadeBack/models"
 	"errors"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type CompanyService struct{}
@@ -13,10 +16,13 @@ func NewCompanyService() *CompanyService {
 	return &CompanyService{}
 }
 
-// GetCompanyByUserID obtém empresa pelo ID do utilizador
+// GetCompanyByUserID obtém a empresa principal do utilizador: a primeira de que é titular
+// ou, se não for titular de nenhuma, a primeira a que está ligado
 func (s *CompanyService) GetCompanyByUserID(userID uint) (*models.Company, error) {
 	var company models.Company
-	if err := config.DB.Where("user_id = ?", userID).First(&company).Error; err != nil {
+	if err := config.DB.Where("id IN (?)", userCompanyIDs(userID)).
+		Order(gorm.Expr("user_id = ? DESC, id ASC", userID)).
+		First(&company).Error; err != nil {
 		return nil, errors.New("empresa não encontrada")
 	}
 	return &company, nil
@@ -27,12 +33,91 @@ func (s *CompanyService) GetByUserID(userID uint) (*models.Company, error) {
 	return s.GetCompanyByUserID(userID)
 }
 
-// UpdateCompany atualiza dados da empresa (campos limitados para cliente)
-func (s *CompanyService) UpdateCompany(userID uint, req models.UpdateCompanyDTO) (*models.Company, error) {
-	var company models.Company
-	if err := config.DB.Where("user_id = ?", userID).First(&company).Error; err != nil {
+// GetUserCompanies lista as empresas do utilizador com o papel dele em cada uma
+func (s *CompanyService) GetUserCompanies(userID uint) ([]models.ClientCompanyDTO, error) {
+	var links []models.UserCompany
+	if err := config.DB.Preload("Company").
+		Joins("JOIN companies ON companies.id = user_companies.company_id").
+		Where("user_companies.user_id = ?", userID).
+		Order("companies.company_name ASC").
+		Find(&links).Error; err != nil {
+		return nil, errors.New("erro ao obter empresas")
+	}
+
+	result := make([]models.ClientCompanyDTO, 0, len(links))
+	for _, link := range links {
+		if link.Company != nil {
+			result = append(result, models.ClientCompanyDTO{Company: *link.Company, CompanyRole: link.Role})
+		}
+	}
+	return result, nil
+}
+
+// GetUserCompany obtém uma empresa a que o utilizador está ligado
+func (s *CompanyService) GetUserCompany(userID, companyID uint) (*models.ClientCompanyDTO, error) {
+	var link models.UserCompany
+	if err := config.DB.Preload("Company").
+		Where("user_id = ? AND company_id = ?", userID, companyID).
+		First(&link).Error; err != nil || link.Company == nil {
 		return nil, errors.New("empresa não encontrada")
 	}
+	return &models.ClientCompanyDTO{Company: *link.Company, CompanyRole: link.Role}, nil
+}
+
+// GetClientCompany obtém a empresa companyID do cliente, ou a empresa principal se companyID for 0
+func (s *CompanyService) GetClientCompany(clientID, companyID uint) (*models.ClientCompanyDTO, error) {
+	if companyID == 0 {
+		company, err := s.GetCompanyByUserID(clientID)
+		if err != nil {
+			return nil, err
+		}
+		companyID = company.ID
+	}
+	return s.GetUserCompany(clientID, companyID)
+}
+
+// clientCompany é a empresa do cliente sobre a qual os serviços trabalham (companyID 0 = principal)
+func clientCompany(clientID, companyID uint) (*models.Company, error) {
+	found, err := NewCompanyService().GetClientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return &found.Company, nil
+}
+
+// writableClientCompany é a empresa do cliente em que actorID vai escrever. Quando é o próprio cliente,
+// tem de ser owner ou manager; a equipa do gabinete não depende do papel do cliente na empresa.
+func writableClientCompany(clientID, companyID, actorID uint) (*models.Company, error) {
+	found, err := NewCompanyService().GetClientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if actorID == clientID && !models.CanEditCompany(found.CompanyRole) {
+		return nil, errors.New("sem permissão para alterar esta empresa")
+	}
+	return &found.Company, nil
+}
+
+// UpdateCompany atualiza dados da empresa principal (campos limitados para cliente)
+func (s *CompanyService) UpdateCompany(userID uint, req models.UpdateCompanyDTO) (*models.Company, error) {
+	company, err := s.GetCompanyByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdateUserCompany(userID, company.ID, req)
+}
+
+// UpdateUserCompany atualiza dados de uma empresa do utilizador (campos limitados para cliente).
+// Só o titular e os gerentes podem alterar; quem tem o papel viewer apenas consulta.
+func (s *CompanyService) UpdateUserCompany(userID, companyID uint, req models.UpdateCompanyDTO) (*models.Company, error) {
+	link, err := s.GetUserCompany(userID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if !models.CanEditCompany(link.CompanyRole) {
+		return nil, errors.New("sem permissão para alterar esta empresa")
+	}
+	company := link.Company
 
 	// Atualizar apenas campos permitidos para cliente
 	if req.TradeName != "" {
@@ -55,11 +140,21 @@ func (s *CompanyService) UpdateCompany(userID uint, req models.UpdateCompanyDTO)
 	return &company, nil
 }
 
-// CompleteCompanyData completa dados da empresa após aprovação
-func (s *CompanyService) CompleteCompanyData(userID uint, req models.CompleteCompanyDataDTO) (*models.Company, error) {
-	var company models.Company
-	if err := config.DB.Where("user_id = ?", userID).First(&company).Error; err != nil {
-		return nil, errors.New("empresa não encontrada")
+// CompleteCompanyData completa dados de uma empresa do cliente após aprovação (companyID 0 = principal).
+// Só o titular e os gerentes podem completar os dados.
+func (s *CompanyService) CompleteCompanyData(userID, companyID uint, req models.CompleteCompanyDataDTO) (*models.Company, error) {
+	found, err := writableClientCompany(userID, companyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	company := *found
+
+	// Os dados financeiros não podem mudar enquanto o período corrente estiver fechado
+	if company.ShareCapital != req.ShareCapital || company.BankName != req.BankName || company.IBAN != req.IBAN ||
+		company.BIC != req.BIC || company.AnnualRevenue != req.AnnualRevenue {
+		if err := NewFiscalPeriodService().EnsureWritable(company.ID, time.Now(), models.RoleClient); err != nil {
+			return nil, err
+		}
 	}
 
 	// Atualizar todos os campos
@@ -97,15 +192,16 @@ func (s *CompanyService) CompleteCompanyData(userID uint, req models.CompleteCom
 func (s *CompanyService) AdminUpdateCompany(clientID uint, req models.AdminUpdateCompanyDTO) (*models.Company, error) {
 	// Verificar se o cliente existe
 	var client models.User
-	if err := config.DB.Where("id = ? AND role = ? AND status = ?", clientID, "client", "approved").First(&client).Error; err != nil {
+	if err := config.DB.Where("id = ? AND role = ? AND status = ?", clientID, models.RoleClient, "approved").First(&client).Error; err != nil {
 		return nil, errors.New("cliente não encontrado ou não aprovado")
 	}
 
-	// Encontrar a empresa do cliente
-	var company models.Company
-	if err := config.DB.Where("user_id = ?", clientID).First(&company).Error; err != nil {
-		return nil, errors.New("empresa não encontrada")
+	// Encontrar a empresa principal do cliente
+	found, err := s.GetCompanyByUserID(clientID)
+	if err != nil {
+		return nil, err
 	}
+	company := *found
 
 	// Preparar dados para atualização
 	updateData := make(map[string]interface{})
@@ -206,19 +302,257 @@ func (s *CompanyService) AdminUpdateCompany(clientID uint, req models.AdminUpdat
 	return &company, nil
 }
 
-// CreateCompany cria nova empresa para um utilizador
+// CreateCompany cria nova empresa para um utilizador, que fica como titular
 func (s *CompanyService) CreateCompany(userID uint, companyData models.Company) (*models.Company, error) {
-	// Verificar se o utilizador já tem empresa
-	var existingCompany models.Company
-	if config.DB.Where("user_id = ?", userID).First(&existingCompany).Error == nil {
-		return nil, errors.New("utilizador já tem empresa")
-	}
-
 	companyData.UserID = userID
 
-	if err := config.DB.Create(&companyData).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&companyData).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserCompany{UserID: userID, CompanyID: companyData.ID, Role: models.CompanyRoleOwner}).Error
+	})
+	if err != nil {
 		return nil, errors.New("erro ao criar empresa")
 	}
 
 	return &companyData, nil
 }
+
+// attachUserCompanies preenche, através de user_companies, as empresas de cada utilizador (Companies) e a
+// empresa principal (Company), escolhida como em GetCompanyByUserID. Com scope, só entram as empresas da carteira.
+func attachUserCompanies(users []models.User, scope *PortfolioScope) error {
+	if len(users) == 0 {
+		return nil
+	}
+	byID := make(map[uint]*models.User, len(users))
+	userIDs := make([]uint, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+		userIDs[i] = users[i].ID
+	}
+
+	var links []models.UserCompany
+	query := config.DB.Preload("Company").
+		Joins("JOIN companies ON companies.id = user_companies.company_id").
+		Where("user_companies.user_id IN ?", userIDs)
+	if err := scope.Companies(query, "user_companies.company_id").
+		Order("companies.company_name ASC, companies.id ASC").
+		Find(&links).Error; err != nil {
+		return errors.New("erro ao obter empresas")
+	}
+
+	for _, link := range links {
+		user := byID[link.UserID]
+		if user == nil || link.Company == nil {
+			continue
+		}
+		user.Companies = append(user.Companies, models.ClientCompanyDTO{Company: *link.Company, CompanyRole: link.Role})
+	}
+	for i := range users {
+		users[i].Company = principalCompany(&users[i])
+	}
+	return nil
+}
+
+// attachUserCompany é attachUserCompanies para um só utilizador, com todas as empresas
+func attachUserCompany(user *models.User) error {
+	users := []models.User{*user}
+	if err := attachUserCompanies(users, nil); err != nil {
+		return err
+	}
+	*user = users[0]
+	return nil
+}
+
+// principalCompany escolhe entre as empresas já carregadas a primeira de que o utilizador é titular
+// ou, se não for titular de nenhuma, a primeira a que está ligado
+func principalCompany(user *models.User) *models.Company {
+	var principal *models.Company
+	for i := range user.Companies {
+		company := &user.Companies[i].Company
+		switch {
+		case principal == nil:
+			principal = company
+		case (company.UserID == user.ID) != (principal.UserID == user.ID):
+			if company.UserID == user.ID {
+				principal = company
+			}
+		case company.ID < principal.ID:
+			principal = company
+		}
+	}
+	if principal == nil {
+		return nil
+	}
+	copied := *principal
+	return &copied
+}
+
+// userCompanyIDs é a subconsulta com as empresas a que o utilizador está ligado
+func userCompanyIDs(userID uint) *gorm.DB {
+	return config.DB.Model(&models.UserCompany{}).Select("company_id").Where("user_id = ?", userID)
+}
+
+// ===== EMPRESAS DE VÁRIOS UTILIZADORES (gestão) =====
+
+// CreateClientCompany acrescenta uma empresa a um cliente existente. A nova empresa entra
+// nas carteiras dos contabilistas que já acompanham as outras empresas do cliente.
+func (s *CompanyService) CreateClientCompany(clientID uint, req models.CreateClientCompanyDTO, createdBy uint, ip string) (*models.ClientCompanyDTO, error) {
+	var client models.User
+	if err := config.DB.Where("id = ? AND role = ? AND status = ?", clientID, models.RoleClient, "approved").First(&client).Error; err != nil {
+		return nil, errors.New("cliente aprovado não encontrado")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.CompanyRoleOwner
+	}
+	if !models.IsValidCompanyRole(role) {
+		return nil, errors.New("papel inválido (use owner, manager ou viewer)")
+	}
+
+	nipc := strings.TrimSpace(req.NIPC)
+	if nipc != "" {
+		var count int64
+		config.DB.Model(&models.Company{}).Where("nipc = ?", nipc).Count(&count)
+		if count > 0 {
+			return nil, errors.New("já existe uma empresa com este NIPC")
+		}
+	}
+
+	company := models.Company{
+		UserID:           clientID,
+		CompanyName:      strings.TrimSpace(req.CompanyName),
+		NIPC:             nipc,
+		LegalForm:        req.LegalForm,
+		CAE:              req.CAE,
+		TradeName:        req.TradeName,
+		AccountingRegime: req.AccountingRegime,
+		VATRegime:        req.VATRegime,
+		BusinessActivity: req.BusinessActivity,
+		Address:          req.Address,
+		PostalCode:       req.PostalCode,
+		City:             req.City,
+		District:         req.District,
+		Status:           "active",
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&company).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.UserCompany{UserID: clientID, CompanyID: company.ID, Role: role}).Error; err != nil {
+			return err
+		}
+
+		var accountantIDs []uint
+		if err := tx.Model(&models.CompanyAccountant{}).
+			Distinct("accountant_id").
+			Where("company_id IN (?) AND company_id <> ?", userCompanyIDs(clientID), company.ID).
+			Pluck("accountant_id", &accountantIDs).Error; err != nil {
+			return err
+		}
+		for _, accountantID := range accountantIDs {
+			if err := tx.Create(&models.CompanyAccountant{CompanyID: company.ID, AccountantID: accountantID, AssignedBy: createdBy}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("erro ao criar empresa")
+	}
+
+	NewAuditService().Record(createdBy, models.AuditActionCompanyCreate, "company", &company.ID, map[string]interface{}{
+		"client_id":    clientID,
+		"company_name": company.CompanyName,
+		"nipc":         company.NIPC,
+		"role":         role,
+	}, ip)
+
+	return &models.ClientCompanyDTO{Company: company, CompanyRole: role}, nil
+}
+
+// GetCompanyUsers lista os utilizadores ligados a uma empresa e os respetivos papéis
+func (s *CompanyService) GetCompanyUsers(companyID uint) ([]models.UserCompany, error) {
+	var company models.Company
+	if err := config.DB.Select("id").First(&company, companyID).Error; err != nil {
+		return nil, errors.New("empresa não encontrada")
+	}
+
+	var links []models.UserCompany
+	if err := config.DB.Preload("User").Where("company_id = ?", companyID).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, errors.New("erro ao obter utilizadores da empresa")
+	}
+	return links, nil
+}
+
+// SetCompanyUser liga um cliente a uma empresa ou muda o seu papel. A empresa mantém sempre um titular.
+func (s *CompanyService) SetCompanyUser(companyID uint, req models.CompanyUserDTO, editorID uint, ip string) (*models.UserCompany, error) {
+	if !models.IsValidCompanyRole(req.Role) {
+		return nil, errors.New("papel inválido (use owner, manager ou viewer)")
+	}
+	var company models.Company
+	if err := config.DB.Select("id").First(&company, companyID).Error; err != nil {
+		return nil, errors.New("empresa não encontrada")
+	}
+	var user models.User
+	if err := config.DB.Where("id = ? AND role = ?", req.UserID, models.RoleClient).First(&user).Error; err != nil {
+		return nil, errors.New("cliente não encontrado")
+	}
+
+	var link models.UserCompany
+	previous := ""
+	if config.DB.Where("company_id = ? AND user_id = ?", companyID, req.UserID).First(&link).Error == nil {
+		previous = link.Role
+		if previous == models.CompanyRoleOwner && req.Role != models.CompanyRoleOwner && s.countOwners(companyID) <= 1 {
+			return nil, errors.New("a empresa tem de manter pelo menos um titular")
+		}
+		link.Role = req.Role
+		if err := config.DB.Save(&link).Error; err != nil {
+			return nil, errors.New("erro ao atualizar o acesso à empresa")
+		}
+	} else {
+		link = models.UserCompany{UserID: req.UserID, CompanyID: companyID, Role: req.Role}
+		if err := config.DB.Create(&link).Error; err != nil {
+			return nil, errors.New("erro ao ligar o cliente à empresa")
+		}
+	}
+
+	NewAuditService().Record(editorID, models.AuditActionCompanyUserLink, "company", &companyID, map[string]interface{}{
+		"user_id":       req.UserID,
+		"role":          req.Role,
+		"previous_role": previous,
+	}, ip)
+
+	link.User = &user
+	return &link, nil
+}
+
+// RemoveCompanyUser retira o acesso de um cliente a uma empresa. O último titular não pode ser retirado.
+func (s *CompanyService) RemoveCompanyUser(companyID, userID, editorID uint, ip string) error {
+	var link models.UserCompany
+	if err := config.DB.Where("company_id = ? AND user_id = ?", companyID, userID).First(&link).Error; err != nil {
+		return errors.New("acesso à empresa não encontrado")
+	}
+	if link.Role == models.CompanyRoleOwner && s.countOwners(companyID) <= 1 {
+		return errors.New("a empresa tem de manter pelo menos um titular")
+	}
+
+	if err := config.DB.Delete(&link).Error; err != nil {
+		return errors.New("erro ao retirar o acesso à empresa")
+	}
+
+	NewAuditService().Record(editorID, models.AuditActionCompanyUnlink, "company", &companyID, map[string]interface{}{
+		"user_id": userID,
+		"role":    link.Role,
+	}, ip)
+	return nil
+}
+
+func (s *CompanyService) countOwners(companyID uint) int64 {
+	var count int64
+	config.DB.Model(&models.UserCompany{}).Where("company_id = ? AND role = ?", companyID, models.CompanyRoleOwner).Count(&count)
+	return count
+}
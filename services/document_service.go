@@ -0,0 +1,252 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// allowedDocumentTypes são as extensões aceites para cada tipo MIME detetado
+var allowedDocumentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"text/plain":      ".txt", // CSV e extratos em texto
+	"text/xml":        ".xml", // SAF-T
+	"application/zip": ".zip", // XLSX e arquivos
+}
+
+var validDocumentTypes = map[string]bool{
+	models.DocumentTypeInvoice:         true,
+	models.DocumentTypePurchaseInvoice: true,
+	models.DocumentTypeSalesInvoice:    true,
+	models.DocumentTypeReceipt:         true,
+	models.DocumentTypeBankStatement:   true,
+	models.DocumentTypePayroll:         true,
+	models.DocumentTypeStockReport:     true,
+	models.DocumentTypeSAFT:            true,
+	models.DocumentTypeCitizenCard:     true,
+	models.DocumentTypeOther:           true,
+}
+
+var fiscalPeriodPattern = regexp.MustCompile(`^\d{4}(-(0[1-9]|1[0-2]))?$`)
+
+type DocumentService struct{}
+
+func NewDocumentService() *DocumentService {
+	return &DocumentService{}
+}
+
+// UploadDocument guarda um documento enviado pelo cliente na empresa associada
+func (s *DocumentService) UploadDocument(userID, companyID uint, fileHeader *multipart.FileHeader, docType, fiscalPeriod, notes string) (*models.Document, error) {
+	company, err := writableClientCompany(userID, companyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validDocumentTypes[docType] {
+		return nil, errors.New("tipo de documento inválido")
+	}
+	if fiscalPeriod != "" && !fiscalPeriodPattern.MatchString(fiscalPeriod) {
+		return nil, errors.New("período fiscal inválido (use AAAA-MM ou AAAA)")
+	}
+	if err := NewFiscalPeriodService().EnsureDocumentPeriodWritable(company.ID, fiscalPeriod, models.RoleClient); err != nil {
+		return nil, err
+	}
+
+	maxSize := maxUploadSize()
+	if fileHeader.Size > maxSize {
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, errors.New("erro ao ler ficheiro")
+	}
+	defer src.Close()
+
+	// Copiar para um ficheiro temporário calculando o hash e limitando o tamanho
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, errors.New("erro ao processar ficheiro")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, errors.New("erro ao processar ficheiro")
+	}
+	if size > maxSize {
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+	if size == 0 {
+		return nil, errors.New("ficheiro vazio")
+	}
+
+	// Detetar o tipo pelo conteúdo e não pelo nome ou cabeçalho enviado
+	head := make([]byte, 512)
+	n, _ := tmp.ReadAt(head, 0)
+	mimeType := strings.TrimSpace(strings.Split(http.DetectContentType(head[:n]), ";")[0])
+	ext, ok := allowedDocumentTypes[mimeType]
+	if !ok {
+		return nil, errors.New("tipo de ficheiro não permitido: " + mimeType)
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	var existing models.Document
+	if config.DB.Where("company_id = ? AND sha256 = ?", company.ID, hash).First(&existing).Error == nil {
+		return nil, errors.New("este documento já foi enviado")
+	}
+
+	period := fiscalPeriod
+	if period == "" {
+		period = "sem-periodo"
+	}
+	key := fmt.Sprintf("companies/%d/%s/%s/%s%s", company.ID, docType, period, hash, ext)
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.New("erro ao processar ficheiro")
+	}
+	if err := utils.GetStorage().Save(key, tmp); err != nil {
+		return nil, errors.New("erro ao guardar ficheiro")
+	}
+
+	document := models.Document{
+		CompanyID:    company.ID,
+		UploadedBy:   userID,
+		Type:         docType,
+		FiscalPeriod: fiscalPeriod,
+		OriginalName: filepath.Base(fileHeader.Filename),
+		MimeType:     mimeType,
+		Size:         size,
+		SHA256:       hash,
+		StorageKey:   key,
+		Notes:        notes,
+		Status:       models.DocumentStatusReceived,
+	}
+	if err := config.DB.Create(&document).Error; err != nil {
+		utils.GetStorage().Delete(key)
+		return nil, errors.New("erro ao guardar documento")
+	}
+
+	NewChecklistService().MarkDocumentSubmitted(&document)
+	NewEFaturaService().MatchDocument(&document)
+
+	return &document, nil
+}
+
+// GetClientDocuments lista os documentos da empresa do cliente
+func (s *DocumentService) GetClientDocuments(userID, companyID uint, docType, fiscalPeriod, status string) ([]models.Document, error) {
+	company, err := clientCompany(userID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listCompanyDocuments(company.ID, docType, fiscalPeriod, status)
+}
+
+// GetDocumentsByClient lista os documentos da empresa de um cliente (para contabilistas)
+func (s *DocumentService) GetDocumentsByClient(clientID, companyID uint, docType, fiscalPeriod, status string) ([]models.Document, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listCompanyDocuments(company.ID, docType, fiscalPeriod, status)
+}
+
+// GetClientDocument obtém um documento de um cliente
+func (s *DocumentService) GetClientDocument(clientID, companyID, documentID uint) (*models.Document, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var document models.Document
+	if err := config.DB.Where("id = ? AND company_id = ?", documentID, company.ID).First(&document).Error; err != nil {
+		return nil, errors.New("documento não encontrado")
+	}
+	return &document, nil
+}
+
+// OpenDocument abre o conteúdo guardado de um documento
+func (s *DocumentService) OpenDocument(document *models.Document) (io.ReadCloser, error) {
+	reader, err := utils.GetStorage().Open(document.StorageKey)
+	if err != nil {
+		return nil, errors.New("ficheiro do documento não encontrado")
+	}
+	return reader, nil
+}
+
+// UpdateDocumentStatus aceita ou rejeita um documento de um cliente
+func (s *DocumentService) UpdateDocumentStatus(clientID, companyID, documentID uint, req models.UpdateDocumentStatusDTO, reviewerID uint, reviewerRole string) (*models.Document, error) {
+	document, err := s.GetClientDocument(clientID, companyID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := NewFiscalPeriodService().EnsureDocumentPeriodWritable(document.CompanyID, document.FiscalPeriod, reviewerRole); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	document.Status = req.Status
+	document.ReviewNotes = req.Notes
+	document.ReviewedBy = &reviewerID
+	document.ReviewedAt = &now
+	if err := config.DB.Save(document).Error; err != nil {
+		return nil, errors.New("erro ao atualizar documento")
+	}
+
+	if req.Status == models.DocumentStatusRejected {
+		message := fmt.Sprintf("O documento \"%s\" foi rejeitado.", document.OriginalName)
+		if req.Notes != "" {
+			message += " Motivo: " + req.Notes
+		}
+		NewInboxService().Notify(clientID, models.EventDocumentRejected, "Documento rejeitado", message, "/api/client/documents")
+	}
+
+	return document, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func (s *DocumentService) listCompanyDocuments(companyID uint, docType, fiscalPeriod, status string) ([]models.Document, error) {
+	query := config.DB.Where("company_id = ?", companyID)
+	if docType != "" {
+		query = query.Where("type = ?", docType)
+	}
+	if fiscalPeriod != "" {
+		query = query.Where("fiscal_period = ?", fiscalPeriod)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	documents := []models.Document{}
+	if err := query.Order("created_at DESC").Find(&documents).Error; err != nil {
+		return nil, errors.New("erro ao obter documentos")
+	}
+	return documents, nil
+}
+
+// maxUploadSize lê o limite de upload de MAX_UPLOAD_MB (10 MB por omissão)
+func maxUploadSize() int64 {
+	if value, err := strconv.Atoi(os.Getenv("MAX_UPLOAD_MB")); err == nil && value > 0 {
+		return int64(value) << 20
+	}
+	return 10 << 20
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Peso de cada campo coincidente na pontuação de duplicados
+var duplicateFieldWeights = map[string]int{
+	"nipc":     50,
+	"nif":      40,
+	"iban":     35,
+	"email":    30,
+	"username": 30,
+	"phone":    15,
+}
+
+const (
+	// companyNameWeight é o peso máximo de um nome de empresa parecido
+	companyNameWeight = 30
+	// companyNameThreshold é a semelhança mínima para considerar dois nomes parecidos
+	companyNameThreshold = 0.85
+)
+
+type DuplicateService struct{}
+
+func NewDuplicateService() *DuplicateService {
+	return &DuplicateService{}
+}
+
+// duplicateFingerprint contém os campos normalizados usados na comparação
+type duplicateFingerprint struct {
+	username    string
+	nif         string
+	email       string
+	phone       string
+	nipc        string
+	iban        string
+	companyName string
+}
+
+// AnalyzeRequest compara uma solicitação com utilizadores, empresas e outras solicitações
+// e devolve os prováveis duplicados ordenados por pontuação
+func (s *DuplicateService) AnalyzeRequest(request *models.RegistrationRequest) (*models.DuplicateReportDTO, error) {
+	target := fingerprintFromRequest(request)
+	report := models.DuplicateReportDTO{
+		RiskLevel: models.DuplicateRiskNone,
+		Matches:   []models.DuplicateMatchDTO{},
+		Conflicts: []string{},
+	}
+
+	// Utilizadores existentes
+	var users []models.User
+	if err := config.DB.Select("id, username, name, email, phone, nif, status").Find(&users).Error; err != nil {
+		return nil, errors.New("erro ao analisar duplicados")
+	}
+	for _, user := range users {
+		candidate := duplicateFingerprint{
+			username: user.Username,
+			nif:      strings.TrimSpace(user.NIF),
+			email:    strings.ToLower(strings.TrimSpace(user.Email)),
+			phone:    utils.NormalizePhone(user.Phone),
+		}
+		if match := scoreDuplicate(target, candidate); match != nil {
+			match.Source = "user"
+			match.ID = user.ID
+			match.Label = user.Name
+			match.Status = user.Status
+			report.Matches = append(report.Matches, *match)
+
+			if target.username != "" && target.username == candidate.username {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("username já está em uso pelo utilizador #%d", user.ID))
+			}
+			if target.nif != "" && target.nif == candidate.nif {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("NIF já está associado ao utilizador #%d", user.ID))
+			}
+			if target.email != "" && target.email == candidate.email {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("email já está associado ao utilizador #%d", user.ID))
+			}
+		}
+	}
+
+	// Empresas existentes
+	var companies []models.Company
+	if err := config.DB.Select("id, company_name, trade_name, nipc, iban, status").Find(&companies).Error; err != nil {
+		return nil, errors.New("erro ao analisar duplicados")
+	}
+	for _, company := range companies {
+		candidate := duplicateFingerprint{
+			nipc:        strings.TrimSpace(company.NIPC),
+			iban:        utils.NormalizeIBAN(company.IBAN),
+			companyName: utils.NormalizeCompanyName(company.CompanyName),
+		}
+		match := scoreDuplicate(target, candidate)
+		if company.TradeName != "" {
+			candidate.companyName = utils.NormalizeCompanyName(company.TradeName)
+			if tradeMatch := scoreDuplicate(target, candidate); tradeMatch != nil && (match == nil || tradeMatch.Score > match.Score) {
+				match = tradeMatch
+			}
+		}
+		if match != nil {
+			match.Source = "company"
+			match.ID = company.ID
+			match.Label = company.CompanyName
+			match.Status = company.Status
+			report.Matches = append(report.Matches, *match)
+
+			if target.nipc != "" && target.nipc == candidate.nipc {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("NIPC já está associado à empresa #%d", company.ID))
+			}
+		}
+	}
+
+	// Outras solicitações ainda não convertidas em utilizador
+	var requests []models.RegistrationRequest
+	if err := config.DB.Where("id <> ? AND user_id IS NULL", request.ID).Find(&requests).Error; err != nil {
+		return nil, errors.New("erro ao analisar duplicados")
+	}
+	for i := range requests {
+		other := &requests[i]
+		if match := scoreDuplicate(target, fingerprintFromRequest(other)); match != nil {
+			match.Source = "registration_request"
+			match.ID = other.ID
+			match.Label = other.Username
+			if other.CompanyName != nil && *other.CompanyName != "" {
+				match.Label = *other.CompanyName
+			}
+			match.Status = other.Status
+			report.Matches = append(report.Matches, *match)
+		}
+	}
+
+	sort.SliceStable(report.Matches, func(i, j int) bool {
+		return report.Matches[i].Score > report.Matches[j].Score
+	})
+
+	if len(report.Matches) > 0 {
+		report.HighestScore = report.Matches[0].Score
+	}
+	switch {
+	case report.HighestScore >= 60:
+		report.RiskLevel = models.DuplicateRiskHigh
+	case report.HighestScore >= 30:
+		report.RiskLevel = models.DuplicateRiskMedium
+	case report.HighestScore > 0:
+		report.RiskLevel = models.DuplicateRiskLow
+	}
+
+	return &report, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func fingerprintFromRequest(request *models.RegistrationRequest) duplicateFingerprint {
+	fp := duplicateFingerprint{
+		username: request.Username,
+		nipc:     strings.TrimSpace(request.NIPC),
+	}
+	if request.NIF != nil {
+		fp.nif = strings.TrimSpace(*request.NIF)
+	}
+	if request.Email != nil {
+		fp.email = strings.ToLower(strings.TrimSpace(*request.Email))
+	}
+	if request.Phone != nil {
+		fp.phone = utils.NormalizePhone(*request.Phone)
+	}
+	if request.IBAN != nil {
+		fp.iban = utils.NormalizeIBAN(*request.IBAN)
+	}
+	if request.CompanyName != nil && *request.CompanyName != "" {
+		fp.companyName = utils.NormalizeCompanyName(*request.CompanyName)
+	} else if request.TradeName != nil {
+		fp.companyName = utils.NormalizeCompanyName(*request.TradeName)
+	}
+	return fp
+}
+
+// scoreDuplicate pontua a semelhança entre dois registos; devolve nil se nada coincidir
+func scoreDuplicate(target, candidate duplicateFingerprint) *models.DuplicateMatchDTO {
+	match := models.DuplicateMatchDTO{MatchedFields: []string{}}
+
+	fields := []struct {
+		name  string
+		value string
+		other string
+	}{
+		{"nipc", target.nipc, candidate.nipc},
+		{"nif", target.nif, candidate.nif},
+		{"iban", target.iban, candidate.iban},
+		{"email", target.email, candidate.email},
+		{"username", target.username, candidate.username},
+		{"phone", target.phone, candidate.phone},
+	}
+	for _, field := range fields {
+		if field.value != "" && field.value == field.other {
+			match.Score += duplicateFieldWeights[field.name]
+			match.MatchedFields = append(match.MatchedFields, field.name)
+		}
+	}
+
+	if similarity := utils.Similarity(target.companyName, candidate.companyName); similarity >= companyNameThreshold {
+		match.Score += int(similarity * companyNameWeight)
+		match.MatchedFields = append(match.MatchedFields, "company_name")
+		match.NameSimilarity = float64(int(similarity*100)) / 100
+	}
+
+	if len(match.MatchedFields) == 0 {
+		return nil
+	}
+	if match.Score > 100 {
+		match.Score = 100
+	}
+	return &match
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Tipos de documento enviados pelo cliente que podem corresponder a faturas de compra
+var purchaseDocumentTypes = []string{models.DocumentTypeInvoice, models.DocumentTypePurchaseInvoice}
+
+type EFaturaService struct{}
+
+func NewEFaturaService() *EFaturaService {
+	return &EFaturaService{}
+}
+
+// ImportCSV importa o CSV de faturas de compra do e-Fatura para a empresa do cliente,
+// ignorando as faturas já importadas (mesmo ATCUD ou mesmo NIF do emitente e número).
+// As faturas com data num período fechado não são importadas e ficam nos erros da importação.
+func (s *EFaturaService) ImportCSV(clientID, companyID, uploadedBy uint, fileHeader *multipart.FileHeader, role string) (*models.EFaturaImportResultDTO, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := maxUploadSize()
+	if fileHeader.Size > maxSize {
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, errors.New("erro ao ler ficheiro")
+	}
+	defer src.Close()
+
+	rows, rowErrors, err := utils.ParseEFaturaCSV(src)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.EFaturaImportResultDTO{
+		Import: models.EFaturaImport{
+			CompanyID:  company.ID,
+			UploadedBy: uploadedBy,
+			FileName:   filepath.Base(fileHeader.Filename),
+			TotalRows:  len(rows) + len(rowErrors),
+			Invalid:    len(rowErrors),
+		},
+		Errors: []models.EFaturaRowErrorDTO{},
+	}
+	for _, rowError := range rowErrors {
+		result.Errors = append(result.Errors, models.EFaturaRowErrorDTO{Line: rowError.Line, Message: rowError.Message})
+	}
+
+	if err := config.DB.Create(&result.Import).Error; err != nil {
+		return nil, errors.New("erro ao registar importação")
+	}
+
+	documents := s.candidateDocuments(company.ID)
+	guard := newPeriodGuard(company.ID, role)
+	for _, row := range rows {
+		if err := guard.check(row.IssueDate); err != nil {
+			result.Import.Invalid++
+			result.Errors = append(result.Errors, models.EFaturaRowErrorDTO{Line: row.Line, Message: err.Error()})
+			continue
+		}
+		if s.isDuplicate(company.ID, &row) {
+			result.Import.Duplicates++
+			continue
+		}
+
+		invoice := models.PurchaseInvoice{
+			CompanyID:    company.ID,
+			ImportID:     result.Import.ID,
+			SupplierNIF:  row.SupplierNIF,
+			SupplierName: row.SupplierName,
+			DocumentNo:   row.DocumentNo,
+			ATCUD:        row.ATCUD,
+			DocumentType: row.DocumentType,
+			IssueDate:    row.IssueDate,
+			FiscalPeriod: row.IssueDate.Format("2006-01"),
+			NetAmount:    row.NetAmount,
+			VATAmount:    row.VATAmount,
+			TotalAmount:  row.TotalAmount,
+			Situation:    row.Situation,
+			Sector:       row.Sector,
+			MatchStatus:  models.PurchaseInvoiceUnmatched,
+		}
+		if document := findMatchingDocument(&invoice, documents); document != nil {
+			now := time.Now()
+			invoice.MatchStatus = models.PurchaseInvoiceMatched
+			invoice.DocumentID = &document.ID
+			invoice.MatchedAt = &now
+			result.Import.Matched++
+		}
+
+		if err := config.DB.Create(&invoice).Error; err != nil {
+			result.Import.Invalid++
+			result.Errors = append(result.Errors, models.EFaturaRowErrorDTO{Line: row.Line, Message: "erro ao guardar fatura"})
+			continue
+		}
+		result.Import.Created++
+	}
+
+	config.DB.Save(&result.Import)
+	return &result, nil
+}
+
+// GetPurchaseInvoices lista as faturas de compra importadas da empresa do cliente
+func (s *EFaturaService) GetPurchaseInvoices(clientID, companyID uint, fiscalPeriod, matchStatus string) ([]models.PurchaseInvoice, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("company_id = ?", company.ID)
+	if fiscalPeriod != "" {
+		query = query.Where("fiscal_period = ?", fiscalPeriod)
+	}
+	if matchStatus != "" {
+		query = query.Where("match_status = ?", matchStatus)
+	}
+
+	invoices := []models.PurchaseInvoice{}
+	if err := query.Order("issue_date DESC, id DESC").Find(&invoices).Error; err != nil {
+		return nil, errors.New("erro ao obter faturas de compra")
+	}
+	return invoices, nil
+}
+
+// SetMatch associa manualmente uma fatura a um documento do cliente, ou desfaz a associação
+func (s *EFaturaService) SetMatch(clientID, companyID, invoiceID uint, req models.MatchPurchaseInvoiceDTO) (*models.PurchaseInvoice, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoice models.PurchaseInvoice
+	if err := config.DB.Where("id = ? AND company_id = ?", invoiceID, company.ID).First(&invoice).Error; err != nil {
+		return nil, errors.New("fatura não encontrada")
+	}
+
+	if req.DocumentID == nil {
+		invoice.MatchStatus = models.PurchaseInvoiceUnmatched
+		invoice.DocumentID = nil
+		invoice.MatchedAt = nil
+	} else {
+		var document models.Document
+		if err := config.DB.Where("id = ? AND company_id = ?", *req.DocumentID, company.ID).First(&document).Error; err != nil {
+			return nil, errors.New("documento não encontrado")
+		}
+		now := time.Now()
+		invoice.MatchStatus = models.PurchaseInvoiceMatched
+		invoice.DocumentID = &document.ID
+		invoice.MatchedAt = &now
+	}
+
+	if err := config.DB.Save(&invoice).Error; err != nil {
+		return nil, errors.New("erro ao atualizar fatura")
+	}
+	return &invoice, nil
+}
+
+// MatchDocument associa um documento acabado de enviar às faturas ainda sem correspondência
+func (s *EFaturaService) MatchDocument(document *models.Document) {
+	if document.Type != models.DocumentTypeInvoice && document.Type != models.DocumentTypePurchaseInvoice {
+		return
+	}
+
+	var invoices []models.PurchaseInvoice
+	query := config.DB.Where("company_id = ? AND match_status = ?", document.CompanyID, models.PurchaseInvoiceUnmatched)
+	if document.FiscalPeriod != "" {
+		query = query.Where("fiscal_period LIKE ?", document.FiscalPeriod+"%")
+	}
+	if err := query.Find(&invoices).Error; err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, invoice := range invoices {
+		if findMatchingDocument(&invoice, []models.Document{*document}) == nil {
+			continue
+		}
+		config.DB.Model(&invoice).Updates(map[string]interface{}{
+			"match_status": models.PurchaseInvoiceMatched,
+			"document_id":  document.ID,
+			"matched_at":   now,
+		})
+	}
+}
+
+// ClassifyVAT define a categoria da fatura na declaração de IVA e a percentagem dedutível;
+// as faturas de períodos fechados não podem ser reclassificadas
+func (s *EFaturaService) ClassifyVAT(clientID, companyID, invoiceID uint, req models.ClassifyPurchaseInvoiceDTO, role string) (*models.PurchaseInvoice, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoice models.PurchaseInvoice
+	if err := config.DB.Where("id = ? AND company_id = ?", invoiceID, company.ID).First(&invoice).Error; err != nil {
+		return nil, errors.New("fatura não encontrada")
+	}
+	if err := NewFiscalPeriodService().EnsureWritable(company.ID, invoice.IssueDate, role); err != nil {
+		return nil, err
+	}
+
+	invoice.ExpenseCategory = req.ExpenseCategory
+	invoice.DeductiblePercent = req.DeductiblePercent
+	if err := config.DB.Save(&invoice).Error; err != nil {
+		return nil, errors.New("erro ao atualizar fatura")
+	}
+	return &invoice, nil
+}
+
+// GetDiscrepancyReport lista, por mês, as faturas comunicadas à AT que o cliente não enviou
+func (s *EFaturaService) GetDiscrepancyReport(clientID, companyID uint, from, to string) (*models.DiscrepancyReportDTO, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, month := range []string{from, to} {
+		if month != "" {
+			if _, err := time.Parse("2006-01", month); err != nil {
+				return nil, errors.New("mês inválido (use AAAA-MM)")
+			}
+		}
+	}
+
+	query := config.DB.Where("company_id = ?", company.ID)
+	if from != "" {
+		query = query.Where("fiscal_period >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("fiscal_period <= ?", to)
+	}
+
+	var invoices []models.PurchaseInvoice
+	if err := query.Order("fiscal_period ASC, issue_date ASC, supplier_name ASC").Find(&invoices).Error; err != nil {
+		return nil, errors.New("erro ao obter faturas de compra")
+	}
+
+	report := models.DiscrepancyReportDTO{CompanyID: company.ID, From: from, To: to, Periods: []models.DiscrepancyPeriodDTO{}}
+	periods := make(map[string]*models.DiscrepancyPeriodDTO)
+	for _, invoice := range invoices {
+		report.Known++
+		if invoice.MatchStatus == models.PurchaseInvoiceMatched {
+			report.Matched++
+			continue
+		}
+
+		report.Missing++
+		report.TotalAmount += invoice.TotalAmount
+		report.VATAmount += invoice.VATAmount
+
+		period, ok := periods[invoice.FiscalPeriod]
+		if !ok {
+			period = &models.DiscrepancyPeriodDTO{FiscalPeriod: invoice.FiscalPeriod, Invoices: []models.PurchaseInvoice{}}
+			periods[invoice.FiscalPeriod] = period
+		}
+		period.Count++
+		period.TotalAmount += invoice.TotalAmount
+		period.VATAmount += invoice.VATAmount
+		period.Invoices = append(period.Invoices, invoice)
+	}
+
+	for _, period := range periods {
+		period.TotalAmount = roundAmount(period.TotalAmount)
+		period.VATAmount = roundAmount(period.VATAmount)
+		report.Periods = append(report.Periods, *period)
+	}
+	sort.Slice(report.Periods, func(i, j int) bool {
+		return report.Periods[i].FiscalPeriod < report.Periods[j].FiscalPeriod
+	})
+	report.TotalAmount = roundAmount(report.TotalAmount)
+	report.VATAmount = roundAmount(report.VATAmount)
+	return &report, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func (s *EFaturaService) isDuplicate(companyID uint, row *utils.EFaturaRow) bool {
+	var count int64
+	query := config.DB.Model(&models.PurchaseInvoice{}).Where("company_id = ?", companyID)
+	if row.ATCUD != "" {
+		query = query.Where("atcud = ? OR (supplier_nif = ? AND document_no = ?)", row.ATCUD, row.SupplierNIF, row.DocumentNo)
+	} else {
+		query = query.Where("supplier_nif = ? AND document_no = ?", row.SupplierNIF, row.DocumentNo)
+	}
+	query.Count(&count)
+	return count > 0
+}
+
+// candidateDocuments devolve os documentos de compra do cliente que podem ser associados
+func (s *EFaturaService) candidateDocuments(companyID uint) []models.Document {
+	var documents []models.Document
+	config.DB.Where("company_id = ? AND type IN ? AND status <> ?", companyID, purchaseDocumentTypes, models.DocumentStatusRejected).
+		Find(&documents)
+	return documents
+}
+
+// findMatchingDocument procura um documento cujo nome ou notas contenha o número
+// da fatura ou o ATCUD, no mesmo período fiscal (ou sem período)
+func findMatchingDocument(invoice *models.PurchaseInvoice, documents []models.Document) *models.Document {
+	keys := []string{}
+	for _, value := range []string{invoice.DocumentNo, invoice.ATCUD} {
+		if key := matchKey(value); len(key) >= 4 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	for i := range documents {
+		document := &documents[i]
+		if document.Status == models.DocumentStatusRejected {
+			continue
+		}
+		if document.FiscalPeriod != "" && !strings.HasPrefix(invoice.FiscalPeriod, document.FiscalPeriod) {
+			continue
+		}
+
+		haystack := matchKey(document.OriginalName + " " + document.Notes)
+		for _, key := range keys {
+			if strings.Contains(haystack, key) {
+				return document
+			}
+		}
+	}
+	return nil
+}
+
+// matchKey reduz um texto a letras e dígitos em maiúsculas ("FT 2024A/12" -> "FT2024A12")
+func matchKey(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, utils.RemoveAccents(value))
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+)
+
+// emailVerificationTTL é a validade do link de verificação
+const emailVerificationTTL = 72 * time.Hour
+
+// emailVerificationResendCooldown é o intervalo mínimo entre dois envios do link para a mesma solicitação
+const emailVerificationResendCooldown = 5 * time.Minute
+
+type EmailVerificationService struct{}
+
+func NewEmailVerificationService() *EmailVerificationService {
+	return &EmailVerificationService{}
+}
+
+// SendVerification gera um novo token e envia o link de verificação para o email da solicitação
+func (s *EmailVerificationService) SendVerification(request *models.RegistrationRequest) error {
+	if request.Email == nil || *request.Email == "" {
+		return errors.New("solicitação sem email")
+	}
+	if request.EmailVerifiedAt != nil {
+		return errors.New("email já verificado")
+	}
+
+	now := time.Now()
+	request.EmailVerificationToken = utils.GenerateRandomToken()
+	request.EmailVerificationSentAt = &now
+	if err := config.DB.Model(request).Updates(map[string]interface{}{
+		"email_verification_token":   request.EmailVerificationToken,
+		"email_verification_sent_at": now,
+	}).Error; err != nil {
+		return errors.New("erro ao gerar token de verificação")
+	}
+
+	link := fmt.Sprintf("%s/api/auth/verify-email?token=%s", appBaseURL(), url.QueryEscape(request.EmailVerificationToken))
+	if err := NewNotificationService().NotifyRequest(models.EventEmailVerification, request, map[string]interface{}{
+		"Link":  link,
+		"Hours": int(emailVerificationTTL.Hours()),
+	}); err != nil {
+		return errors.New("erro ao enviar email de verificação")
+	}
+
+	return nil
+}
+
+// VerifyEmail confirma o email associado ao token
+func (s *EmailVerificationService) VerifyEmail(token string) (*models.RegistrationRequest, error) {
+	if token == "" {
+		return nil, errors.New("token de verificação inválido")
+	}
+
+	var request models.RegistrationRequest
+	if err := config.DB.Where("email_verification_token = ?", token).First(&request).Error; err != nil {
+		return nil, errors.New("token de verificação inválido")
+	}
+	if request.EmailVerifiedAt != nil {
+		return &request, nil
+	}
+	if request.EmailVerificationSentAt == nil || time.Since(*request.EmailVerificationSentAt) > emailVerificationTTL {
+		return nil, errors.New("token de verificação expirado")
+	}
+
+	now := time.Now()
+	request.EmailVerifiedAt = &now
+	if err := config.DB.Model(&request).Update("email_verified_at", now).Error; err != nil {
+		return nil, errors.New("erro ao verificar email")
+	}
+
+	// Só depois de verificado o email é que a solicitação entra na distribuição automática
+	_ = NewReviewQueueService().AutoAssign(&request)
+
+	return &request, nil
+}
+
+// ResendVerification reenvia o link de verificação para uma solicitação pendente por verificar.
+// Não devolve erro: quem pede não fica a saber se o email existe, já foi verificado ou está no
+// intervalo entre envios. Dentro desse intervalo o token atual mantém-se e nada é enviado.
+func (s *EmailVerificationService) ResendVerification(email string) {
+	var request models.RegistrationRequest
+	if err := config.DB.Where("email = ? AND status = ?", email, "pending").First(&request).Error; err != nil {
+		return
+	}
+	if request.EmailVerifiedAt != nil {
+		return
+	}
+	if request.EmailVerificationSentAt != nil && time.Since(*request.EmailVerificationSentAt) < emailVerificationResendCooldown {
+		return
+	}
+
+	if err := s.SendVerification(&request); err != nil {
+		log.Printf("⚠️  Erro ao reenviar verificação da solicitação %d: %v", request.ID, err)
+	}
+}
+
+// appBaseURL devolve o endereço público da API usado nos links enviados por email
+func appBaseURL() string {
+	if base := os.Getenv("APP_BASE_URL"); base != "" {
+		return base
+	}
+	return "http://localhost:8080"
+}
@@ -0,0 +1,588 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const exportBatchSize = 500
+
+// exportRow é uma linha exportada, indexada pela chave da coluna
+type exportRow map[string]interface{}
+
+// exportDataset define as colunas, os filtros aceites e a leitura em lotes de um conjunto de dados
+type exportDataset struct {
+	columns []models.ExportColumnDTO
+	filters []string
+	iterate func(scope *PortfolioScope, filters map[string]string, emit func(exportRow) error) error
+}
+
+var exportDatasets = map[string]exportDataset{
+	"users-overview": {
+		columns: []models.ExportColumnDTO{
+			{Key: "id", Label: "ID"},
+			{Key: "username", Label: "Utilizador"},
+			{Key: "status", Label: "Estado"},
+			{Key: "role", Label: "Perfil"},
+			{Key: "source", Label: "Origem"},
+			{Key: "name", Label: "Nome"},
+			{Key: "email", Label: "Email"},
+			{Key: "phone", Label: "Telemóvel"},
+			{Key: "nif", Label: "NIF"},
+			{Key: "date_of_birth", Label: "Data de nascimento"},
+			{Key: "citizen_card_number", Label: "Cartão de cidadão", Sensitive: true},
+			{Key: "citizen_card_expiry", Label: "Validade do CC"},
+			{Key: "fiscal_address", Label: "Morada fiscal"},
+			{Key: "fiscal_postal_code", Label: "Código postal"},
+			{Key: "fiscal_city", Label: "Localidade"},
+			{Key: "fiscal_district", Label: "Distrito"},
+			{Key: "official_email", Label: "Email oficial", Sensitive: true},
+			{Key: "billing_software", Label: "Software de faturação"},
+			{Key: "company_role", Label: "Papel na empresa"},
+			{Key: "company_name", Label: "Empresa"},
+			{Key: "trade_name", Label: "Nome comercial"},
+			{Key: "nipc", Label: "NIPC"},
+			{Key: "legal_form", Label: "Forma jurídica"},
+			{Key: "cae", Label: "CAE"},
+			{Key: "accounting_regime", Label: "Regime de contabilidade"},
+			{Key: "vat_regime", Label: "Regime de IVA"},
+			{Key: "share_capital", Label: "Capital social"},
+			{Key: "company_address", Label: "Sede"},
+			{Key: "company_postal_code", Label: "Código postal da sede"},
+			{Key: "company_city", Label: "Localidade da sede"},
+			{Key: "company_district", Label: "Distrito da sede"},
+			{Key: "bank_name", Label: "Banco"},
+			{Key: "iban", Label: "IBAN", Sensitive: true},
+			{Key: "bic", Label: "BIC"},
+			{Key: "annual_revenue", Label: "Volume de negócios"},
+			{Key: "number_employees", Label: "Trabalhadores"},
+			{Key: "request_status", Label: "Estado do pedido"},
+			{Key: "submitted_at", Label: "Pedido submetido em"},
+			{Key: "invitation_status", Label: "Estado do convite"},
+		},
+		filters: []string{"status", "role", "source"},
+		iterate: iterateUsersOverview,
+	},
+	"requests": {
+		columns: []models.ExportColumnDTO{
+			{Key: "id", Label: "ID"},
+			{Key: "request_type", Label: "Tipo"},
+			{Key: "status", Label: "Estado"},
+			{Key: "submitted_at", Label: "Submetido em"},
+			{Key: "email_verified_at", Label: "Email verificado em"},
+			{Key: "reviewed_at", Label: "Revisto em"},
+			{Key: "assigned_to", Label: "Atribuído a (ID)"},
+			{Key: "username", Label: "Utilizador"},
+			{Key: "name", Label: "Nome"},
+			{Key: "email", Label: "Email"},
+			{Key: "phone", Label: "Telemóvel"},
+			{Key: "nif", Label: "NIF"},
+			{Key: "citizen_card_number", Label: "Cartão de cidadão", Sensitive: true},
+			{Key: "official_email", Label: "Email oficial", Sensitive: true},
+			{Key: "company_name", Label: "Empresa"},
+			{Key: "nipc", Label: "NIPC"},
+			{Key: "legal_form", Label: "Forma jurídica"},
+			{Key: "vat_regime", Label: "Regime de IVA"},
+			{Key: "company_district", Label: "Distrito da sede"},
+			{Key: "iban", Label: "IBAN", Sensitive: true},
+			{Key: "review_notes", Label: "Notas da revisão"},
+		},
+		filters: []string{"status", "request_type", "assigned_to"},
+		iterate: iterateRequests,
+	},
+	"clients": {
+		columns: []models.ExportColumnDTO{
+			{Key: "id", Label: "ID"},
+			{Key: "username", Label: "Utilizador"},
+			{Key: "name", Label: "Nome"},
+			{Key: "email", Label: "Email"},
+			{Key: "phone", Label: "Telemóvel"},
+			{Key: "nif", Label: "NIF"},
+			{Key: "status", Label: "Estado"},
+			{Key: "citizen_card_number", Label: "Cartão de cidadão", Sensitive: true},
+			{Key: "official_email", Label: "Email oficial", Sensitive: true},
+			{Key: "billing_software", Label: "Software de faturação"},
+			{Key: "created_at", Label: "Cliente desde"},
+			{Key: "company_id", Label: "ID da empresa"},
+			{Key: "company_role", Label: "Papel na empresa"},
+			{Key: "company_name", Label: "Empresa"},
+			{Key: "nipc", Label: "NIPC"},
+			{Key: "cae", Label: "CAE"},
+			{Key: "legal_form", Label: "Forma jurídica"},
+			{Key: "accounting_regime", Label: "Regime de contabilidade"},
+			{Key: "vat_regime", Label: "Regime de IVA"},
+			{Key: "company_status", Label: "Estado da empresa"},
+			{Key: "district", Label: "Distrito"},
+			{Key: "iban", Label: "IBAN", Sensitive: true},
+			{Key: "bic", Label: "BIC"},
+		},
+		filters: []string{"company_status", "vat_regime", "district"},
+		iterate: iterateClients,
+	},
+}
+
+type ExportService struct{}
+
+func NewExportService() *ExportService {
+	return &ExportService{}
+}
+
+// ExportJob é uma exportação validada, pronta a ser escrita na resposta
+type ExportJob struct {
+	Filename    string
+	ContentType string
+
+	dataset  string
+	format   string
+	columns  []models.ExportColumnDTO
+	filters  map[string]string
+	userID   uint
+	unmasked bool
+	scope    *PortfolioScope
+	ip       string
+}
+
+// GetDatasets lista os conjuntos de dados exportáveis com as colunas e os filtros
+func (s *ExportService) GetDatasets() []models.ExportDatasetDTO {
+	result := make([]models.ExportDatasetDTO, 0, len(exportDatasets))
+	for _, name := range []string{"users-overview", "requests", "clients"} {
+		dataset := exportDatasets[name]
+		result = append(result, models.ExportDatasetDTO{Dataset: name, Columns: dataset.columns, Filters: dataset.filters})
+	}
+	return result
+}
+
+// Prepare valida o formato, as colunas e os filtros de uma exportação. As colunas sensíveis
+// só saem sem máscara se o utilizador tiver a permissão export_sensitive e, sem clients.all,
+// só são exportados os clientes da carteira do utilizador.
+func (s *ExportService) Prepare(userID uint, name, format, columns string, query map[string]string, ip string) (*ExportJob, error) {
+	dataset, ok := exportDatasets[name]
+	if !ok {
+		return nil, errors.New("exportação não encontrada")
+	}
+
+	job := &ExportJob{
+		dataset:  name,
+		format:   strings.ToLower(format),
+		filters:  make(map[string]string),
+		userID:   userID,
+		unmasked: NewPermissionService().HasPermission(userID, models.PermissionExportSensitive),
+		scope:    NewPortfolioService().ScopeFor(userID, NewPermissionService().HasPermission(userID, models.PermissionClientsAll)),
+		ip:       ip,
+	}
+
+	switch job.format {
+	case "", "csv":
+		job.format = "csv"
+		job.ContentType = "text/csv; charset=utf-8"
+	case "xlsx":
+		job.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return nil, errors.New("formato inválido (use csv ou xlsx)")
+	}
+
+	if strings.TrimSpace(columns) == "" {
+		job.columns = dataset.columns
+	} else {
+		byKey := make(map[string]models.ExportColumnDTO, len(dataset.columns))
+		for _, column := range dataset.columns {
+			byKey[column.Key] = column
+		}
+		for _, key := range strings.Split(columns, ",") {
+			key = strings.TrimSpace(key)
+			column, ok := byKey[key]
+			if !ok {
+				return nil, fmt.Errorf("coluna desconhecida: %s", key)
+			}
+			job.columns = append(job.columns, column)
+		}
+	}
+
+	for _, filter := range dataset.filters {
+		if value := strings.TrimSpace(query[filter]); value != "" {
+			job.filters[filter] = value
+		}
+	}
+	if value, ok := job.filters["assigned_to"]; ok {
+		if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+			return nil, errors.New("assigned_to inválido")
+		}
+	}
+
+	job.Filename = fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102-150405"), job.format)
+	return job, nil
+}
+
+// Write escreve a exportação em lotes no writer e regista-a na auditoria com o número de linhas
+func (j *ExportJob) Write(w io.Writer) error {
+	var rows int
+	var err error
+	if j.format == "xlsx" {
+		rows, err = j.writeXLSX(w)
+	} else {
+		rows, err = j.writeCSV(w)
+	}
+
+	columns := make([]string, len(j.columns))
+	for i, column := range j.columns {
+		columns[i] = column.Key
+	}
+	details := map[string]interface{}{
+		"format":   j.format,
+		"columns":  columns,
+		"filters":  j.filters,
+		"rows":     rows,
+		"unmasked": j.unmasked,
+	}
+	if err != nil {
+		details["error"] = err.Error()
+	}
+	NewAuditService().Record(j.userID, models.AuditActionExport, j.dataset, nil, details, j.ip)
+	return err
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func (j *ExportJob) writeCSV(w io.Writer) (int, error) {
+	// BOM para o Excel reconhecer UTF-8; separador ";" e vírgula decimal, como no Excel em português
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return 0, err
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+
+	header := make([]string, len(j.columns))
+	for i, column := range j.columns {
+		header[i] = column.Label
+	}
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err := exportDatasets[j.dataset].iterate(j.scope, j.filters, func(row exportRow) error {
+		record := make([]string, len(j.columns))
+		for i, column := range j.columns {
+			record[i] = formatCSVCell(j.cellValue(column, row))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportBatchSize == 0 {
+			writer.Flush()
+		}
+		return writer.Error()
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	return rows, err
+}
+
+func (j *ExportJob) writeXLSX(w io.Writer) (int, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Exportação"
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return 0, err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return 0, err
+	}
+
+	headerStyle, _ := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	dateFormat := "dd/mm/yyyy"
+	dateStyle, _ := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	amountStyle, _ := file.NewStyle(&excelize.Style{NumFmt: 4}) // #,##0.00
+
+	header := make([]interface{}, len(j.columns))
+	for i, column := range j.columns {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: column.Label}
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = exportDatasets[j.dataset].iterate(j.scope, j.filters, func(row exportRow) error {
+		values := make([]interface{}, len(j.columns))
+		for i, column := range j.columns {
+			switch value := j.cellValue(column, row).(type) {
+			case time.Time:
+				values[i] = excelize.Cell{StyleID: dateStyle, Value: value}
+			case float64:
+				values[i] = excelize.Cell{StyleID: amountStyle, Value: value}
+			default:
+				values[i] = value
+			}
+		}
+		rows++
+		cell, err := excelize.CoordinatesToCellName(1, rows+1)
+		if err != nil {
+			return err
+		}
+		return stream.SetRow(cell, values)
+	})
+	if err != nil {
+		return rows, err
+	}
+	if err := stream.Flush(); err != nil {
+		return rows, err
+	}
+	return rows, file.Write(w)
+}
+
+// cellValue devolve o valor da coluna, sem ponteiros e com máscara nas colunas sensíveis
+func (j *ExportJob) cellValue(column models.ExportColumnDTO, row exportRow) interface{} {
+	value := derefExportValue(row[column.Key])
+	if column.Sensitive && !j.unmasked && value != nil {
+		return maskSensitiveValue(fmt.Sprint(value))
+	}
+	return value
+}
+
+// escapeFormula antepõe um apóstrofo aos textos que o Excel ou o LibreOffice interpretariam como fórmula ao abrir o CSV;
+// no XLSX as células de texto nunca são fórmulas e o valor é guardado sem alterações
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func derefExportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil || v.IsZero() {
+			return nil
+		}
+		return *v
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *uint:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *bool:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
+
+// maskSensitiveValue mantém apenas os últimos 4 caracteres
+func maskSensitiveValue(value string) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) == 0 {
+		return ""
+	}
+	if len(runes) <= 4 {
+		return "****"
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+func formatCSVCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			return v.Format("02/01/2006")
+		}
+		return v.Format("02/01/2006 15:04")
+	case float64:
+		return strings.Replace(strconv.FormatFloat(v, 'f', 2, 64), ".", ",", 1)
+	case bool:
+		if v {
+			return "Sim"
+		}
+		return "Não"
+	}
+	return fmt.Sprint(value)
+}
+
+func iterateUsersOverview(scope *PortfolioScope, filters map[string]string, emit func(exportRow) error) error {
+	return NewAdminService().EachCompleteUserOverview(scope, exportBatchSize, func(dto models.CompleteUserOverviewDTO) error {
+		if (filters["status"] != "" && dto.Status != filters["status"]) ||
+			(filters["role"] != "" && dto.Role != filters["role"]) ||
+			(filters["source"] != "" && dto.Source != filters["source"]) {
+			return nil
+		}
+
+		var id interface{}
+		if dto.ID != 0 {
+			id = dto.ID
+		}
+		row := exportRow{
+			"id": id, "username": dto.Username, "status": dto.Status, "role": dto.Role, "source": dto.Source,
+			"name": dto.Name, "email": dto.Email, "phone": dto.Phone, "nif": dto.NIF,
+			"date_of_birth": dto.DateOfBirth, "citizen_card_number": dto.CitizenCardNumber, "citizen_card_expiry": dto.CitizenCardExpiry,
+			"fiscal_address": dto.FiscalAddress, "fiscal_postal_code": dto.FiscalPostalCode, "fiscal_city": dto.FiscalCity, "fiscal_district": dto.FiscalDistrict,
+			"official_email": dto.OfficialEmail, "billing_software": dto.BillingSoftware,
+			"company_role": dto.CompanyRole, "company_name": dto.CompanyName, "trade_name": dto.TradeName, "nipc": dto.NIPC, "legal_form": dto.LegalForm, "cae": dto.CAE,
+			"accounting_regime": dto.AccountingRegime, "vat_regime": dto.VATRegime, "share_capital": dto.ShareCapital,
+			"company_address": dto.CompanyAddress, "company_postal_code": dto.CompanyPostalCode, "company_city": dto.CompanyCity, "company_district": dto.CompanyDistrict,
+			"bank_name": dto.BankName, "iban": dto.IBAN, "bic": dto.BIC,
+			"annual_revenue": dto.AnnualRevenue, "number_employees": dto.NumberEmployees,
+			"request_status": dto.RequestStatus, "submitted_at": dto.SubmittedAt, "invitation_status": dto.InvitationStatus,
+		}
+		return emit(row)
+	})
+}
+
+func iterateRequests(scope *PortfolioScope, filters map[string]string, emit func(exportRow) error) error {
+	query := scope.Requests(config.DB.Model(&models.RegistrationRequest{}))
+	if filters["status"] != "" {
+		query = query.Where("status = ?", filters["status"])
+	}
+	if filters["request_type"] != "" {
+		query = query.Where("request_type = ?", filters["request_type"])
+	}
+	if filters["assigned_to"] != "" {
+		query = query.Where("assigned_to = ?", filters["assigned_to"])
+	}
+
+	var batch []models.RegistrationRequest
+	var emitErr error
+	result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, req := range batch {
+			row := exportRow{
+				"id": req.ID, "request_type": req.RequestType, "status": req.Status,
+				"submitted_at": req.SubmittedAt, "email_verified_at": req.EmailVerifiedAt, "reviewed_at": req.ReviewedAt, "assigned_to": req.AssignedTo,
+				"username": req.Username, "name": req.Name, "email": req.Email, "phone": req.Phone, "nif": req.NIF,
+				"citizen_card_number": req.CitizenCardNumber, "official_email": req.OfficialEmail,
+				"company_name": req.CompanyName, "nipc": req.NIPC, "legal_form": req.LegalForm, "vat_regime": req.VATRegime,
+				"company_district": req.CompanyDistrict, "iban": req.IBAN, "review_notes": req.ReviewNotes,
+			}
+			if emitErr = emit(row); emitErr != nil {
+				return emitErr
+			}
+		}
+		return nil
+	})
+	if emitErr != nil {
+		return emitErr
+	}
+	if result.Error != nil {
+		return errors.New("erro ao obter pedidos de registo")
+	}
+	return nil
+}
+
+func iterateClients(scope *PortfolioScope, filters map[string]string, emit func(exportRow) error) error {
+	// Empresas a exportar: as da carteira (com scope) que cumprem os filtros
+	companies := scope.Companies(config.DB.Model(&models.Company{}).Select("companies.id"), "companies.id")
+	if filters["company_status"] != "" {
+		companies = companies.Where("companies.status = ?", filters["company_status"])
+	}
+	if filters["vat_regime"] != "" {
+		companies = companies.Where("companies.vat_regime = ?", filters["vat_regime"])
+	}
+	if filters["district"] != "" {
+		companies = companies.Where("companies.district = ?", filters["district"])
+	}
+
+	query := scope.Clients(config.DB.Model(&models.User{}).
+		Where("users.role = ? AND users.status = ?", models.RoleClient, "approved"), "users.id")
+	if len(filters) > 0 {
+		query = query.Where("users.id IN (?)", config.DB.Model(&models.UserCompany{}).Select("user_id").Where("company_id IN (?)", companies))
+	}
+
+	// Uma linha por empresa a que o cliente está ligado (ou uma só, sem empresa)
+	var batch []models.User
+	var emitErr error
+	result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		userIDs := make([]uint, len(batch))
+		for i, user := range batch {
+			userIDs[i] = user.ID
+		}
+		var links []models.UserCompany
+		if err := config.DB.Preload("Company").Where("user_id IN ? AND company_id IN (?)", userIDs, companies).
+			Order("company_id ASC").Find(&links).Error; err != nil {
+			return err
+		}
+		linksByUser := make(map[uint][]models.UserCompany)
+		for _, link := range links {
+			if link.Company != nil {
+				linksByUser[link.UserID] = append(linksByUser[link.UserID], link)
+			}
+		}
+
+		for _, user := range batch {
+			userLinks := linksByUser[user.ID]
+			if len(userLinks) == 0 {
+				userLinks = []models.UserCompany{{}}
+			}
+			for _, link := range userLinks {
+				row := exportRow{
+					"id": user.ID, "username": user.Username, "name": user.Name, "email": user.Email, "phone": user.Phone, "nif": user.NIF,
+					"status": user.Status, "citizen_card_number": user.CitizenCardNumber, "official_email": user.OfficialEmail,
+					"billing_software": user.BillingSoftware, "created_at": user.CreatedAt,
+				}
+				if company := link.Company; company != nil {
+					row["company_id"] = company.ID
+					row["company_role"] = link.Role
+					row["company_name"] = company.CompanyName
+					row["nipc"] = company.NIPC
+					row["cae"] = company.CAE
+					row["legal_form"] = company.LegalForm
+					row["accounting_regime"] = company.AccountingRegime
+					row["vat_regime"] = company.VATRegime
+					row["company_status"] = company.Status
+					row["district"] = company.District
+					row["iban"] = company.IBAN
+					row["bic"] = company.BIC
+				}
+				if emitErr = emit(row); emitErr != nil {
+					return emitErr
+				}
+			}
+		}
+		return nil
+	})
+	if emitErr != nil {
+		return emitErr
+	}
+	if result.Error != nil {
+		return errors.New("erro ao obter clientes aprovados")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"RVContabilidadeBack/models"
+	"bytes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestFormatCSVCellEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+351 912 345 678", "'+351 912 345 678"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"Empresa, Lda", "Empresa, Lda"},
+		{"", ""},
+		{-12.5, "-12,50"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCSVCell(tt.value); got != tt.want {
+			t.Errorf("formatCSVCell(%q): obtido %q, esperado %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriteXLSXKeepsTextUnchanged(t *testing.T) {
+	values := []string{"=HYPERLINK(\"http://x\")", "+351 912 345 678", "-2+3", "@SUM(A1)"}
+	exportDatasets["test-xlsx"] = exportDataset{
+		columns: []models.ExportColumnDTO{{Key: "name", Label: "Nome"}},
+		iterate: func(_ *PortfolioScope, _ map[string]string, emit func(exportRow) error) error {
+			for _, value := range values {
+				if err := emit(exportRow{"name": value}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	defer delete(exportDatasets, "test-xlsx")
+
+	job := &ExportJob{dataset: "test-xlsx", format: "xlsx", columns: exportDatasets["test-xlsx"].columns}
+	var buf bytes.Buffer
+	rows, err := job.writeXLSX(&buf)
+	if err != nil || rows != len(values) {
+		t.Fatalf("writeXLSX: %d linhas, erro %v", rows, err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("XLSX inválido: %v", err)
+	}
+	sheet := file.GetSheetName(0)
+	for i, want := range values {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		got, err := file.GetCellValue(sheet, cell)
+		if err != nil || got != want {
+			t.Errorf("célula %s: obtido %q (%v), esperado %q", cell, got, err, want)
+		}
+		if formula, _ := file.GetCellFormula(sheet, cell); formula != "" {
+			t.Errorf("célula %s guardada como fórmula: %q", cell, formula)
+		}
+	}
+}
+
+func TestExportCellValueMasksSensitiveColumns(t *testing.T) {
+	column := models.ExportColumnDTO{Key: "iban", Sensitive: true}
+	row := exportRow{"iban": "PT50000201231234567890154"}
+
+	if got := (&ExportJob{}).cellValue(column, row); got != "*********************0154" {
+		t.Errorf("com máscara: obtido %q", got)
+	}
+	if got := (&ExportJob{unmasked: true}).cellValue(column, row); got != "PT50000201231234567890154" {
+		t.Errorf("sem máscara: obtido %q", got)
+	}
+}
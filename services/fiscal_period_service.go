@@ -0,0 +1,230 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// periodStatusRank ordena os estados do mais aberto para o mais fechado
+var periodStatusRank = map[string]int{
+	models.PeriodStatusOpen:       0,
+	models.PeriodStatusSoftClosed: 1,
+	models.PeriodStatusHardClosed: 2,
+}
+
+type FiscalPeriodService struct{}
+
+func NewFiscalPeriodService() *FiscalPeriodService {
+	return &FiscalPeriodService{}
+}
+
+// ClosePeriod fecha um período (open → soft_closed/hard_closed ou soft_closed → hard_closed)
+func (s *FiscalPeriodService) ClosePeriod(companyID, periodID, userID uint, req models.CloseFiscalPeriodDTO) (*models.FiscalPeriod, error) {
+	period, err := s.getPeriod(companyID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	if periodStatusRank[req.Status] <= periodStatusRank[period.Status] {
+		return nil, fmt.Errorf("o período %s já está fechado", periodLabel(period))
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return s.transition(tx, period, req.Status, "close", req.Reason, userID)
+	}); err != nil {
+		return nil, errors.New("erro ao fechar período")
+	}
+	return period, nil
+}
+
+// CloseFiscalYear fecha todos os períodos de um exercício que ainda estejam menos fechados que o estado pedido
+func (s *FiscalPeriodService) CloseFiscalYear(companyID, yearID, userID uint, req models.CloseFiscalPeriodDTO) (*models.FiscalYear, error) {
+	var year models.FiscalYear
+	if err := config.DB.Preload("Periods", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number ASC")
+	}).Where("id = ? AND company_id = ?", yearID, companyID).First(&year).Error; err != nil {
+		return nil, errors.New("exercício não encontrado")
+	}
+
+	changed := 0
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range year.Periods {
+			period := &year.Periods[i]
+			if periodStatusRank[req.Status] <= periodStatusRank[period.Status] {
+				continue
+			}
+			if err := s.transition(tx, period, req.Status, "close", req.Reason, userID); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.New("erro ao fechar exercício")
+	}
+	if changed == 0 {
+		return nil, fmt.Errorf("o exercício %d já está fechado", year.Year)
+	}
+	return &year, nil
+}
+
+// ReopenPeriod reabre um período fechado (apenas admin); o motivo fica registado no histórico
+func (s *FiscalPeriodService) ReopenPeriod(companyID, periodID, userID uint, req models.ReopenFiscalPeriodDTO) (*models.FiscalPeriod, error) {
+	period, err := s.getPeriod(companyID, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.PeriodStatusOpen
+	}
+	if periodStatusRank[status] >= periodStatusRank[period.Status] {
+		return nil, fmt.Errorf("o período %s não pode ser reaberto para %s", periodLabel(period), status)
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return s.transition(tx, period, status, "reopen", strings.TrimSpace(req.Reason), userID)
+	}); err != nil {
+		return nil, errors.New("erro ao reabrir período")
+	}
+	return period, nil
+}
+
+// GetPeriodHistory devolve os fechos e reaberturas de um período, do mais recente para o mais antigo
+func (s *FiscalPeriodService) GetPeriodHistory(companyID, periodID uint) ([]models.FiscalPeriodHistory, error) {
+	if _, err := s.getPeriod(companyID, periodID); err != nil {
+		return nil, err
+	}
+
+	history := []models.FiscalPeriodHistory{}
+	if err := config.DB.Preload("PerformedByUser").
+		Where("fiscal_period_id = ?", periodID).
+		Order("created_at DESC").Find(&history).Error; err != nil {
+		return nil, errors.New("erro ao obter histórico do período")
+	}
+	return history, nil
+}
+
+// PeriodForDate devolve o período da empresa que contém a data, ou nil se não houver exercício aberto para ela
+func (s *FiscalPeriodService) PeriodForDate(companyID uint, date time.Time) *models.FiscalPeriod {
+	var period models.FiscalPeriod
+	if err := config.DB.Where("company_id = ? AND start_date <= ? AND end_date >= ?", companyID, date, date).First(&period).Error; err != nil {
+		return nil
+	}
+	return &period
+}
+
+// EnsureWritable rejeita alterações com efeito numa data de um período fechado.
+// Os períodos soft_closed só aceitam alterações de contabilistas e admins; os hard_closed não aceitam nenhuma.
+func (s *FiscalPeriodService) EnsureWritable(companyID uint, date time.Time, role string) error {
+	period := s.PeriodForDate(companyID, date)
+	if period == nil {
+		return nil
+	}
+	return checkPeriodWritable(period, role)
+}
+
+// EnsureDocumentPeriodWritable aplica EnsureWritable ao período fiscal de um documento (AAAA-MM ou AAAA).
+// Os documentos anuais ficam bloqueados quando o último mês do ano fecha.
+func (s *FiscalPeriodService) EnsureDocumentPeriodWritable(companyID uint, fiscalPeriod, role string) error {
+	if fiscalPeriod == "" {
+		return nil
+	}
+
+	var date time.Time
+	if len(fiscalPeriod) == 4 {
+		parsed, err := time.Parse("2006", fiscalPeriod)
+		if err != nil {
+			return nil
+		}
+		date = parsed.AddDate(1, 0, -1)
+	} else {
+		parsed, err := time.Parse("2006-01", fiscalPeriod)
+		if err != nil {
+			return nil
+		}
+		date = parsed
+	}
+	return s.EnsureWritable(companyID, date, role)
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// periodGuard aplica EnsureWritable às muitas datas de uma importação, consultando cada dia uma só vez
+type periodGuard struct {
+	companyID uint
+	role      string
+	checked   map[string]error
+}
+
+func newPeriodGuard(companyID uint, role string) *periodGuard {
+	return &periodGuard{companyID: companyID, role: role, checked: map[string]error{}}
+}
+
+func (g *periodGuard) check(date time.Time) error {
+	key := date.Format("2006-01-02")
+	if err, ok := g.checked[key]; ok {
+		return err
+	}
+	err := NewFiscalPeriodService().EnsureWritable(g.companyID, date, g.role)
+	g.checked[key] = err
+	return err
+}
+
+func (s *FiscalPeriodService) getPeriod(companyID, periodID uint) (*models.FiscalPeriod, error) {
+	var period models.FiscalPeriod
+	if err := config.DB.Where("id = ? AND company_id = ?", periodID, companyID).First(&period).Error; err != nil {
+		return nil, errors.New("período não encontrado")
+	}
+	return &period, nil
+}
+
+// transition altera o estado do período e regista a alteração no histórico
+func (s *FiscalPeriodService) transition(tx *gorm.DB, period *models.FiscalPeriod, status, action, reason string, userID uint) error {
+	history := models.FiscalPeriodHistory{
+		CompanyID:      period.CompanyID,
+		FiscalPeriodID: period.ID,
+		Action:         action,
+		FromStatus:     period.Status,
+		ToStatus:       status,
+		Reason:         reason,
+		PerformedBy:    userID,
+	}
+
+	period.Status = status
+	if status == models.PeriodStatusOpen {
+		period.ClosedBy = nil
+		period.ClosedAt = nil
+	} else {
+		now := time.Now()
+		period.ClosedBy = &userID
+		period.ClosedAt = &now
+	}
+
+	if err := tx.Save(period).Error; err != nil {
+		return err
+	}
+	return tx.Create(&history).Error
+}
+
+func checkPeriodWritable(period *models.FiscalPeriod, role string) error {
+	switch period.Status {
+	case models.PeriodStatusHardClosed:
+		return fmt.Errorf("o período %s está fechado definitivamente", periodLabel(period))
+	case models.PeriodStatusSoftClosed:
+		if role == models.RoleClient {
+			return fmt.Errorf("o período %s está fechado", periodLabel(period))
+		}
+	}
+	return nil
+}
+
+func periodLabel(period *models.FiscalPeriod) string {
+	return period.StartDate.Format("2006-01")
+}
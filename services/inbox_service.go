@@ -0,0 +1,135 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InboxService struct{}
+
+func NewInboxService() *InboxService {
+	return &InboxService{}
+}
+
+// Notify cria uma notificação na caixa de entrada do utilizador
+func (s *InboxService) Notify(userID uint, event, title, message, link string) {
+	notification := models.Notification{
+		UserID:  userID,
+		Event:   event,
+		Title:   title,
+		Message: message,
+		Link:    link,
+	}
+	if err := config.DB.Create(&notification).Error; err != nil {
+		log.Printf("⚠️  Erro ao criar notificação para o utilizador %d: %v", userID, err)
+	}
+}
+
+// GetNotifications lista as notificações do utilizador (as mais recentes primeiro)
+func (s *InboxService) GetNotifications(userID uint, unreadOnly bool, limit, offset int) (*models.NotificationListDTO, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := config.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	// Sessão própria para reutilizar as condições na contagem e na listagem
+	query = query.Session(&gorm.Session{})
+
+	result := models.NotificationListDTO{Notifications: []models.Notification{}}
+	if err := query.Count(&result.Total).Error; err != nil {
+		return nil, errors.New("erro ao obter notificações")
+	}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&result.Notifications).Error; err != nil {
+		return nil, errors.New("erro ao obter notificações")
+	}
+
+	unread, err := s.UnreadCount(userID)
+	if err != nil {
+		return nil, err
+	}
+	result.Unread = unread
+
+	return &result, nil
+}
+
+// UnreadCount devolve o número de notificações por ler
+func (s *InboxService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	if err := config.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, errors.New("erro ao contar notificações")
+	}
+	return count, nil
+}
+
+// MarkAsRead marca uma notificação do utilizador como lida
+func (s *InboxService) MarkAsRead(userID, notificationID uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := config.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		return nil, errors.New("notificação não encontrada")
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		if err := config.DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, errors.New("erro ao marcar notificação como lida")
+		}
+	}
+
+	return &notification, nil
+}
+
+// MarkManyAsRead marca várias (ou todas) as notificações do utilizador como lidas
+func (s *InboxService) MarkManyAsRead(userID uint, req models.NotificationBulkDTO) (int64, error) {
+	query, err := s.bulkQuery(userID, req)
+	if err != nil {
+		return 0, err
+	}
+
+	result := query.Where("read_at IS NULL").Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, errors.New("erro ao marcar notificações como lidas")
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteMany elimina várias (ou todas) as notificações do utilizador
+func (s *InboxService) DeleteMany(userID uint, req models.NotificationBulkDTO) (int64, error) {
+	query, err := s.bulkQuery(userID, req)
+	if err != nil {
+		return 0, err
+	}
+
+	result := query.Delete(&models.Notification{})
+	if result.Error != nil {
+		return 0, errors.New("erro ao eliminar notificações")
+	}
+	return result.RowsAffected, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func (s *InboxService) bulkQuery(userID uint, req models.NotificationBulkDTO) (*gorm.DB, error) {
+	if !req.All && len(req.IDs) == 0 {
+		return nil, errors.New("indique as notificações ou all=true")
+	}
+
+	query := config.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if !req.All {
+		query = query.Where("id IN ?", req.IDs)
+	}
+	return query, nil
+}
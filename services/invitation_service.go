@@ -0,0 +1,401 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// invitationTTL é a validade de um convite enviado a um cliente
+const invitationTTL = 7 * 24 * time.Hour
+
+type InvitationService struct{}
+
+func NewInvitationService() *InvitationService {
+	return &InvitationService{}
+}
+
+// CreateInvitation regista o convite com os dados pré-preenchidos e envia o link ao cliente
+func (s *InvitationService) CreateInvitation(req models.CreateInvitationDTO, invitedBy uint) (*models.ClientInvitation, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	nif := strings.ReplaceAll(req.NIF, " ", "")
+	nipc := strings.ReplaceAll(req.NIPC, " ", "")
+
+	if nif != "" && !utils.ValidNIF(nif) {
+		return nil, errors.New("NIF inválido")
+	}
+	if nipc != "" && !utils.ValidNIF(nipc) {
+		return nil, errors.New("NIPC inválido")
+	}
+	if err := s.checkDuplicates(email, nif, nipc, req.Username); err != nil {
+		return nil, err
+	}
+
+	language := strings.ToLower(strings.TrimSpace(req.Language))
+	if language == "" {
+		language = models.DefaultLanguage
+	}
+
+	now := time.Now()
+	invitation := models.ClientInvitation{
+		Token:             utils.GenerateRandomToken(),
+		Status:            models.InvitationStatusPending,
+		InvitedBy:         invitedBy,
+		ExpiresAt:         now.Add(invitationTTL),
+		SentAt:            now,
+		SendCount:         1,
+		Email:             email,
+		Name:              strings.TrimSpace(req.Name),
+		Phone:             strings.TrimSpace(req.Phone),
+		NIF:               nif,
+		Username:          strings.TrimSpace(req.Username),
+		Language:          language,
+		CompanyName:       strings.TrimSpace(req.CompanyName),
+		NIPC:              nipc,
+		LegalForm:         req.LegalForm,
+		CAE:               req.CAE,
+		TradeName:         req.TradeName,
+		AccountingRegime:  req.AccountingRegime,
+		VATRegime:         req.VATRegime,
+		BusinessActivity:  req.BusinessActivity,
+		CompanyAddress:    req.CompanyAddress,
+		CompanyPostalCode: req.CompanyPostalCode,
+		CompanyCity:       req.CompanyCity,
+		CompanyDistrict:   req.CompanyDistrict,
+		Notes:             req.Notes,
+	}
+	if err := config.DB.Create(&invitation).Error; err != nil {
+		return nil, errors.New("erro ao criar convite")
+	}
+
+	if err := s.send(&invitation); err != nil {
+		log.Printf("⚠️  Erro ao enviar convite %d: %v", invitation.ID, err)
+	}
+
+	return &invitation, nil
+}
+
+// GetInvitations lista os convites, dos mais recentes para os mais antigos (filtro opcional por estado)
+func (s *InvitationService) GetInvitations(status string, scope *PortfolioScope) ([]models.ClientInvitation, error) {
+	query := scope.Invitations(config.DB.Preload("InvitedByUser")).Order("created_at DESC")
+	switch status {
+	case "":
+	case models.InvitationStatusExpired:
+		query = query.Where("status = ? AND expires_at < ?", models.InvitationStatusPending, time.Now())
+	case models.InvitationStatusPending:
+		query = query.Where("status = ? AND expires_at >= ?", models.InvitationStatusPending, time.Now())
+	default:
+		query = query.Where("status = ?", status)
+	}
+
+	var invitations []models.ClientInvitation
+	if err := query.Find(&invitations).Error; err != nil {
+		return nil, errors.New("erro ao obter convites")
+	}
+	for i := range invitations {
+		refreshInvitationStatus(&invitations[i])
+	}
+	return invitations, nil
+}
+
+// GetInvitation devolve um convite pelo ID, se estiver no scope do utilizador
+func (s *InvitationService) GetInvitation(invitationID uint, scope *PortfolioScope) (*models.ClientInvitation, error) {
+	var invitation models.ClientInvitation
+	if err := scope.Invitations(config.DB.Preload("InvitedByUser")).First(&invitation, invitationID).Error; err != nil {
+		return nil, errors.New("convite não encontrado")
+	}
+	refreshInvitationStatus(&invitation)
+	return &invitation, nil
+}
+
+// ResendInvitation gera um novo link e renova o prazo de um convite por aceitar (pendente ou expirado)
+func (s *InvitationService) ResendInvitation(invitationID uint, scope *PortfolioScope) (*models.ClientInvitation, error) {
+	invitation, err := s.GetInvitation(invitationID, scope)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Status == models.InvitationStatusAccepted {
+		return nil, errors.New("convite já foi aceite")
+	}
+	if invitation.Status == models.InvitationStatusRevoked {
+		return nil, errors.New("convite foi revogado")
+	}
+
+	now := time.Now()
+	invitation.Token = utils.GenerateRandomToken()
+	invitation.Status = models.InvitationStatusPending
+	invitation.SentAt = now
+	invitation.ExpiresAt = now.Add(invitationTTL)
+	invitation.SendCount++
+	if err := config.DB.Model(invitation).Updates(map[string]interface{}{
+		"token":      invitation.Token,
+		"sent_at":    invitation.SentAt,
+		"expires_at": invitation.ExpiresAt,
+		"send_count": invitation.SendCount,
+	}).Error; err != nil {
+		return nil, errors.New("erro ao renovar convite")
+	}
+
+	if err := s.send(invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// RevokeInvitation cancela um convite por aceitar; o link deixa de funcionar
+func (s *InvitationService) RevokeInvitation(invitationID, revokedBy uint, scope *PortfolioScope) (*models.ClientInvitation, error) {
+	invitation, err := s.GetInvitation(invitationID, scope)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Status == models.InvitationStatusAccepted {
+		return nil, errors.New("convite já foi aceite")
+	}
+	if invitation.Status == models.InvitationStatusRevoked {
+		return nil, errors.New("convite já foi revogado")
+	}
+
+	now := time.Now()
+	invitation.Status = models.InvitationStatusRevoked
+	invitation.RevokedAt = &now
+	invitation.RevokedBy = &revokedBy
+	invitation.Token = ""
+	if err := config.DB.Model(invitation).Updates(map[string]interface{}{
+		"status":     invitation.Status,
+		"revoked_at": now,
+		"revoked_by": revokedBy,
+		"token":      "",
+	}).Error; err != nil {
+		return nil, errors.New("erro ao revogar convite")
+	}
+
+	return invitation, nil
+}
+
+// CheckToken devolve o convite associado a um token válido, para o cliente ver os dados pré-preenchidos
+func (s *InvitationService) CheckToken(token string) (*models.ClientInvitation, error) {
+	if token == "" {
+		return nil, errors.New("convite inválido")
+	}
+
+	var invitation models.ClientInvitation
+	if err := config.DB.Preload("InvitedByUser").Where("token = ?", token).First(&invitation).Error; err != nil {
+		return nil, errors.New("convite inválido")
+	}
+	refreshInvitationStatus(&invitation)
+
+	switch invitation.Status {
+	case models.InvitationStatusAccepted:
+		return nil, errors.New("convite já foi aceite")
+	case models.InvitationStatusRevoked:
+		return nil, errors.New("convite foi revogado")
+	case models.InvitationStatusExpired:
+		return nil, errors.New("convite expirado")
+	}
+	return &invitation, nil
+}
+
+// AcceptInvitation cria a conta e a empresa já aprovadas com os dados do convite e a password escolhida.
+// Devolve a sessão para o cliente seguir diretamente para completar os dados pessoais e da empresa.
+func (s *InvitationService) AcceptInvitation(req models.AcceptInvitationDTO) (*models.AuthResponse, error) {
+	invitation, err := s.CheckToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Os dados preenchidos pelo contabilista prevalecem; o cliente só completa o que falta
+	username := firstNonEmpty(invitation.Username, strings.TrimSpace(req.Username), invitation.Email)
+	name := firstNonEmpty(invitation.Name, strings.TrimSpace(req.Name))
+	phone := firstNonEmpty(invitation.Phone, strings.TrimSpace(req.Phone))
+	nif := firstNonEmpty(invitation.NIF, strings.ReplaceAll(req.NIF, " ", ""))
+
+	if name == "" || phone == "" || nif == "" {
+		return nil, errors.New("indique o nome, o telemóvel e o NIF")
+	}
+	if !utils.ValidNIF(nif) {
+		return nil, errors.New("NIF inválido")
+	}
+	if err := NewAuthService().checkExistingUser(nif, invitation.Email); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("erro ao processar password")
+	}
+
+	now := time.Now()
+	optional := func(value string) *string {
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+	request := models.RegistrationRequest{
+		RequestType:       "invitation",
+		Status:            "approved",
+		ReviewedAt:        &now,
+		ReviewedBy:        &invitation.InvitedBy,
+		ReviewNotes:       "Convite aceite",
+		ApprovalToken:     utils.GenerateRandomToken(),
+		AssignedTo:        &invitation.InvitedBy,
+		AssignedAt:        &invitation.CreatedAt,
+		EmailVerifiedAt:   &now, // O link foi recebido no email convidado
+		Username:          username,
+		Name:              &name,
+		Email:             &invitation.Email,
+		Phone:             &phone,
+		NIF:               &nif,
+		PasswordHash:      string(hashedPassword),
+		CompanyName:       &invitation.CompanyName,
+		NIPC:              invitation.NIPC,
+		LegalForm:         invitation.LegalForm,
+		CAE:               optional(invitation.CAE),
+		TradeName:         optional(invitation.TradeName),
+		AccountingRegime:  optional(invitation.AccountingRegime),
+		VATRegime:         optional(invitation.VATRegime),
+		BusinessActivity:  optional(invitation.BusinessActivity),
+		CompanyAddress:    optional(invitation.CompanyAddress),
+		CompanyPostalCode: optional(invitation.CompanyPostalCode),
+		CompanyCity:       optional(invitation.CompanyCity),
+		CompanyDistrict:   optional(invitation.CompanyDistrict),
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Marcar o convite primeiro, para que dois pedidos simultâneos não criem duas contas
+		claim := tx.Model(&models.ClientInvitation{}).
+			Where("id = ? AND status = ?", invitation.ID, models.InvitationStatusPending).
+			Updates(map[string]interface{}{
+				"status":      models.InvitationStatusAccepted,
+				"accepted_at": now,
+			})
+		if claim.Error != nil {
+			return errors.New("erro ao aceitar convite")
+		}
+		if claim.RowsAffected == 0 {
+			return errors.New("convite já foi aceite")
+		}
+
+		userID, companyID, err := NewAdminService().createUserAndCompany(tx, request)
+		if err != nil {
+			return err
+		}
+		request.UserID = &userID
+		request.CompanyID = &companyID
+		if err := tx.Create(&request).Error; err != nil {
+			return errors.New("erro ao registar solicitação do convite")
+		}
+
+		return tx.Model(&models.ClientInvitation{}).Where("id = ?", invitation.ID).Updates(map[string]interface{}{
+			"user_id":    userID,
+			"company_id": companyID,
+			"request_id": request.ID,
+			"token":      "",
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, *request.UserID).Error; err != nil {
+		return nil, errors.New("erro ao obter utilizador criado")
+	}
+	if invitation.Language != "" {
+		config.DB.Model(&user).Update("language", invitation.Language)
+	}
+
+	NewInboxService().Notify(user.ID, models.EventRequestApproved, "Bem-vindo",
+		"A sua conta foi criada. Complete os seus dados pessoais e os dados da empresa.", "/api/client/profile")
+	NewInboxService().Notify(invitation.InvitedBy, models.EventInviteAccepted, "Convite aceite",
+		fmt.Sprintf("%s aceitou o convite para a empresa %s.", user.Name, invitation.CompanyName),
+		fmt.Sprintf("/api/admin/clients/%d", user.ID))
+
+	token, err := utils.GenerateToken(user.ID, user.Username, user.NIF, user.Role)
+	if err != nil {
+		return nil, errors.New("erro ao gerar token")
+	}
+
+	return &models.AuthResponse{
+		Token: token,
+		User:  user,
+	}, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// send coloca na caixa de saída o email com o link do convite
+func (s *InvitationService) send(invitation *models.ClientInvitation) error {
+	invitedByName := "A RV Contabilidade"
+	var inviter models.User
+	if config.DB.First(&inviter, invitation.InvitedBy).Error == nil && inviter.Name != "" {
+		invitedByName = inviter.Name
+	}
+
+	link := fmt.Sprintf("%s/api/auth/invitation?token=%s", appBaseURL(), url.QueryEscape(invitation.Token))
+	_, err := NewNotificationService().Enqueue(models.EventClientInvitation, invitation.Language, invitation.Email, map[string]interface{}{
+		"Name":          invitation.Name,
+		"CompanyName":   invitation.CompanyName,
+		"InvitedByName": invitedByName,
+		"Notes":         invitation.Notes,
+		"Link":          link,
+		"Days":          int(invitationTTL.Hours() / 24),
+	}, nil, nil)
+	if err != nil {
+		return errors.New("erro ao enviar convite")
+	}
+	return nil
+}
+
+// checkDuplicates impede convidar quem já é cliente, já tem convite por aceitar ou solicitação pendente
+func (s *InvitationService) checkDuplicates(email, nif, nipc, username string) error {
+	if err := NewAuthService().checkExistingUser(nif, email); err != nil {
+		return err
+	}
+
+	var existing models.ClientInvitation
+	if config.DB.Where("LOWER(email) = ? AND status = ? AND expires_at >= ?", email, models.InvitationStatusPending, time.Now()).
+		First(&existing).Error == nil {
+		return errors.New("já existe um convite pendente para este email")
+	}
+	if nipc != "" && config.DB.Where("nipc = ? AND status = ? AND expires_at >= ?", nipc, models.InvitationStatusPending, time.Now()).
+		First(&existing).Error == nil {
+		return errors.New("já existe um convite pendente para este NIPC")
+	}
+
+	var request models.RegistrationRequest
+	if config.DB.Where("LOWER(email) = ? AND status = ?", email, "pending").First(&request).Error == nil {
+		return errors.New("já existe uma solicitação pendente com este email")
+	}
+
+	if username == "" {
+		username = email
+	}
+	return NewAuthService().checkUniqueIdentifiers(username, nipc)
+}
+
+// refreshInvitationStatus marca como expirado (só na leitura) um convite pendente fora do prazo
+func refreshInvitationStatus(invitation *models.ClientInvitation) {
+	if invitation.Status == models.InvitationStatusPending && time.Now().After(invitation.ExpiresAt) {
+		invitation.Status = models.InvitationStatusExpired
+	}
+}
+
+// firstNonEmpty devolve o primeiro valor não vazio
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
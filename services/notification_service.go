@@ -0,0 +1,420 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"bytes"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// outboxBatchSize é o número máximo de emails enviados em cada ciclo do dispatcher
+	outboxBatchSize = 20
+	// outboxMaxAttempts é o número de tentativas antes de marcar um email como falhado
+	outboxMaxAttempts = 5
+)
+
+// defaultEmailTemplates são usados quando não existe template editado na base de dados
+var defaultEmailTemplates = map[string]map[string]models.EmailTemplate{
+	models.EventEmailVerification: {
+		"pt": {
+			Subject: "Confirme o seu email - RV Contabilidade",
+			Body: "Olá {{.Name}},\n\nRecebemos o seu pedido de registo na RV Contabilidade.\n" +
+				"Para confirmar o seu email, abra o link abaixo (válido durante {{.Hours}} horas):\n\n{{.Link}}\n\n" +
+				"Se não fez este pedido, ignore esta mensagem.\n",
+		},
+		"en": {
+			Subject: "Confirm your email - RV Contabilidade",
+			Body: "Hello {{.Name}},\n\nWe received your registration request at RV Contabilidade.\n" +
+				"To confirm your email, open the link below (valid for {{.Hours}} hours):\n\n{{.Link}}\n\n" +
+				"If you did not make this request, please ignore this message.\n",
+		},
+	},
+	models.EventRequestReceived: {
+		"pt": {
+			Subject: "Recebemos o seu pedido de registo",
+			Body: "Olá {{.Name}},\n\nO seu pedido de registo (utilizador {{.Username}}) foi recebido e será revisto pela nossa equipa.\n" +
+				"Receberá um email assim que for analisado.\n",
+		},
+		"en": {
+			Subject: "We received your registration request",
+			Body: "Hello {{.Name}},\n\nYour registration request (username {{.Username}}) was received and will be reviewed by our team.\n" +
+				"You will receive an email once it has been reviewed.\n",
+		},
+	},
+	models.EventRequestApproved: {
+		"pt": {
+			Subject: "O seu registo foi aprovado",
+			Body: "Olá {{.Name}},\n\nO seu pedido de registo foi aprovado. Já pode entrar com o utilizador {{.Username}}.\n" +
+				"{{if .ReviewNotes}}\nNotas da contabilista: {{.ReviewNotes}}\n{{end}}",
+		},
+		"en": {
+			Subject: "Your registration was approved",
+			Body: "Hello {{.Name}},\n\nYour registration request was approved. You can now log in as {{.Username}}.\n" +
+				"{{if .ReviewNotes}}\nAccountant notes: {{.ReviewNotes}}\n{{end}}",
+		},
+	},
+	models.EventRequestRejected: {
+		"pt": {
+			Subject: "O seu pedido de registo não foi aprovado",
+			Body: "Olá {{.Name}},\n\nLamentamos, mas o seu pedido de registo não foi aprovado.\n" +
+				"{{if .ReviewNotes}}\nMotivo: {{.ReviewNotes}}\n{{end}}\nPara mais informações contacte-nos.\n",
+		},
+		"en": {
+			Subject: "Your registration request was not approved",
+			Body: "Hello {{.Name}},\n\nWe are sorry, but your registration request was not approved.\n" +
+				"{{if .ReviewNotes}}\nReason: {{.ReviewNotes}}\n{{end}}\nPlease contact us for more information.\n",
+		},
+	},
+	models.EventRequestNeedsInfo: {
+		"pt": {
+			Subject: "Precisamos de mais informação sobre o seu registo",
+			Body: "Olá {{.Name}},\n\nPara concluirmos a análise do seu pedido de registo precisamos de mais informação:\n\n" +
+				"{{.ReviewNotes}}\n\nResponda a este email ou contacte-nos.\n",
+		},
+		"en": {
+			Subject: "We need more information about your registration",
+			Body: "Hello {{.Name}},\n\nTo finish reviewing your registration request we need more information:\n\n" +
+				"{{.ReviewNotes}}\n\nPlease reply to this email or contact us.\n",
+		},
+	},
+	models.EventAccountBlocked: {
+		"pt": {
+			Subject: "A sua conta foi bloqueada",
+			Body:    "Olá {{.Name}},\n\nA sua conta RV Contabilidade ({{.Username}}) foi bloqueada.\n{{if .Notes}}\nMotivo: {{.Notes}}\n{{end}}\nContacte o suporte para mais informações.\n",
+		},
+		"en": {
+			Subject: "Your account was blocked",
+			Body:    "Hello {{.Name}},\n\nYour RV Contabilidade account ({{.Username}}) was blocked.\n{{if .Notes}}\nReason: {{.Notes}}\n{{end}}\nPlease contact support for more information.\n",
+		},
+	},
+	models.EventPasswordChanged: {
+		"pt": {
+			Subject: "A sua password foi alterada",
+			Body:    "Olá {{.Name}},\n\nA password da sua conta ({{.Username}}) foi alterada.\nSe não foi você, contacte-nos imediatamente.\n",
+		},
+		"en": {
+			Subject: "Your password was changed",
+			Body:    "Hello {{.Name}},\n\nThe password of your account ({{.Username}}) was changed.\nIf this was not you, contact us immediately.\n",
+		},
+	},
+	models.EventAccountActivation: {
+		"pt": {
+			Subject: "Ative a sua conta - RV Contabilidade",
+			Body: "Olá {{.Name}},\n\nA sua conta na RV Contabilidade foi criada com o utilizador {{.Username}}.\n" +
+				"Para definir a sua password e ativar a conta, abra o link abaixo (válido durante {{.Days}} dias):\n\n{{.Link}}\n",
+		},
+		"en": {
+			Subject: "Activate your account - RV Contabilidade",
+			Body: "Hello {{.Name}},\n\nYour RV Contabilidade account was created with the username {{.Username}}.\n" +
+				"To set your password and activate the account, open the link below (valid for {{.Days}} days):\n\n{{.Link}}\n",
+		},
+	},
+	models.EventClientInvitation: {
+		"pt": {
+			Subject: "Convite para a RV Contabilidade",
+			Body: "Olá{{if .Name}} {{.Name}}{{end}},\n\n{{.InvitedByName}} convidou-o a aceder à área de cliente da RV Contabilidade para a empresa {{.CompanyName}}.\n" +
+				"{{if .Notes}}\n{{.Notes}}\n{{end}}\nPara aceitar o convite e escolher a sua password, abra o link abaixo (válido durante {{.Days}} dias):\n\n{{.Link}}\n",
+		},
+		"en": {
+			Subject: "Invitation to RV Contabilidade",
+			Body: "Hello{{if .Name}} {{.Name}}{{end}},\n\n{{.InvitedByName}} invited you to the RV Contabilidade client area for the company {{.CompanyName}}.\n" +
+				"{{if .Notes}}\n{{.Notes}}\n{{end}}\nTo accept the invitation and choose your password, open the link below (valid for {{.Days}} days):\n\n{{.Link}}\n",
+		},
+	},
+}
+
+type NotificationService struct{}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// Enqueue renderiza o template do evento e coloca o email na caixa de saída
+func (s *NotificationService) Enqueue(event, language, to string, data map[string]interface{}, userID, requestID *uint) (*models.OutboxEmail, error) {
+	if to == "" {
+		return nil, errors.New("destinatário sem email")
+	}
+	if language == "" {
+		language = models.DefaultLanguage
+	}
+
+	tmpl, err := s.findTemplate(event, language)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := renderTemplate(tmpl.Subject, data)
+	if err != nil {
+		return nil, errors.New("erro ao gerar assunto do email")
+	}
+	body, err := renderTemplate(tmpl.Body, data)
+	if err != nil {
+		return nil, errors.New("erro ao gerar corpo do email")
+	}
+
+	email := models.OutboxEmail{
+		Event:         event,
+		Language:      tmpl.Language,
+		ToAddress:     to,
+		Subject:       subject,
+		Body:          body,
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+		UserID:        userID,
+		RequestID:     requestID,
+	}
+	if err := config.DB.Create(&email).Error; err != nil {
+		return nil, errors.New("erro ao guardar email na caixa de saída")
+	}
+
+	return &email, nil
+}
+
+// NotifyRequest envia um evento para o email de uma solicitação de registo
+func (s *NotificationService) NotifyRequest(event string, request *models.RegistrationRequest, extra map[string]interface{}) error {
+	if request.Email == nil || *request.Email == "" {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"Name":        request.Username,
+		"Username":    request.Username,
+		"ReviewNotes": request.ReviewNotes,
+	}
+	if request.Name != nil && *request.Name != "" {
+		data["Name"] = *request.Name
+	}
+	if request.CompanyName != nil {
+		data["CompanyName"] = *request.CompanyName
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+
+	_, err := s.Enqueue(event, models.DefaultLanguage, *request.Email, data, request.UserID, &request.ID)
+	return err
+}
+
+// NotifyUser envia um evento para o email de um utilizador
+func (s *NotificationService) NotifyUser(event string, user *models.User, extra map[string]interface{}) error {
+	data := map[string]interface{}{
+		"Name":     user.Name,
+		"Username": user.Username,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+
+	_, err := s.Enqueue(event, user.Language, user.Email, data, &user.ID, nil)
+	return err
+}
+
+// GetTemplates lista os templates de todos os eventos e idiomas (editados ou por omissão)
+func (s *NotificationService) GetTemplates() ([]models.EmailTemplate, error) {
+	var stored []models.EmailTemplate
+	if err := config.DB.Find(&stored).Error; err != nil {
+		return nil, errors.New("erro ao obter templates")
+	}
+
+	byKey := make(map[string]models.EmailTemplate)
+	for event, languages := range defaultEmailTemplates {
+		for language, tmpl := range languages {
+			tmpl.Event = event
+			tmpl.Language = language
+			byKey[event+"/"+language] = tmpl
+		}
+	}
+	for _, tmpl := range stored {
+		byKey[tmpl.Event+"/"+tmpl.Language] = tmpl
+	}
+
+	templates := make([]models.EmailTemplate, 0, len(byKey))
+	for _, tmpl := range byKey {
+		templates = append(templates, tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Event != templates[j].Event {
+			return templates[i].Event < templates[j].Event
+		}
+		return templates[i].Language < templates[j].Language
+	})
+
+	return templates, nil
+}
+
+// UpdateTemplate cria ou atualiza o template de um evento num idioma
+func (s *NotificationService) UpdateTemplate(event, language string, req models.EmailTemplateDTO, updatedBy uint) (*models.EmailTemplate, error) {
+	if _, ok := defaultEmailTemplates[event]; !ok {
+		return nil, errors.New("evento desconhecido")
+	}
+	language = strings.ToLower(strings.TrimSpace(language))
+	if len(language) < 2 || len(language) > 5 {
+		return nil, errors.New("idioma inválido")
+	}
+	if _, err := template.New("subject").Parse(req.Subject); err != nil {
+		return nil, errors.New("template inválido: " + err.Error())
+	}
+	if _, err := template.New("body").Parse(req.Body); err != nil {
+		return nil, errors.New("template inválido: " + err.Error())
+	}
+
+	var tmpl models.EmailTemplate
+	if config.DB.Where("event = ? AND language = ?", event, language).First(&tmpl).Error != nil {
+		tmpl = models.EmailTemplate{Event: event, Language: language}
+	}
+	tmpl.Subject = req.Subject
+	tmpl.Body = req.Body
+	tmpl.UpdatedBy = &updatedBy
+
+	if err := config.DB.Save(&tmpl).Error; err != nil {
+		return nil, errors.New("erro ao guardar template")
+	}
+
+	return &tmpl, nil
+}
+
+// GetOutbox lista os emails da caixa de saída (os mais recentes primeiro)
+func (s *NotificationService) GetOutbox(status string) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+	query := config.DB.Order("id DESC").Limit(200)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&emails).Error; err != nil {
+		return nil, errors.New("erro ao obter caixa de saída")
+	}
+	return emails, nil
+}
+
+// RetryOutboxEmail volta a colocar um email falhado na fila de envio
+func (s *NotificationService) RetryOutboxEmail(emailID uint) (*models.OutboxEmail, error) {
+	var email models.OutboxEmail
+	if err := config.DB.First(&email, emailID).Error; err != nil {
+		return nil, errors.New("email não encontrado")
+	}
+	if email.Status == models.OutboxStatusSent {
+		return nil, errors.New("email já foi enviado")
+	}
+
+	email.Status = models.OutboxStatusPending
+	email.Attempts = 0
+	email.NextAttemptAt = time.Now()
+	if err := config.DB.Save(&email).Error; err != nil {
+		return nil, errors.New("erro ao reenviar email")
+	}
+
+	return &email, nil
+}
+
+// DispatchPendingEmails envia os emails pendentes cuja próxima tentativa já chegou
+func (s *NotificationService) DispatchPendingEmails() int {
+	var emails []models.OutboxEmail
+	if err := config.DB.Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, time.Now()).
+		Order("id ASC").
+		Limit(outboxBatchSize).
+		Find(&emails).Error; err != nil {
+		log.Printf("❌ Erro ao ler caixa de saída: %v", err)
+		return 0
+	}
+
+	sent := 0
+	mailer := utils.GetMailer()
+	for _, email := range emails {
+		// Reclamar o email para que outra instância não o envie em simultâneo
+		claim := config.DB.Model(&models.OutboxEmail{}).
+			Where("id = ? AND status = ?", email.ID, models.OutboxStatusPending).
+			Update("status", models.OutboxStatusSending)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+
+		err := mailer.Send(utils.EmailMessage{To: email.ToAddress, Subject: email.Subject, Body: email.Body})
+		if err == nil {
+			now := time.Now()
+			config.DB.Model(&email).Updates(map[string]interface{}{
+				"status":     models.OutboxStatusSent,
+				"sent_at":    now,
+				"attempts":   email.Attempts + 1,
+				"last_error": "",
+			})
+			sent++
+			continue
+		}
+
+		attempts := email.Attempts + 1
+		updates := map[string]interface{}{
+			"attempts":   attempts,
+			"last_error": err.Error(),
+			"status":     models.OutboxStatusPending,
+			// Backoff exponencial: 1, 2, 4, 8... minutos
+			"next_attempt_at": time.Now().Add(time.Minute * time.Duration(1<<(attempts-1))),
+		}
+		if attempts >= outboxMaxAttempts {
+			updates["status"] = models.OutboxStatusFailed
+		}
+		config.DB.Model(&email).Updates(updates)
+	}
+
+	return sent
+}
+
+// StartNotificationDispatcher inicia o envio periódico dos emails da caixa de saída
+func StartNotificationDispatcher(interval time.Duration) {
+	// Emails que ficaram a meio do envio (ex.: reinício do servidor) voltam à fila
+	config.DB.Model(&models.OutboxEmail{}).
+		Where("status = ?", models.OutboxStatusSending).
+		Update("status", models.OutboxStatusPending)
+
+	service := NewNotificationService()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			service.DispatchPendingEmails()
+		}
+	}()
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// findTemplate procura o template editado no idioma pedido, depois em português e por fim os templates por omissão
+func (s *NotificationService) findTemplate(event, language string) (*models.EmailTemplate, error) {
+	defaults, ok := defaultEmailTemplates[event]
+	if !ok {
+		return nil, errors.New("evento desconhecido")
+	}
+
+	for _, lang := range []string{language, models.DefaultLanguage} {
+		var tmpl models.EmailTemplate
+		if config.DB.Where("event = ? AND language = ?", event, lang).First(&tmpl).Error == nil {
+			return &tmpl, nil
+		}
+		if tmpl, ok := defaults[lang]; ok {
+			tmpl.Event = event
+			tmpl.Language = lang
+			return &tmpl, nil
+		}
+	}
+
+	return nil, errors.New("template não encontrado")
+}
+
+func renderTemplate(text string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New("email").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// vatMonthlyRevenueThreshold é o volume de negócios a partir do qual o IVA é mensal
+const vatMonthlyRevenueThreshold = 650000
+
+type ObligationService struct{}
+
+func NewObligationService() *ObligationService {
+	return &ObligationService{}
+}
+
+// GetClientObligations lista as obrigações da empresa do cliente entre duas datas (AAAA-MM-DD)
+func (s *ObligationService) GetClientObligations(userID, companyID uint, from, to, status string) ([]models.TaxObligation, error) {
+	company, err := clientCompany(userID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fromDate, err := parseDateOrDefault(from, now.AddDate(0, -3, 0))
+	if err != nil {
+		return nil, err
+	}
+	toDate, err := parseDateOrDefault(to, now.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureObligations(company, toDate); err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("company_id = ? AND due_date >= ? AND due_date <= ?", company.ID, fromDate, endOfDay(toDate))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	obligations := []models.TaxObligation{}
+	if err := query.Order("due_date ASC, type ASC").Find(&obligations).Error; err != nil {
+		return nil, errors.New("erro ao obter obrigações")
+	}
+
+	markOverdue(obligations, now)
+	return obligations, nil
+}
+
+// GetObligations lista as obrigações das empresas ativas da carteira com prazo até due_before (AAAA-MM-DD)
+func (s *ObligationService) GetObligations(dueBefore, status string, companyID uint, scope *PortfolioScope) ([]models.TaxObligation, error) {
+	now := time.Now()
+	dueDate, err := parseDateOrDefault(dueBefore, now.AddDate(0, 0, 30))
+	if err != nil {
+		return nil, err
+	}
+
+	var companies []models.Company
+	query := scope.Companies(config.DB.Where("status = ?", "active"), "id")
+	if companyID != 0 {
+		query = query.Where("id = ?", companyID)
+	}
+	if err := query.Find(&companies).Error; err != nil {
+		return nil, errors.New("erro ao obter empresas")
+	}
+	for i := range companies {
+		if err := s.ensureObligations(&companies[i], dueDate); err != nil {
+			return nil, err
+		}
+	}
+
+	list := scope.Companies(config.DB.Preload("Company").Where("due_date <= ?", endOfDay(dueDate)), "company_id")
+	if status != "" {
+		list = list.Where("status = ?", status)
+	}
+	if companyID != 0 {
+		list = list.Where("company_id = ?", companyID)
+	}
+
+	obligations := []models.TaxObligation{}
+	if err := list.Order("due_date ASC, company_id ASC").Find(&obligations).Error; err != nil {
+		return nil, errors.New("erro ao obter obrigações")
+	}
+
+	markOverdue(obligations, now)
+	return obligations, nil
+}
+
+// UpdateObligation regista a submissão (ou dispensa) de uma obrigação de uma empresa da carteira
+func (s *ObligationService) UpdateObligation(obligationID uint, req models.UpdateObligationDTO, userID uint, scope *PortfolioScope) (*models.TaxObligation, error) {
+	var obligation models.TaxObligation
+	if err := scope.Companies(config.DB, "company_id").First(&obligation, obligationID).Error; err != nil {
+		return nil, errors.New("obrigação não encontrada")
+	}
+
+	obligation.Status = req.Status
+	obligation.Reference = req.Reference
+	obligation.Notes = req.Notes
+	if req.Status == models.ObligationStatusPending {
+		obligation.SubmittedAt = nil
+		obligation.SubmittedBy = nil
+	} else {
+		now := time.Now()
+		obligation.SubmittedAt = &now
+		obligation.SubmittedBy = &userID
+	}
+
+	if err := config.DB.Save(&obligation).Error; err != nil {
+		return nil, errors.New("erro ao atualizar obrigação")
+	}
+
+	obligations := []models.TaxObligation{obligation}
+	markOverdue(obligations, time.Now())
+	return &obligations[0], nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// ensureObligations cria as obrigações da empresa com prazo até à data indicada
+func (s *ObligationService) ensureObligations(company *models.Company, until time.Time) error {
+	// Só se geram obrigações a partir de dois meses antes da criação da empresa na plataforma
+	// ou de hoje, para não encher o calendário com prazos já tratados noutra ferramenta
+	start := time.Now().AddDate(0, -2, 0)
+	if company.CreatedAt.After(start) {
+		start = company.CreatedAt.AddDate(0, -2, 0)
+	}
+
+	obligations := deriveObligations(company, start, endOfDay(until))
+	if len(obligations) == 0 {
+		return nil
+	}
+
+	// Se o prazo de uma obrigação pendente mudar (ex.: alteração do regime), atualiza-se no lugar
+	if err := config.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "company_id"}, {Name: "type"}, {Name: "period"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"due_date":   gorm.Expr("excluded.due_date"),
+			"sequence":   gorm.Expr("tax_obligations.sequence + 1"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			gorm.Expr("tax_obligations.due_date <> excluded.due_date AND tax_obligations.status = ?", models.ObligationStatusPending),
+		}},
+	}).Create(&obligations).Error; err != nil {
+		return errors.New("erro ao gerar obrigações")
+	}
+	return nil
+}
+
+// GetCalendarObligations devolve as obrigações das empresas indicadas com prazo entre duas datas
+func (s *ObligationService) GetCalendarObligations(companies []models.Company, from, to time.Time) ([]models.TaxObligation, error) {
+	obligations := []models.TaxObligation{}
+	if len(companies) == 0 {
+		return obligations, nil
+	}
+
+	companyIDs := make([]uint, 0, len(companies))
+	for i := range companies {
+		if err := s.ensureObligations(&companies[i], to); err != nil {
+			return nil, err
+		}
+		companyIDs = append(companyIDs, companies[i].ID)
+	}
+
+	if err := config.DB.Preload("Company").
+		Where("company_id IN ? AND due_date >= ? AND due_date <= ?", companyIDs, from, endOfDay(to)).
+		Order("due_date ASC").
+		Find(&obligations).Error; err != nil {
+		return nil, errors.New("erro ao obter obrigações")
+	}
+	return obligations, nil
+}
+
+// ObligationLabel devolve o nome legível de um tipo de obrigação
+func ObligationLabel(obligationType string) string {
+	switch obligationType {
+	case models.ObligationVATMonthly:
+		return "IVA - declaração periódica mensal"
+	case models.ObligationVATQuarterly:
+		return "IVA - declaração periódica trimestral"
+	case models.ObligationSAFT:
+		return "Comunicação SAF-T de faturação"
+	case models.ObligationDMR:
+		return "DMR - Declaração Mensal de Remunerações"
+	case models.ObligationSocialSecurity:
+		return "Contribuições à Segurança Social"
+	case models.ObligationModelo22:
+		return "Modelo 22 (IRC)"
+	case models.ObligationIES:
+		return "IES - Informação Empresarial Simplificada"
+	}
+	return obligationType
+}
+
+// deriveObligations calcula as obrigações da empresa com prazo entre duas datas
+func deriveObligations(company *models.Company, from, to time.Time) []models.TaxObligation {
+	var obligations []models.TaxObligation
+	add := func(obligationType, period string, due time.Time) {
+		due = nextBusinessDay(due)
+		if due.Before(from) || due.After(to) {
+			return
+		}
+		obligations = append(obligations, models.TaxObligation{
+			CompanyID: company.ID,
+			Type:      obligationType,
+			Period:    period,
+			DueDate:   due,
+			Status:    models.ObligationStatusPending,
+		})
+	}
+
+	vatFrequency := companyVATFrequency(company)
+	hasEmployees := company.NumberEmployees > 0
+	individual := isIndividualEntrepreneur(company.LegalForm)
+
+	// Percorrer os períodos cujos prazos (até 7 meses depois) podem cair no intervalo
+	first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -7, 0)
+	for month := first; !month.After(to); month = month.AddDate(0, 1, 0) {
+		year, m := month.Year(), month.Month()
+		label := fmt.Sprintf("%d-%02d", year, int(m))
+
+		add(models.ObligationSAFT, label, dayOf(year, m+1, 5))
+
+		switch vatFrequency {
+		case "mensal":
+			add(models.ObligationVATMonthly, label, dayOf(year, m+2, 20))
+		case "trimestral":
+			if m%3 == 0 {
+				add(models.ObligationVATQuarterly, fmt.Sprintf("%d-T%d", year, int(m)/3), dayOf(year, m+2, 20))
+			}
+		}
+
+		if hasEmployees {
+			add(models.ObligationDMR, label, dayOf(year, m+1, 10))
+			add(models.ObligationSocialSecurity, label, dayOf(year, m+1, 20))
+		}
+
+		if m == time.December {
+			yearLabel := fmt.Sprintf("%d", year)
+			if !individual {
+				add(models.ObligationModelo22, yearLabel, dayOf(year+1, time.May, 31))
+			}
+			if !individual || strings.Contains(strings.ToLower(company.AccountingRegime), "organiz") {
+				add(models.ObligationIES, yearLabel, dayOf(year+1, time.July, 15))
+			}
+		}
+	}
+
+	return obligations
+}
+
+// companyVATFrequency devolve "mensal", "trimestral" ou "" (isento) a partir do regime de IVA
+func companyVATFrequency(company *models.Company) string {
+	regime := strings.ToLower(company.VATRegime)
+	switch {
+	case strings.Contains(regime, "isen"):
+		return ""
+	case strings.Contains(regime, "mensal"):
+		return "mensal"
+	case strings.Contains(regime, "trimestral"):
+		return "trimestral"
+	}
+
+	revenue := company.AnnualRevenue
+	if revenue == 0 {
+		revenue = company.EstimatedRevenue
+	}
+	if revenue >= vatMonthlyRevenueThreshold {
+		return "mensal"
+	}
+	return "trimestral"
+}
+
+func isIndividualEntrepreneur(legalForm string) bool {
+	form := strings.ToLower(utils.RemoveAccents(legalForm))
+	return strings.Contains(form, "individual") || strings.Contains(form, "empresario") || form == "eni"
+}
+
+// nextBusinessDay adia prazos que calham ao fim de semana para segunda-feira
+func nextBusinessDay(date time.Time) time.Time {
+	switch date.Weekday() {
+	case time.Saturday:
+		return date.AddDate(0, 0, 2)
+	case time.Sunday:
+		return date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// dayOf devolve o dia indicado, normalizando meses fora do intervalo 1-12
+func dayOf(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+func endOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, time.Local)
+}
+
+func parseDateOrDefault(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("data inválida (use AAAA-MM-DD)")
+	}
+	return date, nil
+}
+
+func markOverdue(obligations []models.TaxObligation, now time.Time) {
+	for i := range obligations {
+		obligations[i].Overdue = obligations[i].Status == models.ObligationStatusPending && now.After(endOfDay(obligations[i].DueDate))
+	}
+}
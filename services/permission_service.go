@@ -0,0 +1,474 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type PermissionService struct{}
+
+func NewPermissionService() *PermissionService {
+	return &PermissionService{}
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,29}$`)
+
+// HasPermission indica se o utilizador tem a permissão, pelo seu perfil ou por concessão explícita
+func (s *PermissionService) HasPermission(userID uint, permission string) bool {
+	var user models.User
+	if err := config.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		return false
+	}
+	return s.EffectivePermissions(user.ID, user.Role)[permission]
+}
+
+// EffectivePermissions junta as permissões do perfil com as concedidas explicitamente ao utilizador
+func (s *PermissionService) EffectivePermissions(userID uint, role string) map[string]bool {
+	permissions := make(map[string]bool)
+	for _, permission := range s.RolePermissions(role) {
+		permissions[permission] = true
+	}
+
+	var grants []string
+	config.DB.Model(&models.UserPermission{}).Where("user_id = ?", userID).Pluck("permission", &grants)
+	for _, permission := range grants {
+		permissions[permission] = true
+	}
+	return permissions
+}
+
+// RolePermissions devolve as permissões de um perfil (vazio se o perfil não existir)
+func (s *PermissionService) RolePermissions(role string) []string {
+	var permissions []string
+	config.DB.Model(&models.RolePermission{}).
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ?", role).
+		Order("role_permissions.permission ASC").
+		Pluck("role_permissions.permission", &permissions)
+	return permissions
+}
+
+// RolesWith devolve os nomes dos perfis que incluem a permissão
+func (s *PermissionService) RolesWith(permission string) []string {
+	var roles []string
+	config.DB.Model(&models.Role{}).
+		Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Where("role_permissions.permission = ?", permission).
+		Pluck("roles.name", &roles)
+	return roles
+}
+
+// GetUserPermissions lista as permissões concedidas a um utilizador
+func (s *PermissionService) GetUserPermissions(userID uint) ([]models.UserPermission, error) {
+	if err := s.ensureStaff(userID); err != nil {
+		return nil, err
+	}
+
+	var permissions []models.UserPermission
+	if err := config.DB.Where("user_id = ?", userID).Order("permission ASC").Find(&permissions).Error; err != nil {
+		return nil, errors.New("erro ao obter permissões")
+	}
+	return permissions, nil
+}
+
+// Grant concede uma permissão avulsa a um membro da equipa
+func (s *PermissionService) Grant(userID uint, permission string, grantedBy uint, ip string) (*models.UserPermission, error) {
+	if !models.IsValidPermission(permission) {
+		return nil, errors.New("permissão desconhecida: " + permission)
+	}
+	if err := s.ensureStaff(userID); err != nil {
+		return nil, err
+	}
+	var count int64
+	config.DB.Model(&models.UserPermission{}).Where("user_id = ? AND permission = ?", userID, permission).Count(&count)
+	if count > 0 {
+		return nil, errors.New("o utilizador já tem esta permissão")
+	}
+
+	grant := models.UserPermission{UserID: userID, Permission: permission, GrantedBy: grantedBy}
+	if err := config.DB.Create(&grant).Error; err != nil {
+		return nil, errors.New("erro ao conceder permissão")
+	}
+
+	NewAuditService().Record(grantedBy, models.AuditActionPermissionGrant, "user", &userID, map[string]interface{}{"permission": permission}, ip)
+	return &grant, nil
+}
+
+// Revoke retira uma permissão a um utilizador
+func (s *PermissionService) Revoke(userID uint, permission string, revokedBy uint, ip string) error {
+	result := config.DB.Where("user_id = ? AND permission = ?", userID, permission).Delete(&models.UserPermission{})
+	if result.Error != nil {
+		return errors.New("erro ao retirar permissão")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("permissão não encontrada")
+	}
+
+	NewAuditService().Record(revokedBy, models.AuditActionPermissionRevoke, "user", &userID, map[string]interface{}{"permission": permission}, ip)
+	return nil
+}
+
+// ===== PERFIS =====
+
+// GetRoles lista os perfis com as respetivas permissões e o número de utilizadores
+func (s *PermissionService) GetRoles() ([]models.Role, error) {
+	var roles []models.Role
+	if err := config.DB.Preload("Permissions").Order("system DESC, name ASC").Find(&roles).Error; err != nil {
+		return nil, errors.New("erro ao obter perfis")
+	}
+	for i := range roles {
+		s.fillRole(&roles[i])
+	}
+	return roles, nil
+}
+
+// GetRole obtém um perfil
+func (s *PermissionService) GetRole(roleID uint) (*models.Role, error) {
+	var role models.Role
+	if err := config.DB.Preload("Permissions").First(&role, roleID).Error; err != nil {
+		return nil, errors.New("perfil não encontrado")
+	}
+	s.fillRole(&role)
+	return &role, nil
+}
+
+// CreateRole cria um perfil novo com o conjunto de permissões indicado
+func (s *PermissionService) CreateRole(req models.RoleDTO, creatorID uint, ip string) (*models.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.New("nome de perfil inválido (2 a 30 caracteres: letras minúsculas, números, - e _)")
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	config.DB.Model(&models.Role{}).Where("name = ?", name).Count(&count)
+	if count > 0 {
+		return nil, errors.New("já existe um perfil com o nome " + name)
+	}
+
+	role := models.Role{Name: name, Description: strings.TrimSpace(req.Description)}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, models.RolePermission{Permission: permission})
+	}
+	if err := config.DB.Create(&role).Error; err != nil {
+		return nil, errors.New("erro ao criar perfil")
+	}
+
+	NewAuditService().Record(creatorID, models.AuditActionRoleCreate, "role", &role.ID, map[string]interface{}{
+		"name":        role.Name,
+		"permissions": permissions,
+	}, ip)
+
+	return s.GetRole(role.ID)
+}
+
+// UpdateRole altera a descrição e substitui as permissões de um perfil.
+// Os perfis base não podem ser renomeados e o perfil admin mantém sempre o acesso à gestão de perfis.
+func (s *PermissionService) UpdateRole(roleID uint, req models.RoleDTO, editorID uint, ip string) (*models.Role, error) {
+	role, err := s.GetRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if name == "" {
+		name = role.Name
+	}
+	if name != role.Name {
+		if role.System {
+			return nil, errors.New("os perfis base não podem ser renomeados")
+		}
+		if !roleNamePattern.MatchString(name) {
+			return nil, errors.New("nome de perfil inválido (2 a 30 caracteres: letras minúsculas, números, - e _)")
+		}
+		var count int64
+		config.DB.Model(&models.Role{}).Where("name = ? AND id <> ?", name, role.ID).Count(&count)
+		if count > 0 {
+			return nil, errors.New("já existe um perfil com o nome " + name)
+		}
+	}
+	if role.Name == models.RoleAdmin {
+		for _, required := range []string{models.PermissionBackofficeAccess, models.PermissionRolesManage} {
+			if !containsString(permissions, required) {
+				return nil, errors.New("o perfil admin tem de manter a permissão " + required)
+			}
+		}
+	}
+
+	previous := role.PermissionNames
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Role{}).Where("id = ?", role.ID).Updates(map[string]interface{}{
+			"name":        name,
+			"description": strings.TrimSpace(req.Description),
+		}).Error; err != nil {
+			return err
+		}
+		if name != role.Name {
+			if err := tx.Model(&models.User{}).Where("role = ?", role.Name).Update("role", name).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			if err := tx.Create(&models.RolePermission{RoleID: role.ID, Permission: permission}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("erro ao atualizar perfil")
+	}
+
+	NewAuditService().Record(editorID, models.AuditActionRoleUpdate, "role", &role.ID, map[string]interface{}{
+		"name":                 name,
+		"previous_name":        role.Name,
+		"permissions":          permissions,
+		"previous_permissions": previous,
+	}, ip)
+
+	return s.GetRole(role.ID)
+}
+
+// DeleteRole elimina um perfil que não seja base nem esteja atribuído a utilizadores
+func (s *PermissionService) DeleteRole(roleID uint, deletedBy uint, ip string) error {
+	role, err := s.GetRole(roleID)
+	if err != nil {
+		return err
+	}
+	if role.System {
+		return errors.New("os perfis base não podem ser eliminados")
+	}
+	if role.UserCount > 0 {
+		return errors.New("perfil atribuído a utilizadores; mude-lhes o perfil antes de o eliminar")
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Role{}, role.ID).Error
+	})
+	if err != nil {
+		return errors.New("erro ao eliminar perfil")
+	}
+
+	NewAuditService().Record(deletedBy, models.AuditActionRoleDelete, "role", &role.ID, map[string]interface{}{
+		"name":        role.Name,
+		"permissions": role.PermissionNames,
+	}, ip)
+	return nil
+}
+
+// SetUserRole muda o perfil de um utilizador. Ninguém pode mudar o próprio perfil
+// nem atribuir um perfil com permissões que não tem.
+func (s *PermissionService) SetUserRole(userID uint, role string, editorID uint, editorRole, ip string) (*models.User, error) {
+	if userID == editorID {
+		return nil, errors.New("não pode alterar o seu próprio perfil")
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("utilizador não encontrado")
+	}
+	if user.Role == role {
+		return &user, nil
+	}
+	if !s.RoleExists(role) {
+		return nil, errors.New("perfil não encontrado")
+	}
+	if (user.Role == models.RoleClient) != (role == models.RoleClient) {
+		return nil, errors.New("não é possível converter clientes em equipa ou equipa em clientes")
+	}
+	if !s.CanAssignRole(editorID, editorRole, role) || !s.CanAssignRole(editorID, editorRole, user.Role) {
+		return nil, errors.New("sem permissão para atribuir o perfil " + role)
+	}
+
+	previous := user.Role
+	if err := config.DB.Model(&user).Update("role", role).Error; err != nil {
+		return nil, errors.New("erro ao alterar perfil")
+	}
+
+	NewAuditService().Record(editorID, models.AuditActionUserRoleChange, "user", &user.ID, map[string]interface{}{
+		"previous_role": previous,
+		"role":          role,
+	}, ip)
+	return &user, nil
+}
+
+// RoleExists indica se existe um perfil com o nome indicado
+func (s *PermissionService) RoleExists(role string) bool {
+	var count int64
+	config.DB.Model(&models.Role{}).Where("name = ?", role).Count(&count)
+	return count > 0
+}
+
+// CanAssignRole indica se o utilizador pode criar contas com o perfil ou atribuí-lo.
+// Os perfis base seguem CanCreateRole; para os restantes é preciso users.manage e
+// ter todas as permissões do perfil, para ninguém dar mais do que tem.
+func (s *PermissionService) CanAssignRole(editorID uint, editorRole, role string) bool {
+	if CanCreateRole(editorRole, role) {
+		return true
+	}
+	editorPermissions := s.EffectivePermissions(editorID, editorRole)
+	if !editorPermissions[models.PermissionUsersManage] || !s.RoleExists(role) {
+		return false
+	}
+	for _, permission := range s.RolePermissions(role) {
+		if !editorPermissions[permission] {
+			return false
+		}
+	}
+	return true
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// ensureStaff só aceita membros da equipa (perfis com acesso à área de gestão)
+func (s *PermissionService) ensureStaff(userID uint) error {
+	var user models.User
+	if err := config.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		return errors.New("utilizador não encontrado")
+	}
+	if !containsString(s.RolePermissions(user.Role), models.PermissionBackofficeAccess) {
+		return errors.New("só é possível conceder permissões a membros da equipa")
+	}
+	return nil
+}
+
+func (s *PermissionService) fillRole(role *models.Role) {
+	role.PermissionNames = make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		role.PermissionNames = append(role.PermissionNames, permission.Permission)
+	}
+	sort.Strings(role.PermissionNames)
+	config.DB.Model(&models.User{}).Where("role = ?", role.Name).Count(&role.UserCount)
+}
+
+// normalizePermissions valida as permissões contra o catálogo e remove repetidas
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if !models.IsValidPermission(permission) {
+			return nil, errors.New("permissão desconhecida: " + permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			result = append(result, permission)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ===== MÁSCARA DE CREDENCIAIS =====
+// Quem não tem credentials.reveal vê o IBAN, o número do cartão de cidadão e o email
+// oficial com a mesma máscara das exportações (só os últimos 4 caracteres).
+
+// MaskUserCredentials aplica a máscara a um utilizador e às relações carregadas
+func MaskUserCredentials(user *models.User) {
+	if user == nil {
+		return
+	}
+	user.CitizenCardNumber = maskSensitiveValue(user.CitizenCardNumber)
+	user.OfficialEmail = maskSensitiveValue(user.OfficialEmail)
+	MaskCompanyCredentials(user.Company)
+	for i := range user.Companies {
+		MaskCompanyCredentials(&user.Companies[i].Company)
+	}
+	MaskRequestCredentials(user.RegistrationRequest)
+}
+
+// MaskCompanyCredentials aplica a máscara a uma empresa
+func MaskCompanyCredentials(company *models.Company) {
+	if company == nil {
+		return
+	}
+	company.IBAN = maskSensitiveValue(company.IBAN)
+	MaskUserCredentials(company.User)
+}
+
+// MaskRequestCredentials aplica a máscara a um pedido de registo
+func MaskRequestCredentials(request *models.RegistrationRequest) {
+	if request == nil {
+		return
+	}
+	maskSensitivePointer(request.IBAN)
+	maskSensitivePointer(request.CitizenCardNumber)
+	maskSensitivePointer(request.OfficialEmail)
+}
+
+// MaskOverviewCredentials aplica a máscara a uma linha da visão completa
+func MaskOverviewCredentials(overview *models.CompleteUserOverviewDTO) {
+	if overview == nil {
+		return
+	}
+	maskSensitivePointer(overview.IBAN)
+	maskSensitivePointer(overview.CitizenCardNumber)
+	maskSensitivePointer(overview.OfficialEmail)
+}
+
+// MaskBankStatementCredentials aplica a máscara ao IBAN de um extrato importado
+func MaskBankStatementCredentials(statement *models.BankStatementImport) {
+	if statement == nil {
+		return
+	}
+	statement.IBAN = maskSensitiveValue(statement.IBAN)
+}
+
+// MaskBankTransactionCredentials aplica a máscara ao IBAN da conta e da contraparte de um movimento
+func MaskBankTransactionCredentials(transaction *models.BankTransaction) {
+	if transaction == nil {
+		return
+	}
+	transaction.IBAN = maskSensitiveValue(transaction.IBAN)
+	transaction.CounterpartyIBAN = maskSensitiveValue(transaction.CounterpartyIBAN)
+}
+
+// MaskReconciliationMatchCredentials aplica a máscara ao movimento carregado numa conciliação
+func MaskReconciliationMatchCredentials(match *models.ReconciliationMatch) {
+	if match == nil {
+		return
+	}
+	MaskBankTransactionCredentials(match.Transaction)
+}
+
+// MaskSupplierRuleCredentials aplica a máscara ao IBAN aprendido de um fornecedor
+func MaskSupplierRuleCredentials(rule *models.SupplierMatchRule) {
+	if rule == nil {
+		return
+	}
+	rule.IBAN = maskSensitiveValue(rule.IBAN)
+}
+
+func maskSensitivePointer(value *string) {
+	if value != nil {
+		*value = maskSensitiveValue(*value)
+	}
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type PortfolioService struct{}
+
+func NewPortfolioService() *PortfolioService {
+	return &PortfolioService{}
+}
+
+// PortfolioScope limita as consultas aos clientes da carteira de um contabilista.
+// Um scope nil ou global (permissão clients.all) não restringe nada.
+type PortfolioScope struct {
+	AccountantID uint
+	Global       bool
+}
+
+// ScopeFor devolve o scope do utilizador; global indica se tem clients.all
+func (s *PortfolioService) ScopeFor(userID uint, global bool) *PortfolioScope {
+	return &PortfolioScope{AccountantID: userID, Global: global}
+}
+
+// IsGlobal indica se o scope vê todos os clientes
+func (sc *PortfolioScope) IsGlobal() bool {
+	return sc == nil || sc.Global
+}
+
+// companyIDs é a subconsulta com as empresas da carteira
+func (sc *PortfolioScope) companyIDs() *gorm.DB {
+	return config.DB.Model(&models.CompanyAccountant{}).Select("company_id").Where("accountant_id = ?", sc.AccountantID)
+}
+
+// clientIDs é a subconsulta com os clientes (utilizadores) ligados às empresas da carteira
+func (sc *PortfolioScope) clientIDs() *gorm.DB {
+	return config.DB.Model(&models.UserCompany{}).Select("user_id").Where("company_id IN (?)", sc.companyIDs())
+}
+
+// Clients restringe uma consulta de clientes à carteira; column é a coluna com o ID do utilizador
+func (sc *PortfolioScope) Clients(query *gorm.DB, column string) *gorm.DB {
+	if sc.IsGlobal() {
+		return query
+	}
+	return query.Where(column+" IN (?)", sc.clientIDs())
+}
+
+// Users restringe uma consulta de utilizadores: a equipa continua visível, os clientes só os da carteira
+func (sc *PortfolioScope) Users(query *gorm.DB, table string) *gorm.DB {
+	if sc.IsGlobal() {
+		return query
+	}
+	return query.Where(fmt.Sprintf("(%s.role <> ? OR %s.id IN (?))", table, table), models.RoleClient, sc.clientIDs())
+}
+
+// Companies restringe uma consulta à carteira; column é a coluna com o ID da empresa
+func (sc *PortfolioScope) Companies(query *gorm.DB, column string) *gorm.DB {
+	if sc.IsGlobal() {
+		return query
+	}
+	return query.Where(column+" IN (?)", sc.companyIDs())
+}
+
+// Requests restringe uma consulta de solicitações às atribuídas ao contabilista ou de empresas da carteira
+func (sc *PortfolioScope) Requests(query *gorm.DB) *gorm.DB {
+	if sc.IsGlobal() {
+		return query
+	}
+	return query.Where("(assigned_to = ? OR company_id IN (?))", sc.AccountantID, sc.companyIDs())
+}
+
+// Invitations restringe uma consulta de convites aos enviados pelo contabilista ou já aceites por empresas da carteira
+func (sc *PortfolioScope) Invitations(query *gorm.DB) *gorm.DB {
+	if sc.IsGlobal() {
+		return query
+	}
+	return query.Where("(invited_by = ? OR company_id IN (?))", sc.AccountantID, sc.companyIDs())
+}
+
+// ClientIDSet carrega os IDs dos clientes da carteira, para filtrar resultados já montados em memória
+func (sc *PortfolioScope) ClientIDSet() map[uint]bool {
+	if sc.IsGlobal() {
+		return nil
+	}
+	var ids []uint
+	sc.clientIDs().Pluck("user_id", &ids)
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// CanAccessCompany indica se a empresa está na carteira do contabilista
+func (s *PortfolioService) CanAccessCompany(accountantID, companyID uint) bool {
+	var count int64
+	config.DB.Model(&models.CompanyAccountant{}).
+		Where("accountant_id = ? AND company_id = ?", accountantID, companyID).
+		Count(&count)
+	return count > 0
+}
+
+// CanAccessClient indica se alguma empresa do cliente está na carteira do contabilista
+func (s *PortfolioService) CanAccessClient(accountantID, clientID uint) bool {
+	var count int64
+	config.DB.Model(&models.CompanyAccountant{}).
+		Joins("JOIN user_companies ON user_companies.company_id = company_accountants.company_id").
+		Where("company_accountants.accountant_id = ? AND user_companies.user_id = ?", accountantID, clientID).
+		Count(&count)
+	return count > 0
+}
+
+// CanAccessUser indica se o utilizador é da equipa ou um cliente da carteira
+func (s *PortfolioService) CanAccessUser(accountantID, userID uint) bool {
+	var user models.User
+	if err := config.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		return false
+	}
+	return user.Role != models.RoleClient || s.CanAccessClient(accountantID, userID)
+}
+
+// GetPortfolios resume as carteiras de todos os membros da equipa
+func (s *PortfolioService) GetPortfolios() ([]models.PortfolioSummaryDTO, error) {
+	var staff []models.User
+	if err := config.DB.Where("role <> ?", models.RoleClient).Order("name ASC").Find(&staff).Error; err != nil {
+		return nil, errors.New("erro ao obter equipa")
+	}
+
+	var counts []struct {
+		AccountantID uint
+		Total        int64
+	}
+	if err := config.DB.Model(&models.CompanyAccountant{}).
+		Select("accountant_id, COUNT(*) AS total").
+		Group("accountant_id").
+		Scan(&counts).Error; err != nil {
+		return nil, errors.New("erro ao obter carteiras")
+	}
+	totals := make(map[uint]int64, len(counts))
+	for _, count := range counts {
+		totals[count.AccountantID] = count.Total
+	}
+
+	result := make([]models.PortfolioSummaryDTO, 0, len(staff))
+	for _, user := range staff {
+		result = append(result, models.PortfolioSummaryDTO{
+			AccountantID: user.ID,
+			Username:     user.Username,
+			Name:         user.Name,
+			Role:         user.Role,
+			Status:       user.Status,
+			Companies:    totals[user.ID],
+		})
+	}
+	return result, nil
+}
+
+// GetPortfolio lista as empresas da carteira de um membro da equipa
+func (s *PortfolioService) GetPortfolio(accountantID uint) ([]models.Company, error) {
+	if _, err := s.getStaff(accountantID); err != nil {
+		return nil, err
+	}
+
+	var companies []models.Company
+	if err := config.DB.Preload("User").
+		Where("id IN (?)", config.DB.Model(&models.CompanyAccountant{}).Select("company_id").Where("accountant_id = ?", accountantID)).
+		Order("company_name ASC").
+		Find(&companies).Error; err != nil {
+		return nil, errors.New("erro ao obter carteira")
+	}
+	return companies, nil
+}
+
+// GetCompanyAccountants lista os contabilistas de uma empresa
+func (s *PortfolioService) GetCompanyAccountants(companyID uint) ([]models.CompanyAccountant, error) {
+	var company models.Company
+	if err := config.DB.Select("id").First(&company, companyID).Error; err != nil {
+		return nil, errors.New("empresa não encontrada")
+	}
+
+	var links []models.CompanyAccountant
+	if err := config.DB.Preload("Accountant").Where("company_id = ?", companyID).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, errors.New("erro ao obter contabilistas da empresa")
+	}
+	return links, nil
+}
+
+// SetCompanyAccountants substitui os contabilistas de uma empresa
+func (s *PortfolioService) SetCompanyAccountants(companyID uint, accountantIDs []uint, assignedBy uint, ip string) ([]models.CompanyAccountant, error) {
+	var company models.Company
+	if err := config.DB.Select("id").First(&company, companyID).Error; err != nil {
+		return nil, errors.New("empresa não encontrada")
+	}
+
+	unique := make([]uint, 0, len(accountantIDs))
+	seen := make(map[uint]bool)
+	for _, id := range accountantIDs {
+		if seen[id] {
+			continue
+		}
+		if _, err := s.getStaff(id); err != nil {
+			return nil, err
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("company_id = ?", companyID).Delete(&models.CompanyAccountant{}).Error; err != nil {
+			return err
+		}
+		for _, id := range unique {
+			if err := tx.Create(&models.CompanyAccountant{CompanyID: companyID, AccountantID: id, AssignedBy: assignedBy}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("erro ao atribuir contabilistas")
+	}
+
+	NewAuditService().Record(assignedBy, models.AuditActionPortfolioAssign, "company", &companyID, map[string]interface{}{
+		"accountant_ids": unique,
+	}, ip)
+
+	return s.GetCompanyAccountants(companyID)
+}
+
+// Reassign passa empresas da carteira de um contabilista para outro. Sem companyIDs passa a carteira inteira.
+func (s *PortfolioService) Reassign(req models.ReassignPortfolioDTO, assignedBy uint, ip string) (*models.ReassignPortfolioResultDTO, error) {
+	if req.FromAccountantID == req.ToAccountantID {
+		return nil, errors.New("o contabilista de origem e o de destino são o mesmo")
+	}
+	var from models.User
+	if err := config.DB.Select("id").First(&from, req.FromAccountantID).Error; err != nil {
+		return nil, errors.New("contabilista de origem não encontrado")
+	}
+	to, err := s.getStaff(req.ToAccountantID)
+	if err != nil {
+		return nil, errors.New("contabilista de destino não encontrado")
+	}
+	if to.Status != string(models.StatusApproved) {
+		return nil, errors.New("o contabilista de destino não está ativo")
+	}
+
+	result := &models.ReassignPortfolioResultDTO{}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("accountant_id = ?", req.FromAccountantID)
+		if len(req.CompanyIDs) > 0 {
+			query = query.Where("company_id IN ?", req.CompanyIDs)
+		}
+		var links []models.CompanyAccountant
+		if err := query.Find(&links).Error; err != nil {
+			return err
+		}
+
+		for _, link := range links {
+			var count int64
+			tx.Model(&models.CompanyAccountant{}).
+				Where("company_id = ? AND accountant_id = ?", link.CompanyID, req.ToAccountantID).
+				Count(&count)
+			if count > 0 {
+				result.AlreadyAssigned++
+			} else {
+				if err := tx.Create(&models.CompanyAccountant{CompanyID: link.CompanyID, AccountantID: req.ToAccountantID, AssignedBy: assignedBy}).Error; err != nil {
+					return err
+				}
+				result.Moved++
+			}
+			if err := tx.Delete(&models.CompanyAccountant{}, link.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("erro ao reatribuir carteira")
+	}
+
+	NewAuditService().Record(assignedBy, models.AuditActionPortfolioMove, "user", &req.FromAccountantID, map[string]interface{}{
+		"to_accountant_id": req.ToAccountantID,
+		"company_ids":      req.CompanyIDs,
+		"moved":            result.Moved,
+		"already_assigned": result.AlreadyAssigned,
+	}, ip)
+
+	return result, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// assignFromRequest põe a empresa criada a partir de uma solicitação na carteira de quem
+// a tinha atribuída ou, na falta dele, de quem a reviu
+func (s *PortfolioService) assignFromRequest(db *gorm.DB, companyID uint, request models.RegistrationRequest) error {
+	accountantID := request.AssignedTo
+	if accountantID == nil {
+		accountantID = request.ReviewedBy
+	}
+	if accountantID == nil {
+		return nil
+	}
+	return db.Create(&models.CompanyAccountant{CompanyID: companyID, AccountantID: *accountantID, AssignedBy: *accountantID}).Error
+}
+
+func (s *PortfolioService) getStaff(userID uint) (*models.User, error) {
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil || user.Role == models.RoleClient {
+		return nil, fmt.Errorf("membro da equipa %d não encontrado", userID)
+	}
+	return &user, nil
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// reconciliationMinConfidence é a confiança mínima para propor uma conciliação
+	reconciliationMinConfidence = 0.5
+	// reconciliationMaxProposals é o número máximo de propostas por movimento
+	reconciliationMaxProposals = 3
+	// reconciliationDateWindow é o número de dias após a fatura em que o pagamento é esperado
+	reconciliationDateWindow = 90
+	// amountTolerance é a diferença abaixo da qual dois valores são iguais
+	amountTolerance = 0.005
+)
+
+// Palavras frequentes nas descrições bancárias que não identificam o fornecedor
+var bankDescriptionStopWords = map[string]bool{
+	"TRF": true, "TRANSF": true, "TRANSFERENCIA": true, "PAGAMENTO": true, "PAG": true, "COMPRA": true,
+	"SEPA": true, "DD": true, "DEBITO": true, "DIRETO": true, "MB": true, "WAY": true, "SERVICOS": true,
+	"REF": true, "ENT": true, "EUR": true, "CARTAO": true, "LEV": true, "MULTIBANCO": true,
+}
+
+type ReconciliationService struct{}
+
+func NewReconciliationService() *ReconciliationService {
+	return &ReconciliationService{}
+}
+
+// RunMatching propõe conciliações entre os pagamentos por conciliar e as faturas de compra em aberto,
+// substituindo as propostas anteriores ainda não decididas
+func (s *ReconciliationService) RunMatching(clientID, companyID uint) (*models.ReconciliationRunDTO, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []models.BankTransaction
+	if err := config.DB.Where("company_id = ? AND match_status = ? AND amount < 0", company.ID, models.BankTransactionUnmatched).
+		Find(&transactions).Error; err != nil {
+		return nil, errors.New("erro ao obter movimentos")
+	}
+
+	var invoices []models.PurchaseInvoice
+	if err := config.DB.Where("company_id = ? AND paid_amount < total_amount - ?", company.ID, amountTolerance).
+		Find(&invoices).Error; err != nil {
+		return nil, errors.New("erro ao obter faturas de compra")
+	}
+
+	rules := make(map[string]models.SupplierMatchRule)
+	var ruleList []models.SupplierMatchRule
+	config.DB.Where("company_id = ?", company.ID).Find(&ruleList)
+	for _, rule := range ruleList {
+		rules[rule.SupplierNIF] = rule
+	}
+
+	// Pares já decididos não voltam a ser propostos
+	decided := make(map[[2]uint]bool)
+	var decidedMatches []models.ReconciliationMatch
+	config.DB.Where("company_id = ? AND status <> ?", company.ID, models.ReconciliationProposed).Find(&decidedMatches)
+	for _, match := range decidedMatches {
+		decided[[2]uint{match.TransactionID, match.PurchaseInvoiceID}] = true
+	}
+
+	if err := config.DB.Where("company_id = ? AND status = ?", company.ID, models.ReconciliationProposed).
+		Delete(&models.ReconciliationMatch{}).Error; err != nil {
+		return nil, errors.New("erro ao limpar propostas anteriores")
+	}
+
+	result := models.ReconciliationRunDTO{Transactions: len(transactions)}
+	for _, transaction := range transactions {
+		var proposals []models.ReconciliationMatch
+		for _, invoice := range invoices {
+			if decided[[2]uint{transaction.ID, invoice.ID}] {
+				continue
+			}
+
+			var rule *models.SupplierMatchRule
+			if r, ok := rules[invoice.SupplierNIF]; ok {
+				rule = &r
+			}
+			confidence, reasons := scoreMatch(&transaction, &invoice, rule)
+			if confidence < reconciliationMinConfidence {
+				continue
+			}
+
+			proposals = append(proposals, models.ReconciliationMatch{
+				CompanyID:         company.ID,
+				TransactionID:     transaction.ID,
+				PurchaseInvoiceID: invoice.ID,
+				Amount:            roundAmount(math.Min(transactionOpenAmount(&transaction), invoiceOpenAmount(&invoice))),
+				Confidence:        math.Round(confidence*100) / 100,
+				Reasons:           strings.Join(reasons, ","),
+				Status:            models.ReconciliationProposed,
+			})
+		}
+
+		sort.Slice(proposals, func(i, j int) bool {
+			return proposals[i].Confidence > proposals[j].Confidence
+		})
+		if len(proposals) > reconciliationMaxProposals {
+			proposals = proposals[:reconciliationMaxProposals]
+		}
+		if len(proposals) == 0 {
+			continue
+		}
+		if err := config.DB.Create(&proposals).Error; err != nil {
+			return nil, errors.New("erro ao guardar propostas")
+		}
+		result.Proposals += len(proposals)
+	}
+
+	return &result, nil
+}
+
+// GetMatches lista as conciliações da empresa do cliente (por omissão as propostas por decidir)
+func (s *ReconciliationService) GetMatches(clientID, companyID uint, status string) ([]models.ReconciliationMatch, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = models.ReconciliationProposed
+	}
+
+	matches := []models.ReconciliationMatch{}
+	if err := config.DB.Preload("Transaction").Preload("PurchaseInvoice").
+		Where("company_id = ? AND status = ?", company.ID, status).
+		Order("confidence DESC, id ASC").
+		Find(&matches).Error; err != nil {
+		return nil, errors.New("erro ao obter conciliações")
+	}
+	return matches, nil
+}
+
+// AcceptMatch aceita uma proposta, conciliando o valor em aberto do movimento e da fatura.
+// Não aceita propostas cujo movimento ou fatura tenham data num período fechado.
+func (s *ReconciliationService) AcceptMatch(clientID, companyID, matchID, userID uint, role string) (*models.ReconciliationMatch, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var match models.ReconciliationMatch
+	if err := config.DB.Where("id = ? AND company_id = ?", matchID, company.ID).First(&match).Error; err != nil {
+		return nil, errors.New("proposta não encontrada")
+	}
+	if match.Status != models.ReconciliationProposed {
+		return nil, errors.New("a proposta já foi decidida")
+	}
+
+	tx := config.DB.Begin()
+	transaction, invoice, err := loadMatchPair(tx, company.ID, match.TransactionID, match.PurchaseInvoiceID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	guard := newPeriodGuard(company.ID, role)
+	if err := ensurePairWritable(guard, transaction, invoice); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	amount := math.Min(transactionOpenAmount(transaction), invoiceOpenAmount(invoice))
+	if amount < amountTolerance {
+		tx.Rollback()
+		return nil, errors.New("o movimento ou a fatura já estão totalmente conciliados")
+	}
+
+	now := time.Now()
+	match.Amount = roundAmount(amount)
+	match.Status = models.ReconciliationAccepted
+	match.DecidedBy = &userID
+	match.DecidedAt = &now
+	if err := tx.Save(&match).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("erro ao aceitar proposta")
+	}
+	if err := applyAllocation(tx, transaction, invoice, match.Amount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	tx.Commit()
+
+	s.learnRule(company.ID, invoice, transaction)
+	return &match, nil
+}
+
+// RejectMatch rejeita uma proposta; o mesmo par não volta a ser proposto
+func (s *ReconciliationService) RejectMatch(clientID, companyID, matchID, userID uint) (*models.ReconciliationMatch, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var match models.ReconciliationMatch
+	if err := config.DB.Where("id = ? AND company_id = ?", matchID, company.ID).First(&match).Error; err != nil {
+		return nil, errors.New("proposta não encontrada")
+	}
+	if match.Status != models.ReconciliationProposed {
+		return nil, errors.New("a proposta já foi decidida")
+	}
+
+	now := time.Now()
+	match.Status = models.ReconciliationRejected
+	match.DecidedBy = &userID
+	match.DecidedAt = &now
+	if err := config.DB.Save(&match).Error; err != nil {
+		return nil, errors.New("erro ao rejeitar proposta")
+	}
+	return &match, nil
+}
+
+// SplitTransaction divide um movimento por várias faturas (ex.: um pagamento de várias faturas do mesmo fornecedor).
+// Tal como AcceptMatch, recusa movimentos e faturas com data num período fechado.
+func (s *ReconciliationService) SplitTransaction(clientID, companyID uint, req models.SplitTransactionDTO, userID uint, role string) ([]models.ReconciliationMatch, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0.0
+	seen := make(map[uint]bool)
+	for _, allocation := range req.Allocations {
+		if seen[allocation.PurchaseInvoiceID] {
+			return nil, errors.New("a mesma fatura aparece mais do que uma vez")
+		}
+		seen[allocation.PurchaseInvoiceID] = true
+		total += allocation.Amount
+	}
+
+	tx := config.DB.Begin()
+	var transaction models.BankTransaction
+	if err := tx.Where("id = ? AND company_id = ?", req.TransactionID, company.ID).First(&transaction).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("movimento não encontrado")
+	}
+	guard := newPeriodGuard(company.ID, role)
+	if err := guard.check(transaction.BookingDate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if total > transactionOpenAmount(&transaction)+amountTolerance {
+		tx.Rollback()
+		return nil, errors.New("a soma das partes excede o valor por conciliar do movimento")
+	}
+
+	now := time.Now()
+	matches := []models.ReconciliationMatch{}
+	invoices := []*models.PurchaseInvoice{}
+	for _, allocation := range req.Allocations {
+		var invoice models.PurchaseInvoice
+		if err := tx.Where("id = ? AND company_id = ?", allocation.PurchaseInvoiceID, company.ID).First(&invoice).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("fatura não encontrada")
+		}
+		if err := guard.check(invoice.IssueDate); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if allocation.Amount > invoiceOpenAmount(&invoice)+amountTolerance {
+			tx.Rollback()
+			return nil, errors.New("o valor atribuído excede o valor em aberto da fatura " + invoice.DocumentNo)
+		}
+
+		var match models.ReconciliationMatch
+		if tx.Where("transaction_id = ? AND purchase_invoice_id = ?", transaction.ID, invoice.ID).First(&match).Error == nil &&
+			match.Status == models.ReconciliationAccepted {
+			tx.Rollback()
+			return nil, errors.New("a fatura " + invoice.DocumentNo + " já está conciliada com este movimento")
+		}
+		match.CompanyID = company.ID
+		match.TransactionID = transaction.ID
+		match.PurchaseInvoiceID = invoice.ID
+		match.Amount = roundAmount(allocation.Amount)
+		match.Confidence = 1
+		match.Reasons = "manual"
+		match.Status = models.ReconciliationAccepted
+		match.Manual = true
+		match.DecidedBy = &userID
+		match.DecidedAt = &now
+		if err := tx.Save(&match).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("erro ao guardar divisão")
+		}
+		if err := applyAllocation(tx, &transaction, &invoice, match.Amount); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		matches = append(matches, match)
+		invoices = append(invoices, &invoice)
+	}
+	tx.Commit()
+
+	for _, invoice := range invoices {
+		s.learnRule(company.ID, invoice, &transaction)
+	}
+	return matches, nil
+}
+
+// GetRules lista as regras aprendidas por fornecedor
+func (s *ReconciliationService) GetRules(clientID, companyID uint) ([]models.SupplierMatchRule, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []models.SupplierMatchRule{}
+	if err := config.DB.Where("company_id = ?", company.ID).Order("accepted_count DESC, supplier_name ASC").Find(&rules).Error; err != nil {
+		return nil, errors.New("erro ao obter regras")
+	}
+	return rules, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// learnRule guarda o IBAN e a palavra-chave com que o fornecedor aparece nos movimentos aceites
+func (s *ReconciliationService) learnRule(companyID uint, invoice *models.PurchaseInvoice, transaction *models.BankTransaction) {
+	var rule models.SupplierMatchRule
+	config.DB.Where("company_id = ? AND supplier_nif = ?", companyID, invoice.SupplierNIF).First(&rule)
+
+	rule.CompanyID = companyID
+	rule.SupplierNIF = invoice.SupplierNIF
+	rule.SupplierName = invoice.SupplierName
+	if transaction.CounterpartyIBAN != "" {
+		rule.IBAN = transaction.CounterpartyIBAN
+	}
+	if keyword := descriptionKeyword(transaction); keyword != "" {
+		rule.Keyword = keyword
+	}
+	rule.AcceptedCount++
+	rule.LastAcceptedAt = time.Now()
+	config.DB.Save(&rule)
+}
+
+func loadMatchPair(tx *gorm.DB, companyID, transactionID, invoiceID uint) (*models.BankTransaction, *models.PurchaseInvoice, error) {
+	var transaction models.BankTransaction
+	if err := tx.Where("id = ? AND company_id = ?", transactionID, companyID).First(&transaction).Error; err != nil {
+		return nil, nil, errors.New("movimento não encontrado")
+	}
+	var invoice models.PurchaseInvoice
+	if err := tx.Where("id = ? AND company_id = ?", invoiceID, companyID).First(&invoice).Error; err != nil {
+		return nil, nil, errors.New("fatura não encontrada")
+	}
+	return &transaction, &invoice, nil
+}
+
+// applyAllocation soma o valor conciliado ao movimento e à fatura e retira as propostas que deixaram de fazer sentido
+// ensurePairWritable recusa a conciliação quando o movimento ou a fatura estão num período fechado
+func ensurePairWritable(guard *periodGuard, transaction *models.BankTransaction, invoice *models.PurchaseInvoice) error {
+	if err := guard.check(transaction.BookingDate); err != nil {
+		return err
+	}
+	return guard.check(invoice.IssueDate)
+}
+
+func applyAllocation(tx *gorm.DB, transaction *models.BankTransaction, invoice *models.PurchaseInvoice, amount float64) error {
+	transaction.MatchedAmount = roundAmount(transaction.MatchedAmount + amount)
+	if transactionOpenAmount(transaction) < amountTolerance {
+		transaction.MatchStatus = models.BankTransactionMatched
+	}
+	if err := tx.Model(transaction).Updates(map[string]interface{}{
+		"matched_amount": transaction.MatchedAmount,
+		"match_status":   transaction.MatchStatus,
+	}).Error; err != nil {
+		return errors.New("erro ao atualizar movimento")
+	}
+
+	invoice.PaidAmount = roundAmount(invoice.PaidAmount + amount)
+	if err := tx.Model(invoice).Update("paid_amount", invoice.PaidAmount).Error; err != nil {
+		return errors.New("erro ao atualizar fatura")
+	}
+
+	if transaction.MatchStatus == models.BankTransactionMatched {
+		tx.Where("transaction_id = ? AND status = ?", transaction.ID, models.ReconciliationProposed).Delete(&models.ReconciliationMatch{})
+	}
+	if invoiceOpenAmount(invoice) < amountTolerance {
+		tx.Where("purchase_invoice_id = ? AND status = ?", invoice.ID, models.ReconciliationProposed).Delete(&models.ReconciliationMatch{})
+	}
+	return nil
+}
+
+// scoreMatch calcula a confiança (0 a 1) de um pagamento corresponder a uma fatura.
+// Sem valor igual nem referência à fatura na descrição não há proposta.
+func scoreMatch(transaction *models.BankTransaction, invoice *models.PurchaseInvoice, rule *models.SupplierMatchRule) (float64, []string) {
+	score := 0.0
+	reasons := []string{}
+	text := matchKey(transaction.Description + " " + transaction.Counterparty)
+
+	amountMatches := false
+	open := invoiceOpenAmount(invoice)
+	diff := math.Abs(transactionOpenAmount(transaction) - open)
+	switch {
+	case diff < amountTolerance:
+		score += 0.4
+		reasons = append(reasons, "valor")
+		amountMatches = true
+	case diff <= open*0.01:
+		score += 0.2
+		reasons = append(reasons, "valor_aproximado")
+		amountMatches = true
+	}
+
+	referenceMatches := false
+	for _, value := range []string{invoice.DocumentNo, invoice.ATCUD} {
+		if key := matchKey(value); len(key) >= 4 && strings.Contains(text, key) {
+			referenceMatches = true
+		}
+	}
+	if referenceMatches {
+		score += 0.3
+		reasons = append(reasons, "referencia")
+	}
+	if !amountMatches && !referenceMatches {
+		return 0, nil
+	}
+
+	days := transaction.BookingDate.Sub(invoice.IssueDate).Hours() / 24
+	if days >= -5 && days <= reconciliationDateWindow {
+		score += 0.15 * (1 - math.Max(days, 0)/reconciliationDateWindow)
+		reasons = append(reasons, "data")
+	} else if !referenceMatches {
+		return 0, nil
+	}
+
+	if invoice.SupplierNIF != "" && strings.Contains(text, invoice.SupplierNIF) {
+		score += 0.2
+		reasons = append(reasons, "nif")
+	}
+	if name := matchKey(utils.NormalizeCompanyName(invoice.SupplierName)); len(name) >= 4 && strings.Contains(text, name) {
+		score += 0.1
+		reasons = append(reasons, "nome")
+	}
+	if rule != nil {
+		if rule.IBAN != "" && rule.IBAN == transaction.CounterpartyIBAN {
+			score += 0.2
+			reasons = append(reasons, "iban")
+		}
+		if rule.Keyword != "" && strings.Contains(text, rule.Keyword) {
+			score += 0.15
+			reasons = append(reasons, "regra_fornecedor")
+		}
+	}
+
+	return math.Min(score, 1), reasons
+}
+
+// descriptionKeyword escolhe o texto que identifica o fornecedor num movimento:
+// a contraparte, se existir, ou a primeira palavra relevante da descrição
+func descriptionKeyword(transaction *models.BankTransaction) string {
+	if key := matchKey(transaction.Counterparty); len(key) >= 4 {
+		return key
+	}
+	for _, word := range strings.FieldsFunc(utils.RemoveAccents(transaction.Description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		word = strings.ToUpper(word)
+		if len(word) >= 4 && !bankDescriptionStopWords[word] && strings.IndexFunc(word, unicode.IsLetter) >= 0 {
+			return word
+		}
+	}
+	return ""
+}
+
+func transactionOpenAmount(transaction *models.BankTransaction) float64 {
+	return math.Abs(transaction.Amount) - transaction.MatchedAmount
+}
+
+func invoiceOpenAmount(invoice *models.PurchaseInvoice) float64 {
+	return invoice.TotalAmount - invoice.PaidAmount
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var deliverableLabels = map[string]string{
+	models.DeliverablePurchaseInvoices: "Faturas de compra",
+	models.DeliverableSalesInvoices:    "Faturas de venda",
+	models.DeliverableBankStatements:   "Extratos bancários",
+	models.DeliverablePayroll:          "Processamento salarial",
+	models.DeliverableStockReport:      "Inventário",
+}
+
+var checklistStatusLabels = map[string]string{
+	models.ChecklistStatusMissing:   "Em falta",
+	models.ChecklistStatusSubmitted: "Entregue",
+	models.ChecklistStatusWaived:    "Dispensado",
+}
+
+// ReportService monta os relatórios em PDF a partir dos dados já expostos pela API
+type ReportService struct{}
+
+func NewReportService() *ReportService {
+	return &ReportService{}
+}
+
+// ClientDossier monta o dossier do cliente: dados pessoais, morada fiscal, preferências, empresa e registo.
+// Sem reveal, o IBAN, o cartão de cidadão e o email oficial saem com máscara.
+func (s *ReportService) ClientDossier(clientID uint, reveal bool) (*utils.PDFReport, string, error) {
+	overview, err := NewAdminService().GetCompleteUserOverview(clientID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !reveal {
+		MaskOverviewCredentials(overview)
+	}
+
+	report := &utils.PDFReport{
+		Title:    "Dossier do cliente",
+		Subtitle: fmt.Sprintf("%s (%s)", textValue(overview.Name), overview.Username),
+		Sections: []utils.PDFReportSection{
+			{
+				Heading: "Dados pessoais",
+				Fields: []utils.PDFReportField{
+					{Label: "Nome", Value: textValue(overview.Name)},
+					{Label: "NIF", Value: textValue(overview.NIF)},
+					{Label: "Email", Value: textValue(overview.Email)},
+					{Label: "Telemóvel", Value: textValue(overview.Phone)},
+					{Label: "Telefone fixo", Value: textValue(overview.FixedPhone)},
+					{Label: "Data de nascimento", Value: dateValue(overview.DateOfBirth)},
+					{Label: "Estado civil", Value: textValue(overview.MaritalStatus)},
+					{Label: "Cartão de cidadão", Value: textValue(overview.CitizenCardNumber)},
+					{Label: "Validade do CC", Value: dateValue(overview.CitizenCardExpiry)},
+					{Label: "Residência fiscal", Value: textValue(overview.TaxResidenceCountry)},
+				},
+			},
+			{
+				Heading: "Morada fiscal",
+				Fields: []utils.PDFReportField{
+					{Label: "Morada", Value: textValue(overview.FiscalAddress)},
+					{Label: "Código postal", Value: joinValues(textValue(overview.FiscalPostalCode), textValue(overview.FiscalCity))},
+					{Label: "Concelho", Value: textValue(overview.FiscalCounty)},
+					{Label: "Distrito", Value: textValue(overview.FiscalDistrict)},
+				},
+			},
+			{
+				Heading: "Preferências",
+				Fields: []utils.PDFReportField{
+					{Label: "Email oficial", Value: textValue(overview.OfficialEmail)},
+					{Label: "Software de faturação", Value: textValue(overview.BillingSoftware)},
+					{Label: "Formato preferido", Value: textValue(overview.PreferredFormat)},
+					{Label: "Periodicidade dos relatórios", Value: textValue(overview.ReportFrequency)},
+					{Label: "Horário de contacto", Value: textValue(overview.PreferredContactHours)},
+				},
+			},
+		},
+	}
+
+	if overview.CompanyID != nil {
+		report.Sections = append(report.Sections, utils.PDFReportSection{
+			Heading: "Empresa",
+			Fields: []utils.PDFReportField{
+				{Label: "Denominação", Value: textValue(overview.CompanyName)},
+				{Label: "Nome comercial", Value: textValue(overview.TradeName)},
+				{Label: "NIPC", Value: textValue(overview.NIPC)},
+				{Label: "Forma jurídica", Value: textValue(overview.LegalForm)},
+				{Label: "CAE", Value: textValue(overview.CAE)},
+				{Label: "Regime de contabilidade", Value: textValue(overview.AccountingRegime)},
+				{Label: "Regime de IVA", Value: textValue(overview.VATRegime)},
+				{Label: "Sede", Value: joinValues(textValue(overview.CompanyAddress), textValue(overview.CompanyPostalCode), textValue(overview.CompanyCity))},
+				{Label: "IBAN", Value: textValue(overview.IBAN)},
+			},
+		})
+	}
+
+	if overview.RequestID != nil {
+		report.Sections = append(report.Sections, utils.PDFReportSection{
+			Heading: "Registo",
+			Fields: []utils.PDFReportField{
+				{Label: "Tipo de pedido", Value: textValue(overview.RequestType)},
+				{Label: "Submetido em", Value: dateTimeValue(overview.SubmittedAt)},
+				{Label: "Revisto em", Value: dateTimeValue(overview.ReviewedAt)},
+				{Label: "Revisto por", Value: textValue(overview.ReviewedByName)},
+				{Label: "Notas", Value: textValue(overview.ReviewNotes)},
+			},
+		})
+	}
+
+	return report, reportFilename("dossier", overview.Username, ""), nil
+}
+
+// CompanySheet monta a ficha da empresa do cliente (IBAN com máscara sem reveal)
+func (s *ReportService) CompanySheet(clientID, companyID uint, reveal bool) (*utils.PDFReport, string, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !reveal {
+		MaskCompanyCredentials(company)
+	}
+
+	foundingDate, groupStartDate := "", ""
+	if company.FoundingDate != nil {
+		foundingDate = utils.FormatPTDate(*company.FoundingDate)
+	}
+	if company.GroupStartDate != nil {
+		groupStartDate = utils.FormatPTDate(*company.GroupStartDate)
+	}
+
+	report := &utils.PDFReport{
+		Title:    "Ficha de empresa",
+		Subtitle: fmt.Sprintf("%s - NIPC %s", company.CompanyName, company.NIPC),
+		Sections: []utils.PDFReportSection{
+			{
+				Heading: "Identificação",
+				Fields: []utils.PDFReportField{
+					{Label: "Denominação", Value: company.CompanyName},
+					{Label: "Nome comercial", Value: company.TradeName},
+					{Label: "NIPC", Value: company.NIPC},
+					{Label: "Forma jurídica", Value: company.LegalForm},
+					{Label: "CAE", Value: company.CAE},
+					{Label: "Data de constituição", Value: foundingDate},
+					{Label: "Início de atividade no grupo", Value: groupStartDate},
+					{Label: "Capital social", Value: utils.FormatPTNumber(company.ShareCapital) + " EUR"},
+					{Label: "Objeto social", Value: company.CorporateObject},
+					{Label: "Estado", Value: company.Status},
+				},
+			},
+			{
+				Heading: "Sede",
+				Fields: []utils.PDFReportField{
+					{Label: "Morada", Value: company.Address},
+					{Label: "Código postal", Value: joinValues(company.PostalCode, company.City)},
+					{Label: "Concelho", Value: company.County},
+					{Label: "Distrito", Value: company.District},
+					{Label: "País", Value: company.Country},
+				},
+			},
+			{
+				Heading: "Enquadramento fiscal",
+				Fields: []utils.PDFReportField{
+					{Label: "Regime de contabilidade", Value: company.AccountingRegime},
+					{Label: "Regime de IVA", Value: company.VATRegime},
+					{Label: "Periodicidade do IVA", Value: companyVATFrequency(company)},
+					{Label: "Atividade", Value: company.BusinessActivity},
+				},
+			},
+			{
+				Heading: "Informação bancária",
+				Fields: []utils.PDFReportField{
+					{Label: "Banco", Value: company.BankName},
+					{Label: "IBAN", Value: company.IBAN},
+					{Label: "BIC/SWIFT", Value: company.BIC},
+				},
+			},
+			{
+				Heading: "Dados operacionais",
+				Fields: []utils.PDFReportField{
+					{Label: "Volume de negócios", Value: utils.FormatPTNumber(company.AnnualRevenue) + " EUR"},
+					{Label: "Volume de negócios estimado", Value: utils.FormatPTNumber(company.EstimatedRevenue) + " EUR"},
+					{Label: "Faturas por mês", Value: strconv.Itoa(company.MonthlyInvoices)},
+					{Label: "Trabalhadores", Value: strconv.Itoa(company.NumberEmployees)},
+					{Label: "Tem existências", Value: utils.FormatPTBool(company.HasStock)},
+					{Label: "Principais clientes", Value: company.MainClients},
+					{Label: "Principais fornecedores", Value: company.MainSuppliers},
+				},
+			},
+		},
+	}
+
+	return report, reportFilename("ficha-empresa", company.NIPC, ""), nil
+}
+
+// MonthlyChecklist monta a checklist dos períodos que terminam no mês indicado (AAAA-MM)
+func (s *ReportService) MonthlyChecklist(clientID, companyID uint, month string) (*utils.PDFReport, string, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return nil, "", errors.New("mês inválido (use AAAA-MM)")
+	}
+	end := start.AddDate(0, 1, -1)
+
+	var items []models.ChecklistItem
+	if err := config.DB.Where("company_id = ? AND period_end BETWEEN ? AND ?", company.ID, start, end).
+		Order("period_start ASC, deliverable ASC").Find(&items).Error; err != nil {
+		return nil, "", errors.New("erro ao obter checklist")
+	}
+
+	now := time.Now()
+	table := &utils.PDFReportTable{
+		Columns: []utils.PDFReportColumn{
+			{Header: "Período", Width: 25},
+			{Header: "Entregável", Width: 50},
+			{Header: "Prazo", Width: 25, Align: "C"},
+			{Header: "Estado", Width: 25},
+			{Header: "Entregue em", Width: 25, Align: "C"},
+			{Header: "Notas", Width: 30},
+		},
+	}
+	missing, late := 0, 0
+	for i := range items {
+		item := &items[i]
+		status := checklistStatusLabels[item.Status]
+		if isChecklistItemLate(item, now) {
+			status += " (atrasado)"
+			late++
+		}
+		if item.Status == models.ChecklistStatusMissing {
+			missing++
+		}
+		table.Rows = append(table.Rows, []string{
+			item.Period,
+			labelOrValue(deliverableLabels, item.Deliverable),
+			utils.FormatPTDate(item.DueDate),
+			status,
+			dateValue(item.SubmittedAt),
+			item.Notes,
+		})
+	}
+
+	report := &utils.PDFReport{
+		Title:    "Checklist de documentos",
+		Subtitle: fmt.Sprintf("%s - NIPC %s - %s", company.CompanyName, company.NIPC, utils.FormatPTPeriod(month)),
+		Sections: []utils.PDFReportSection{
+			{
+				Table: table,
+				Text:  fmt.Sprintf("%d entregáveis, %d em falta, %d atrasados.", len(items), missing, late),
+			},
+		},
+	}
+
+	return report, reportFilename("checklist", company.NIPC, month), nil
+}
+
+// VATSummary monta o resumo da declaração periódica de IVA calculada para o período
+func (s *ReportService) VATSummary(clientID, companyID uint, period string, carriedCredit float64) (*utils.PDFReport, string, error) {
+	result, err := NewVATReturnService().Compute(clientID, companyID, period, carriedCredit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	boxes := &utils.PDFReportTable{
+		Columns: []utils.PDFReportColumn{
+			{Header: "Campo", Width: 15, Align: "C"},
+			{Header: "Descrição", Width: 135},
+			{Header: "Valor (EUR)", Width: 30, Align: "R"},
+		},
+	}
+	for _, box := range result.Boxes {
+		if box.Value == 0 {
+			continue
+		}
+		boxes.Rows = append(boxes.Rows, []string{strconv.Itoa(box.Number), box.Label, utils.FormatPTNumber(box.Value)})
+	}
+
+	rates := &utils.PDFReportTable{
+		Columns: []utils.PDFReportColumn{
+			{Header: "Região", Width: 30},
+			{Header: "Taxa", Width: 30, Align: "R"},
+			{Header: "Base (EUR)", Width: 60, Align: "R"},
+			{Header: "IVA (EUR)", Width: 60, Align: "R"},
+		},
+	}
+	var netTotal, taxTotal float64
+	for _, rate := range result.SalesByRate {
+		rates.Rows = append(rates.Rows, []string{
+			rate.Region,
+			fmt.Sprintf("%g%%", rate.Rate),
+			utils.FormatPTNumber(rate.NetAmount),
+			utils.FormatPTNumber(rate.TaxAmount),
+		})
+		netTotal += rate.NetAmount
+		taxTotal += rate.TaxAmount
+	}
+	if len(rates.Rows) > 0 {
+		rates.Totals = []string{"Total", "", utils.FormatPTNumber(netTotal), utils.FormatPTNumber(taxTotal)}
+	}
+
+	sections := []utils.PDFReportSection{
+		{
+			Heading: "Apuramento",
+			Fields: []utils.PDFReportField{
+				{Label: "Período", Value: fmt.Sprintf("%s (%s a %s)", utils.FormatPTPeriod(result.Period), formatISODate(result.StartDate), formatISODate(result.EndDate))},
+				{Label: "Periodicidade", Value: result.Frequency},
+				{Label: "Imposto a favor do Estado", Value: utils.FormatPTNumber(result.TotalTaxDue) + " EUR"},
+				{Label: "Imposto dedutível", Value: utils.FormatPTNumber(result.TotalDeductible) + " EUR"},
+				{Label: "Excesso do período anterior", Value: utils.FormatPTNumber(result.CarriedCredit) + " EUR"},
+				{Label: "Imposto a entregar", Value: utils.FormatPTNumber(result.TaxPayable) + " EUR"},
+				{Label: "Imposto a recuperar", Value: utils.FormatPTNumber(result.TaxRecoverable) + " EUR"},
+				{Label: "IVA não dedutível", Value: utils.FormatPTNumber(result.NonDeductibleVAT) + " EUR"},
+				{Label: "Faturas de compra", Value: strconv.Itoa(result.PurchaseInvoices)},
+			},
+		},
+		{Heading: "Campos da declaração", Table: boxes},
+		{Heading: "Vendas por taxa", Table: rates},
+	}
+	if len(result.Warnings) > 0 {
+		sections = append(sections, utils.PDFReportSection{
+			Heading: "Avisos",
+			Text:    "- " + strings.Join(result.Warnings, "\n- "),
+		})
+	}
+
+	report := &utils.PDFReport{
+		Title:    "Resumo do IVA",
+		Subtitle: fmt.Sprintf("%s - NIF %s", result.CompanyName, result.NIF),
+		Sections: sections,
+	}
+
+	return report, reportFilename("resumo-iva", result.NIF, result.Period), nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func textValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func dateValue(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return utils.FormatPTDate(*value)
+}
+
+func dateTimeValue(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return utils.FormatPTDateTime(*value)
+}
+
+func formatISODate(value string) string {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return value
+	}
+	return utils.FormatPTDate(date)
+}
+
+func joinValues(values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func labelOrValue(labels map[string]string, value string) string {
+	if label, ok := labels[value]; ok {
+		return label
+	}
+	return value
+}
+
+func reportFilename(kind, identifier, period string) string {
+	parts := []string{kind}
+	for _, part := range []string{identifier, period} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return utils.RemoveAccents(strings.Join(parts, "_")) + ".pdf"
+}
@@ -0,0 +1,448 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultReviewSLAHours é o prazo por omissão para rever uma solicitação
+const defaultReviewSLAHours = 48
+
+// reviewableRequestCondition limita a fila às solicitações sem email ou com o email já verificado
+const reviewableRequestCondition = "email IS NULL OR email_verified_at IS NOT NULL"
+
+// errEmailNotVerified é devolvido ao reclamar, atribuir ou aprovar uma solicitação com o email por verificar
+var errEmailNotVerified = errors.New("o email da solicitação ainda não foi verificado")
+
+type ReviewQueueService struct{}
+
+func NewReviewQueueService() *ReviewQueueService {
+	return &ReviewQueueService{}
+}
+
+// GetMyQueue obtém as solicitações pendentes atribuídas ao contabilista e as que estão por atribuir.
+// As solicitações com o email por verificar só entram na fila depois da verificação.
+func (s *ReviewQueueService) GetMyQueue(userID uint) (*models.MyQueueDTO, error) {
+	var requests []models.RegistrationRequest
+	if err := config.DB.Preload("AssignedToUser").
+		Where("status = ? AND (assigned_to = ? OR assigned_to IS NULL)", "pending", userID).
+		Where(reviewableRequestCondition).
+		Order("submitted_at ASC").
+		Find(&requests).Error; err != nil {
+		return nil, errors.New("erro ao obter fila de revisão")
+	}
+
+	queue := models.MyQueueDTO{
+		Assigned:   []models.QueueItemDTO{},
+		Unassigned: []models.QueueItemDTO{},
+	}
+	now := time.Now()
+	for _, req := range requests {
+		item := s.buildQueueItem(req, now)
+		switch item.SLAStatus {
+		case models.SLAStatusWarning:
+			queue.Stats.Warning++
+		case models.SLAStatusBreached:
+			queue.Stats.Breached++
+		}
+
+		if req.AssignedTo != nil {
+			queue.Assigned = append(queue.Assigned, item)
+		} else {
+			queue.Unassigned = append(queue.Unassigned, item)
+		}
+	}
+	queue.Stats.TotalAssigned = len(queue.Assigned)
+	queue.Stats.TotalUnassigned = len(queue.Unassigned)
+
+	return &queue, nil
+}
+
+// ClaimRequest atribui uma solicitação pendente e livre ao contabilista que a reclama
+func (s *ReviewQueueService) ClaimRequest(requestID, userID uint) (*models.RegistrationRequest, error) {
+	now := time.Now()
+
+	// Atualização condicional para que dois contabilistas não reclamem a mesma solicitação
+	result := config.DB.Model(&models.RegistrationRequest{}).
+		Where("id = ? AND status = ? AND assigned_to IS NULL", requestID, "pending").
+		Where(reviewableRequestCondition).
+		Updates(map[string]interface{}{"assigned_to": userID, "assigned_at": now})
+	if result.Error != nil {
+		return nil, errors.New("erro ao reclamar solicitação")
+	}
+
+	var request models.RegistrationRequest
+	if err := config.DB.First(&request, requestID).Error; err != nil {
+		return nil, errors.New("solicitação não encontrada")
+	}
+
+	if result.RowsAffected == 0 {
+		if request.Status != "pending" {
+			return nil, errors.New("solicitação já foi processada")
+		}
+		if !emailVerifiedOrAbsent(&request) {
+			return nil, errEmailNotVerified
+		}
+		if request.AssignedTo != nil && *request.AssignedTo == userID {
+			return &request, nil
+		}
+		return nil, errors.New("solicitação já atribuída a outro contabilista")
+	}
+
+	s.recordHistory(requestID, models.AssignmentActionClaim, nil, &userID, &userID, "", "")
+
+	return &request, nil
+}
+
+// AssignRequest atribui (ou reatribui) uma solicitação pendente a um contabilista
+func (s *ReviewQueueService) AssignRequest(requestID uint, req models.AssignRequestDTO, performedBy uint) (*models.RegistrationRequest, error) {
+	var request models.RegistrationRequest
+	if err := config.DB.First(&request, requestID).Error; err != nil {
+		return nil, errors.New("solicitação não encontrada")
+	}
+	if request.Status != "pending" {
+		return nil, errors.New("solicitação já foi processada")
+	}
+	if !emailVerifiedOrAbsent(&request) {
+		return nil, errEmailNotVerified
+	}
+
+	if _, err := s.getActiveStaff(req.AccountantID); err != nil {
+		return nil, err
+	}
+
+	previous := request.AssignedTo
+	if previous != nil && *previous == req.AccountantID {
+		return &request, nil
+	}
+
+	now := time.Now()
+	request.AssignedTo = &req.AccountantID
+	request.AssignedAt = &now
+	if err := config.DB.Model(&request).Updates(map[string]interface{}{
+		"assigned_to": req.AccountantID,
+		"assigned_at": now,
+	}).Error; err != nil {
+		return nil, errors.New("erro ao atribuir solicitação")
+	}
+
+	action := models.AssignmentActionAssign
+	if previous != nil {
+		action = models.AssignmentActionReassign
+	}
+	s.recordHistory(requestID, action, previous, &req.AccountantID, &performedBy, "", req.Notes)
+
+	return &request, nil
+}
+
+// ReleaseRequest devolve uma solicitação à fila (apenas o responsável ou um admin)
+func (s *ReviewQueueService) ReleaseRequest(requestID, userID uint, notes string) (*models.RegistrationRequest, error) {
+	var request models.RegistrationRequest
+	if err := config.DB.First(&request, requestID).Error; err != nil {
+		return nil, errors.New("solicitação não encontrada")
+	}
+	if request.Status != "pending" {
+		return nil, errors.New("solicitação já foi processada")
+	}
+	if request.AssignedTo == nil {
+		return nil, errors.New("solicitação não está atribuída")
+	}
+
+	if *request.AssignedTo != userID && !NewPermissionService().HasPermission(userID, models.PermissionRequestsAssign) {
+		return nil, errors.New("solicitação atribuída a outro contabilista")
+	}
+
+	previous := request.AssignedTo
+	if err := config.DB.Model(&request).Updates(map[string]interface{}{
+		"assigned_to": nil,
+		"assigned_at": nil,
+	}).Error; err != nil {
+		return nil, errors.New("erro ao libertar solicitação")
+	}
+	request.AssignedTo = nil
+	request.AssignedAt = nil
+
+	s.recordHistory(requestID, models.AssignmentActionRelease, previous, nil, &userID, "", notes)
+
+	return &request, nil
+}
+
+// GetAssignmentHistory obtém o histórico de atribuições de uma solicitação (da carteira, com scope)
+func (s *ReviewQueueService) GetAssignmentHistory(requestID uint, scope *PortfolioScope) ([]models.RequestAssignmentHistory, error) {
+	var count int64
+	scope.Requests(config.DB.Model(&models.RegistrationRequest{})).Where("id = ?", requestID).Count(&count)
+	if count == 0 {
+		return nil, errors.New("solicitação não encontrada")
+	}
+
+	var history []models.RequestAssignmentHistory
+	if err := config.DB.Preload("FromUser").Preload("ToUser").Preload("PerformedByUser").
+		Where("request_id = ?", requestID).
+		Order("created_at ASC").
+		Find(&history).Error; err != nil {
+		return nil, errors.New("erro ao obter histórico de atribuições")
+	}
+
+	return history, nil
+}
+
+// AutoAssign aplica as regras de atribuição a uma solicitação recém-criada.
+// Sem regras aplicáveis a solicitação fica na fila para ser reclamada manualmente.
+func (s *ReviewQueueService) AutoAssign(request *models.RegistrationRequest) error {
+	if request.AssignedTo != nil || request.Status != "pending" {
+		return nil
+	}
+
+	var rules []models.AssignmentRule
+	if err := config.DB.Where("active = ?", true).Order("priority DESC, id ASC").Find(&rules).Error; err != nil {
+		return errors.New("erro ao obter regras de atribuição")
+	}
+
+	for _, rule := range rules {
+		var accountantID *uint
+
+		switch rule.Strategy {
+		case models.AssignmentStrategyDistrict:
+			if rule.AccountantID != nil && matchesRuleValue(rule.MatchValue, requestDistrict(request)) {
+				accountantID = rule.AccountantID
+			}
+		case models.AssignmentStrategyLegalForm:
+			if rule.AccountantID != nil && matchesRuleValue(rule.MatchValue, request.LegalForm) {
+				accountantID = rule.AccountantID
+			}
+		case models.AssignmentStrategyRoundRobin:
+			accountantID = s.nextRoundRobinAccountant()
+		}
+
+		if accountantID == nil {
+			continue
+		}
+		if _, err := s.getActiveStaff(*accountantID); err != nil {
+			continue
+		}
+
+		now := time.Now()
+		if err := config.DB.Model(&models.RegistrationRequest{}).
+			Where("id = ? AND assigned_to IS NULL", request.ID).
+			Updates(map[string]interface{}{"assigned_to": *accountantID, "assigned_at": now}).Error; err != nil {
+			return errors.New("erro ao atribuir solicitação")
+		}
+		request.AssignedTo = accountantID
+		request.AssignedAt = &now
+
+		s.recordHistory(request.ID, models.AssignmentActionAuto, nil, accountantID, nil, rule.Strategy, "")
+		return nil
+	}
+
+	return nil
+}
+
+// AutoAssignPending aplica as regras de atribuição a todas as solicitações pendentes sem responsável
+func (s *ReviewQueueService) AutoAssignPending() (int, error) {
+	var requests []models.RegistrationRequest
+	if err := config.DB.Where("status = ? AND assigned_to IS NULL", "pending").
+		Where(reviewableRequestCondition).
+		Order("submitted_at ASC").
+		Find(&requests).Error; err != nil {
+		return 0, errors.New("erro ao obter solicitações pendentes")
+	}
+
+	assigned := 0
+	for i := range requests {
+		if err := s.AutoAssign(&requests[i]); err != nil {
+			return assigned, err
+		}
+		if requests[i].AssignedTo != nil {
+			assigned++
+		}
+	}
+
+	return assigned, nil
+}
+
+// GetRules obtém todas as regras de atribuição
+func (s *ReviewQueueService) GetRules() ([]models.AssignmentRule, error) {
+	var rules []models.AssignmentRule
+	if err := config.DB.Preload("Accountant").Order("priority DESC, id ASC").Find(&rules).Error; err != nil {
+		return nil, errors.New("erro ao obter regras de atribuição")
+	}
+	return rules, nil
+}
+
+// CreateRule cria uma nova regra de atribuição
+func (s *ReviewQueueService) CreateRule(req models.AssignmentRuleDTO, createdBy uint) (*models.AssignmentRule, error) {
+	if req.Strategy != models.AssignmentStrategyRoundRobin {
+		if strings.TrimSpace(req.MatchValue) == "" {
+			return nil, errors.New("valor de correspondência é obrigatório")
+		}
+		if req.AccountantID == nil {
+			return nil, errors.New("contabilista é obrigatório")
+		}
+	}
+	if req.AccountantID != nil {
+		if _, err := s.getActiveStaff(*req.AccountantID); err != nil {
+			return nil, err
+		}
+	}
+
+	rule := models.AssignmentRule{
+		Strategy:     req.Strategy,
+		MatchValue:   strings.TrimSpace(req.MatchValue),
+		AccountantID: req.AccountantID,
+		Priority:     req.Priority,
+		Active:       true,
+		CreatedBy:    createdBy,
+	}
+	if req.Strategy == models.AssignmentStrategyRoundRobin {
+		rule.MatchValue = ""
+		rule.AccountantID = nil
+	}
+
+	if err := config.DB.Create(&rule).Error; err != nil {
+		return nil, errors.New("erro ao criar regra de atribuição")
+	}
+
+	return &rule, nil
+}
+
+// DeleteRule elimina uma regra de atribuição
+func (s *ReviewQueueService) DeleteRule(ruleID uint) error {
+	result := config.DB.Delete(&models.AssignmentRule{}, ruleID)
+	if result.Error != nil {
+		return errors.New("erro ao eliminar regra de atribuição")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("regra não encontrada")
+	}
+	return nil
+}
+
+// EnsureCanReview garante que a solicitação não está atribuída a outro contabilista.
+// Quem pode atribuir solicitações (requests.assign) pode sempre rever.
+func (s *ReviewQueueService) EnsureCanReview(request *models.RegistrationRequest, reviewerID uint) error {
+	if request.AssignedTo == nil || *request.AssignedTo == reviewerID {
+		return nil
+	}
+
+	if NewPermissionService().HasPermission(reviewerID, models.PermissionRequestsAssign) {
+		return nil
+	}
+
+	return errors.New("solicitação atribuída a outro contabilista")
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func (s *ReviewQueueService) buildQueueItem(req models.RegistrationRequest, now time.Time) models.QueueItemDTO {
+	slaHours := reviewSLAHours()
+	elapsed := now.Sub(req.SubmittedAt)
+	deadline := req.SubmittedAt.Add(time.Duration(slaHours) * time.Hour)
+
+	status := models.SLAStatusOnTrack
+	if now.After(deadline) {
+		status = models.SLAStatusBreached
+	} else if elapsed >= time.Duration(slaHours)*time.Hour*3/4 {
+		status = models.SLAStatusWarning
+	}
+
+	return models.QueueItemDTO{
+		PendingRequestResponseDTO: buildPendingRequestDTO(req),
+		HoursWaiting:              float64(int(elapsed.Hours()*10)) / 10,
+		SLAHours:                  slaHours,
+		SLADeadline:               deadline,
+		SLAStatus:                 status,
+	}
+}
+
+func (s *ReviewQueueService) getActiveStaff(userID uint) (*models.User, error) {
+	var user models.User
+	reviewerRoles := NewPermissionService().RolesWith(models.PermissionRequestsApprove)
+	if err := config.DB.Where("id = ? AND role IN ? AND status = ?", userID, reviewerRoles, "approved").
+		First(&user).Error; err != nil {
+		return nil, errors.New("contabilista não encontrado")
+	}
+	return &user, nil
+}
+
+// nextRoundRobinAccountant escolhe o revisor seguinte ao último atribuído em round-robin,
+// entre a mesma equipa aceite por getActiveStaff (perfis com requests.approve)
+func (s *ReviewQueueService) nextRoundRobinAccountant() *uint {
+	var accountants []models.User
+	reviewerRoles := NewPermissionService().RolesWith(models.PermissionRequestsApprove)
+	if err := config.DB.Where("role IN ? AND status = ?", reviewerRoles, "approved").
+		Order("id ASC").
+		Find(&accountants).Error; err != nil || len(accountants) == 0 {
+		return nil
+	}
+
+	var last models.RequestAssignmentHistory
+	lastID := uint(0)
+	if config.DB.Where("action = ? AND strategy = ?", models.AssignmentActionAuto, models.AssignmentStrategyRoundRobin).
+		Order("id DESC").
+		First(&last).Error == nil && last.ToUserID != nil {
+		lastID = *last.ToUserID
+	}
+
+	next := accountants[0].ID
+	for _, accountant := range accountants {
+		if accountant.ID > lastID {
+			next = accountant.ID
+			break
+		}
+	}
+
+	return &next
+}
+
+func (s *ReviewQueueService) recordHistory(requestID uint, action string, from, to, performedBy *uint, strategy, notes string) {
+	entry := models.RequestAssignmentHistory{
+		RequestID:   requestID,
+		Action:      action,
+		FromUserID:  from,
+		ToUserID:    to,
+		PerformedBy: performedBy,
+		Strategy:    strategy,
+		Notes:       notes,
+	}
+	config.DB.Create(&entry)
+
+	// Avisar o contabilista quando a solicitação lhe é atribuída por outra pessoa ou pelas regras
+	if to != nil && (performedBy == nil || *performedBy != *to) {
+		NewInboxService().Notify(*to, models.EventRequestAssigned, "Nova solicitação atribuída",
+			fmt.Sprintf("A solicitação #%d foi-lhe atribuída para revisão.", requestID),
+			fmt.Sprintf("/api/admin/requests/%d", requestID))
+	}
+}
+
+// emailVerifiedOrAbsent indica se a solicitação pode ser revista: sem email ou com o email verificado
+func emailVerifiedOrAbsent(request *models.RegistrationRequest) bool {
+	return request.Email == nil || request.EmailVerifiedAt != nil
+}
+
+// reviewSLAHours lê o prazo de revisão de REVIEW_SLA_HOURS
+func reviewSLAHours() int {
+	if value, err := strconv.Atoi(os.Getenv("REVIEW_SLA_HOURS")); err == nil && value > 0 {
+		return value
+	}
+	return defaultReviewSLAHours
+}
+
+func requestDistrict(request *models.RegistrationRequest) string {
+	if request.CompanyDistrict != nil && *request.CompanyDistrict != "" {
+		return *request.CompanyDistrict
+	}
+	if request.FiscalDistrict != nil {
+		return *request.FiscalDistrict
+	}
+	return ""
+}
+
+func matchesRuleValue(ruleValue, value string) bool {
+	return value != "" && strings.EqualFold(strings.TrimSpace(ruleValue), strings.TrimSpace(value))
+}
@@ -0,0 +1,570 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// saftMaxStoredErrors limita os erros guardados por importação (todos são contados)
+	saftMaxStoredErrors = 5000
+	// saftErrorBatchSize é o número de erros gravados de cada vez durante o processamento
+	saftErrorBatchSize = 500
+	// saftSupportedVersion é a versão do esquema SAF-T (PT) suportada
+	saftSupportedVersion = "1.04_01"
+)
+
+type SAFTService struct{}
+
+func NewSAFTService() *SAFTService {
+	return &SAFTService{}
+}
+
+// StartImport guarda o ficheiro SAF-T enviado e inicia o processamento em segundo plano
+func (s *SAFTService) StartImport(clientID, companyID, uploadedBy uint, fileHeader *multipart.FileHeader) (*models.SAFTImport, error) {
+	company, err := writableClientCompany(clientID, companyID, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := saftMaxUploadSize()
+	if fileHeader.Size > maxSize {
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, errors.New("erro ao ler ficheiro")
+	}
+	defer src.Close()
+
+	saftImport := models.SAFTImport{
+		CompanyID:  company.ID,
+		UploadedBy: uploadedBy,
+		FileName:   filepath.Base(fileHeader.Filename),
+		Status:     models.SAFTImportProcessing,
+	}
+	if err := config.DB.Create(&saftImport).Error; err != nil {
+		return nil, errors.New("erro ao criar importação")
+	}
+
+	// Guardar em streaming calculando o hash e o tamanho
+	saftImport.StorageKey = fmt.Sprintf("companies/%d/saft/import-%d.xml", company.ID, saftImport.ID)
+	hasher := sha256.New()
+	counter := &countingReader{reader: io.LimitReader(src, maxSize+1)}
+	if err := utils.GetStorage().Save(saftImport.StorageKey, io.TeeReader(counter, hasher)); err != nil {
+		s.fail(&saftImport, "erro ao guardar ficheiro")
+		return nil, errors.New("erro ao guardar ficheiro")
+	}
+	saftImport.FileSize = counter.count
+	saftImport.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+
+	if saftImport.FileSize > maxSize {
+		utils.GetStorage().Delete(saftImport.StorageKey)
+		s.fail(&saftImport, "ficheiro demasiado grande")
+		return nil, fmt.Errorf("ficheiro excede o tamanho máximo de %d MB", maxSize>>20)
+	}
+
+	var previous models.SAFTImport
+	if config.DB.Where("company_id = ? AND sha256 = ? AND id <> ? AND status IN ?", company.ID, saftImport.SHA256, saftImport.ID,
+		[]string{models.SAFTImportCompleted, models.SAFTImportWithErrors, models.SAFTImportProcessing}).
+		First(&previous).Error == nil {
+		utils.GetStorage().Delete(saftImport.StorageKey)
+		s.fail(&saftImport, fmt.Sprintf("ficheiro já importado (importação #%d)", previous.ID))
+		return nil, errors.New("este ficheiro SAF-T já foi importado")
+	}
+
+	if err := config.DB.Model(&saftImport).Updates(map[string]interface{}{
+		"storage_key": saftImport.StorageKey,
+		"file_size":   saftImport.FileSize,
+		"sha256":      saftImport.SHA256,
+	}).Error; err != nil {
+		return nil, errors.New("erro ao criar importação")
+	}
+
+	go s.processImport(saftImport.ID)
+
+	return &saftImport, nil
+}
+
+// GetImports lista as importações SAF-T da empresa de um cliente
+func (s *SAFTService) GetImports(clientID, companyID uint) ([]models.SAFTImport, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := []models.SAFTImport{}
+	if err := config.DB.Where("company_id = ?", company.ID).Order("created_at DESC").Find(&imports).Error; err != nil {
+		return nil, errors.New("erro ao obter importações")
+	}
+	return imports, nil
+}
+
+// GetImportDetail devolve uma importação com os resumos e os erros encontrados (no máximo 1000)
+func (s *SAFTService) GetImportDetail(clientID, companyID, importID uint) (*models.SAFTImportDetailDTO, error) {
+	saftImport, err := s.getImport(clientID, companyID, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := models.SAFTImportDetailDTO{Import: *saftImport, Summaries: []models.SAFTSalesSummary{}, Errors: []models.SAFTImportError{}}
+	if err := config.DB.Where("import_id = ?", importID).Order("period ASC, tax_rate DESC, customer_name ASC").Find(&detail.Summaries).Error; err != nil {
+		return nil, errors.New("erro ao obter resumos da importação")
+	}
+	if err := config.DB.Where("import_id = ?", importID).Order("line ASC").Limit(1000).Find(&detail.Errors).Error; err != nil {
+		return nil, errors.New("erro ao obter erros da importação")
+	}
+	return &detail, nil
+}
+
+// GetSalesSummary agrega as vendas de uma importação por period, tax_rate ou customer
+func (s *SAFTService) GetSalesSummary(clientID, companyID, importID uint, groupBy string) ([]models.SAFTSummaryRowDTO, error) {
+	if _, err := s.getImport(clientID, companyID, importID); err != nil {
+		return nil, err
+	}
+
+	var groupColumn string
+	switch groupBy {
+	case "", "period":
+		groupColumn = "period"
+	case "tax_rate":
+		groupColumn = "CAST(tax_rate AS TEXT)"
+	case "customer":
+		groupColumn = "customer_tax_id || ' - ' || customer_name"
+	default:
+		return nil, errors.New("agrupamento inválido (use period, tax_rate ou customer)")
+	}
+
+	rows := []models.SAFTSummaryRowDTO{}
+	if err := config.DB.Model(&models.SAFTSalesSummary{}).
+		Select(groupColumn+" AS key, SUM(documents) AS documents, SUM(net_amount) AS net_amount, SUM(tax_amount) AS tax_amount").
+		Where("import_id = ?", importID).
+		Group(groupColumn).
+		Order("key ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, errors.New("erro ao obter resumo de vendas")
+	}
+	return rows, nil
+}
+
+// ResumePendingImports volta a processar importações interrompidas (ex.: reinício do servidor)
+func (s *SAFTService) ResumePendingImports() {
+	var imports []models.SAFTImport
+	config.DB.Where("status = ? AND storage_key <> ''", models.SAFTImportProcessing).Find(&imports)
+	for _, saftImport := range imports {
+		go s.processImport(saftImport.ID)
+	}
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+func (s *SAFTService) getImport(clientID, companyID, importID uint) (*models.SAFTImport, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var saftImport models.SAFTImport
+	if err := config.DB.Where("id = ? AND company_id = ?", importID, company.ID).First(&saftImport).Error; err != nil {
+		return nil, errors.New("importação não encontrada")
+	}
+	return &saftImport, nil
+}
+
+func (s *SAFTService) fail(saftImport *models.SAFTImport, reason string) {
+	now := time.Now()
+	saftImport.Status = models.SAFTImportFailed
+	saftImport.FailureReason = reason
+	saftImport.CompletedAt = &now
+	config.DB.Model(saftImport).Updates(map[string]interface{}{
+		"status":         saftImport.Status,
+		"failure_reason": reason,
+		"completed_at":   now,
+	})
+}
+
+// processImport lê o ficheiro guardado, valida-o e grava os resumos e os erros
+func (s *SAFTService) processImport(importID uint) {
+	var saftImport models.SAFTImport
+	if err := config.DB.First(&saftImport, importID).Error; err != nil {
+		return
+	}
+	var company models.Company
+	if err := config.DB.First(&company, saftImport.CompanyID).Error; err != nil {
+		s.fail(&saftImport, "empresa não encontrada")
+		return
+	}
+
+	reader, err := utils.GetStorage().Open(saftImport.StorageKey)
+	if err != nil {
+		s.fail(&saftImport, "ficheiro não encontrado")
+		return
+	}
+	defer reader.Close()
+
+	// Recomeçar do zero se a importação já tinha sido processada parcialmente
+	config.DB.Where("import_id = ?", importID).Delete(&models.SAFTImportError{})
+	config.DB.Where("import_id = ?", importID).Delete(&models.SAFTSalesSummary{})
+
+	// Os períodos fechados são verificados com o papel de quem carregou o ficheiro
+	var uploader models.User
+	config.DB.Select("id", "role").First(&uploader, saftImport.UploadedBy)
+
+	state := newSAFTImportState(&saftImport, &company, uploader.Role)
+	parseErr := utils.ParseSAFT(reader, utils.SAFTVisitor{
+		OnHeader:   state.onHeader,
+		OnCustomer: state.onCustomer,
+		OnInvoice:  state.onInvoice,
+	})
+	if parseErr == nil && !state.headerSeen {
+		parseErr = errors.New("ficheiro sem cabeçalho SAF-T (Header)")
+	}
+	state.flushErrors()
+
+	if parseErr != nil {
+		log.Printf("⚠️  Importação SAF-T %d falhou: %v", importID, parseErr)
+		saftImport.ErrorCount = state.errorCount
+		saftImport.WarningCount = state.warningCount
+		config.DB.Model(&saftImport).Updates(map[string]interface{}{
+			"error_count":   saftImport.ErrorCount,
+			"warning_count": saftImport.WarningCount,
+		})
+		s.fail(&saftImport, parseErr.Error())
+		return
+	}
+
+	summaries := state.summaries()
+	if len(summaries) > 0 {
+		if err := config.DB.CreateInBatches(summaries, 500).Error; err != nil {
+			s.fail(&saftImport, "erro ao guardar resumos de vendas")
+			return
+		}
+	}
+
+	now := time.Now()
+	saftImport.Status = models.SAFTImportCompleted
+	if state.errorCount > 0 {
+		saftImport.Status = models.SAFTImportWithErrors
+	}
+	saftImport.DocumentCount = state.documentCount
+	saftImport.CancelledCount = state.cancelledCount
+	saftImport.CustomerCount = len(state.customers)
+	saftImport.NetTotal = roundAmount(state.netTotal)
+	saftImport.TaxTotal = roundAmount(state.taxTotal)
+	saftImport.GrossTotal = roundAmount(state.grossTotal)
+	saftImport.ErrorCount = state.errorCount
+	saftImport.WarningCount = state.warningCount
+	saftImport.CompletedAt = &now
+	if err := config.DB.Save(&saftImport).Error; err != nil {
+		log.Printf("❌ Erro ao concluir importação SAF-T %d: %v", importID, err)
+	}
+}
+
+// saftSeries guarda o último documento lido de cada série
+type saftSeries struct {
+	lastNumber  int
+	lastHash    string
+	hashControl string
+}
+
+type saftSummaryKey struct {
+	period     string
+	taxRate    float64
+	taxCode    string
+	taxRegion  string
+	customerID string
+}
+
+// saftImportState acumula o estado da validação enquanto o ficheiro é lido
+type saftImportState struct {
+	saftImport     *models.SAFTImport
+	company        *models.Company
+	periods        *periodGuard
+	headerSeen     bool
+	customers      map[string]utils.SAFTCustomer
+	series         map[string]*saftSeries
+	totals         map[saftSummaryKey]*models.SAFTSalesSummary
+	pendingErrors  []models.SAFTImportError
+	storedErrors   int
+	errorCount     int
+	warningCount   int
+	documentCount  int
+	cancelledCount int
+	netTotal       float64
+	taxTotal       float64
+	grossTotal     float64
+}
+
+func newSAFTImportState(saftImport *models.SAFTImport, company *models.Company, role string) *saftImportState {
+	return &saftImportState{
+		saftImport: saftImport,
+		company:    company,
+		periods:    newPeriodGuard(company.ID, role),
+		customers:  make(map[string]utils.SAFTCustomer),
+		series:     make(map[string]*saftSeries),
+		totals:     make(map[saftSummaryKey]*models.SAFTSalesSummary),
+	}
+}
+
+func (st *saftImportState) addIssue(severity string, line int, documentNo, message string) {
+	if severity == models.SAFTSeverityError {
+		st.errorCount++
+	} else {
+		st.warningCount++
+	}
+	if st.storedErrors >= saftMaxStoredErrors {
+		return
+	}
+
+	st.storedErrors++
+	st.pendingErrors = append(st.pendingErrors, models.SAFTImportError{
+		ImportID:   st.saftImport.ID,
+		Line:       line,
+		DocumentNo: documentNo,
+		Severity:   severity,
+		Message:    message,
+	})
+	if len(st.pendingErrors) >= saftErrorBatchSize {
+		st.flushErrors()
+	}
+}
+
+func (st *saftImportState) flushErrors() {
+	if len(st.pendingErrors) == 0 {
+		return
+	}
+	if err := config.DB.Create(&st.pendingErrors).Error; err != nil {
+		log.Printf("⚠️  Erro ao guardar erros da importação SAF-T %d: %v", st.saftImport.ID, err)
+	}
+	st.pendingErrors = st.pendingErrors[:0]
+}
+
+func (st *saftImportState) onHeader(header *utils.SAFTHeader, line int) error {
+	st.headerSeen = true
+
+	st.saftImport.AuditFileVersion = header.AuditFileVersion
+	st.saftImport.TaxRegistrationNumber = header.TaxRegistrationNumber
+	st.saftImport.ProductID = header.ProductID
+	st.saftImport.FiscalYear = header.FiscalYear
+	st.saftImport.StartDate = header.StartDate
+	st.saftImport.EndDate = header.EndDate
+	config.DB.Model(st.saftImport).Updates(map[string]interface{}{
+		"audit_file_version":      header.AuditFileVersion,
+		"tax_registration_number": header.TaxRegistrationNumber,
+		"product_id":              header.ProductID,
+		"fiscal_year":             header.FiscalYear,
+		"start_date":              header.StartDate,
+		"end_date":                header.EndDate,
+	})
+
+	if header.AuditFileVersion != saftSupportedVersion {
+		st.addIssue(models.SAFTSeverityWarning, line, "",
+			fmt.Sprintf("versão %s do SAF-T; esperada %s", header.AuditFileVersion, saftSupportedVersion))
+	}
+
+	if normalizeTaxID(header.TaxRegistrationNumber) != normalizeTaxID(st.company.NIPC) {
+		st.addIssue(models.SAFTSeverityError, line, "",
+			fmt.Sprintf("NIF do ficheiro (%s) não corresponde ao NIPC da empresa (%s)", header.TaxRegistrationNumber, st.company.NIPC))
+		return fmt.Errorf("NIF do ficheiro (%s) não corresponde ao NIPC da empresa", header.TaxRegistrationNumber)
+	}
+	return nil
+}
+
+func (st *saftImportState) onCustomer(customer *utils.SAFTCustomer, line int) {
+	st.customers[customer.CustomerID] = *customer
+}
+
+func (st *saftImportState) onInvoice(invoice *utils.SAFTInvoice, line int) {
+	if !st.headerSeen {
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo, "documento antes do cabeçalho")
+		return
+	}
+	st.documentCount++
+	st.checkSequence(invoice, line)
+
+	if invoice.ATCUD == "" {
+		st.addIssue(models.SAFTSeverityWarning, line, invoice.InvoiceNo, "documento sem ATCUD")
+	}
+	if _, ok := st.customers[invoice.CustomerID]; !ok {
+		st.addIssue(models.SAFTSeverityWarning, line, invoice.InvoiceNo,
+			fmt.Sprintf("cliente %s não existe na tabela de clientes", invoice.CustomerID))
+	}
+	if len(invoice.InvoiceDate) < 7 {
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo, "data do documento inválida")
+		return
+	}
+
+	// Documentos anulados fazem parte da numeração e da cadeia de hash, mas não das vendas
+	if invoice.DocumentStatus.InvoiceStatus == "A" {
+		st.cancelledCount++
+		return
+	}
+
+	// Documentos de períodos fechados ficam fora dos resumos de vendas
+	if date, err := time.Parse("2006-01", invoice.InvoiceDate[:7]); err == nil {
+		if len(invoice.InvoiceDate) >= 10 {
+			if day, err := time.Parse("2006-01-02", invoice.InvoiceDate[:10]); err == nil {
+				date = day
+			}
+		}
+		if err := st.periods.check(date); err != nil {
+			st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo, err.Error())
+			return
+		}
+	}
+
+	sign := 1.0
+	if invoice.InvoiceType == "NC" {
+		sign = -1.0
+	}
+
+	lineNet, lineTax := 0.0, 0.0
+	touched := make(map[saftSummaryKey]bool)
+	for _, item := range invoice.Lines {
+		net := item.CreditAmount - item.DebitAmount
+		tax := net * item.Tax.TaxPercentage / 100
+		lineNet += net
+		lineTax += tax
+
+		key := saftSummaryKey{
+			period:     invoice.InvoiceDate[:7],
+			taxRate:    item.Tax.TaxPercentage,
+			taxCode:    item.Tax.TaxCode,
+			taxRegion:  item.Tax.TaxCountryRegion,
+			customerID: invoice.CustomerID,
+		}
+		summary, ok := st.totals[key]
+		if !ok {
+			customer := st.customers[invoice.CustomerID]
+			summary = &models.SAFTSalesSummary{
+				ImportID:      st.saftImport.ID,
+				CompanyID:     st.company.ID,
+				Period:        key.period,
+				TaxRate:       key.taxRate,
+				TaxCode:       key.taxCode,
+				TaxRegion:     key.taxRegion,
+				CustomerTaxID: customer.CustomerTaxID,
+				CustomerName:  customer.CompanyName,
+			}
+			st.totals[key] = summary
+		}
+		summary.NetAmount += net
+		summary.TaxAmount += tax
+		if !touched[key] {
+			summary.Documents++
+			touched[key] = true
+		}
+	}
+
+	if math.Abs(math.Abs(lineNet)-invoice.DocumentTotals.NetTotal) > 0.01 {
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo,
+			fmt.Sprintf("soma das linhas (%.2f) difere do NetTotal (%.2f)", math.Abs(lineNet), invoice.DocumentTotals.NetTotal))
+	}
+	if math.Abs(math.Abs(lineTax)-invoice.DocumentTotals.TaxPayable) > 0.05 {
+		st.addIssue(models.SAFTSeverityWarning, line, invoice.InvoiceNo,
+			fmt.Sprintf("IVA calculado (%.2f) difere do TaxPayable (%.2f)", math.Abs(lineTax), invoice.DocumentTotals.TaxPayable))
+	}
+
+	st.netTotal += sign * invoice.DocumentTotals.NetTotal
+	st.taxTotal += sign * invoice.DocumentTotals.TaxPayable
+	st.grossTotal += sign * invoice.DocumentTotals.GrossTotal
+}
+
+// checkSequence valida a numeração sequencial e a continuidade da cadeia de hash de cada série
+func (st *saftImportState) checkSequence(invoice *utils.SAFTInvoice, line int) {
+	seriesName, number, ok := utils.SplitSAFTDocumentNo(invoice.InvoiceNo)
+	if !ok {
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo, "número de documento inválido (esperado \"TIPO SÉRIE/NÚMERO\")")
+		return
+	}
+
+	series, seen := st.series[seriesName]
+	if !seen {
+		series = &saftSeries{}
+		st.series[seriesName] = series
+		if number != 1 {
+			st.addIssue(models.SAFTSeverityWarning, line, invoice.InvoiceNo,
+				fmt.Sprintf("série %s começa no número %d (documentos anteriores noutro ficheiro?)", seriesName, number))
+		}
+	} else if number != series.lastNumber+1 {
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo,
+			fmt.Sprintf("numeração não sequencial na série %s: esperado %d, encontrado %d", seriesName, series.lastNumber+1, number))
+	}
+
+	// A assinatura só pode ser confirmada com a chave pública do produtor do software;
+	// aqui verifica-se que cada documento tem uma assinatura válida e diferente da anterior
+	signature, err := base64.StdEncoding.DecodeString(invoice.Hash)
+	switch {
+	case invoice.Hash == "" || invoice.Hash == "0":
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo, "documento sem hash: cadeia de assinaturas interrompida")
+	case err != nil || (len(signature) != 128 && len(signature) != 256):
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo, "hash inválido (esperada assinatura RSA em base64)")
+	case seen && invoice.Hash == series.lastHash:
+		st.addIssue(models.SAFTSeverityError, line, invoice.InvoiceNo, "hash igual ao do documento anterior: cadeia de assinaturas interrompida")
+	}
+
+	if seen && series.hashControl != "" && invoice.HashControl != series.hashControl {
+		st.addIssue(models.SAFTSeverityWarning, line, invoice.InvoiceNo,
+			fmt.Sprintf("versão da chave (HashControl) mudou de %s para %s", series.hashControl, invoice.HashControl))
+	}
+
+	series.lastNumber = number
+	series.lastHash = invoice.Hash
+	series.hashControl = invoice.HashControl
+}
+
+func (st *saftImportState) summaries() []models.SAFTSalesSummary {
+	summaries := make([]models.SAFTSalesSummary, 0, len(st.totals))
+	for _, summary := range st.totals {
+		summary.NetAmount = roundAmount(summary.NetAmount)
+		summary.TaxAmount = roundAmount(summary.TaxAmount)
+		summaries = append(summaries, *summary)
+	}
+	return summaries
+}
+
+// countingReader conta os bytes lidos
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// normalizeTaxID remove espaços e o prefixo do país de um NIF/NIPC
+func normalizeTaxID(value string) string {
+	value = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+	return strings.TrimPrefix(value, "PT")
+}
+
+func roundAmount(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// saftMaxUploadSize lê o limite de upload de SAFT_MAX_UPLOAD_MB (500 MB por omissão)
+func saftMaxUploadSize() int64 {
+	if value, err := strconv.Atoi(os.Getenv("SAFT_MAX_UPLOAD_MB")); err == nil && value > 0 {
+		return int64(value) << 20
+	}
+	return 500 << 20
+}
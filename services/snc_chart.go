@@ -0,0 +1,265 @@
+package services
+
+import "RVContabilidadeBack/models"
+
+// sncAccount é uma conta do código de contas do SNC (Portaria n.º 218/2015)
+type sncAccount struct {
+	code string
+	name string
+}
+
+// sncChartOfAccounts é o plano de contas base criado para cada empresa.
+// As contas sem subcontas nesta lista aceitam lançamentos; cada empresa pode criar subcontas.
+var sncChartOfAccounts = []sncAccount{
+	// Classe 1 - Meios financeiros líquidos
+	{"11", "Caixa"},
+	{"12", "Depósitos à ordem"},
+	{"13", "Outros depósitos bancários"},
+	{"14", "Outros instrumentos financeiros"},
+	{"141", "Derivados"},
+	{"142", "Instrumentos financeiros detidos para negociação"},
+	{"143", "Outros ativos e passivos financeiros"},
+
+	// Classe 2 - Contas a receber e a pagar
+	{"21", "Clientes"},
+	{"211", "Clientes c/c"},
+	{"212", "Clientes - títulos a receber"},
+	{"218", "Adiantamentos de clientes"},
+	{"219", "Perdas por imparidade acumuladas"},
+	{"22", "Fornecedores"},
+	{"221", "Fornecedores c/c"},
+	{"222", "Fornecedores - títulos a pagar"},
+	{"225", "Faturas em receção e conferência"},
+	{"228", "Adiantamentos a fornecedores"},
+	{"229", "Perdas por imparidade acumuladas"},
+	{"23", "Pessoal"},
+	{"231", "Remunerações a pagar"},
+	{"232", "Adiantamentos"},
+	{"237", "Cauções"},
+	{"238", "Outras operações"},
+	{"239", "Perdas por imparidade acumuladas"},
+	{"24", "Estado e outros entes públicos"},
+	{"241", "Imposto sobre o rendimento"},
+	{"242", "Retenção de impostos sobre rendimentos"},
+	{"243", "Imposto sobre o valor acrescentado (IVA)"},
+	{"2431", "IVA - Suportado"},
+	{"2432", "IVA - Dedutível"},
+	{"2433", "IVA - Liquidado"},
+	{"2434", "IVA - Regularizações"},
+	{"2435", "IVA - Apuramento"},
+	{"2436", "IVA - A pagar"},
+	{"2437", "IVA - A recuperar"},
+	{"2438", "IVA - Reembolsos pedidos"},
+	{"2439", "IVA - Liquidações oficiosas"},
+	{"244", "Outros impostos"},
+	{"245", "Contribuições para a Segurança Social"},
+	{"246", "Tributos das autarquias locais"},
+	{"248", "Outras tributações"},
+	{"25", "Financiamentos obtidos"},
+	{"251", "Instituições de crédito e sociedades financeiras"},
+	{"252", "Mercado de valores mobiliários"},
+	{"253", "Participantes de capital"},
+	{"254", "Subsidiárias, associadas e empreendimentos conjuntos"},
+	{"258", "Outros financiadores"},
+	{"26", "Acionistas/sócios"},
+	{"261", "Acionistas c/ subscrição"},
+	{"262", "Quotas não liberadas"},
+	{"263", "Adiantamentos por conta de lucros"},
+	{"264", "Resultados atribuídos"},
+	{"265", "Lucros disponíveis"},
+	{"266", "Empréstimos concedidos - empresa-mãe"},
+	{"268", "Outras operações"},
+	{"269", "Perdas por imparidade acumuladas"},
+	{"27", "Outras contas a receber e a pagar"},
+	{"271", "Fornecedores de investimentos"},
+	{"272", "Devedores e credores por acréscimos"},
+	{"273", "Benefícios pós-emprego"},
+	{"274", "Impostos diferidos"},
+	{"275", "Credores por subscrições não liberadas"},
+	{"276", "Adiantamentos por conta de vendas"},
+	{"278", "Outros devedores e credores"},
+	{"279", "Perdas por imparidade acumuladas"},
+	{"28", "Diferimentos"},
+	{"281", "Gastos a reconhecer"},
+	{"282", "Rendimentos a reconhecer"},
+	{"29", "Provisões"},
+	{"291", "Impostos"},
+	{"292", "Garantias a clientes"},
+	{"293", "Processos judiciais em curso"},
+	{"294", "Acidentes de trabalho e doenças profissionais"},
+	{"295", "Matérias ambientais"},
+	{"296", "Contratos onerosos"},
+	{"297", "Reestruturação"},
+	{"298", "Outras provisões"},
+
+	// Classe 3 - Inventários e ativos biológicos
+	{"31", "Compras"},
+	{"311", "Mercadorias"},
+	{"312", "Matérias-primas, subsidiárias e de consumo"},
+	{"313", "Ativos biológicos"},
+	{"317", "Devoluções de compras"},
+	{"318", "Descontos e abatimentos em compras"},
+	{"32", "Mercadorias"},
+	{"33", "Matérias-primas, subsidiárias e de consumo"},
+	{"34", "Produtos acabados e intermédios"},
+	{"35", "Subprodutos, desperdícios, resíduos e refugos"},
+	{"36", "Produtos e trabalhos em curso"},
+	{"37", "Ativos biológicos"},
+	{"38", "Reclassificação e regularização de inventários e ativos biológicos"},
+	{"39", "Adiantamentos por conta de compras"},
+
+	// Classe 4 - Investimentos
+	{"41", "Investimentos financeiros"},
+	{"42", "Propriedades de investimento"},
+	{"43", "Ativos fixos tangíveis"},
+	{"431", "Terrenos e recursos naturais"},
+	{"432", "Edifícios e outras construções"},
+	{"433", "Equipamento básico"},
+	{"434", "Equipamento de transporte"},
+	{"435", "Equipamento administrativo"},
+	{"436", "Equipamentos biológicos"},
+	{"437", "Outros ativos fixos tangíveis"},
+	{"438", "Depreciações acumuladas"},
+	{"439", "Perdas por imparidade acumuladas"},
+	{"44", "Ativos intangíveis"},
+	{"441", "Goodwill"},
+	{"442", "Projetos de desenvolvimento"},
+	{"443", "Programas de computador"},
+	{"444", "Propriedade industrial"},
+	{"446", "Outros ativos intangíveis"},
+	{"448", "Amortizações acumuladas"},
+	{"449", "Perdas por imparidade acumuladas"},
+	{"45", "Investimentos em curso"},
+	{"46", "Ativos não correntes detidos para venda"},
+
+	// Classe 5 - Capital, reservas e resultados transitados
+	{"51", "Capital subscrito"},
+	{"52", "Ações (quotas) próprias"},
+	{"53", "Outros instrumentos de capital próprio"},
+	{"54", "Prémios de emissão"},
+	{"55", "Reservas"},
+	{"551", "Reservas legais"},
+	{"552", "Outras reservas"},
+	{"56", "Resultados transitados"},
+	{"57", "Ajustamentos em ativos financeiros"},
+	{"58", "Excedentes de revalorização"},
+	{"59", "Outras variações no capital próprio"},
+
+	// Classe 6 - Gastos
+	{"61", "Custo das mercadorias vendidas e das matérias consumidas"},
+	{"611", "Mercadorias"},
+	{"612", "Matérias-primas, subsidiárias e de consumo"},
+	{"613", "Ativos biológicos"},
+	{"62", "Fornecimentos e serviços externos"},
+	{"621", "Subcontratos"},
+	{"622", "Serviços especializados"},
+	{"6221", "Trabalhos especializados"},
+	{"6222", "Publicidade e propaganda"},
+	{"6223", "Vigilância e segurança"},
+	{"6224", "Honorários"},
+	{"6225", "Comissões"},
+	{"6226", "Conservação e reparação"},
+	{"6228", "Outros serviços especializados"},
+	{"623", "Materiais"},
+	{"6231", "Ferramentas e utensílios de desgaste rápido"},
+	{"6232", "Livros e documentação técnica"},
+	{"6233", "Material de escritório"},
+	{"6234", "Artigos para oferta"},
+	{"6238", "Outros materiais"},
+	{"624", "Energia e fluidos"},
+	{"6241", "Eletricidade"},
+	{"6242", "Combustíveis"},
+	{"6243", "Água"},
+	{"6248", "Outros fluidos"},
+	{"625", "Deslocações, estadas e transportes"},
+	{"6251", "Deslocações e estadas"},
+	{"6252", "Transportes de pessoal"},
+	{"6253", "Transportes de mercadorias"},
+	{"6258", "Outras deslocações"},
+	{"626", "Serviços diversos"},
+	{"6261", "Rendas e alugueres"},
+	{"6262", "Comunicação"},
+	{"6263", "Seguros"},
+	{"6264", "Royalties"},
+	{"6265", "Contencioso e notariado"},
+	{"6266", "Despesas de representação"},
+	{"6267", "Limpeza, higiene e conforto"},
+	{"6268", "Outros serviços"},
+	{"63", "Gastos com o pessoal"},
+	{"631", "Remunerações dos órgãos sociais"},
+	{"632", "Remunerações do pessoal"},
+	{"633", "Benefícios pós-emprego"},
+	{"634", "Indemnizações"},
+	{"635", "Encargos sobre remunerações"},
+	{"636", "Seguros de acidentes no trabalho e doenças profissionais"},
+	{"637", "Gastos de ação social"},
+	{"638", "Outros gastos com o pessoal"},
+	{"64", "Gastos de depreciação e de amortização"},
+	{"641", "Propriedades de investimento"},
+	{"642", "Ativos fixos tangíveis"},
+	{"643", "Ativos intangíveis"},
+	{"65", "Perdas por imparidade"},
+	{"66", "Perdas por reduções de justo valor"},
+	{"67", "Provisões do período"},
+	{"68", "Outros gastos"},
+	{"681", "Impostos"},
+	{"682", "Descontos de pronto pagamento concedidos"},
+	{"683", "Dívidas incobráveis"},
+	{"684", "Perdas em inventários"},
+	{"685", "Gastos em subsidiárias, associadas e empreendimentos conjuntos"},
+	{"686", "Gastos em investimentos financeiros"},
+	{"687", "Gastos em investimentos não financeiros"},
+	{"688", "Outros gastos"},
+	{"69", "Gastos de financiamento"},
+	{"691", "Juros suportados"},
+	{"692", "Diferenças de câmbio desfavoráveis"},
+	{"698", "Outros gastos de financiamento"},
+
+	// Classe 7 - Rendimentos
+	{"71", "Vendas"},
+	{"711", "Mercadorias"},
+	{"712", "Produtos acabados e intermédios"},
+	{"713", "Subprodutos, desperdícios, resíduos e refugos"},
+	{"714", "Ativos biológicos"},
+	{"716", "IVA das vendas com imposto incluído"},
+	{"717", "Devoluções de vendas"},
+	{"718", "Descontos e abatimentos em vendas"},
+	{"72", "Prestações de serviços"},
+	{"721", "Serviços principais"},
+	{"725", "Serviços secundários"},
+	{"726", "IVA dos serviços com imposto incluído"},
+	{"728", "Descontos e abatimentos"},
+	{"73", "Variações nos inventários da produção"},
+	{"74", "Trabalhos para a própria entidade"},
+	{"75", "Subsídios à exploração"},
+	{"76", "Reversões"},
+	{"77", "Ganhos por aumentos de justo valor"},
+	{"78", "Outros rendimentos"},
+	{"781", "Rendimentos suplementares"},
+	{"782", "Descontos de pronto pagamento obtidos"},
+	{"783", "Recuperação de dívidas a receber"},
+	{"784", "Ganhos em inventários"},
+	{"785", "Rendimentos e ganhos em subsidiárias, associadas e empreendimentos conjuntos"},
+	{"786", "Rendimentos e ganhos em investimentos financeiros"},
+	{"787", "Rendimentos e ganhos em investimentos não financeiros"},
+	{"788", "Outros rendimentos"},
+	{"79", "Juros, dividendos e outros rendimentos similares"},
+	{"791", "Juros obtidos"},
+	{"792", "Dividendos obtidos"},
+	{"798", "Outros rendimentos similares"},
+
+	// Classe 8 - Resultados
+	{"81", "Resultado líquido do período"},
+	{"811", "Resultado antes de impostos"},
+	{"812", "Imposto sobre o rendimento do período"},
+	{"818", "Resultado líquido"},
+	{"89", "Dividendos antecipados"},
+}
+
+// defaultJournals são os diários criados para cada empresa
+var defaultJournals = []models.Journal{
+	{Code: "VND", Name: "Vendas", Type: models.JournalTypeSales},
+	{Code: "CMP", Name: "Compras", Type: models.JournalTypePurchases},
+	{Code: "BNC", Name: "Bancos", Type: models.JournalTypeBank},
+	{Code: "DIV", Name: "Operações diversas", Type: models.JournalTypeGeneral},
+}
@@ -4,7 +4,10 @@ import (
 	"RVContabilidadeBack/config"
 	"RVContabilidadeBack/models"
 	"errors"
+	"log"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct{}
@@ -16,9 +19,13 @@ func NewUserService() *UserService {
 // GetProfile obtém o perfil do utilizador
 func (s *UserService) GetProfile(userID uint) (*models.User, error) {
 	var user models.User
-	if err := config.DB.Preload("Company").First(&user, userID).Error; err != nil {
+	if err := config.DB.First(&user, userID).Error; err != nil {
 		return nil, errors.New("utilizador não encontrado")
 	}
+	if err := attachUserCompany(&user); err != nil {
+		return nil, err
+	}
+	user.UnreadNotifications, _ = NewInboxService().UnreadCount(userID)
 	return &user, nil
 }
 
@@ -44,6 +51,35 @@ func (s *UserService) UpdateProfile(userID uint, req models.UpdateProfileDTO) (*
 	return &user, nil
 }
 
+// ChangePassword altera a password do utilizador depois de confirmar a atual
+func (s *UserService) ChangePassword(userID uint, req models.ChangePasswordDTO) error {
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return errors.New("utilizador não encontrado")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return errors.New("password atual incorreta")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("erro ao processar password")
+	}
+
+	if err := config.DB.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+		return errors.New("erro ao alterar password")
+	}
+
+	if user.Email != "" {
+		if err := NewNotificationService().NotifyUser(models.EventPasswordChanged, &user, nil); err != nil {
+			log.Printf("⚠️  Erro ao notificar alteração de password do utilizador %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
 // GetRequestHistory obtém o histórico de solicitações do utilizador
 func (s *UserService) GetRequestHistory(userID uint) (map[string]interface{}, error) {
 	// Buscar dados do utilizador
@@ -174,18 +210,24 @@ func (s *UserService) CompleteUserData(userID uint, req models.CompleteUserDataD
 // GetAllClients obtém todos os clientes (para admins/contabilistas)
 func (s *UserService) GetAllClients() ([]models.User, error) {
 	var users []models.User
-	if err := config.DB.Where("role = ?", "client").Preload("Company").Find(&users).Error; err != nil {
+	if err := config.DB.Where("role = ?", models.RoleClient).Find(&users).Error; err != nil {
 		return nil, errors.New("erro ao obter lista de clientes")
 	}
+	if err := attachUserCompanies(users, nil); err != nil {
+		return nil, err
+	}
 	return users, nil
 }
 
 // GetClientByID obtém um cliente específico por ID
 func (s *UserService) GetClientByID(clientID uint) (*models.User, error) {
 	var user models.User
-	if err := config.DB.Where("role = ?", "client").Preload("Company").First(&user, clientID).Error; err != nil {
+	if err := config.DB.Where("role = ?", models.RoleClient).First(&user, clientID).Error; err != nil {
 		return nil, errors.New("cliente não encontrado")
 	}
+	if err := attachUserCompany(&user); err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
 
@@ -208,7 +250,7 @@ func (s *UserService) UpdateUserStatus(userID uint, req models.UpdateUserStatusD
 // AdminUpdateClient permite admin/contabilista editar dados de cliente
 func (s *UserService) AdminUpdateClient(clientID uint, req models.AdminUpdateClientDTO) (*models.User, error) {
 	var user models.User
-	if err := config.DB.Where("role = ?", "client").First(&user, clientID).Error; err != nil {
+	if err := config.DB.Where("role = ?", models.RoleClient).First(&user, clientID).Error; err != nil {
 		return nil, errors.New("cliente não encontrado")
 	}
 
@@ -282,36 +324,9 @@ func (s *UserService) AdminUpdateClient(clientID uint, req models.AdminUpdateCli
 	}
 
 	// Recarregar com relacionamentos
-	if err := config.DB.Preload("Company").First(&user, user.ID).Error; err != nil {
+	if err := attachUserCompany(&user); err != nil {
 		return nil, errors.New("erro ao recarregar dados do cliente")
 	}
 
 	return &user, nil
 }
-
-// DeleteClient elimina um cliente
-func (s *UserService) DeleteClient(clientID uint) error {
-	// Verificar se o cliente existe e é cliente
-	var client models.User
-	if err := config.DB.Where("id = ? AND role = ?", clientID, "client").First(&client).Error; err != nil {
-		return errors.New("cliente não encontrado")
-	}
-
-	// Iniciar transação
-	tx := config.DB.Begin()
-
-	// Eliminar empresa(s) do cliente
-	if err := tx.Where("user_id = ?", clientID).Delete(&models.Company{}).Error; err != nil {
-		tx.Rollback()
-		return errors.New("erro ao eliminar empresa do cliente")
-	}
-
-	// Eliminar cliente
-	if err := tx.Delete(&client).Error; err != nil {
-		tx.Rollback()
-		return errors.New("erro ao eliminar cliente")
-	}
-
-	tx.Commit()
-	return nil
-}
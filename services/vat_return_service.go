@@ -0,0 +1,467 @@
+package services
+
+import (
+	"RVContabilidadeBack/config"
+	"RVContabilidadeBack/models"
+	"RVContabilidadeBack/utils"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vatRegionRates são as taxas reduzida, intermédia e normal de cada região (Continente, Açores, Madeira)
+var vatRegionRates = map[string][3]float64{
+	"PT":    {6, 13, 23},
+	"PT-AC": {4, 9, 16},
+	"PT-MA": {5, 12, 22},
+}
+
+// Campos da declaração onde é declarada a base e o imposto de cada escalão de taxa (reduzida, intermédia, normal)
+var (
+	vatSalesBaseBoxes  = [3]int{1, 5, 3}
+	vatSalesTaxBoxes   = [3]int{2, 6, 4}
+	vatInventoryBoxes  = [3]int{21, 23, 22}
+	vatReturnBoxLabels = map[int]string{
+		1:  "Base tributável - taxa reduzida",
+		2:  "Imposto a favor do Estado - taxa reduzida",
+		3:  "Base tributável - taxa normal",
+		4:  "Imposto a favor do Estado - taxa normal",
+		5:  "Base tributável - taxa intermédia",
+		6:  "Imposto a favor do Estado - taxa intermédia",
+		7:  "Transmissões intracomunitárias de bens e prestações de serviços isentas",
+		8:  "Operações isentas com direito à dedução",
+		9:  "Operações isentas sem direito à dedução",
+		10: "Base tributável - aquisições intracomunitárias de bens",
+		11: "Imposto - aquisições intracomunitárias de bens",
+		12: "Base tributável - serviços adquiridos com imposto liquidado pelo adquirente",
+		13: "Imposto - serviços adquiridos com imposto liquidado pelo adquirente",
+		14: "Aquisições intracomunitárias de bens isentas",
+		15: "Operações não tributadas",
+		16: "Base tributável - outras operações com imposto liquidado pelo adquirente",
+		17: "Imposto - outras operações com imposto liquidado pelo adquirente",
+		18: "Base tributável - importações de bens com imposto liquidado pelo adquirente",
+		19: "Imposto - importações de bens com imposto liquidado pelo adquirente",
+		20: "Imposto dedutível - imobilizado",
+		21: "Imposto dedutível - existências à taxa reduzida",
+		22: "Imposto dedutível - existências à taxa normal",
+		23: "Imposto dedutível - existências à taxa intermédia",
+		24: "Imposto dedutível - outros bens e serviços",
+	}
+)
+
+var (
+	vatMonthPattern   = regexp.MustCompile(`^(\d{4})-(0[1-9]|1[0-2])$`)
+	vatQuarterPattern = regexp.MustCompile(`^(\d{4})-T([1-4])$`)
+	foreignVATPrefix  = regexp.MustCompile(`^([A-Z]{2})[0-9A-Z]+$`)
+)
+
+// euVATPrefixes são os prefixos dos números de IVA dos outros Estados-membros (VIES), incluindo
+// EL (Grécia) e XI (Irlanda do Norte, para bens)
+var euVATPrefixes = map[string]bool{
+	"AT": true, "BE": true, "BG": true, "CY": true, "CZ": true, "DE": true, "DK": true, "EE": true,
+	"EL": true, "ES": true, "FI": true, "FR": true, "HR": true, "HU": true, "IE": true, "IT": true,
+	"LT": true, "LU": true, "LV": true, "MT": true, "NL": true, "PL": true, "RO": true, "SE": true,
+	"SI": true, "SK": true, "XI": true,
+}
+
+type VATReturnService struct{}
+
+func NewVATReturnService() *VATReturnService {
+	return &VATReturnService{}
+}
+
+// Compute calcula a declaração periódica de IVA de um cliente a partir das vendas (SAF-T importado)
+// e das compras (e-Fatura importado). period é AAAA-MM (regime mensal) ou AAAA-Tn (regime trimestral).
+func (s *VATReturnService) Compute(clientID, companyID uint, period string, carriedCredit float64) (*models.VATReturnDTO, error) {
+	company, err := clientCompany(clientID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	frequency := companyVATFrequency(company)
+	if frequency == "" {
+		return nil, errors.New("a empresa está isenta de IVA e não entrega declaração periódica")
+	}
+	start, end, err := vatReturnPeriod(period, frequency)
+	if err != nil {
+		return nil, err
+	}
+	if carriedCredit < 0 {
+		return nil, errors.New("o crédito do período anterior não pode ser negativo")
+	}
+
+	months := []string{}
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		months = append(months, month.Format("2006-01"))
+	}
+
+	result := &models.VATReturnDTO{
+		CompanyID:     company.ID,
+		NIF:           company.NIPC,
+		CompanyName:   company.CompanyName,
+		Period:        period,
+		Frequency:     frequency,
+		StartDate:     start.Format("2006-01-02"),
+		EndDate:       end.AddDate(0, 0, -1).Format("2006-01-02"),
+		CarriedCredit: roundAmount(carriedCredit),
+		Warnings:      []string{},
+	}
+	boxes := make(map[int]float64)
+
+	if err := s.addSales(company.ID, months, boxes, result); err != nil {
+		return nil, err
+	}
+	if err := s.addPurchases(company.ID, months, boxes, result); err != nil {
+		return nil, err
+	}
+
+	for number := 1; number <= 24; number++ {
+		result.Boxes = append(result.Boxes, models.VATReturnBoxDTO{
+			Number: number,
+			Label:  vatReturnBoxLabels[number],
+			Value:  roundAmount(boxes[number]),
+		})
+	}
+
+	result.TotalTaxDue = roundAmount(boxes[2] + boxes[4] + boxes[6] + boxes[11] + boxes[13] + boxes[17] + boxes[19])
+	result.TotalDeductible = roundAmount(boxes[20] + boxes[21] + boxes[22] + boxes[23] + boxes[24])
+	balance := roundAmount(result.TotalTaxDue - result.TotalDeductible - result.CarriedCredit)
+	if balance >= 0 {
+		result.TaxPayable = balance
+	} else {
+		result.TaxRecoverable = -balance
+	}
+	result.NonDeductibleVAT = roundAmount(result.NonDeductibleVAT)
+
+	return result, nil
+}
+
+// ExportXML devolve a declaração calculada em XML com a estrutura da declaração periódica da AT
+// (rosto com os quadros 01, 02, 05 e 06 e os campos pelos códigos oficiais), para conferência
+func (s *VATReturnService) ExportXML(clientID, companyID uint, period string, carriedCredit float64) ([]byte, string, error) {
+	result, err := s.Compute(clientID, companyID, period, carriedCredit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := buildVATReturnXML(result)
+	if err != nil {
+		return nil, "", err
+	}
+	filename := fmt.Sprintf("dp-iva-%s-%s.xml", result.NIF, result.Period)
+	return content, filename, nil
+}
+
+// ===== MÉTODOS PRIVADOS =====
+
+// addSales soma as vendas de cada mês, usando a importação SAF-T concluída mais recente desse mês
+func (s *VATReturnService) addSales(companyID uint, months []string, boxes map[int]float64, result *models.VATReturnDTO) error {
+	var latest []struct {
+		Period   string
+		ImportID uint
+	}
+	if err := config.DB.Table("saft_sales_summaries").
+		Select("saft_sales_summaries.period, MAX(saft_sales_summaries.import_id) AS import_id").
+		Joins("JOIN saft_imports ON saft_imports.id = saft_sales_summaries.import_id").
+		Where("saft_sales_summaries.company_id = ? AND saft_sales_summaries.period IN ? AND saft_imports.status IN ?",
+			companyID, months, []string{models.SAFTImportCompleted, models.SAFTImportWithErrors}).
+		Group("saft_sales_summaries.period").
+		Scan(&latest).Error; err != nil {
+		return errors.New("erro ao obter vendas")
+	}
+
+	imported := make(map[string]bool)
+	rates := make(map[string]*models.VATRateTotalsDTO)
+	for _, item := range latest {
+		imported[item.Period] = true
+
+		var summaries []models.SAFTSalesSummary
+		if err := config.DB.Where("import_id = ? AND period = ?", item.ImportID, item.Period).Find(&summaries).Error; err != nil {
+			return errors.New("erro ao obter vendas")
+		}
+		for _, summary := range summaries {
+			if summary.TaxRate == 0 {
+				boxes[vatExemptSalesBox(summary.CustomerTaxID)] += summary.NetAmount
+				continue
+			}
+
+			region, class, ok := classifyVATRate(summary.TaxRegion, summary.TaxRate)
+			if !ok {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("%s: taxa de IVA %.2f%% não reconhecida, declarada na taxa normal", item.Period, summary.TaxRate))
+				region, class = "PT", 2
+			}
+			boxes[vatSalesBaseBoxes[class]] += summary.NetAmount
+			boxes[vatSalesTaxBoxes[class]] += summary.TaxAmount
+
+			key := fmt.Sprintf("%s|%.2f", region, summary.TaxRate)
+			totals, ok := rates[key]
+			if !ok {
+				totals = &models.VATRateTotalsDTO{Region: region, Rate: summary.TaxRate}
+				rates[key] = totals
+			}
+			totals.NetAmount += summary.NetAmount
+			totals.TaxAmount += summary.TaxAmount
+		}
+	}
+
+	for _, month := range months {
+		if !imported[month] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: sem SAF-T de faturação importado", month))
+		}
+	}
+
+	result.SalesByRate = make([]models.VATRateTotalsDTO, 0, len(rates))
+	for _, totals := range rates {
+		totals.NetAmount = roundAmount(totals.NetAmount)
+		totals.TaxAmount = roundAmount(totals.TaxAmount)
+		result.SalesByRate = append(result.SalesByRate, *totals)
+	}
+	sort.Slice(result.SalesByRate, func(i, j int) bool {
+		if result.SalesByRate[i].Region != result.SalesByRate[j].Region {
+			return result.SalesByRate[i].Region < result.SalesByRate[j].Region
+		}
+		return result.SalesByRate[i].Rate < result.SalesByRate[j].Rate
+	})
+	return nil
+}
+
+// addPurchases soma o IVA dedutível das faturas de compra do e-Fatura por categoria
+func (s *VATReturnService) addPurchases(companyID uint, months []string, boxes map[int]float64, result *models.VATReturnDTO) error {
+	var invoices []models.PurchaseInvoice
+	if err := config.DB.Where("company_id = ? AND fiscal_period IN ?", companyID, months).Find(&invoices).Error; err != nil {
+		return errors.New("erro ao obter faturas de compra")
+	}
+
+	unmatched, unknownRate := 0, 0
+	for _, invoice := range invoices {
+		if strings.Contains(normalizeVATText(invoice.Situation), "anulad") {
+			continue
+		}
+		result.PurchaseInvoices++
+		if invoice.MatchStatus != models.PurchaseInvoiceMatched {
+			unmatched++
+		}
+
+		vat := invoice.VATAmount
+		if strings.Contains(normalizeVATText(invoice.DocumentType), "credito") {
+			vat = -math.Abs(vat)
+		}
+		deductible := vat * purchaseDeductiblePercent(&invoice) / 100
+		result.NonDeductibleVAT += vat - deductible
+
+		switch invoice.ExpenseCategory {
+		case models.PurchaseExpenseAssets:
+			boxes[20] += deductible
+		case models.PurchaseExpenseInventory:
+			class := 2
+			if invoice.NetAmount != 0 {
+				if _, rateClass, ok := classifyVATRate("", math.Abs(invoice.VATAmount/invoice.NetAmount)*100); ok {
+					class = rateClass
+				} else {
+					unknownRate++
+				}
+			}
+			boxes[vatInventoryBoxes[class]] += deductible
+		default:
+			boxes[24] += deductible
+		}
+	}
+
+	if unmatched > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d faturas de compra sem documento do cliente", unmatched))
+	}
+	if unknownRate > 0 {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("%d faturas de existências com taxas mistas ou desconhecidas, declaradas na taxa normal", unknownRate))
+	}
+	return nil
+}
+
+// vatExemptSalesBox devolve o campo de uma venda isenta pelo NIF do cliente: 7 se for de outro
+// Estado-membro (transmissões intracomunitárias), 8 se for de fora da UE (exportações, isentas com
+// direito à dedução) e 9 nos restantes casos (clientes nacionais)
+func vatExemptSalesBox(customerTaxID string) int {
+	taxID := strings.ToUpper(strings.ReplaceAll(customerTaxID, " ", ""))
+	match := foreignVATPrefix.FindStringSubmatch(taxID)
+	switch {
+	case match == nil || match[1] == "PT":
+		return 9
+	case euVATPrefixes[match[1]]:
+		return 7
+	}
+	return 8
+}
+
+// vatReturnPeriod converte AAAA-MM ou AAAA-Tn no intervalo [início, fim) do período
+func vatReturnPeriod(period, frequency string) (time.Time, time.Time, error) {
+	if match := vatMonthPattern.FindStringSubmatch(period); match != nil {
+		if frequency != "mensal" {
+			return time.Time{}, time.Time{}, errors.New("a empresa entrega a declaração trimestralmente (use AAAA-Tn)")
+		}
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	if match := vatQuarterPattern.FindStringSubmatch(period); match != nil {
+		if frequency != "trimestral" {
+			return time.Time{}, time.Time{}, errors.New("a empresa entrega a declaração mensalmente (use AAAA-MM)")
+		}
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		start := time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, 0), nil
+	}
+	return time.Time{}, time.Time{}, errors.New("período inválido (use AAAA-MM ou AAAA-Tn)")
+}
+
+// classifyVATRate devolve a região e o escalão (0 reduzida, 1 intermédia, 2 normal) de uma taxa.
+// Sem região indicada, procura a taxa nas três regiões (as taxas não se repetem entre regiões).
+func classifyVATRate(region string, rate float64) (string, int, bool) {
+	regions := []string{"PT", "PT-AC", "PT-MA"}
+	if _, ok := vatRegionRates[region]; ok {
+		regions = []string{region}
+	}
+	for _, candidate := range regions {
+		for class, value := range vatRegionRates[candidate] {
+			if math.Abs(rate-value) < 0.5 {
+				return candidate, class, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// purchaseDeductiblePercent devolve a percentagem de IVA dedutível de uma fatura de compra.
+// Sem indicação do contabilista, as despesas de alojamento e restauração não são dedutíveis (art. 21.º CIVA).
+func purchaseDeductiblePercent(invoice *models.PurchaseInvoice) float64 {
+	if invoice.DeductiblePercent != nil {
+		return *invoice.DeductiblePercent
+	}
+	sector := normalizeVATText(invoice.Sector)
+	if strings.Contains(sector, "alojamento") || strings.Contains(sector, "restauracao") {
+		return 0
+	}
+	return 100
+}
+
+func normalizeVATText(value string) string {
+	return strings.ToLower(utils.RemoveAccents(value))
+}
+
+func formatXMLAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// Campos do quadro 06 pela ordem do modelo oficial: operações (1 a 24), regularizações (40 e 41),
+// excesso do período anterior (61) e apuramento (90 a 94)
+var vatReturnXMLFields = []int{
+	1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24,
+	40, 41, 61, 90, 91, 92, 93, 94,
+}
+
+// buildVATReturnXML monta o XML da declaração periódica a partir da declaração calculada
+func buildVATReturnXML(result *models.VATReturnDTO) ([]byte, error) {
+	year, period, err := vatReturnXMLPeriod(result.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[int]float64)
+	for _, box := range result.Boxes {
+		values[box.Number] = box.Value
+	}
+	values[61] = result.CarriedCredit
+	for _, number := range []int{1, 3, 5, 7, 8, 9, 10, 12, 14, 15, 16, 18} {
+		values[90] += values[number]
+	}
+	for _, number := range []int{2, 4, 6, 11, 13, 17, 19, 41} {
+		values[91] += values[number]
+	}
+	for _, number := range []int{20, 21, 22, 23, 24, 40, 61} {
+		values[92] += values[number]
+	}
+	if balance := roundAmount(values[91] - values[92]); balance >= 0 {
+		values[93] = balance
+	} else {
+		values[94] = -balance
+	}
+
+	doc := vatReturnXML{
+		Version: "1",
+		Header: vatReturnXMLHeader{
+			Quadro01: vatReturnXMLQuadro01{NIF: result.NIF},
+			Quadro02: vatReturnXMLQuadro02{Year: year, Period: period},
+			Quadro05: vatReturnXMLQuadro05{Frequency: strings.ToUpper(result.Frequency[:1])},
+		},
+	}
+	for _, number := range vatReturnXMLFields {
+		doc.Header.Quadro06.Fields = append(doc.Header.Quadro06.Fields, vatReturnXMLField{
+			XMLName: xml.Name{Local: fmt.Sprintf("Campo%d", number)},
+			Value:   formatXMLAmount(roundAmount(values[number])),
+		})
+	}
+
+	content, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.New("erro ao gerar XML")
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+// vatReturnXMLPeriod converte AAAA-MM em (AAAA, MM) e AAAA-Tn no mês final do trimestre com T (03T, 06T, ...),
+// como no quadro 02 da declaração
+func vatReturnXMLPeriod(period string) (string, string, error) {
+	if match := vatMonthPattern.FindStringSubmatch(period); match != nil {
+		return match[1], match[2], nil
+	}
+	if match := vatQuarterPattern.FindStringSubmatch(period); match != nil {
+		quarter, _ := strconv.Atoi(match[2])
+		return match[1], fmt.Sprintf("%02dT", quarter*3), nil
+	}
+	return "", "", errors.New("período inválido (use AAAA-MM ou AAAA-Tn)")
+}
+
+// vatReturnXML segue a estrutura da declaração periódica de IVA (rosto e quadros)
+type vatReturnXML struct {
+	XMLName xml.Name           `xml:"DPIVA"`
+	Version string             `xml:"versao,attr"`
+	Header  vatReturnXMLHeader `xml:"Rosto"`
+}
+
+type vatReturnXMLHeader struct {
+	Quadro01 vatReturnXMLQuadro01 `xml:"Quadro01"` // Número de identificação fiscal
+	Quadro02 vatReturnXMLQuadro02 `xml:"Quadro02"` // Período a que respeita a declaração
+	Quadro05 vatReturnXMLQuadro05 `xml:"Quadro05"` // Periodicidade
+	Quadro06 vatReturnXMLQuadro06 `xml:"Quadro06"` // Apuramento do imposto
+}
+
+type vatReturnXMLQuadro01 struct {
+	NIF string `xml:"NIF"`
+}
+
+type vatReturnXMLQuadro02 struct {
+	Year   string `xml:"Ano"`
+	Period string `xml:"Periodo"` // MM ou 03T, 06T, 09T, 12T
+}
+
+type vatReturnXMLQuadro05 struct {
+	Frequency string `xml:"Periodicidade"` // M ou T
+}
+
+type vatReturnXMLQuadro06 struct {
+	Fields []vatReturnXMLField
+}
+
+// vatReturnXMLField é um campo do quadro 06; o nome do elemento é o código do campo (Campo1, Campo61, ...)
+type vatReturnXMLField struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"RVContabilidadeBack/models"
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestVATExemptSalesBox(t *testing.T) {
+	tests := []struct {
+		taxID string
+		want  int
+	}{
+		{"ESB12345678", 7},
+		{"DE 123456789", 7},
+		{"EL123456789", 7},
+		{"XI123456789", 7},
+		{"US123456789", 8},
+		{"GB123456789", 8},
+		{"CH123456789", 8},
+		{"PT509442013", 9},
+		{"509442013", 9},
+		{"", 9},
+	}
+
+	for _, tt := range tests {
+		if got := vatExemptSalesBox(tt.taxID); got != tt.want {
+			t.Errorf("vatExemptSalesBox(%q): obtido campo %d, esperado %d", tt.taxID, got, tt.want)
+		}
+	}
+}
+
+// dpIVADocument descreve a estrutura esperada da declaração periódica, para validar o XML gerado
+type dpIVADocument struct {
+	XMLName xml.Name `xml:"DPIVA"`
+	Version string   `xml:"versao,attr"`
+	Rosto   struct {
+		Quadro01 struct {
+			NIF string `xml:"NIF"`
+		} `xml:"Quadro01"`
+		Quadro02 struct {
+			Ano     string `xml:"Ano"`
+			Periodo string `xml:"Periodo"`
+		} `xml:"Quadro02"`
+		Quadro05 struct {
+			Periodicidade string `xml:"Periodicidade"`
+		} `xml:"Quadro05"`
+		Quadro06 struct {
+			Fields []struct {
+				XMLName xml.Name
+				Value   string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"Quadro06"`
+	} `xml:"Rosto"`
+}
+
+func TestBuildVATReturnXMLFollowsDeclarationStructure(t *testing.T) {
+	result := &models.VATReturnDTO{
+		NIF:           "509442013",
+		Period:        "2025-T1",
+		Frequency:     "trimestral",
+		CarriedCredit: 50,
+	}
+	for number := 1; number <= 24; number++ {
+		result.Boxes = append(result.Boxes, models.VATReturnBoxDTO{Number: number})
+	}
+	result.Boxes[0].Value = 1000   // campo 1
+	result.Boxes[1].Value = 60     // campo 2
+	result.Boxes[2].Value = 2000   // campo 3
+	result.Boxes[3].Value = 460    // campo 4
+	result.Boxes[7].Value = 300    // campo 8
+	result.Boxes[23].Value = 120.5 // campo 24
+
+	content, err := buildVATReturnXML(result)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte(xml.Header)) {
+		t.Fatalf("XML sem declaração inicial")
+	}
+
+	var doc dpIVADocument
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = true
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatalf("XML inválido: %v", err)
+	}
+
+	if doc.Version != "1" || doc.Rosto.Quadro01.NIF != "509442013" {
+		t.Errorf("rosto: versão %q, NIF %q", doc.Version, doc.Rosto.Quadro01.NIF)
+	}
+	if doc.Rosto.Quadro02.Ano != "2025" || doc.Rosto.Quadro02.Periodo != "03T" || doc.Rosto.Quadro05.Periodicidade != "T" {
+		t.Errorf("período: %+v, periodicidade %q", doc.Rosto.Quadro02, doc.Rosto.Quadro05.Periodicidade)
+	}
+
+	amount := regexp.MustCompile(`^-?\d+\.\d{2}$`)
+	values := make(map[int]string)
+	order := []int{}
+	for _, field := range doc.Rosto.Quadro06.Fields {
+		code, ok := strings.CutPrefix(field.XMLName.Local, "Campo")
+		number, err := strconv.Atoi(code)
+		if !ok || err != nil {
+			t.Fatalf("elemento inesperado no quadro 06: %s", field.XMLName.Local)
+		}
+		if !amount.MatchString(field.Value) {
+			t.Errorf("campo %d com valor mal formatado: %q", number, field.Value)
+		}
+		values[number] = field.Value
+		order = append(order, number)
+	}
+
+	if len(order) != len(vatReturnXMLFields) {
+		t.Fatalf("quadro 06 com %d campos, esperados %d", len(order), len(vatReturnXMLFields))
+	}
+	for i, number := range vatReturnXMLFields {
+		if order[i] != number {
+			t.Fatalf("campo na posição %d: obtido %d, esperado %d", i, order[i], number)
+		}
+	}
+
+	want := map[int]string{
+		1: "1000.00", 2: "60.00", 3: "2000.00", 4: "460.00", 8: "300.00", 24: "120.50",
+		61: "50.00",
+		90: "3300.00", // 1 + 3 + 8
+		91: "520.00",  // 2 + 4
+		92: "170.50",  // 24 + 61
+		93: "349.50",
+		94: "0.00",
+	}
+	for number, value := range want {
+		if values[number] != value {
+			t.Errorf("campo %d: obtido %s, esperado %s", number, values[number], value)
+		}
+	}
+}
+
+func TestVATReturnXMLPeriod(t *testing.T) {
+	tests := []struct {
+		period, year, want string
+	}{
+		{"2025-03", "2025", "03"},
+		{"2025-12", "2025", "12"},
+		{"2025-T1", "2025", "03T"},
+		{"2025-T4", "2025", "12T"},
+	}
+
+	for _, tt := range tests {
+		year, period, err := vatReturnXMLPeriod(tt.period)
+		if err != nil || year != tt.year || period != tt.want {
+			t.Errorf("vatReturnXMLPeriod(%q): obtido %q %q (%v), esperado %q %q", tt.period, year, period, err, tt.year, tt.want)
+		}
+	}
+	if _, _, err := vatReturnXMLPeriod("2025-T5"); err == nil {
+		t.Errorf("vatReturnXMLPeriod(\"2025-T5\"): esperado erro")
+	}
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BankStatementLine é um movimento lido de um extrato bancário
+type BankStatementLine struct {
+	BookingDate      time.Time
+	ValueDate        time.Time
+	Amount           float64 // Positivo para créditos, negativo para débitos
+	Currency         string
+	Description      string
+	Counterparty     string
+	CounterpartyIBAN string
+	Reference        string // Referência do banco; vazio se o formato não a tiver
+	Balance          *float64
+}
+
+// BankStatement é o conteúdo de um extrato bancário
+type BankStatement struct {
+	IBAN           string
+	Currency       string
+	OpeningBalance *float64
+	ClosingBalance *float64
+	Lines          []BankStatementLine
+}
+
+// BankStatementParser lê um formato de extrato bancário.
+// Detect recebe o início do ficheiro e indica se o parser o reconhece.
+type BankStatementParser interface {
+	Format() string
+	Detect(head []byte) bool
+	Parse(data []byte) (*BankStatement, error)
+}
+
+var (
+	bankParsersMu sync.Mutex
+	bankParsers   []BankStatementParser
+)
+
+func init() {
+	RegisterBankStatementParser(camtParser{})
+	RegisterBankStatementParser(ofxParser{})
+	for _, profile := range bankCSVProfiles {
+		RegisterBankStatementParser(profile)
+	}
+}
+
+// RegisterBankStatementParser adiciona um parser; os registados primeiro têm prioridade na deteção
+func RegisterBankStatementParser(parser BankStatementParser) {
+	bankParsersMu.Lock()
+	defer bankParsersMu.Unlock()
+	bankParsers = append(bankParsers, parser)
+}
+
+// BankStatementFormats devolve os formatos suportados
+func BankStatementFormats() []string {
+	bankParsersMu.Lock()
+	defer bankParsersMu.Unlock()
+
+	formats := make([]string, 0, len(bankParsers))
+	for _, parser := range bankParsers {
+		formats = append(formats, parser.Format())
+	}
+	return formats
+}
+
+// ParseBankStatement lê um extrato no formato indicado ou, se vazio, deteta o formato pelo conteúdo.
+// bankName (ex.: Company.BankName) escolhe o CSV do banco quando o ficheiro não o identifica.
+func ParseBankStatement(data []byte, format, bankName string) (*BankStatement, string, error) {
+	bankParsersMu.Lock()
+	parsers := append([]BankStatementParser(nil), bankParsers...)
+	bankParsersMu.Unlock()
+
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+
+	candidates := []BankStatementParser{}
+	if format != "" {
+		for _, parser := range parsers {
+			if parser.Format() == format {
+				candidates = append(candidates, parser)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, "", fmt.Errorf("formato de extrato não suportado: %s", format)
+		}
+	} else {
+		hinted := BankCSVFormatForBank(bankName)
+		for _, parser := range parsers {
+			// O CSV do banco da empresa é tentado antes do genérico
+			if parser.Format() == "csv" && hinted != "" {
+				for _, other := range parsers {
+					if other.Format() == hinted && !other.Detect(head) {
+						candidates = append(candidates, other)
+					}
+				}
+			}
+			if parser.Detect(head) {
+				candidates = append(candidates, parser)
+			}
+		}
+	}
+
+	var firstErr error
+	for _, parser := range candidates {
+		statement, err := parser.Parse(data)
+		if err == nil && len(statement.Lines) == 0 {
+			err = errors.New("extrato sem movimentos")
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		assignDerivedReferences(statement)
+		return statement, parser.Format(), nil
+	}
+
+	if firstErr != nil {
+		return nil, "", firstErr
+	}
+	return nil, "", errors.New("formato de extrato não reconhecido (suportados: CAMT.053, OFX e CSV dos bancos portugueses)")
+}
+
+// assignDerivedReferences gera uma referência estável para movimentos sem referência do banco,
+// para que reimportar o mesmo extrato não duplique movimentos
+func assignDerivedReferences(statement *BankStatement) {
+	seen := make(map[string]int)
+	for i := range statement.Lines {
+		line := &statement.Lines[i]
+		if line.Reference != "" {
+			continue
+		}
+
+		balance := ""
+		if line.Balance != nil {
+			balance = fmt.Sprintf("%.2f", *line.Balance)
+		}
+		key := fmt.Sprintf("%s|%s|%.2f|%s|%s", line.BookingDate.Format("2006-01-02"), line.ValueDate.Format("2006-01-02"),
+			line.Amount, strings.Join(strings.Fields(strings.ToUpper(line.Description)), " "), balance)
+
+		// Movimentos iguais no mesmo dia distinguem-se pela ordem no extrato
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+		line.Reference = "auto-" + hex.EncodeToString(sum[:12])
+	}
+}
+
+// containsFold indica se o início do ficheiro contém o texto, ignorando maiúsculas
+func containsFold(head []byte, value string) bool {
+	return bytes.Contains(bytes.ToLower(head), []byte(strings.ToLower(value)))
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+)
+
+// camtParser lê extratos ISO 20022 CAMT.053 (BkToCstmrStmt)
+type camtParser struct{}
+
+type camtDocument struct {
+	Statements []struct {
+		Account struct {
+			IBAN     string `xml:"Id>IBAN"`
+			Currency string `xml:"Ccy"`
+		} `xml:"Acct"`
+		Balances []struct {
+			Code    string     `xml:"Tp>CdOrPrtry>Cd"`
+			Amount  camtAmount `xml:"Amt"`
+			CdtDbt  string     `xml:"CdtDbtInd"`
+			Date    string     `xml:"Dt>Dt"`
+			Instant string     `xml:"Dt>DtTm"`
+		} `xml:"Bal"`
+		Entries []struct {
+			Amount         camtAmount `xml:"Amt"`
+			CdtDbt         string     `xml:"CdtDbtInd"`
+			Status         string     `xml:"Sts"`
+			BookingDate    string     `xml:"BookgDt>Dt"`
+			BookingInstant string     `xml:"BookgDt>DtTm"`
+			ValueDate      string     `xml:"ValDt>Dt"`
+			ValueInstant   string     `xml:"ValDt>DtTm"`
+			ServicerRef    string     `xml:"AcctSvcrRef"`
+			AdditionalInfo string     `xml:"AddtlNtryInf"`
+			Details        []struct {
+				AccountServicerRef string   `xml:"Refs>AcctSvcrRef"`
+				EndToEndID         string   `xml:"Refs>EndToEndId"`
+				Unstructured       []string `xml:"RmtInf>Ustrd"`
+				DebtorName         string   `xml:"RltdPties>Dbtr>Nm"`
+				DebtorIBAN         string   `xml:"RltdPties>DbtrAcct>Id>IBAN"`
+				CreditorName       string   `xml:"RltdPties>Cdtr>Nm"`
+				CreditorIBAN       string   `xml:"RltdPties>CdtrAcct>Id>IBAN"`
+			} `xml:"NtryDtls>TxDtls"`
+		} `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtAmount struct {
+	Value    float64 `xml:",chardata"`
+	Currency string  `xml:"Ccy,attr"`
+}
+
+func (camtParser) Format() string {
+	return "camt053"
+}
+
+func (camtParser) Detect(head []byte) bool {
+	return bytes.Contains(head, []byte("BkToCstmrStmt")) || bytes.Contains(head, []byte("camt.053"))
+}
+
+func (camtParser) Parse(data []byte) (*BankStatement, error) {
+	var document camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = saftCharsetReader
+	if err := decoder.Decode(&document); err != nil {
+		return nil, errors.New("CAMT.053 inválido: " + err.Error())
+	}
+	if len(document.Statements) == 0 {
+		return nil, errors.New("CAMT.053 sem extratos (Stmt)")
+	}
+
+	statement := &BankStatement{}
+	for _, stmt := range document.Statements {
+		if statement.IBAN == "" {
+			statement.IBAN = NormalizeIBAN(stmt.Account.IBAN)
+			statement.Currency = stmt.Account.Currency
+		} else if NormalizeIBAN(stmt.Account.IBAN) != statement.IBAN {
+			return nil, errors.New("o ficheiro tem extratos de várias contas; importe uma conta de cada vez")
+		}
+
+		for _, balance := range stmt.Balances {
+			amount := balance.Amount.Value
+			if balance.CdtDbt == "DBIT" {
+				amount = -amount
+			}
+			switch balance.Code {
+			case "OPBD", "PRCD":
+				if statement.OpeningBalance == nil {
+					statement.OpeningBalance = &amount
+				}
+			case "CLBD":
+				statement.ClosingBalance = &amount
+			}
+		}
+
+		for _, entry := range stmt.Entries {
+			// Movimentos pendentes (PDNG) ainda podem ser alterados pelo banco
+			if entry.Status == "PDNG" {
+				continue
+			}
+
+			line := BankStatementLine{
+				Amount:      entry.Amount.Value,
+				Currency:    entry.Amount.Currency,
+				Description: entry.AdditionalInfo,
+				Reference:   entry.ServicerRef,
+			}
+			if entry.CdtDbt == "DBIT" {
+				line.Amount = -line.Amount
+			}
+			line.BookingDate = camtDate(entry.BookingDate, entry.BookingInstant)
+			line.ValueDate = camtDate(entry.ValueDate, entry.ValueInstant)
+			if line.BookingDate.IsZero() {
+				line.BookingDate = line.ValueDate
+			}
+			if line.BookingDate.IsZero() {
+				return nil, errors.New("movimento sem data no CAMT.053")
+			}
+
+			if len(entry.Details) > 0 {
+				details := entry.Details[0]
+				if line.Reference == "" {
+					line.Reference = details.AccountServicerRef
+				}
+				if line.Reference == "" && details.EndToEndID != "" && details.EndToEndID != "NOTPROVIDED" {
+					line.Reference = details.EndToEndID
+				}
+				if remittance := strings.TrimSpace(strings.Join(details.Unstructured, " ")); remittance != "" {
+					line.Description = strings.TrimSpace(line.Description + " " + remittance)
+				}
+				// A contraparte de um crédito é o devedor; a de um débito é o credor
+				if line.Amount >= 0 {
+					line.Counterparty, line.CounterpartyIBAN = details.DebtorName, NormalizeIBAN(details.DebtorIBAN)
+				} else {
+					line.Counterparty, line.CounterpartyIBAN = details.CreditorName, NormalizeIBAN(details.CreditorIBAN)
+				}
+			}
+			statement.Lines = append(statement.Lines, line)
+		}
+	}
+	return statement, nil
+}
+
+func camtDate(date, instant string) time.Time {
+	if date != "" {
+		if parsed, err := time.Parse("2006-01-02", date); err == nil {
+			return parsed
+		}
+	}
+	if len(instant) >= 10 {
+		if parsed, err := time.Parse("2006-01-02", instant[:10]); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// bankCSVProfile descreve o CSV exportado pelo homebanking de um banco português.
+// Os nomes das colunas estão normalizados (ver NormalizeCSVHeader); um nome também
+// corresponde a colunas que comecem por ele ("saldo" corresponde a "saldo contabilistico eur").
+type bankCSVProfile struct {
+	format      string
+	bankNames   []string // Palavras do Company.BankName que identificam o banco
+	markers     []string // Textos que identificam o banco no próprio ficheiro
+	date        []string
+	valueDate   []string
+	description []string
+	amount      []string // Montante com sinal
+	debit       []string
+	credit      []string
+	balance     []string
+	reference   []string
+}
+
+var bankCSVProfiles = []bankCSVProfile{
+	{
+		format:      "csv_cgd",
+		bankNames:   []string{"caixa geral", "cgd"},
+		markers:     []string{"caixa geral de dep", "caixadirecta"},
+		date:        []string{"data mov", "data movimento"},
+		valueDate:   []string{"data valor"},
+		description: []string{"descricao"},
+		debit:       []string{"debito"},
+		credit:      []string{"credito"},
+		balance:     []string{"saldo contabilistico", "saldo"},
+	},
+	{
+		format:      "csv_millennium",
+		bankNames:   []string{"millennium", "bcp", "comercial portugues"},
+		markers:     []string{"millennium", "millenniumbcp"},
+		date:        []string{"data lancamento", "data movimento"},
+		valueDate:   []string{"data valor"},
+		description: []string{"descricao"},
+		amount:      []string{"montante", "valor"},
+		balance:     []string{"saldo"},
+	},
+	{
+		format:      "csv_novobanco",
+		bankNames:   []string{"novo banco", "novobanco"},
+		markers:     []string{"novo banco", "novobanco"},
+		date:        []string{"data operacao", "data"},
+		valueDate:   []string{"data valor"},
+		description: []string{"descricao"},
+		debit:       []string{"debito"},
+		credit:      []string{"credito"},
+		balance:     []string{"saldo"},
+	},
+	{
+		format:      "csv_santander",
+		bankNames:   []string{"santander"},
+		markers:     []string{"santander"},
+		date:        []string{"data operacao"},
+		valueDate:   []string{"data valor"},
+		description: []string{"descricao"},
+		amount:      []string{"montante"},
+		balance:     []string{"saldo contabilistico", "saldo"},
+	},
+	{
+		format:      "csv_bpi",
+		bankNames:   []string{"bpi", "portugues de investimento"},
+		markers:     []string{"bpi net", "banco bpi"},
+		date:        []string{"data mov", "data movimento"},
+		valueDate:   []string{"data valor"},
+		description: []string{"descricao do movimento", "descricao"},
+		amount:      []string{"valor"},
+		balance:     []string{"saldo"},
+	},
+	{
+		format:      "csv_credito_agricola",
+		bankNames:   []string{"credito agricola", "caixa agricola"},
+		markers:     []string{"credito agricola"},
+		date:        []string{"data"},
+		valueDate:   []string{"data valor"},
+		description: []string{"descritivo", "descricao"},
+		debit:       []string{"debito"},
+		credit:      []string{"credito"},
+		balance:     []string{"saldo"},
+	},
+	{
+		// Formato genérico para outros bancos, pelo nome das colunas mais comuns
+		format:      "csv",
+		date:        []string{"data mov", "data movimento", "data lancamento", "data operacao", "data"},
+		valueDate:   []string{"data valor"},
+		description: []string{"descricao do movimento", "descricao", "descritivo", "movimento"},
+		amount:      []string{"montante", "valor", "importancia"},
+		debit:       []string{"debito"},
+		credit:      []string{"credito"},
+		balance:     []string{"saldo contabilistico", "saldo"},
+		reference:   []string{"referencia", "n documento", "numero documento"},
+	},
+}
+
+// BankCSVFormatForBank devolve o formato CSV do banco com o nome indicado (ex.: Company.BankName)
+func BankCSVFormatForBank(bankName string) string {
+	name := NormalizeCSVHeader(bankName)
+	if name == "" {
+		return ""
+	}
+	for _, profile := range bankCSVProfiles {
+		for _, bank := range profile.bankNames {
+			if strings.Contains(name, bank) {
+				return profile.format
+			}
+		}
+	}
+	return ""
+}
+
+func (p bankCSVProfile) Format() string {
+	return p.format
+}
+
+func (p bankCSVProfile) Detect(head []byte) bool {
+	if len(p.markers) == 0 {
+		// O genérico aceita qualquer texto com separadores
+		return bytes.ContainsAny(head, ";,") && !bytes.Contains(head, []byte("<"))
+	}
+	for _, marker := range p.markers {
+		if containsFold(head, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p bankCSVProfile) Parse(data []byte) (*BankStatement, error) {
+	reader, err := NewCSVReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Os bancos colocam linhas com dados da conta antes do cabeçalho
+	var columns map[string]int
+	var preamble []string
+	for columns == nil {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, fmt.Errorf("cabeçalho do extrato não encontrado (%s)", p.format)
+		}
+		if err != nil {
+			continue
+		}
+		columns = p.matchHeader(record)
+		if columns == nil {
+			preamble = append(preamble, strings.Join(record, " "))
+		}
+	}
+
+	statement := &BankStatement{Currency: "EUR", IBAN: findIBAN(preamble)}
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("linha %d do extrato inválida: %v", line, err)
+		}
+
+		field := func(key string) string {
+			if i, ok := columns[key]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		// Linhas sem data são rodapés (saldo final, totais...)
+		bookingDate, err := ParsePTDate(field("date"))
+		if err != nil {
+			continue
+		}
+
+		entry := BankStatementLine{
+			BookingDate: bookingDate,
+			ValueDate:   bookingDate,
+			Currency:    statement.Currency,
+			Description: field("description"),
+			Reference:   field("reference"),
+		}
+		if valueDate, err := ParsePTDate(field("value_date")); err == nil {
+			entry.ValueDate = valueDate
+		}
+
+		if _, ok := columns["amount"]; ok {
+			if entry.Amount, err = ParsePTAmount(field("amount")); err != nil {
+				return nil, fmt.Errorf("linha %d do extrato: montante inválido %q", line, field("amount"))
+			}
+		} else {
+			debit, debitErr := parseOptionalAmount(field("debit"))
+			credit, creditErr := parseOptionalAmount(field("credit"))
+			if debitErr != nil || creditErr != nil {
+				return nil, fmt.Errorf("linha %d do extrato: débito/crédito inválido", line)
+			}
+			entry.Amount = math.Abs(credit) - math.Abs(debit)
+		}
+
+		if balance, err := ParsePTAmount(field("balance")); err == nil {
+			entry.Balance = &balance
+		}
+		statement.Lines = append(statement.Lines, entry)
+	}
+	return statement, nil
+}
+
+// matchHeader devolve o índice de cada coluna se a linha for o cabeçalho do extrato
+func (p bankCSVProfile) matchHeader(record []string) map[string]int {
+	// A data-valor é procurada antes da data para "data valor" não ser tomada por "data"
+	keys := []struct {
+		name    string
+		aliases []string
+	}{
+		{"value_date", p.valueDate},
+		{"date", p.date},
+		{"description", p.description},
+		{"debit", p.debit},
+		{"credit", p.credit},
+		{"amount", p.amount},
+		{"balance", p.balance},
+		{"reference", p.reference},
+	}
+
+	columns := make(map[string]int)
+	for i, header := range record {
+		normalized := NormalizeCSVHeader(header)
+		for _, key := range keys {
+			if _, taken := columns[key.name]; taken {
+				continue
+			}
+			if matchesHeaderAlias(normalized, key.aliases) {
+				columns[key.name] = i
+				break
+			}
+		}
+	}
+
+	_, hasDate := columns["date"]
+	_, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasDate || !(hasAmount || (hasDebit && hasCredit)) {
+		return nil
+	}
+	return columns
+}
+
+func matchesHeaderAlias(header string, aliases []string) bool {
+	for _, alias := range aliases {
+		if header == alias || strings.HasPrefix(header, alias+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// findIBAN procura um IBAN português nas linhas antes do cabeçalho
+func findIBAN(lines []string) string {
+	for _, line := range lines {
+		compact := NormalizeIBAN(strings.ReplaceAll(strings.ToUpper(line), ".", ""))
+		if i := strings.Index(compact, "PT50"); i >= 0 && len(compact) >= i+25 {
+			return compact[i : i+25]
+		}
+	}
+	return ""
+}
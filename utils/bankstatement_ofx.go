@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ofxParser lê extratos OFX 1.x (SGML) e 2.x (XML)
+type ofxParser struct{}
+
+var (
+	ofxTransactionPattern = regexp.MustCompile(`(?is)<STMTTRN>(.*?)(?:</STMTTRN>|</BANKTRANLIST>)`)
+	ofxLedgerPattern      = regexp.MustCompile(`(?is)<LEDGERBAL>(.*?)</LEDGERBAL>`)
+)
+
+func (ofxParser) Format() string {
+	return "ofx"
+}
+
+func (ofxParser) Detect(head []byte) bool {
+	return containsFold(head, "OFXHEADER") || containsFold(head, "<OFX>")
+}
+
+func (ofxParser) Parse(data []byte) (*BankStatement, error) {
+	// OFX 1.x declara frequentemente CHARSET:1252
+	if containsFold(data[:min(len(data), 1024)], "CHARSET:1252") {
+		if decoded, err := charmap.Windows1252.NewDecoder().Bytes(data); err == nil {
+			data = decoded
+		}
+	}
+	content := string(data)
+
+	statement := &BankStatement{
+		Currency: ofxTag(content, "CURDEF"),
+	}
+	account := ofxTag(content, "ACCTID")
+	if len(NormalizeIBAN(account)) >= 15 && strings.HasPrefix(strings.ToUpper(account), "PT") {
+		statement.IBAN = NormalizeIBAN(account)
+	}
+	if ledger := ofxLedgerPattern.FindStringSubmatch(content); ledger != nil {
+		if amount, err := ParsePTAmount(ofxTag(ledger[1], "BALAMT")); err == nil {
+			statement.ClosingBalance = &amount
+		}
+	}
+
+	transactions := ofxTransactionPattern.FindAllStringSubmatch(content, -1)
+	if len(transactions) == 0 && !containsFold(data, "<BANKTRANLIST>") {
+		return nil, errors.New("OFX sem lista de movimentos (BANKTRANLIST)")
+	}
+
+	for _, match := range transactions {
+		block := match[1]
+
+		amount, err := ParsePTAmount(ofxTag(block, "TRNAMT"))
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido no movimento %s", ofxTag(block, "FITID"))
+		}
+		posted, err := ofxDate(ofxTag(block, "DTPOSTED"))
+		if err != nil {
+			return nil, fmt.Errorf("data inválida no movimento %s", ofxTag(block, "FITID"))
+		}
+
+		line := BankStatementLine{
+			BookingDate:  posted,
+			ValueDate:    posted,
+			Amount:       amount,
+			Currency:     statement.Currency,
+			Counterparty: ofxTag(block, "NAME"),
+			Reference:    ofxTag(block, "FITID"),
+		}
+		if userDate, err := ofxDate(ofxTag(block, "DTUSER")); err == nil {
+			line.ValueDate = userDate
+		}
+		line.Description = strings.TrimSpace(strings.Join([]string{ofxTag(block, "NAME"), ofxTag(block, "MEMO")}, " "))
+		if line.Reference == "" {
+			line.Reference = ofxTag(block, "REFNUM")
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+	return statement, nil
+}
+
+// ofxTag devolve o valor de um elemento OFX; em SGML os elementos simples não têm fecho
+func ofxTag(content, tag string) string {
+	start := strings.Index(content, "<"+tag+">")
+	if start < 0 {
+		return ""
+	}
+	value := content[start+len(tag)+2:]
+	if end := strings.IndexAny(value, "<\r\n"); end >= 0 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(htmlUnescapeOFX(value))
+}
+
+func htmlUnescapeOFX(value string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", "\"", "&apos;", "'").Replace(value)
+}
+
+// ofxDate lê datas OFX como 20240131, 20240131120000 ou 20240131120000.000[+0:GMT]
+func ofxDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("data inválida")
+	}
+	return time.Parse("20060102", value[:8])
+}
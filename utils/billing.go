@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	billingKeyMu sync.Mutex
+	billingKey   *rsa.PrivateKey
+)
+
+// InvoiceHashMessage monta o texto assinado de um documento de faturação (Despacho n.º 8632/2014):
+// InvoiceDate;SystemEntryDate;InvoiceNo;GrossTotal;Hash do documento anterior da série
+func InvoiceHashMessage(invoiceDate, systemEntryDate time.Time, documentNo string, grossTotal float64, previousHash string) string {
+	return fmt.Sprintf("%s;%s;%s;%.2f;%s",
+		invoiceDate.Format("2006-01-02"),
+		systemEntryDate.Format("2006-01-02T15:04:05"),
+		documentNo,
+		grossTotal,
+		previousHash)
+}
+
+// InvoiceATCUD monta o código único do documento (Decreto-Lei n.º 28/2019): o código de validação
+// da série atribuído pela AT, um hífen e o número sequencial do documento na série
+func InvoiceATCUD(validationCode string, number int) string {
+	return fmt.Sprintf("%s-%d", validationCode, number)
+}
+
+// SignInvoice assina a mensagem com RSA-SHA1 e devolve a assinatura em base64
+func SignInvoice(message string) (string, error) {
+	key, err := getBillingKey()
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha1.Sum([]byte(message))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// InvoiceHashExcerpt devolve os caracteres 1, 11, 21 e 31 da assinatura, impressos nos documentos
+func InvoiceHashExcerpt(hash string) string {
+	excerpt := make([]byte, 0, 4)
+	for _, position := range []int{0, 10, 20, 30} {
+		if position < len(hash) {
+			excerpt = append(excerpt, hash[position])
+		}
+	}
+	return string(excerpt)
+}
+
+// BillingCertificateNumber é o número de certificação do programa atribuído pela AT (BILLING_CERTIFICATE_NUMBER)
+func BillingCertificateNumber() string {
+	if number := os.Getenv("BILLING_CERTIFICATE_NUMBER"); number != "" {
+		return number
+	}
+	return "0000"
+}
+
+// BillingKeyVersion é a versão da chave privada usada nas assinaturas (HashControl)
+func BillingKeyVersion() string {
+	if version := os.Getenv("BILLING_KEY_VERSION"); version != "" {
+		return version
+	}
+	return "1"
+}
+
+// SetBillingPrivateKey substitui a chave de assinatura (por exemplo, lida de um cofre de segredos)
+func SetBillingPrivateKey(key *rsa.PrivateKey) {
+	billingKeyMu.Lock()
+	defer billingKeyMu.Unlock()
+	billingKey = key
+}
+
+// getBillingKey lê a chave privada em PEM (PKCS#1 ou PKCS#8) indicada em BILLING_PRIVATE_KEY_FILE
+func getBillingKey() (*rsa.PrivateKey, error) {
+	billingKeyMu.Lock()
+	defer billingKeyMu.Unlock()
+
+	if billingKey != nil {
+		return billingKey, nil
+	}
+
+	path := os.Getenv("BILLING_PRIVATE_KEY_FILE")
+	if path == "" {
+		return nil, errors.New("chave de assinatura de faturação não configurada")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave de assinatura: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("chave de assinatura inválida")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		billingKey = key
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("chave de assinatura inválida")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("a chave de assinatura tem de ser RSA")
+	}
+	billingKey = key
+	return key, nil
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// Exemplo da especificação técnica da AT para a assinatura dos documentos (Portaria n.º 363/2010)
+const atExampleHash = "mYJEv4iGwLcnQbRD7dPs2uD1mX08XjXIKcGg3GEHmwMhmmGYusffIJjTdSITLX+uujTwzqmL/U5nvt6S9s8ijN3LwkJXsiEpt099e1MET/J8y3+Y1bN+K+YPJQiVmlQS0fXETsOPo8SwUZdBALt0vTo1VhUZKejACcjEYJ9G6nI="
+
+func TestInvoiceHashMessage(t *testing.T) {
+	day := time.Date(2010, 5, 18, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name         string
+		entry        time.Time
+		documentNo   string
+		grossTotal   float64
+		previousHash string
+		want         string
+	}{
+		{
+			name:       "primeiro documento da série",
+			entry:      time.Date(2010, 5, 18, 11, 22, 19, 0, time.UTC),
+			documentNo: "FAC 001/14",
+			grossTotal: 3.12,
+			want:       "2010-05-18;2010-05-18T11:22:19;FAC 001/14;3.12;",
+		},
+		{
+			name:         "documento seguinte encadeado",
+			entry:        time.Date(2010, 5, 18, 15, 43, 25, 0, time.UTC),
+			documentNo:   "FAC 001/15",
+			grossTotal:   25.62,
+			previousHash: atExampleHash,
+			want:         "2010-05-18;2010-05-18T15:43:25;FAC 001/15;25.62;" + atExampleHash,
+		},
+		{
+			name:       "total com duas casas decimais",
+			entry:      time.Date(2010, 5, 18, 9, 5, 3, 0, time.UTC),
+			documentNo: "FT 2025A/1",
+			grossTotal: 100,
+			want:       "2010-05-18;2010-05-18T09:05:03;FT 2025A/1;100.00;",
+		},
+	}
+	for _, tc := range cases {
+		if got := InvoiceHashMessage(day, tc.entry, tc.documentNo, tc.grossTotal, tc.previousHash); got != tc.want {
+			t.Errorf("%s: obtido %q, esperado %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestInvoiceHashExcerpt(t *testing.T) {
+	cases := []struct {
+		hash string
+		want string
+	}{
+		{atExampleHash, "mc2X"},
+		{"b123456789h123456789G123456789U", "bhGU"},
+		{"abcdefghijkl", "ak"},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		if got := InvoiceHashExcerpt(tc.hash); got != tc.want {
+			t.Errorf("InvoiceHashExcerpt(%q) = %q, esperado %q", tc.hash, got, tc.want)
+		}
+	}
+}
+
+func TestInvoiceATCUD(t *testing.T) {
+	cases := []struct {
+		code   string
+		number int
+		want   string
+	}{
+		{"CSDF7T5H", 35, "CSDF7T5H-35"},
+		{"AAJFJMVNTN", 1, "AAJFJMVNTN-1"},
+		{"JJZ3V9WR", 1024, "JJZ3V9WR-1024"},
+	}
+	for _, tc := range cases {
+		if got := InvoiceATCUD(tc.code, tc.number); got != tc.want {
+			t.Errorf("InvoiceATCUD(%q, %d) = %q, esperado %q", tc.code, tc.number, got, tc.want)
+		}
+	}
+}
+
+func TestValidNIF(t *testing.T) {
+	cases := []struct {
+		nif  string
+		want bool
+	}{
+		{"123456789", true},
+		{"999999990", true}, // Consumidor final
+		{"509442013", true},
+		{"500000000", true}, // Dígito de controlo 10 passa a 0
+		{"123456780", false},
+		{"510000000", false},
+		{"12345678", false},
+		{"1234567890", false},
+		{"12345678A", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		if got := ValidNIF(tc.nif); got != tc.want {
+			t.Errorf("ValidNIF(%q) = %v, esperado %v", tc.nif, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ClientImportRecord é uma linha do ficheiro de importação de clientes, com os valores por campo
+type ClientImportRecord struct {
+	Line   int
+	Fields map[string]string
+}
+
+// ClientImportColumn associa um campo de User/Company aos nomes (normalizados) aceites no cabeçalho
+type ClientImportColumn struct {
+	Field    string
+	Aliases  []string
+	Required bool
+}
+
+// ClientImportColumns são as colunas reconhecidas na importação de clientes
+var ClientImportColumns = []ClientImportColumn{
+	{Field: "name", Aliases: []string{"nome", "nome cliente", "nome completo"}, Required: true},
+	{Field: "email", Aliases: []string{"email", "e mail", "correio eletronico"}, Required: true},
+	{Field: "nif", Aliases: []string{"nif", "nif cliente", "contribuinte"}, Required: true},
+	{Field: "company_name", Aliases: []string{"empresa", "nome empresa", "denominacao", "firma"}, Required: true},
+	{Field: "nipc", Aliases: []string{"nipc", "nif empresa"}, Required: true},
+	{Field: "legal_form", Aliases: []string{"forma juridica", "natureza juridica"}, Required: true},
+	{Field: "username", Aliases: []string{"username", "utilizador"}},
+	{Field: "phone", Aliases: []string{"telemovel", "telefone", "contacto"}},
+	{Field: "fiscal_address", Aliases: []string{"morada", "morada fiscal"}},
+	{Field: "fiscal_postal_code", Aliases: []string{"codigo postal", "cp"}},
+	{Field: "fiscal_city", Aliases: []string{"localidade", "cidade"}},
+	{Field: "trade_name", Aliases: []string{"nome comercial"}},
+	{Field: "cae", Aliases: []string{"cae"}},
+	{Field: "vat_regime", Aliases: []string{"regime iva", "regime de iva"}},
+	{Field: "accounting_regime", Aliases: []string{"regime contabilidade", "regime contabilistico"}},
+	{Field: "company_address", Aliases: []string{"morada empresa", "sede"}},
+	{Field: "company_postal_code", Aliases: []string{"codigo postal empresa"}},
+	{Field: "company_city", Aliases: []string{"localidade empresa"}},
+	{Field: "bank_name", Aliases: []string{"banco"}},
+	{Field: "iban", Aliases: []string{"iban"}},
+	{Field: "billing_software", Aliases: []string{"software faturacao", "software de faturacao"}},
+}
+
+// ReadClientImportFile lê um ficheiro CSV ou XLSX (primeira folha) de clientes a importar.
+// O formato é escolhido pela extensão do nome do ficheiro.
+func ReadClientImportFile(r io.Reader, filename string) ([]ClientImportRecord, error) {
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader, err := NewCSVReader(r)
+		if err != nil {
+			return nil, err
+		}
+		if rows, err = reader.ReadAll(); err != nil {
+			return nil, errors.New("CSV inválido: " + err.Error())
+		}
+	case ".xlsx":
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, errors.New("XLSX inválido")
+		}
+		defer file.Close()
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("ficheiro vazio")
+		}
+		if rows, err = file.GetRows(sheets[0]); err != nil {
+			return nil, errors.New("XLSX inválido")
+		}
+	default:
+		return nil, errors.New("formato não suportado (use CSV ou XLSX)")
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("ficheiro vazio")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		normalized := NormalizeCSVHeader(name)
+		for _, column := range ClientImportColumns {
+			if _, found := columns[column.Field]; found {
+				continue
+			}
+			for _, alias := range column.Aliases {
+				if normalized == alias {
+					columns[column.Field] = i
+					break
+				}
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, column := range ClientImportColumns {
+		if _, found := columns[column.Field]; column.Required && !found {
+			missing = append(missing, column.Field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.New("formato não reconhecido: faltam as colunas " + strings.Join(missing, ", "))
+	}
+
+	records := []ClientImportRecord{}
+	for i, row := range rows[1:] {
+		if isBlankRecord(row) {
+			continue
+		}
+		record := ClientImportRecord{Line: i + 2, Fields: make(map[string]string)}
+		for field, index := range columns {
+			if index < len(row) {
+				record.Fields[field] = strings.TrimSpace(row[index])
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
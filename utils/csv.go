@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// NewCSVReader prepara a leitura de um CSV exportado por portais e bancos portugueses:
+// remove o BOM, converte Windows-1252 para UTF-8 e deteta o separador (";" ou ",")
+func NewCSVReader(r io.Reader) (*csv.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		if data, err = charmap.Windows1252.NewDecoder().Bytes(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("ficheiro vazio")
+	}
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = ','
+	if bytes.Count(firstLine, []byte(";")) >= bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	return reader, nil
+}
+
+// NormalizeCSVHeader normaliza o nome de uma coluna para comparação
+// ("Nº Fatura / ATCUD" -> "n fatura atcud")
+func NormalizeCSVHeader(header string) string {
+	header = strings.ToLower(RemoveAccents(header))
+	fields := strings.FieldsFunc(header, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9')
+	})
+	return strings.Join(fields, " ")
+}
+
+// ParsePTAmount converte valores como "1.234,56 €", "-12,30" ou "1234.56" em float64
+func ParsePTAmount(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	value = strings.NewReplacer("€", "", "EUR", "", " ", "", " ", "").Replace(value)
+	if value == "" {
+		return 0, errors.New("valor vazio")
+	}
+
+	// O separador que aparece por último é o decimal ("1.234,56" ou "1,234.56")
+	if strings.LastIndex(value, ",") > strings.LastIndex(value, ".") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	} else if !strings.Contains(value, ",") && isPTThousands(value) {
+		// Sem vírgula, "1.234" e "1.234.567" usam o ponto como separador de milhares
+		value = strings.ReplaceAll(value, ".", "")
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// isPTThousands indica se os pontos do valor separam grupos de exatamente três dígitos
+// ("1.234", "12.345.678"), ou seja, se são separadores de milhares e não decimais ("0.125" é decimal)
+func isPTThousands(value string) bool {
+	groups := strings.Split(strings.TrimLeft(value, "+-"), ".")
+	if len(groups) < 2 || groups[0] == "" || groups[0][0] == '0' || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParsePTDate aceita datas nos formatos AAAA-MM-DD, DD/MM/AAAA, DD-MM-AAAA e AAAAMMDD
+func ParsePTDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > 10 {
+		value = value[:10]
+	}
+	for _, layout := range []string{"2006-01-02", "02/01/2006", "02-01-2006", "20060102", "02.01.2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, errors.New("data inválida")
+}
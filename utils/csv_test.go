@@ -0,0 +1,37 @@
+package utils
+
+import "testing"
+
+func TestParsePTAmount(t *testing.T) {
+	cases := []struct {
+		input string
+		want  float64
+	}{
+		{"1.234,56 €", 1234.56},
+		{"-12,30", -12.30},
+		{"1234.56", 1234.56},
+		{"1,234.56", 1234.56},
+		{"1.234", 1234},
+		{"-1.234", -1234},
+		{"12.345.678", 12345678},
+		{"12.345.678,90", 12345678.90},
+		{"1.5", 1.5},
+		{"12.50", 12.50},
+		{"0.125", 0.125},
+		{"1 000,00 EUR", 1000},
+	}
+	for _, tc := range cases {
+		got, err := ParsePTAmount(tc.input)
+		if err != nil {
+			t.Errorf("ParsePTAmount(%q): erro inesperado %v", tc.input, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParsePTAmount(%q) = %v, esperado %v", tc.input, got, tc.want)
+		}
+	}
+
+	if _, err := ParsePTAmount(""); err == nil {
+		t.Error("ParsePTAmount(\"\"): esperado erro")
+	}
+}
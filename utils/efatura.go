@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// EFaturaRow é uma fatura de compra do CSV exportado do portal e-Fatura
+type EFaturaRow struct {
+	Line         int
+	SupplierNIF  string
+	SupplierName string
+	DocumentNo   string
+	ATCUD        string
+	DocumentType string
+	IssueDate    time.Time
+	NetAmount    float64
+	VATAmount    float64
+	TotalAmount  float64
+	Situation    string
+	Sector       string
+}
+
+// EFaturaRowError é uma linha do CSV que não pôde ser lida
+type EFaturaRowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Nomes (normalizados) das colunas do CSV do e-Fatura, incluindo variantes de exportações antigas
+var eFaturaColumns = map[string][]string{
+	"supplier":      {"emitente", "comerciante"},
+	"supplier_nif":  {"nif emitente", "nif do emitente", "nif comerciante"},
+	"supplier_name": {"nome emitente", "nome do emitente", "designacao emitente"},
+	"document":      {"n fatura atcud", "no fatura atcud", "numero fatura atcud", "n fatura", "numero fatura", "numero documento", "n documento"},
+	"atcud":         {"atcud"},
+	"type":          {"tipo", "tipo documento"},
+	"date":          {"data emissao", "data", "data documento"},
+	"total":         {"total", "valor total", "total documento"},
+	"vat":           {"iva", "valor iva", "total iva"},
+	"net":           {"base tributavel", "valor tributavel", "base"},
+	"situation":     {"situacao", "estado"},
+	"sector":        {"setor", "sector"},
+}
+
+// ParseEFaturaCSV lê o CSV de faturas de compra exportado do e-Fatura.
+// As linhas inválidas são devolvidas em separado para não bloquear a importação.
+func ParseEFaturaCSV(r io.Reader) ([]EFaturaRow, []EFaturaRowError, error) {
+	reader, err := NewCSVReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("ficheiro sem cabeçalho")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		normalized := NormalizeCSVHeader(name)
+		for key, aliases := range eFaturaColumns {
+			if _, found := columns[key]; found {
+				continue
+			}
+			for _, alias := range aliases {
+				if normalized == alias {
+					columns[key] = i
+					break
+				}
+			}
+		}
+	}
+
+	_, hasSupplier := columns["supplier"]
+	_, hasSupplierNIF := columns["supplier_nif"]
+	if _, ok := columns["document"]; !ok || (!hasSupplier && !hasSupplierNIF) {
+		return nil, nil, errors.New("formato não reconhecido: esperadas as colunas do e-Fatura (Emitente, Nº Fatura / ATCUD, Data Emissão, Total)")
+	}
+
+	rows := []EFaturaRow{}
+	rowErrors := []EFaturaRowError{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		line++
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, EFaturaRowError{Line: line, Message: err.Error()})
+			continue
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row, err := parseEFaturaRecord(record, columns)
+		if err != nil {
+			rowErrors = append(rowErrors, EFaturaRowError{Line: line, Message: err.Error()})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, *row)
+	}
+	return rows, rowErrors, nil
+}
+
+func parseEFaturaRecord(record []string, columns map[string]int) (*EFaturaRow, error) {
+	field := func(key string) string {
+		if i, ok := columns[key]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row := EFaturaRow{
+		SupplierNIF:  field("supplier_nif"),
+		SupplierName: field("supplier_name"),
+		ATCUD:        field("atcud"),
+		DocumentType: field("type"),
+		Situation:    field("situation"),
+		Sector:       field("sector"),
+	}
+
+	// "Emitente" vem como "509999999 - Empresa, Lda"
+	if supplier := field("supplier"); supplier != "" {
+		nif, name, found := strings.Cut(supplier, " - ")
+		if found {
+			row.SupplierNIF, row.SupplierName = strings.TrimSpace(nif), strings.TrimSpace(name)
+		} else if row.SupplierNIF == "" {
+			row.SupplierNIF = supplier
+		}
+	}
+	row.SupplierNIF = strings.TrimPrefix(strings.ToUpper(strings.ReplaceAll(row.SupplierNIF, " ", "")), "PT")
+	if row.SupplierNIF == "" {
+		return nil, errors.New("NIF do emitente em falta")
+	}
+
+	// "Nº Fatura / ATCUD" vem como "FT 2024A/123 / JJ4T9RRF-123"
+	row.DocumentNo = field("document")
+	if i := strings.LastIndex(row.DocumentNo, " / "); i >= 0 {
+		if row.ATCUD == "" {
+			row.ATCUD = strings.TrimSpace(row.DocumentNo[i+3:])
+		}
+		row.DocumentNo = strings.TrimSpace(row.DocumentNo[:i])
+	}
+	if row.ATCUD == "0" {
+		row.ATCUD = ""
+	}
+	if row.DocumentNo == "" {
+		return nil, errors.New("número da fatura em falta")
+	}
+
+	date, err := ParsePTDate(field("date"))
+	if err != nil {
+		return nil, fmt.Errorf("data de emissão inválida: %q", field("date"))
+	}
+	row.IssueDate = date
+
+	if row.TotalAmount, err = parseOptionalAmount(field("total")); err != nil {
+		return nil, fmt.Errorf("total inválido: %q", field("total"))
+	}
+	if row.VATAmount, err = parseOptionalAmount(field("vat")); err != nil {
+		return nil, fmt.Errorf("IVA inválido: %q", field("vat"))
+	}
+	if row.NetAmount, err = parseOptionalAmount(field("net")); err != nil {
+		return nil, fmt.Errorf("base tributável inválida: %q", field("net"))
+	}
+	if row.NetAmount == 0 && row.TotalAmount != 0 {
+		row.NetAmount = row.TotalAmount - row.VATAmount
+	}
+	return &row, nil
+}
+
+func parseOptionalAmount(value string) (float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	return ParsePTAmount(value)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
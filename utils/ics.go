@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ICSEvent é um evento de dia inteiro de um calendário iCalendar (RFC 5545)
+type ICSEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Date         time.Time
+	Sequence     int
+	LastModified time.Time
+	Cancelled    bool
+	Reminders    []int // Dias de antecedência dos alarmes
+}
+
+// BuildICS gera um calendário iCalendar com os eventos indicados
+func BuildICS(name string, events []ICSEvent) []byte {
+	var b strings.Builder
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//RV Contabilidade//Prazos Fiscais//PT")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:"+escapeICSText(name))
+	writeICSLine(&b, "X-WR-TIMEZONE:Europe/Lisbon")
+
+	for _, event := range events {
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+event.UID)
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+event.Date.Format("20060102"))
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+event.Date.AddDate(0, 0, 1).Format("20060102"))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(event.Summary))
+		if event.Description != "" {
+			writeICSLine(&b, "DESCRIPTION:"+escapeICSText(event.Description))
+		}
+		writeICSLine(&b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		if !event.LastModified.IsZero() {
+			writeICSLine(&b, "LAST-MODIFIED:"+event.LastModified.UTC().Format("20060102T150405Z"))
+		}
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		if event.Cancelled {
+			writeICSLine(&b, "STATUS:CANCELLED")
+		} else {
+			writeICSLine(&b, "STATUS:CONFIRMED")
+			for _, days := range event.Reminders {
+				writeICSLine(&b, "BEGIN:VALARM")
+				writeICSLine(&b, "ACTION:DISPLAY")
+				writeICSLine(&b, "DESCRIPTION:"+escapeICSText(event.Summary))
+				writeICSLine(&b, fmt.Sprintf("TRIGGER:-P%dD", days))
+				writeICSLine(&b, "END:VALARM")
+			}
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// escapeICSText escapa os caracteres especiais de valores TEXT
+func escapeICSText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// writeICSLine escreve uma linha terminada em CRLF, dobrando-a a cada 75 octetos
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Não partir caracteres UTF-8 a meio
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // O espaço inicial da linha de continuação também conta
+	}
+	b.WriteString(line + "\r\n")
+}
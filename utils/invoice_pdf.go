@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// InvoicePDFParty é o emitente ou o adquirente de um documento
+type InvoicePDFParty struct {
+	Name       string
+	TaxID      string
+	Address    string
+	PostalCode string
+	City       string
+}
+
+// InvoicePDFLine é uma linha de um documento
+type InvoicePDFLine struct {
+	Description     string
+	Quantity        float64
+	UnitPrice       float64
+	TaxRate         float64
+	NetAmount       float64
+	ExemptionReason string
+}
+
+// InvoicePDFTax é o resumo de uma taxa de IVA
+type InvoicePDFTax struct {
+	Rate float64
+	Base float64
+	Tax  float64
+}
+
+// InvoicePDF reúne os dados impressos num documento de faturação
+type InvoicePDF struct {
+	Title      string // Fatura, Fatura-recibo, Fatura simplificada, Nota de crédito
+	DocumentNo string
+	ATCUD      string
+	IssueDate  string
+	DueDate    string
+	Issuer     InvoicePDFParty
+	Customer   InvoicePDFParty
+	Lines      []InvoicePDFLine
+	Taxes      []InvoicePDFTax
+	NetTotal   float64
+	TaxTotal   float64
+	GrossTotal float64
+	Reference  string // Documento de origem e motivo (notas de crédito)
+	QRCode     string // Conteúdo do código QR (Portaria n.º 195/2020)
+	Footer     string // Excerto do hash e número de certificação
+}
+
+// RenderInvoicePDF gera o PDF A4 de um documento de faturação com o código QR
+func RenderInvoicePDF(doc InvoicePDF) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 45)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-40)
+		if doc.QRCode != "" {
+			png, err := qrcode.Encode(doc.QRCode, qrcode.Medium, 256)
+			if err == nil {
+				pdf.RegisterImageOptionsReader("qrcode", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+				pdf.ImageOptions("qrcode", 15, pdf.GetY(), 30, 30, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+			}
+		}
+		pdf.SetXY(50, pdf.GetY()+20)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 4, tr(doc.Footer), "", 1, "L", false, 0, "")
+		pdf.SetX(50)
+		pdf.CellFormat(0, 4, tr(fmt.Sprintf("ATCUD: %s  |  Página %d/{nb}", doc.ATCUD, pdf.PageNo())), "", 0, "L", false, 0, "")
+	})
+	pdf.AliasNbPages("")
+	pdf.AddPage()
+
+	// Emitente
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(110, 6, tr(doc.Issuer.Name), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 6, tr(doc.Title), "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(110, 5, tr(doc.Issuer.Address), "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr(doc.DocumentNo), "", 1, "R", false, 0, "")
+	pdf.CellFormat(110, 5, tr(strings.TrimSpace(doc.Issuer.PostalCode+" "+doc.Issuer.City)), "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr("Data: "+doc.IssueDate), "", 1, "R", false, 0, "")
+	pdf.CellFormat(110, 5, tr("NIF: "+doc.Issuer.TaxID), "", 0, "L", false, 0, "")
+	if doc.DueDate != "" {
+		pdf.CellFormat(0, 5, tr("Vencimento: "+doc.DueDate), "", 0, "R", false, 0, "")
+	}
+	pdf.Ln(10)
+
+	// Adquirente
+	pdf.SetX(110)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 5, tr(doc.Customer.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	for _, text := range []string{doc.Customer.Address, strings.TrimSpace(doc.Customer.PostalCode + " " + doc.Customer.City), "NIF: " + doc.Customer.TaxID} {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		pdf.SetX(110)
+		pdf.CellFormat(0, 5, tr(text), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	if doc.Reference != "" {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.MultiCell(0, 5, tr(doc.Reference), "", "L", false)
+		pdf.Ln(2)
+	}
+
+	// Linhas
+	widths := []float64{85, 20, 25, 15, 35}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for i, header := range []string{"Descrição", "Qtd.", "Preço unit.", "IVA", "Valor"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, tr(header), "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range doc.Lines {
+		description := line.Description
+		if line.ExemptionReason != "" {
+			description += " (" + line.ExemptionReason + ")"
+		}
+		pdf.CellFormat(widths[0], 6, tr(truncatePDFText(description, 60)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, FormatPTNumber(line.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, FormatPTNumber(line.UnitPrice), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, fmt.Sprintf("%g%%", line.TaxRate), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 6, FormatPTNumber(line.NetAmount), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Resumo do IVA e totais
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(20, 6, "Taxa", "B", 0, "R", false, 0, "")
+	pdf.CellFormat(30, 6, tr("Incidência"), "B", 0, "R", false, 0, "")
+	pdf.CellFormat(30, 6, "IVA", "B", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	for _, tax := range doc.Taxes {
+		pdf.CellFormat(20, 6, fmt.Sprintf("%g%%", tax.Rate), "", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 6, FormatPTNumber(tax.Base), "", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 6, FormatPTNumber(tax.Tax), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	for _, total := range []struct {
+		label string
+		value float64
+		bold  bool
+	}{
+		{"Total ilíquido", doc.NetTotal, false},
+		{"Total IVA", doc.TaxTotal, false},
+		{"Total (EUR)", doc.GrossTotal, true},
+	} {
+		style := ""
+		if total.bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.SetX(120)
+		pdf.CellFormat(40, 6, tr(total.label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(35, 6, FormatPTNumber(total.value), "", 1, "R", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatPTNumber formata um valor com duas casas decimais, vírgula decimal e ponto nos milhares
+func FormatPTNumber(value float64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	text := fmt.Sprintf("%.2f", value)
+	integer, decimals := text[:len(text)-3], text[len(text)-2:]
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + "," + decimals
+}
+
+func truncatePDFText(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
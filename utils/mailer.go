@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EmailMessage representa um email a enviar
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string // Texto simples
+	HTML    string // Opcional
+}
+
+// Mailer é o transporte de email usado pela aplicação
+type Mailer interface {
+	Send(msg EmailMessage) error
+}
+
+var (
+	mailerMu      sync.Mutex
+	currentMailer Mailer
+)
+
+// GetMailer devolve o transporte configurado (MAIL_TRANSPORT=smtp|file, por omissão file)
+func GetMailer() Mailer {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+
+	if currentMailer == nil {
+		currentMailer = newMailerFromEnv()
+	}
+	return currentMailer
+}
+
+// SetMailer substitui o transporte de email (por exemplo, em testes)
+func SetMailer(m Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	currentMailer = m
+}
+
+func newMailerFromEnv() Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "RV Contabilidade <no-reply@rvcontabilidade.com>"
+	}
+
+	if strings.ToLower(os.Getenv("MAIL_TRANSPORT")) == "smtp" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	}
+
+	dir := os.Getenv("MAIL_OUTBOX_DIR")
+	if dir == "" {
+		dir = "mail_outbox"
+	}
+	return &FileMailer{Dir: dir, From: from}
+}
+
+// SMTPMailer envia emails através de um servidor SMTP
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send envia a mensagem por SMTP
+func (m *SMTPMailer) Send(msg EmailMessage) error {
+	if m.Host == "" {
+		return fmt.Errorf("SMTP_HOST não configurado")
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, extractAddress(m.From), []string{msg.To}, buildMIMEMessage(m.From, msg))
+}
+
+// FileMailer grava cada email num ficheiro .eml (para desenvolvimento)
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// Send grava a mensagem no diretório configurado
+func (m *FileMailer) Send(msg EmailMessage) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitizeFileName(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), buildMIMEMessage(m.From, msg), 0o644)
+}
+
+func buildMIMEMessage(from string, msg EmailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		b.WriteString(msg.Body)
+		return []byte(b.String())
+	}
+
+	boundary := "rv-" + GenerateRandomToken()
+	b.WriteString("Content-Type: multipart/alternative; boundary=" + boundary + "\r\n\r\n")
+	b.WriteString("--" + boundary + "\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Body + "\r\n")
+	b.WriteString("--" + boundary + "\r\nContent-Type: text/html; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.HTML + "\r\n")
+	b.WriteString("--" + boundary + "--\r\n")
+	return []byte(b.String())
+}
+
+func extractAddress(from string) string {
+	if start := strings.Index(from, "<"); start >= 0 {
+		if end := strings.Index(from[start:], ">"); end > 0 {
+			return from[start+1 : start+end]
+		}
+	}
+	return from
+}
+
+func sanitizeFileName(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, value)
+}
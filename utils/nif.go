@@ -0,0 +1,23 @@
+package utils
+
+// ValidNIF valida um NIF/NIPC português (9 dígitos com dígito de controlo módulo 11)
+func ValidNIF(nif string) bool {
+	if len(nif) != 9 {
+		return false
+	}
+	for _, r := range nif {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	sum := 0
+	for i := 0; i < 8; i++ {
+		sum += int(nif[i]-'0') * (9 - i)
+	}
+	check := 11 - sum%11
+	if check >= 10 {
+		check = 0
+	}
+	return int(nif[8]-'0') == check
+}
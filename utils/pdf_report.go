@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var ptMonths = []string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"}
+
+// PDFReport é o modelo de um relatório: cabeçalho com logótipo e título, secções e rodapé paginado
+type PDFReport struct {
+	Title    string
+	Subtitle string
+	Sections []PDFReportSection
+}
+
+// PDFReportSection é um bloco do relatório com campos (rótulo/valor), uma tabela e/ou texto livre
+type PDFReportSection struct {
+	Heading string
+	Fields  []PDFReportField
+	Table   *PDFReportTable
+	Text    string
+}
+
+// PDFReportField é um par rótulo/valor
+type PDFReportField struct {
+	Label string
+	Value string
+}
+
+// PDFReportTable é uma tabela com cabeçalho repetido em cada página e linha de totais opcional
+type PDFReportTable struct {
+	Columns []PDFReportColumn
+	Rows    [][]string
+	Totals  []string
+}
+
+// PDFReportColumn define o cabeçalho, a largura (mm) e o alinhamento (L, C, R) de uma coluna
+type PDFReportColumn struct {
+	Header string
+	Width  float64
+	Align  string
+}
+
+// RenderPDFReport escreve o relatório em PDF A4 diretamente no writer (por exemplo, a resposta HTTP).
+// O logótipo é lido de REPORT_LOGO_FILE (PNG ou JPEG) e o nome do gabinete de REPORT_ISSUER_NAME.
+func RenderPDFReport(w io.Writer, report PDFReport) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	generatedAt := FormatPTDateTime(time.Now())
+
+	logo := registerReportLogo(pdf)
+	issuer := os.Getenv("REPORT_ISSUER_NAME")
+	if issuer == "" {
+		issuer = "RV Contabilidade"
+	}
+
+	pdf.SetHeaderFunc(func() {
+		x := 15.0
+		if logo != "" {
+			pdf.ImageOptions(logo, 15, 10, 0, 14, false, gofpdf.ImageOptions{}, 0, "")
+			x = 50
+		}
+		pdf.SetXY(x, 10)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(0, 5, tr(issuer), "", 1, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetY(27)
+		pdf.Line(15, 26, 195, 26)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(90, 5, tr("Gerado em "+generatedAt), "T", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("Página %d/{nb}", pdf.PageNo())), "T", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.AliasNbPages("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.MultiCell(0, 8, tr(report.Title), "", "L", false)
+	if report.Subtitle != "" {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(report.Subtitle), "", "L", false)
+	}
+	pdf.Ln(4)
+
+	for _, section := range report.Sections {
+		renderReportSection(pdf, tr, section)
+	}
+
+	return pdf.Output(w)
+}
+
+func renderReportSection(pdf *gofpdf.Fpdf, tr func(string) string, section PDFReportSection) {
+	if section.Heading != "" {
+		// Evita títulos órfãos no fundo da página
+		_, pageHeight := pdf.GetPageSize()
+		if pdf.GetY() > pageHeight-45 {
+			pdf.AddPage()
+		}
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetFillColor(235, 235, 235)
+		pdf.CellFormat(0, 7, tr(section.Heading), "", 1, "L", true, 0, "")
+		pdf.Ln(1)
+	}
+
+	for _, field := range section.Fields {
+		value := field.Value
+		if strings.TrimSpace(value) == "" {
+			value = "-"
+		}
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(55, 5, tr(field.Label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 5, tr(value), "", "L", false)
+	}
+
+	if section.Table != nil {
+		renderReportTable(pdf, tr, section.Table)
+	}
+
+	if section.Text != "" {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 5, tr(section.Text), "", "L", false)
+	}
+	pdf.Ln(4)
+}
+
+func renderReportTable(pdf *gofpdf.Fpdf, tr func(string) string, table *PDFReportTable) {
+	const rowHeight = 6.0
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetFillColor(220, 220, 220)
+		for _, column := range table.Columns {
+			pdf.CellFormat(column.Width, rowHeight, tr(column.Header), "B", 0, columnAlign(column), true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 8)
+	}
+
+	row := func(values []string, border string) {
+		if pdf.GetY()+rowHeight > pageHeight-bottom {
+			pdf.AddPage()
+			header()
+		}
+		for i, column := range table.Columns {
+			value := ""
+			if i < len(values) {
+				value = values[i]
+			}
+			pdf.CellFormat(column.Width, rowHeight, fitPDFText(pdf, tr(value), column.Width-2), border, 0, columnAlign(column), false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	header()
+	if len(table.Rows) == 0 {
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, rowHeight, tr("Sem registos"), "", 1, "L", false, 0, "")
+		return
+	}
+	for _, values := range table.Rows {
+		row(values, "")
+	}
+	if len(table.Totals) > 0 {
+		pdf.SetFont("Helvetica", "B", 8)
+		row(table.Totals, "T")
+	}
+}
+
+// fitPDFText corta o texto (já convertido para a codificação do PDF) para caber na largura indicada
+func fitPDFText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+func columnAlign(column PDFReportColumn) string {
+	if column.Align == "" {
+		return "L"
+	}
+	return column.Align
+}
+
+// registerReportLogo regista o logótipo configurado e devolve o nome da imagem (vazio se não houver)
+func registerReportLogo(pdf *gofpdf.Fpdf) string {
+	path := os.Getenv("REPORT_LOGO_FILE")
+	if path == "" {
+		return ""
+	}
+
+	imageType := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if imageType == "jpeg" {
+		imageType = "jpg"
+	}
+	if imageType != "png" && imageType != "jpg" {
+		log.Printf("Logótipo dos relatórios ignorado (formato não suportado): %s", path)
+		return ""
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Erro ao abrir logótipo dos relatórios: %v", err)
+		return ""
+	}
+	defer file.Close()
+
+	pdf.RegisterImageOptionsReader("logo", gofpdf.ImageOptions{ImageType: imageType}, file)
+	if pdf.Err() {
+		log.Printf("Erro ao ler logótipo dos relatórios: %v", pdf.Error())
+		pdf.ClearError()
+		return ""
+	}
+	return "logo"
+}
+
+// FormatPTDate formata uma data como DD/MM/AAAA
+func FormatPTDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format("02/01/2006")
+}
+
+// FormatPTDateTime formata uma data e hora como DD/MM/AAAA HH:MM
+func FormatPTDateTime(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format("02/01/2006 15:04")
+}
+
+// FormatPTPeriod escreve um período por extenso: "2025-03" → "março de 2025", "2025-T1" → "1.º trimestre de 2025"
+func FormatPTPeriod(period string) string {
+	if date, err := time.Parse("2006-01", period); err == nil {
+		return fmt.Sprintf("%s de %d", ptMonths[date.Month()-1], date.Year())
+	}
+	if parts := strings.Split(period, "-T"); len(parts) == 2 {
+		if quarter, err := strconv.Atoi(parts[1]); err == nil && quarter >= 1 && quarter <= 4 {
+			return fmt.Sprintf("%d.º trimestre de %s", quarter, parts[0])
+		}
+	}
+	return period
+}
+
+// FormatPTBool escreve um booleano como Sim/Não
+func FormatPTBool(value bool) string {
+	if value {
+		return "Sim"
+	}
+	return "Não"
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// SAFTHeader é o cabeçalho de um ficheiro SAF-T (PT)
+type SAFTHeader struct {
+	AuditFileVersion          string `xml:"AuditFileVersion"`
+	CompanyID                 string `xml:"CompanyID"`
+	TaxRegistrationNumber     string `xml:"TaxRegistrationNumber"`
+	TaxAccountingBasis        string `xml:"TaxAccountingBasis"`
+	CompanyName               string `xml:"CompanyName"`
+	FiscalYear                string `xml:"FiscalYear"`
+	StartDate                 string `xml:"StartDate"`
+	EndDate                   string `xml:"EndDate"`
+	CurrencyCode              string `xml:"CurrencyCode"`
+	ProductID                 string `xml:"ProductID"`
+	ProductVersion            string `xml:"ProductVersion"`
+	SoftwareCertificateNumber string `xml:"SoftwareCertificateNumber"`
+}
+
+// SAFTCustomer é um cliente da tabela MasterFiles/Customer
+type SAFTCustomer struct {
+	CustomerID    string `xml:"CustomerID"`
+	CustomerTaxID string `xml:"CustomerTaxID"`
+	CompanyName   string `xml:"CompanyName"`
+}
+
+// SAFTTax é o imposto aplicado a uma linha
+type SAFTTax struct {
+	TaxType          string  `xml:"TaxType"`
+	TaxCountryRegion string  `xml:"TaxCountryRegion"`
+	TaxCode          string  `xml:"TaxCode"`
+	TaxPercentage    float64 `xml:"TaxPercentage"`
+}
+
+// SAFTLine é uma linha de um documento de faturação
+type SAFTLine struct {
+	LineNumber   int     `xml:"LineNumber"`
+	Quantity     float64 `xml:"Quantity"`
+	UnitPrice    float64 `xml:"UnitPrice"`
+	DebitAmount  float64 `xml:"DebitAmount"`
+	CreditAmount float64 `xml:"CreditAmount"`
+	Tax          SAFTTax `xml:"Tax"`
+}
+
+// SAFTInvoice é um documento de SourceDocuments/SalesInvoices
+type SAFTInvoice struct {
+	InvoiceNo      string `xml:"InvoiceNo"`
+	ATCUD          string `xml:"ATCUD"`
+	DocumentStatus struct {
+		InvoiceStatus string `xml:"InvoiceStatus"`
+	} `xml:"DocumentStatus"`
+	Hash            string     `xml:"Hash"`
+	HashControl     string     `xml:"HashControl"`
+	Period          string     `xml:"Period"`
+	InvoiceDate     string     `xml:"InvoiceDate"`
+	InvoiceType     string     `xml:"InvoiceType"`
+	SystemEntryDate string     `xml:"SystemEntryDate"`
+	CustomerID      string     `xml:"CustomerID"`
+	Lines           []SAFTLine `xml:"Line"`
+	DocumentTotals  struct {
+		TaxPayable float64 `xml:"TaxPayable"`
+		NetTotal   float64 `xml:"NetTotal"`
+		GrossTotal float64 `xml:"GrossTotal"`
+	} `xml:"DocumentTotals"`
+}
+
+// SAFTVisitor recebe os elementos do ficheiro à medida que são lidos.
+// O número de linha indica onde o elemento termina no ficheiro.
+type SAFTVisitor struct {
+	OnHeader   func(header *SAFTHeader, line int) error
+	OnCustomer func(customer *SAFTCustomer, line int)
+	OnInvoice  func(invoice *SAFTInvoice, line int)
+}
+
+// ParseSAFT lê um ficheiro SAF-T (PT) em streaming, sem o carregar todo em memória.
+// Apenas o cabeçalho, os clientes e os documentos de faturação são descodificados.
+func ParseSAFT(r io.Reader, visitor SAFTVisitor) error {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = saftCharsetReader
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			line, _ := decoder.InputPos()
+			return fmt.Errorf("XML inválido na linha %d: %v", line, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "Header":
+			var header SAFTHeader
+			if err := decoder.DecodeElement(&header, &start); err != nil {
+				line, _ := decoder.InputPos()
+				return fmt.Errorf("cabeçalho inválido na linha %d: %v", line, err)
+			}
+			if visitor.OnHeader != nil {
+				line, _ := decoder.InputPos()
+				if err := visitor.OnHeader(&header, line); err != nil {
+					return err
+				}
+			}
+		case "Customer":
+			var customer SAFTCustomer
+			if err := decoder.DecodeElement(&customer, &start); err != nil {
+				line, _ := decoder.InputPos()
+				return fmt.Errorf("cliente inválido na linha %d: %v", line, err)
+			}
+			if visitor.OnCustomer != nil {
+				line, _ := decoder.InputPos()
+				visitor.OnCustomer(&customer, line)
+			}
+		case "Invoice":
+			var invoice SAFTInvoice
+			if err := decoder.DecodeElement(&invoice, &start); err != nil {
+				line, _ := decoder.InputPos()
+				return fmt.Errorf("documento inválido na linha %d: %v", line, err)
+			}
+			if visitor.OnInvoice != nil {
+				line, _ := decoder.InputPos()
+				visitor.OnInvoice(&invoice, line)
+			}
+		}
+	}
+}
+
+// SplitSAFTDocumentNo separa "FT A/123" em série ("FT A") e número (123)
+func SplitSAFTDocumentNo(documentNo string) (string, int, bool) {
+	slash := strings.LastIndex(documentNo, "/")
+	if slash <= 0 || slash == len(documentNo)-1 {
+		return "", 0, false
+	}
+
+	number := 0
+	for _, r := range documentNo[slash+1:] {
+		if r < '0' || r > '9' {
+			return "", 0, false
+		}
+		number = number*10 + int(r-'0')
+	}
+	return documentNo[:slash], number, true
+}
+
+// saftCharsetReader aceita ficheiros em Windows-1252/ISO-8859-1, permitidos pela AT
+func saftCharsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "windows-1252", "cp1252":
+		return charmap.Windows1252.NewDecoder().Reader(input), nil
+	case "iso-8859-1", "latin1":
+		return charmap.ISO8859_1.NewDecoder().Reader(input), nil
+	case "iso-8859-15":
+		return charmap.ISO8859_15.NewDecoder().Reader(input), nil
+	}
+	return nil, fmt.Errorf("codificação não suportada: %s", label)
+}
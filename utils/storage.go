@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Storage guarda ficheiros enviados pelos clientes (documentos, extratos, etc.)
+type Storage interface {
+	Save(key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+var (
+	storageMu      sync.Mutex
+	currentStorage Storage
+)
+
+// GetStorage devolve o armazenamento configurado (STORAGE_DRIVER=local, por omissão local)
+func GetStorage() Storage {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+
+	if currentStorage == nil {
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		currentStorage = &LocalStorage{Dir: dir}
+	}
+	return currentStorage
+}
+
+// SetStorage substitui o armazenamento (por exemplo, por um compatível com S3)
+func SetStorage(s Storage) {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	currentStorage = s
+}
+
+// LocalStorage guarda os ficheiros num diretório local
+type LocalStorage struct {
+	Dir string
+}
+
+// Save grava o conteúdo na chave indicada
+func (s *LocalStorage) Save(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
+// Open abre o ficheiro guardado na chave indicada
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete remove o ficheiro guardado na chave indicada
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path converte a chave num caminho dentro do diretório, recusando chaves que saiam dele
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", errors.New("chave de armazenamento inválida")
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
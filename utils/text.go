@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Sufixos de forma jurídica ignorados na comparação de nomes de empresas
+var companyNameStopWords = map[string]bool{
+	"lda": true, "limitada": true, "unipessoal": true, "sa": true, "s": true, "a": true,
+	"sociedade": true, "anonima": true, "por": true, "quotas": true, "e": true,
+	"de": true, "da": true, "do": true, "das": true, "dos": true, "eireli": true,
+}
+
+// RemoveAccents remove acentos e cedilhas de uma string
+func RemoveAccents(value string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, value)
+	if err != nil {
+		return value
+	}
+	return result
+}
+
+// NormalizeCompanyName normaliza o nome de uma empresa para comparação aproximada
+// (minúsculas, sem acentos, sem pontuação e sem sufixos como "Lda" ou "Unipessoal")
+func NormalizeCompanyName(name string) string {
+	name = strings.ToLower(RemoveAccents(name))
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var words []string
+	for _, field := range fields {
+		if !companyNameStopWords[field] {
+			words = append(words, field)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizePhone devolve os últimos 9 dígitos de um número de telefone
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+	result := digits.String()
+	if len(result) > 9 {
+		result = result[len(result)-9:]
+	}
+	return result
+}
+
+// NormalizeIBAN remove espaços e converte o IBAN para maiúsculas
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(iban), " ", ""))
+}
+
+// Similarity devolve a semelhança entre duas strings (0 a 1) com base na distância de Levenshtein
+func Similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}